@@ -74,7 +74,11 @@ func startBackgroundServices() {
 	service.GlobalHub.Start()
 	service.StartAutoSync()
 	service.StartStatusChecks()
+	service.StartSNMPTrapReceiver()
+	service.StartEscalationScheduler()
+	service.StartNotificationWorkers()
 	service.InitQQWSServ()
+	service.StartTelegramBot()
 	mcp.InitClients()
 
 	if err := service.InitCronScheduler(); err != nil {
@@ -12,6 +12,7 @@ func setupAIDomainRoutes(rg *gin.RouterGroup) {
 	setupKnowledgeBaseRoutes(rg)
 	setupChatRoutes(rg)
 	setupConsultRoutes(rg)
+	setupLLMUsageRoutes(rg)
 	setupMCPServersRoutes(rg)
 }
 
@@ -24,6 +25,14 @@ func setupChatRoutes(rg *gin.RouterGroup) {
 	chats := rg.Group("/chats", api.PrivilegesMiddleware(1))
 	chats.GET("", api.SearchChatsCtrl)
 	chats.POST("", api.SendChatCtrl)
+	chats.POST("/stream", api.StreamChatCtrl)
+
+	conversations := rg.Group("/conversations", api.PrivilegesMiddleware(1))
+	conversations.GET("", api.SearchConversationsCtrl)
+	conversations.POST("", api.AddConversationCtrl)
+	conversations.GET("/:id", api.GetConversationCtrl)
+	conversations.PUT("/:id", api.UpdateConversationCtrl)
+	conversations.DELETE("/:id", api.DeleteConversationCtrl)
 }
 
 func setupKnowledgeBaseRoutes(rg *gin.RouterGroup) {
@@ -38,6 +47,7 @@ func setupKnowledgeBaseRoutes(rg *gin.RouterGroup) {
 func setupProviderRoutes(rg *gin.RouterGroup) {
 	providersRead := rg.Group("/providers", api.PrivilegesMiddleware(1))
 	providersRead.GET("", api.SearchProvidersCtrl)
+	providersRead.GET("/circuits", api.GetProviderCircuitsCtrl)
 	providersRead.GET("/:id", api.GetProviderByIDCtrl)
 
 	providersWrite := rg.Group("/providers", api.PrivilegesMiddleware(2))
@@ -66,6 +76,13 @@ func setupConsultRoutes(rg *gin.RouterGroup) {
 	}
 }
 
+func setupLLMUsageRoutes(rg *gin.RouterGroup) {
+	usage := rg.Group("/usage", api.PrivilegesMiddleware(1))
+	usage.GET("", api.SearchLLMUsageCtrl)
+	usage.GET("/summary", api.SummarizeLLMUsageCtrl)
+	usage.GET("/budgets", api.GetLLMBudgetsCtrl)
+}
+
 func setupMCPServersRoutes(rg *gin.RouterGroup) {
 	group := rg.Group("/mcp-servers", api.PrivilegesMiddleware(2))
 	group.GET("", api.ListMCPServersCtrl)
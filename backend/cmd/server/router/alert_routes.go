@@ -10,6 +10,9 @@ func setupAlertDomainRoutes(rg *gin.RouterGroup) {
 	setupAlarmRoutes(rg)
 	setupAlertRoutes(rg)
 	setupTriggerRoutes(rg)
+	setupIncidentRoutes(rg)
+	setupEscalationPolicyRoutes(rg)
+	setupSilenceRoutes(rg)
 }
 
 func setupAlarmRoutes(rg *gin.RouterGroup) {
@@ -23,6 +26,9 @@ func setupAlarmRoutes(rg *gin.RouterGroup) {
 	alarms.POST("/:id/sessions", api.PrivilegesMiddleware(2), api.LoginAlarmCtrl)
 	alarms.POST("/:id/media-bindings", api.PrivilegesMiddleware(2), api.SetupAlarmMediaCtrl)
 	alarms.POST("/:id/event-tokens", api.PrivilegesMiddleware(2), api.RegenerateAlarmEventTokenCtrl)
+	alarms.POST("/:id/mapping-tests", api.PrivilegesMiddleware(2), api.TestAlarmPayloadMappingCtrl)
+	alarms.POST("/:id/signing-secrets", api.PrivilegesMiddleware(2), api.GenerateAlarmSigningSecretCtrl)
+	alarms.DELETE("/:id/signing-secrets", api.PrivilegesMiddleware(2), api.DisableAlarmSigningCtrl)
 }
 
 func setupTriggerRoutes(rg *gin.RouterGroup) {
@@ -35,6 +41,42 @@ func setupTriggerRoutes(rg *gin.RouterGroup) {
 	triggersPrivileged.DELETE("/:id", api.DeleteTriggerByIDCtrl)
 }
 
+func setupIncidentRoutes(rg *gin.RouterGroup) {
+	incidentsRead := rg.Group("/incidents", api.PrivilegesMiddleware(1))
+	incidentsRead.GET("", api.SearchIncidentsCtrl)
+	incidentsRead.GET("/:id", api.GetIncidentByIDCtrl)
+
+	incidentsWrite := rg.Group("/incidents", api.PrivilegesMiddleware(2))
+	incidentsWrite.POST("", api.AddIncidentCtrl)
+	incidentsWrite.PUT("/:id", api.UpdateIncidentCtrl)
+	incidentsWrite.DELETE("/:id", api.DeleteIncidentByIDCtrl)
+	incidentsWrite.POST("/:id/notes", api.AddIncidentNoteCtrl)
+	incidentsWrite.POST("/:id/alerts", api.AttachIncidentAlertsCtrl)
+}
+
+func setupEscalationPolicyRoutes(rg *gin.RouterGroup) {
+	policiesRead := rg.Group("/escalation-policies", api.PrivilegesMiddleware(1))
+	policiesRead.GET("", api.SearchEscalationPoliciesCtrl)
+	policiesRead.GET("/:id", api.GetEscalationPolicyByIDCtrl)
+
+	policiesWrite := rg.Group("/escalation-policies", api.PrivilegesMiddleware(2))
+	policiesWrite.POST("", api.AddEscalationPolicyCtrl)
+	policiesWrite.PUT("/:id", api.UpdateEscalationPolicyCtrl)
+	policiesWrite.DELETE("/:id", api.DeleteEscalationPolicyByIDCtrl)
+}
+
+func setupSilenceRoutes(rg *gin.RouterGroup) {
+	silencesRead := rg.Group("/silences", api.PrivilegesMiddleware(1))
+	silencesRead.GET("", api.SearchSilencesCtrl)
+	silencesRead.GET("/:id", api.GetSilenceByIDCtrl)
+
+	silencesWrite := rg.Group("/silences", api.PrivilegesMiddleware(2))
+	silencesWrite.POST("", api.AddSilenceCtrl)
+	silencesWrite.PUT("/:id", api.UpdateSilenceCtrl)
+	silencesWrite.DELETE("/:id", api.DeleteSilenceByIDCtrl)
+	silencesWrite.POST("/:id/expirations", api.ExpireSilenceCtrl)
+}
+
 func setupAlertRoutes(rg *gin.RouterGroup) {
 	webhooks := rg.Group("/webhooks")
 	webhooks.POST("", api.AlertWebhookCtrl)
@@ -44,11 +86,13 @@ func setupAlertRoutes(rg *gin.RouterGroup) {
 	alertsRead.GET("", api.SearchAlertsCtrl)
 	alertsRead.GET("/:id", api.GetAlertByIDCtrl)
 	alertsRead.GET("/scores", api.GetAlertScoreCtrl)
+	alertsRead.GET("/:id/escalations", api.GetAlertEscalationCtrl)
 
 	alertsWrite := rg.Group("/alerts", api.PrivilegesMiddleware(2))
 	alertsWrite.POST("", api.AddAlertCtrl)
 	alertsWrite.DELETE("/:id", api.DeleteAlertByIDCtrl)
 	alertsWrite.PUT("/:id", api.UpdateAlertCtrl)
+	alertsWrite.DELETE("/:id/escalations", api.CancelAlertEscalationCtrl)
 
 	testAlerts := rg.Group("/test-alerts", api.PrivilegesMiddleware(2))
 	testAlerts.POST("", api.GenerateTestAlertsCtrl)
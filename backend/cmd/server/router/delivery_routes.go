@@ -10,6 +10,8 @@ func setupDeliveryDomainRoutes(rg *gin.RouterGroup) {
 	setupActionRoutes(rg)
 	setupMediaRoutes(rg)
 	setupSiteMessageRoutes(rg)
+	setupOnCallRoutes(rg)
+	setupNotificationDeliveryRoutes(rg)
 }
 
 func setupActionRoutes(rg *gin.RouterGroup) {
@@ -18,6 +20,7 @@ func setupActionRoutes(rg *gin.RouterGroup) {
 	actionsPrivileged.GET("", api.SearchActionsCtrl)
 	actionsPrivileged.GET("/:id", api.GetActionByIDCtrl)
 	actionsPrivileged.POST("", api.AddActionCtrl)
+	actionsPrivileged.POST("/message-previews", api.PreviewActionMessageCtrl)
 	actionsPrivileged.PUT("/:id", api.UpdateActionCtrl)
 	actionsPrivileged.DELETE("/:id", api.DeleteActionByIDCtrl)
 	actionsPrivileged.POST("/:id/test-runs", api.TestActionCtrl)
@@ -27,6 +30,7 @@ func setupMediaRoutes(rg *gin.RouterGroup) {
 	media := rg.Group("/media")
 	media.POST("/qq/messages", api.HandleQQMessageCtrl)
 	media.GET("/qq/socket-sessions", api.HandleQQWebSocket)
+	media.POST("/telegram/updates", api.HandleTelegramUpdateCtrl)
 
 	mediaPrivileged := rg.Group("/media", api.PrivilegesMiddleware(2))
 	mediaPrivileged.GET("", api.SearchMediaCtrl)
@@ -48,3 +52,33 @@ func setupSiteMessageRoutes(rg *gin.RouterGroup) {
 	msgProtected.PUT("/read-status", api.MarkAllSiteMessagesAsReadCtrl)
 	msgProtected.DELETE("/:id", api.DeleteSiteMessageCtrl)
 }
+
+func setupOnCallRoutes(rg *gin.RouterGroup) {
+	rg.GET("/on-call", api.PrivilegesMiddleware(1), api.GetAllOnCallCtrl)
+
+	schedules := rg.Group("/on-call-schedules")
+	schedulesRead := schedules.Group("", api.PrivilegesMiddleware(1))
+	schedulesRead.GET("", api.SearchOnCallSchedulesCtrl)
+	schedulesRead.GET("/:id", api.GetOnCallScheduleByIDCtrl)
+	schedulesRead.GET("/:id/on-call", api.GetOnCallCtrl)
+	schedulesRead.GET("/:id/overrides", api.GetOnCallOverridesCtrl)
+
+	schedulesWrite := schedules.Group("", api.PrivilegesMiddleware(2))
+	schedulesWrite.POST("", api.AddOnCallScheduleCtrl)
+	schedulesWrite.PUT("/:id", api.UpdateOnCallScheduleCtrl)
+	schedulesWrite.DELETE("/:id", api.DeleteOnCallScheduleByIDCtrl)
+	schedulesWrite.POST("/:id/overrides", api.AddOnCallOverrideCtrl)
+	schedulesWrite.DELETE("/:id/overrides/:override_id", api.DeleteOnCallOverrideCtrl)
+}
+
+func setupNotificationDeliveryRoutes(rg *gin.RouterGroup) {
+	// Deliveries and digests hold message bodies for every recipient, so only administrators may read or resend them
+	deliveries := rg.Group("/notification-deliveries", api.PrivilegesMiddleware(3))
+	deliveries.GET("", api.SearchNotificationDeliveriesCtrl)
+	deliveries.GET("/:id", api.GetNotificationDeliveryByIDCtrl)
+	deliveries.POST("/:id/retries", api.RetryNotificationDeliveryCtrl)
+
+	digests := rg.Group("/notification-digests", api.PrivilegesMiddleware(3))
+	digests.GET("", api.SearchNotificationDigestsCtrl)
+	digests.GET("/:id", api.GetNotificationDigestByIDCtrl)
+}
@@ -7,6 +7,7 @@ require (
 	github.com/gin-gonic/gin v1.11.0
 	github.com/golang-jwt/jwt/v5 v5.3.1
 	github.com/gorilla/websocket v1.5.3
+	github.com/gosnmp/gosnmp v1.42.1
 	github.com/johnfercher/maroto/v2 v2.3.3
 	github.com/json-iterator/go v1.1.12
 	github.com/mark3labs/mcp-go v0.45.0
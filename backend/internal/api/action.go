@@ -137,3 +137,18 @@ func TestActionCtrl(c *gin.Context) {
 	}
 	respondSuccessMessage(c, http.StatusOK, "test action executed")
 }
+
+// PreviewActionMessageCtrl handles POST /delivery/actions/message-previews
+func PreviewActionMessageCtrl(c *gin.Context) {
+	var req service.MessagePreviewReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	preview, err := service.PreviewActionMessageServ(req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, preview)
+}
@@ -236,3 +236,58 @@ func RegenerateAlarmEventTokenCtrl(c *gin.Context) {
 	}
 	respondSuccess(c, http.StatusOK, alarm)
 }
+
+// TestAlarmPayloadMappingCtrl handles POST /alert/alarms/:id/mapping-tests
+// Shows the alert a sample payload would produce without persisting it
+func TestAlarmPayloadMappingCtrl(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "invalid alarm ID")
+		return
+	}
+
+	var req service.AlarmMappingTestReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	result, err := service.TestAlarmPayloadMappingServ(uint(id), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, result)
+}
+
+// GenerateAlarmSigningSecretCtrl handles POST /alert/alarms/:id/signing-secrets
+// The new secret is returned once; senders sign "<timestamp>.<body>" with HMAC-SHA256
+func GenerateAlarmSigningSecretCtrl(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "invalid alarm ID")
+		return
+	}
+
+	alarm, err := service.GenerateAlarmSigningSecretServ(uint(id))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, alarm)
+}
+
+// DisableAlarmSigningCtrl handles DELETE /alert/alarms/:id/signing-secrets
+func DisableAlarmSigningCtrl(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "invalid alarm ID")
+		return
+	}
+
+	if err := service.DisableAlarmSigningServ(uint(id)); err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccessMessage(c, http.StatusOK, "webhook signing disabled")
+}
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"regexp"
 	"strconv"
@@ -43,8 +45,14 @@ func AlertWebhookCtrl(c *gin.Context) {
 		"user_agent":  c.GetHeader("User-Agent"),
 	}, nil, "")
 
+	body, err := c.GetRawData()
+	if err != nil {
+		service.LogService("error", "webhook failed to read body", map[string]interface{}{"error": err.Error()}, nil, "")
+		respondBadRequest(c, err.Error())
+		return
+	}
 	var payload map[string]interface{}
-	if err := c.ShouldBindJSON(&payload); err != nil {
+	if err := json.Unmarshal(body, &payload); err != nil {
 		service.LogService("error", "webhook invalid JSON", map[string]interface{}{"error": err.Error()}, nil, "")
 		respondBadRequest(c, err.Error())
 		return
@@ -70,6 +78,30 @@ func AlertWebhookCtrl(c *gin.Context) {
 		return
 	}
 
+	if err := service.VerifyAlarmWebhookSignatureServ(alarmID, body, c.GetHeader(service.AlarmSignatureHeader), c.GetHeader(service.AlarmTimestampHeader)); err != nil {
+		service.LogService("warn", "webhook signature rejected", map[string]interface{}{"alarm_id": alarmID, "error": err.Error()}, nil, c.ClientIP())
+		respondError(c, err)
+		auditWebhookRejection(c, alarmID, err)
+		return
+	}
+
+	if alarmID > 0 {
+		alarm, err := service.GetAlarmByIDServ(alarmID)
+		if err != nil {
+			service.LogService("error", "webhook alarm lookup failed", map[string]interface{}{"alarm_id": alarmID, "error": err.Error()}, nil, "")
+			respondError(c, err)
+			return
+		}
+		if alarm.Type == service.AlarmTypeAlertmanager {
+			processAlertmanagerWebhook(c, alarmID, body)
+			return
+		}
+		if alarm.PayloadMapping != nil {
+			processMappedWebhook(c, alarmID, *alarm.PayloadMapping, payload)
+			return
+		}
+	}
+
 	req, hostID, status, err := parseWebhookPayload(payload, alarmID)
 	if err != nil {
 		service.LogService("warn", "webhook missing message", map[string]interface{}{"payload_keys": getMapKeys(payload)}, nil, "")
@@ -89,6 +121,23 @@ func AlertWebhookCtrl(c *gin.Context) {
 	processWebhookAlert(c, req, status)
 }
 
+// auditWebhookRejection records a rejected webhook after the response is written, so the logged status is
+// the one the sender received; the request is unauthenticated so no user is attached
+func auditWebhookRejection(c *gin.Context, alarmID uint, reason error) {
+	entry := model.AuditLog{
+		Username:  fmt.Sprintf("alarm:%d", alarmID),
+		Action:    "Reject webhook: " + reason.Error(),
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		IP:        c.ClientIP(),
+		Status:    c.Writer.Status(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	go func(e model.AuditLog) {
+		_ = service.AddAuditLogServ(e)
+	}(entry)
+}
+
 func extractWebhookToken(c *gin.Context, payload map[string]interface{}) string {
 	eventToken := strings.TrimSpace(c.GetHeader("X-Alarm-Token"))
 	if eventToken == "" {
@@ -268,6 +317,37 @@ func processWebhookAlert(c *gin.Context, req service.AlertReq, status int) {
 	respondSuccessMessage(c, http.StatusAccepted, "alert accepted")
 }
 
+// processAlertmanagerWebhook handles the grouped Alertmanager v4 payload, one alert per element of alerts[]
+func processAlertmanagerWebhook(c *gin.Context, alarmID uint, body []byte) {
+	var payload service.AlertmanagerWebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		service.LogService("error", "alertmanager webhook invalid payload", map[string]interface{}{"alarm_id": alarmID, "error": err.Error()}, nil, "")
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	result, err := service.IngestAlertmanagerWebhookServ(alarmID, payload)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusAccepted, result)
+}
+
+// processMappedWebhook builds the alert from the alarm's declarative payload mapping instead of guessing field names
+func processMappedWebhook(c *gin.Context, alarmID uint, mapping service.AlarmPayloadMapping, payload map[string]interface{}) {
+	result, err := service.ApplyAlarmPayloadMappingServ(mapping, payload, alarmID)
+	if err != nil {
+		service.LogService("warn", "webhook payload mapping failed", map[string]interface{}{"alarm_id": alarmID, "error": err.Error()}, nil, "")
+		respondError(c, err)
+		return
+	}
+	if len(result.Warnings) > 0 {
+		service.LogService("debug", "webhook payload mapping warnings", map[string]interface{}{"alarm_id": alarmID, "warnings": result.Warnings}, nil, "")
+	}
+	processWebhookAlert(c, result.Alert, result.Alert.Status)
+}
+
 func getMapKeys(m map[string]interface{}) []string {
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -519,17 +599,29 @@ func SearchAlertsCtrl(c *gin.Context) {
 		respondBadRequest(c, "invalid alarm_id")
 		return
 	}
+	incidentID, err := parseOptionalInt(c, "incident_id")
+	if err != nil {
+		respondBadRequest(c, "invalid incident_id")
+		return
+	}
+	suppressed, err := parseOptionalBool(c, "suppressed")
+	if err != nil {
+		respondBadRequest(c, "invalid suppressed")
+		return
+	}
 	filter := model.AlertFilter{
-		Query:     c.Query("q"),
-		Severity:  severity,
-		Status:    status,
-		AlarmID:   alarmID,
-		HostID:    hostID,
-		ItemID:    itemID,
-		Limit:     limit,
-		Offset:    offset,
-		SortBy:    c.Query("sort"),
-		SortOrder: c.Query("order"),
+		Query:      c.Query("q"),
+		Severity:   severity,
+		Status:     status,
+		AlarmID:    alarmID,
+		HostID:     hostID,
+		ItemID:     itemID,
+		IncidentID: incidentID,
+		Suppressed: suppressed,
+		Limit:      limit,
+		Offset:     offset,
+		SortBy:     c.Query("sort"),
+		SortOrder:  c.Query("order"),
 	}
 	alerts, err := service.SearchAlertsServ(filter)
 	if err != nil {
@@ -75,24 +75,26 @@ func GetAlertAnalyticsCtrl(c *gin.Context) {
 
 	// Get Severity Distribution
 	type sevResult struct {
-		Severity int `json:"severity"`
-		Count    int `json:"count"`
+		Severity    int `json:"severity"`
+		Count       int `json:"count"`
+		Occurrences int `json:"occurrences"`
 	}
 	var sevResults []sevResult
 	database.DB.Model(&model.Alert{}).
-		Select("severity, count(*) as count").
+		Select("severity, count(*) as count, COALESCE(SUM(occurrence_count), 0) as occurrences").
 		Group("severity").
 		Scan(&sevResults)
 
 	// Get Top Noisy Hosts
 	type hostResult struct {
-		HostID uint   `json:"host_id"`
-		Count  int    `json:"count"`
-		Name   string `json:"name"`
+		HostID      uint   `json:"host_id"`
+		Count       int    `json:"count"`
+		Occurrences int    `json:"occurrences"`
+		Name        string `json:"name"`
 	}
 	var hostResults []hostResult
 	database.DB.Table("alerts").
-		Select("items.host_id, count(alerts.id) as count, hosts.name").
+		Select("items.host_id, count(alerts.id) as count, COALESCE(SUM(alerts.occurrence_count), 0) as occurrences, hosts.name").
 		Joins("left join items on items.id = alerts.item_id").
 		Joins("left join hosts on hosts.id = items.host_id").
 		Where("items.host_id > 0").
@@ -111,10 +113,30 @@ func GetAlertAnalyticsCtrl(c *gin.Context) {
 		Order("date").
 		Scan(&trendResults)
 
+	// Get Most Repeated Alerts (deduplicated deliveries)
+	type repeatedResult struct {
+		ID              uint       `json:"id"`
+		Message         string     `json:"message"`
+		Severity        int        `json:"severity"`
+		Status          int        `json:"status"`
+		OccurrenceCount int        `json:"occurrence_count"`
+		LastSeenAt      *time.Time `json:"last_seen_at"`
+	}
+	var repeatedResults []repeatedResult
+	database.DB.Model(&model.Alert{}).
+		Select("id, message, severity, status, occurrence_count, last_seen_at").
+		Where("occurrence_count > 1").
+		Order("occurrence_count desc").
+		Limit(10).
+		Scan(&repeatedResults)
+
 	// Summary Stats
 	var totalAlerts int64
 	database.DB.Model(&model.Alert{}).Count(&totalAlerts)
 
+	var totalOccurrences int64
+	database.DB.Model(&model.Alert{}).Select("COALESCE(SUM(occurrence_count), 0)").Scan(&totalOccurrences)
+
 	healthScore, _ := service.GetHealthScoreServ()
 
 	var activeHosts int64
@@ -126,10 +148,12 @@ func GetAlertAnalyticsCtrl(c *gin.Context) {
 		"severityDist": sevResults,
 		"topHosts":     hostResults,
 		"trend":        trendResults,
+		"topRepeated":  repeatedResults,
 		"summary": gin.H{
-			"totalAlerts":  totalAlerts,
-			"systemHealth": healthScore.Score,
-			"activeHosts":  activeHosts,
+			"totalAlerts":      totalAlerts,
+			"totalOccurrences": totalOccurrences,
+			"systemHealth":     healthScore.Score,
+			"activeHosts":      activeHosts,
 		},
 	})
 }
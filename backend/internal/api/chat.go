@@ -18,11 +18,8 @@ func SendChatCtrl(c *gin.Context) {
 		respondBadRequest(c, err.Error())
 		return
 	}
-	if val, ok := c.Get("privileges"); ok {
-		if privileges, ok := val.(int); ok {
-			chatReq.Privileges = privileges
-		}
-	}
+	chatReq.Privileges = getRequesterPrivileges(c)
+	chatReq.UserID = requestUserID(c)
 
 	chatRes, err := service.SendChatServ(chatReq)
 	if err != nil {
@@ -32,6 +29,46 @@ func SendChatCtrl(c *gin.Context) {
 	respondSuccess(c, http.StatusOK, chatRes)
 }
 
+// StreamChatCtrl handles POST /chats/stream. The answer is sent as server-sent events named after the
+// service.ChatStreamEvent type; closing the connection cancels the request.
+func StreamChatCtrl(c *gin.Context) {
+	var chatReq service.ChatReq
+	if err := c.ShouldBindJSON(&chatReq); err != nil {
+		service.LogService("warn", "chat binding error", map[string]interface{}{"error": err.Error()}, nil, c.ClientIP())
+		respondBadRequest(c, err.Error())
+		return
+	}
+	chatReq.Privileges = getRequesterPrivileges(c)
+	chatReq.UserID = requestUserID(c)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Keep reverse proxies from buffering the stream
+	emit := func(event service.ChatStreamEvent) error {
+		c.SSEvent(event.Type, event)
+		c.Writer.Flush()
+		return c.Request.Context().Err()
+	}
+
+	chatRes, err := service.StreamChatServ(c.Request.Context(), chatReq, emit)
+	if c.Request.Context().Err() != nil {
+		return
+	}
+	if err != nil {
+		_ = emit(service.ChatStreamEvent{Type: service.ChatStreamError, Error: err.Error()})
+		return
+	}
+	_ = emit(service.ChatStreamEvent{
+		Type:           service.ChatStreamDone,
+		Content:        chatRes.Content,
+		MessageID:      chatRes.ID,
+		ConversationID: chatRes.ConversationID,
+		ProviderID:     chatRes.ProviderID,
+		Model:          chatRes.Model,
+	})
+}
+
 // GetAllChatsCtrl handles GET /chats
 func GetAllChatsCtrl(c *gin.Context) {
 	// Parse optional pagination parameters
@@ -46,7 +83,7 @@ func GetAllChatsCtrl(c *gin.Context) {
 	respondSuccess(c, http.StatusOK, chats)
 }
 
-// SearchChatsCtrl handles GET /chat/search
+// SearchChatsCtrl handles GET /ai/chats; users other than admins only find their own messages
 func SearchChatsCtrl(c *gin.Context) {
 	providerID, err := parseOptionalInt(c, "provider_id")
 	if err != nil {
@@ -58,16 +95,31 @@ func SearchChatsCtrl(c *gin.Context) {
 		respondBadRequest(c, "invalid user_id")
 		return
 	}
+	conversationID, err := parseOptionalInt(c, "conversation_id")
+	if err != nil {
+		respondBadRequest(c, "invalid conversation_id")
+		return
+	}
+	if getRequesterPrivileges(c) < 3 {
+		uid := requestUserID(c)
+		if uid == nil {
+			respondError(c, model.ErrUnauthorized)
+			return
+		}
+		ownID := int(*uid)
+		userID = &ownID
+	}
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 	filter := model.ChatFilter{
-		Query:      c.Query("q"),
-		Role:       parseOptionalString(c, "role"),
-		ProviderID: providerID,
-		UserID:     userID,
-		Model:      parseOptionalString(c, "model"),
-		Limit:      limit,
-		Offset:     offset,
+		Query:          c.Query("q"),
+		Role:           parseOptionalString(c, "role"),
+		ProviderID:     providerID,
+		UserID:         userID,
+		ConversationID: conversationID,
+		Model:          parseOptionalString(c, "model"),
+		Limit:          limit,
+		Offset:         offset,
 	}
 	chats, err := service.SearchChatsServ(filter)
 	if err != nil {
@@ -88,7 +140,7 @@ func ConsultAlertCtrl(c *gin.Context) {
 	providerID, _ := strconv.Atoi(c.DefaultQuery("provider_id", "1"))
 	model := c.Query("model")
 
-	chatRes, err := service.ConsultAlertServ(uint(providerID), model, alertID)
+	chatRes, err := service.ConsultAlertServ(uint(providerID), model, alertID, requestUserID(c))
 	if err != nil {
 		respondError(c, err)
 		return
@@ -107,7 +159,7 @@ func ConsultItemCtrl(c *gin.Context) {
 	providerID, _ := strconv.Atoi(c.DefaultQuery("provider_id", "1"))
 	model := c.Query("model")
 
-	chatRes, err := service.ConsultItemServ(uint(providerID), model, uint(itemID))
+	chatRes, err := service.ConsultItemServ(uint(providerID), model, uint(itemID), requestUserID(c))
 	if err != nil {
 		respondError(c, err)
 		return
@@ -126,7 +178,7 @@ func ConsultHostCtrl(c *gin.Context) {
 	providerID, _ := strconv.Atoi(c.DefaultQuery("provider_id", "1"))
 	model := c.Query("model")
 
-	chatRes, err := service.ConsultHostServ(uint(providerID), model, uint(hostID))
+	chatRes, err := service.ConsultHostServ(uint(providerID), model, uint(hostID), requestUserID(c))
 	if err != nil {
 		respondError(c, err)
 		return
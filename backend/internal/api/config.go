@@ -76,6 +76,23 @@ func ModifyMainConfigCtrl(c *gin.Context) {
 	repository.SetConfigValue("ai.analysis_timeout_seconds", req.AI.AnalysisTimeoutSeconds)
 	repository.SetConfigValue("ai.analysis_min_severity", req.AI.AnalysisMinSeverity)
 	repository.SetConfigValue("ai.language", req.AI.Language)
+	repository.SetConfigValue("ai.chat_history_token_budget", req.AI.ChatHistoryTokenBudget)
+	if req.AI.Routing != nil {
+		repository.SetConfigValue("ai.routing.routes", req.AI.Routing.Routes)
+		repository.SetConfigValue("ai.routing.retries", req.AI.Routing.Retries)
+		repository.SetConfigValue("ai.routing.timeout_seconds", req.AI.Routing.TimeoutSeconds)
+		repository.SetConfigValue("ai.routing.breaker_threshold", req.AI.Routing.BreakerThreshold)
+		repository.SetConfigValue("ai.routing.breaker_cooldown_seconds", req.AI.Routing.BreakerCooldownSeconds)
+	}
+	if req.AI.Budget != nil {
+		repository.SetConfigValue("ai.budget.provider_daily_tokens", req.AI.Budget.ProviderDailyTokens)
+		repository.SetConfigValue("ai.budget.provider_monthly_tokens", req.AI.Budget.ProviderMonthlyTokens)
+		repository.SetConfigValue("ai.budget.user_daily_tokens", req.AI.Budget.UserDailyTokens)
+		repository.SetConfigValue("ai.budget.user_monthly_tokens", req.AI.Budget.UserMonthlyTokens)
+		repository.SetConfigValue("ai.budget.providers", req.AI.Budget.Providers)
+		repository.SetConfigValue("ai.budget.users", req.AI.Budget.Users)
+		repository.SetConfigValue("ai.budget.degraded_min_severity", req.AI.Budget.DegradedMinSeverity)
+	}
 
 	repository.SetConfigValue("gmail.enabled", req.Gmail.Enabled)
 	repository.SetConfigValue("gmail.credentials_file", req.Gmail.CredentialsFile)
@@ -88,6 +105,8 @@ func ModifyMainConfigCtrl(c *gin.Context) {
 	repository.SetConfigValue("smtp.username", req.SMTP.Username)
 	repository.SetConfigValue("smtp.password", req.SMTP.Password)
 	repository.SetConfigValue("smtp.from", req.SMTP.From)
+	repository.SetConfigValue("smtp.security", req.SMTP.Security)
+	repository.SetConfigValue("smtp.skip_verify", req.SMTP.SkipVerify)
 
 	repository.SetConfigValue("site_message.min_alert_severity", req.SiteMessage.MinAlertSeverity)
 	repository.SetConfigValue("site_message.min_log_severity", req.SiteMessage.MinLogSeverity)
@@ -96,6 +115,47 @@ func ModifyMainConfigCtrl(c *gin.Context) {
 	repository.SetConfigValue("media_rate_limit.protocol_interval_seconds", req.MediaRateLimit.ProtocolIntervalSeconds)
 	repository.SetConfigValue("media_rate_limit.media_interval_seconds", req.MediaRateLimit.MediaIntervalSeconds)
 
+	if req.SNMPTrap != nil {
+		repository.SetConfigValue("snmp_trap.enabled", req.SNMPTrap.Enabled)
+		repository.SetConfigValue("snmp_trap.listen_address", req.SNMPTrap.ListenAddress)
+		repository.SetConfigValue("snmp_trap.community", req.SNMPTrap.Community)
+		repository.SetConfigValue("snmp_trap.default_severity", req.SNMPTrap.DefaultSeverity)
+		repository.SetConfigValue("snmp_trap.alarm_id", req.SNMPTrap.AlarmID)
+		repository.SetConfigValue("snmp_trap.accept_unknown_hosts", req.SNMPTrap.AcceptUnknownHosts)
+		repository.SetConfigValue("snmp_trap.v3.username", req.SNMPTrap.V3.Username)
+		repository.SetConfigValue("snmp_trap.v3.auth_protocol", req.SNMPTrap.V3.AuthProtocol)
+		repository.SetConfigValue("snmp_trap.v3.auth_password", req.SNMPTrap.V3.AuthPassword)
+		repository.SetConfigValue("snmp_trap.v3.priv_protocol", req.SNMPTrap.V3.PrivProtocol)
+		repository.SetConfigValue("snmp_trap.v3.priv_password", req.SNMPTrap.V3.PrivPassword)
+		repository.SetConfigValue("snmp_trap.rules", req.SNMPTrap.Rules)
+	}
+
+	if req.Incident != nil {
+		repository.SetConfigValue("incident.enabled", req.Incident.Enabled)
+		repository.SetConfigValue("incident.window_minutes", req.Incident.WindowMinutes)
+		repository.SetConfigValue("incident.min_severity", req.Incident.MinSeverity)
+		repository.SetConfigValue("incident.label_keys", req.Incident.LabelKeys)
+		repository.SetConfigValue("incident.label_similarity", req.Incident.LabelSimilarity)
+	}
+
+	if req.Notification != nil {
+		repository.SetConfigValue("notification.workers", req.Notification.Workers)
+		repository.SetConfigValue("notification.max_attempts", req.Notification.MaxAttempts)
+		repository.SetConfigValue("notification.retry_base_seconds", req.Notification.RetryBaseSeconds)
+		repository.SetConfigValue("notification.retry_max_seconds", req.Notification.RetryMaxSeconds)
+		repository.SetConfigValue("notification.send_timeout_seconds", req.Notification.SendTimeoutSeconds)
+	}
+
+	if req.Telegram != nil {
+		repository.SetConfigValue("telegram.enabled", req.Telegram.Enabled)
+		repository.SetConfigValue("telegram.bot_token", req.Telegram.BotToken)
+		repository.SetConfigValue("telegram.api_base_url", req.Telegram.APIBaseURL)
+		repository.SetConfigValue("telegram.mode", req.Telegram.Mode)
+		repository.SetConfigValue("telegram.webhook_url", req.Telegram.WebhookURL)
+		repository.SetConfigValue("telegram.webhook_secret", req.Telegram.WebhookSecret)
+		repository.SetConfigValue("telegram.poll_timeout_seconds", req.Telegram.PollTimeoutSeconds)
+	}
+
 	repository.SetConfigValue("external", req.External)
 
 	if err := repository.SaveConfig(); err != nil {
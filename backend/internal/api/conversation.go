@@ -0,0 +1,121 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"nagare/internal/model"
+	"nagare/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchConversationsCtrl handles GET /ai/conversations; admins may filter by user_id, others only see their own
+func SearchConversationsCtrl(c *gin.Context) {
+	userID, err := parseOptionalInt(c, "user_id")
+	if err != nil {
+		respondBadRequest(c, "invalid user_id")
+		return
+	}
+	withTotal, _ := parseOptionalBool(c, "with_total")
+	limit := 50
+	if l, err := parseOptionalInt(c, "limit"); err == nil && l != nil {
+		limit = *l
+	}
+	offset := 0
+	if o, err := parseOptionalInt(c, "offset"); err == nil && o != nil {
+		offset = *o
+	}
+
+	filter := model.ConversationFilter{
+		Query:     c.Query("q"),
+		Limit:     limit,
+		Offset:    offset,
+		SortBy:    c.Query("sort"),
+		SortOrder: c.Query("order"),
+	}
+	if userID != nil {
+		uid := uint(*userID)
+		filter.UserID = &uid
+	}
+	privileges := getRequesterPrivileges(c)
+	conversations, err := service.SearchConversationsServ(filter, requestUserID(c), privileges)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if withTotal != nil && *withTotal {
+		total, err := service.CountConversationsServ(filter, requestUserID(c), privileges)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		respondSuccess(c, http.StatusOK, gin.H{"items": conversations, "total": total})
+		return
+	}
+	respondSuccess(c, http.StatusOK, conversations)
+}
+
+// GetConversationCtrl handles GET /ai/conversations/:id
+func GetConversationCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid conversation ID")
+		return
+	}
+	conversation, err := service.GetConversationServ(uint(id), requestUserID(c), getRequesterPrivileges(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, conversation)
+}
+
+// AddConversationCtrl handles POST /ai/conversations
+func AddConversationCtrl(c *gin.Context) {
+	var req service.ConversationReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	conversation, err := service.AddConversationServ(req, requestUserID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, conversation)
+}
+
+// UpdateConversationCtrl handles PUT /ai/conversations/:id
+func UpdateConversationCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid conversation ID")
+		return
+	}
+	var req service.ConversationReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	conversation, err := service.UpdateConversationServ(uint(id), req, requestUserID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, conversation)
+}
+
+// DeleteConversationCtrl handles DELETE /ai/conversations/:id
+func DeleteConversationCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid conversation ID")
+		return
+	}
+	if err := service.DeleteConversationServ(uint(id), requestUserID(c), getRequesterPrivileges(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccessMessage(c, http.StatusOK, "conversation deleted")
+}
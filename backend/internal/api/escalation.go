@@ -0,0 +1,149 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"nagare/internal/model"
+	"nagare/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchEscalationPoliciesCtrl handles GET /alert/escalation-policies
+func SearchEscalationPoliciesCtrl(c *gin.Context) {
+	enabled, err := parseOptionalInt(c, "enabled")
+	if err != nil {
+		respondBadRequest(c, "invalid enabled")
+		return
+	}
+	withTotal, _ := parseOptionalBool(c, "with_total")
+	limit := 100
+	if l, err := parseOptionalInt(c, "limit"); err == nil && l != nil {
+		limit = *l
+	}
+	offset := 0
+	if o, err := parseOptionalInt(c, "offset"); err == nil && o != nil {
+		offset = *o
+	}
+	filter := model.EscalationPolicyFilter{
+		Query:     c.Query("q"),
+		Enabled:   enabled,
+		Limit:     limit,
+		Offset:    offset,
+		SortBy:    c.Query("sort"),
+		SortOrder: c.Query("order"),
+	}
+	policies, err := service.SearchEscalationPoliciesServ(filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if withTotal != nil && *withTotal {
+		total, err := service.CountEscalationPoliciesServ(filter)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		respondSuccess(c, http.StatusOK, gin.H{"items": policies, "total": total})
+		return
+	}
+	respondSuccess(c, http.StatusOK, policies)
+}
+
+// GetEscalationPolicyByIDCtrl handles GET /alert/escalation-policies/:id
+func GetEscalationPolicyByIDCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid escalation policy ID")
+		return
+	}
+	policy, err := service.GetEscalationPolicyByIDServ(uint(id))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, policy)
+}
+
+// AddEscalationPolicyCtrl handles POST /alert/escalation-policies
+func AddEscalationPolicyCtrl(c *gin.Context) {
+	var req service.EscalationPolicyReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	policy, err := service.AddEscalationPolicyServ(req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, policy)
+}
+
+// UpdateEscalationPolicyCtrl handles PUT /alert/escalation-policies/:id
+func UpdateEscalationPolicyCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid escalation policy ID")
+		return
+	}
+	var req service.EscalationPolicyReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	if err := service.UpdateEscalationPolicyServ(uint(id), req); err != nil {
+		respondError(c, err)
+		return
+	}
+	policy, err := service.GetEscalationPolicyByIDServ(uint(id))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, policy)
+}
+
+// DeleteEscalationPolicyByIDCtrl handles DELETE /alert/escalation-policies/:id
+func DeleteEscalationPolicyByIDCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid escalation policy ID")
+		return
+	}
+	if err := service.DeleteEscalationPolicyServ(uint(id)); err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccessMessage(c, http.StatusOK, "escalation policy deleted")
+}
+
+// GetAlertEscalationCtrl handles GET /alert/alerts/:id/escalations
+func GetAlertEscalationCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid alert ID")
+		return
+	}
+	escalation, err := service.GetAlertEscalationServ(uint(id))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, escalation)
+}
+
+// CancelAlertEscalationCtrl handles DELETE /alert/alerts/:id/escalations
+func CancelAlertEscalationCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid alert ID")
+		return
+	}
+	if err := service.CancelAlertEscalationServ(uint(id), requestUserID(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccessMessage(c, http.StatusOK, "alert escalation cancelled")
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"nagare/internal/model"
+	"nagare/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchIncidentsCtrl handles GET /alert/incidents
+func SearchIncidentsCtrl(c *gin.Context) {
+	status, err := parseOptionalInt(c, "status")
+	if err != nil {
+		respondBadRequest(c, "invalid status")
+		return
+	}
+	severity, err := parseOptionalInt(c, "severity")
+	if err != nil {
+		respondBadRequest(c, "invalid severity")
+		return
+	}
+	groupID, err := parseOptionalInt(c, "group_id")
+	if err != nil {
+		respondBadRequest(c, "invalid group_id")
+		return
+	}
+	commanderID, err := parseOptionalInt(c, "commander_id")
+	if err != nil {
+		respondBadRequest(c, "invalid commander_id")
+		return
+	}
+	active, err := parseOptionalBool(c, "active")
+	if err != nil {
+		respondBadRequest(c, "invalid active")
+		return
+	}
+	withTotal, _ := parseOptionalBool(c, "with_total")
+	limit := 100
+	if l, err := parseOptionalInt(c, "limit"); err == nil && l != nil {
+		limit = *l
+	}
+	offset := 0
+	if o, err := parseOptionalInt(c, "offset"); err == nil && o != nil {
+		offset = *o
+	}
+
+	filter := model.IncidentFilter{
+		Query:       c.Query("q"),
+		Status:      status,
+		Severity:    severity,
+		GroupID:     groupID,
+		CommanderID: commanderID,
+		Active:      active != nil && *active,
+		Limit:       limit,
+		Offset:      offset,
+		SortBy:      c.Query("sort"),
+		SortOrder:   c.Query("order"),
+	}
+	incidents, err := service.SearchIncidentsServ(filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if withTotal != nil && *withTotal {
+		total, err := service.CountIncidentsServ(filter)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		respondSuccess(c, http.StatusOK, gin.H{"items": incidents, "total": total})
+		return
+	}
+	respondSuccess(c, http.StatusOK, incidents)
+}
+
+// GetIncidentByIDCtrl handles GET /alert/incidents/:id
+func GetIncidentByIDCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid incident ID")
+		return
+	}
+	incident, err := service.GetIncidentByIDServ(uint(id))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, incident)
+}
+
+// AddIncidentCtrl handles POST /alert/incidents
+func AddIncidentCtrl(c *gin.Context) {
+	var req service.IncidentReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	incident, err := service.AddIncidentServ(req, requestUserID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, incident)
+}
+
+// UpdateIncidentCtrl handles PUT /alert/incidents/:id
+func UpdateIncidentCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid incident ID")
+		return
+	}
+	var req service.IncidentUpdateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	incident, err := service.UpdateIncidentServ(uint(id), req, requestUserID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, incident)
+}
+
+// DeleteIncidentByIDCtrl handles DELETE /alert/incidents/:id
+func DeleteIncidentByIDCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid incident ID")
+		return
+	}
+	if err := service.DeleteIncidentServ(uint(id)); err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccessMessage(c, http.StatusOK, "incident deleted")
+}
+
+// AddIncidentNoteCtrl handles POST /alert/incidents/:id/notes
+func AddIncidentNoteCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid incident ID")
+		return
+	}
+	var req service.IncidentNoteReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	if err := service.AddIncidentNoteServ(uint(id), req, requestUserID(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccessMessage(c, http.StatusCreated, "note added")
+}
+
+// AttachIncidentAlertsCtrl handles POST /alert/incidents/:id/alerts
+func AttachIncidentAlertsCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid incident ID")
+		return
+	}
+	var req service.IncidentAlertsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	if err := service.AttachAlertsToIncidentServ(uint(id), req, requestUserID(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccessMessage(c, http.StatusOK, "alerts attached")
+}
+
+// requestUserID returns the authenticated user's ID, or nil for anonymous requests
+func requestUserID(c *gin.Context) *uint {
+	if val, ok := c.Get("uid"); ok {
+		if id, ok := val.(uint); ok {
+			return &id
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"net/http"
+
+	"nagare/internal/model"
+	"nagare/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseLLMUsageFilter reads the filter shared by the usage endpoints; users other than admins are limited
+// to their own usage
+func parseLLMUsageFilter(c *gin.Context) (model.LLMUsageFilter, bool) {
+	var filter model.LLMUsageFilter
+	providerID, err := parseOptionalUint(c, "provider_id")
+	if err != nil {
+		respondBadRequest(c, "invalid provider_id")
+		return filter, false
+	}
+	userID, err := parseOptionalUint(c, "user_id")
+	if err != nil {
+		respondBadRequest(c, "invalid user_id")
+		return filter, false
+	}
+	from, err := parseOptionalUnixTime(c, "from")
+	if err != nil {
+		respondBadRequest(c, "invalid from")
+		return filter, false
+	}
+	to, err := parseOptionalUnixTime(c, "to")
+	if err != nil {
+		respondBadRequest(c, "invalid to")
+		return filter, false
+	}
+	if getRequesterPrivileges(c) < 3 {
+		userID = requestUserID(c)
+		if userID == nil {
+			respondError(c, model.ErrUnauthorized)
+			return filter, false
+		}
+	}
+
+	filter = model.LLMUsageFilter{
+		ProviderID: providerID,
+		UserID:     userID,
+		Feature:    c.Query("feature"),
+		Outcome:    c.Query("outcome"),
+		Model:      c.Query("model"),
+		From:       from,
+		To:         to,
+	}
+	return filter, true
+}
+
+// SearchLLMUsageCtrl handles GET /ai/usage
+func SearchLLMUsageCtrl(c *gin.Context) {
+	filter, ok := parseLLMUsageFilter(c)
+	if !ok {
+		return
+	}
+	withTotal, _ := parseOptionalBool(c, "with_total")
+	filter.Limit = 100
+	if l, err := parseOptionalInt(c, "limit"); err == nil && l != nil {
+		filter.Limit = *l
+	}
+	if o, err := parseOptionalInt(c, "offset"); err == nil && o != nil {
+		filter.Offset = *o
+	}
+	filter.SortBy = c.Query("sort")
+	filter.SortOrder = c.Query("order")
+
+	usages, err := service.SearchLLMUsageServ(filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if withTotal != nil && *withTotal {
+		total, err := service.CountLLMUsageServ(filter)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		respondSuccess(c, http.StatusOK, gin.H{"items": usages, "total": total})
+		return
+	}
+	respondSuccess(c, http.StatusOK, usages)
+}
+
+// SummarizeLLMUsageCtrl handles GET /ai/usage/summary?group_by=provider|model|feature|user|outcome|day|month
+func SummarizeLLMUsageCtrl(c *gin.Context) {
+	filter, ok := parseLLMUsageFilter(c)
+	if !ok {
+		return
+	}
+	summaries, err := service.SummarizeLLMUsageServ(filter, c.Query("group_by"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, summaries)
+}
+
+// GetLLMBudgetsCtrl handles GET /ai/usage/budgets
+func GetLLMBudgetsCtrl(c *gin.Context) {
+	budgets, err := service.GetLLMBudgetsServ(requestUserID(c), getRequesterPrivileges(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, budgets)
+}
@@ -287,6 +287,20 @@ func HandleQQMessageCtrl(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// HandleTelegramUpdateCtrl receives Bot API updates when the Telegram bot runs in webhook mode
+func HandleTelegramUpdateCtrl(c *gin.Context) {
+	var update media.TelegramUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	if err := service.HandleTelegramWebhookServ(c.GetHeader("X-Telegram-Bot-Api-Secret-Token"), update); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
 // HandleQQWebSocket handles Reverse WebSocket connections from NapCat (OneBot 11)
 func HandleQQWebSocket(c *gin.Context) {
 	media.GlobalQQWSManager.HandleReverseWS(c)
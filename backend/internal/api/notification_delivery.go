@@ -0,0 +1,188 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"nagare/internal/model"
+	"nagare/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchNotificationDeliveriesCtrl handles GET /delivery/notification-deliveries
+func SearchNotificationDeliveriesCtrl(c *gin.Context) {
+	status, err := parseOptionalInt(c, "status")
+	if err != nil {
+		respondBadRequest(c, "invalid status")
+		return
+	}
+	alertID, err := parseOptionalUint(c, "alert_id")
+	if err != nil {
+		respondBadRequest(c, "invalid alert_id")
+		return
+	}
+	incidentID, err := parseOptionalUint(c, "incident_id")
+	if err != nil {
+		respondBadRequest(c, "invalid incident_id")
+		return
+	}
+	actionID, err := parseOptionalUint(c, "action_id")
+	if err != nil {
+		respondBadRequest(c, "invalid action_id")
+		return
+	}
+	mediaID, err := parseOptionalUint(c, "media_id")
+	if err != nil {
+		respondBadRequest(c, "invalid media_id")
+		return
+	}
+	digestID, err := parseOptionalUint(c, "digest_id")
+	if err != nil {
+		respondBadRequest(c, "invalid digest_id")
+		return
+	}
+	from, err := parseOptionalUnixTime(c, "from")
+	if err != nil {
+		respondBadRequest(c, "invalid from")
+		return
+	}
+	to, err := parseOptionalUnixTime(c, "to")
+	if err != nil {
+		respondBadRequest(c, "invalid to")
+		return
+	}
+	withTotal, _ := parseOptionalBool(c, "with_total")
+	limit := 100
+	if l, err := parseOptionalInt(c, "limit"); err == nil && l != nil {
+		limit = *l
+	}
+	offset := 0
+	if o, err := parseOptionalInt(c, "offset"); err == nil && o != nil {
+		offset = *o
+	}
+
+	filter := model.NotificationDeliveryFilter{
+		Query:      c.Query("q"),
+		Kind:       c.Query("kind"),
+		Status:     status,
+		AlertID:    alertID,
+		IncidentID: incidentID,
+		ActionID:   actionID,
+		MediaID:    mediaID,
+		DigestID:   digestID,
+		From:       from,
+		To:         to,
+		Limit:      limit,
+		Offset:     offset,
+		SortBy:     c.Query("sort"),
+		SortOrder:  c.Query("order"),
+	}
+	deliveries, err := service.SearchNotificationDeliveriesServ(filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if withTotal != nil && *withTotal {
+		total, err := service.CountNotificationDeliveriesServ(filter)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		respondSuccess(c, http.StatusOK, gin.H{"items": deliveries, "total": total})
+		return
+	}
+	respondSuccess(c, http.StatusOK, deliveries)
+}
+
+// GetNotificationDeliveryByIDCtrl handles GET /delivery/notification-deliveries/:id
+func GetNotificationDeliveryByIDCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid delivery ID")
+		return
+	}
+	delivery, err := service.GetNotificationDeliveryByIDServ(uint(id))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, delivery)
+}
+
+// RetryNotificationDeliveryCtrl handles POST /delivery/notification-deliveries/:id/retries
+func RetryNotificationDeliveryCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid delivery ID")
+		return
+	}
+	delivery, err := service.RetryNotificationDeliveryServ(uint(id), requestUserID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, delivery)
+}
+
+// SearchNotificationDigestsCtrl handles GET /delivery/notification-digests
+func SearchNotificationDigestsCtrl(c *gin.Context) {
+	status, err := parseOptionalInt(c, "status")
+	if err != nil {
+		respondBadRequest(c, "invalid status")
+		return
+	}
+	mediaID, err := parseOptionalUint(c, "media_id")
+	if err != nil {
+		respondBadRequest(c, "invalid media_id")
+		return
+	}
+	withTotal, _ := parseOptionalBool(c, "with_total")
+	limit := 100
+	if l, err := parseOptionalInt(c, "limit"); err == nil && l != nil {
+		limit = *l
+	}
+	offset := 0
+	if o, err := parseOptionalInt(c, "offset"); err == nil && o != nil {
+		offset = *o
+	}
+
+	filter := model.NotificationDigestFilter{
+		Status:    status,
+		MediaID:   mediaID,
+		Limit:     limit,
+		Offset:    offset,
+		SortBy:    c.Query("sort"),
+		SortOrder: c.Query("order"),
+	}
+	digests, err := service.SearchNotificationDigestsServ(filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if withTotal != nil && *withTotal {
+		total, err := service.CountNotificationDigestsServ(filter)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		respondSuccess(c, http.StatusOK, gin.H{"items": digests, "total": total})
+		return
+	}
+	respondSuccess(c, http.StatusOK, digests)
+}
+
+// GetNotificationDigestByIDCtrl handles GET /delivery/notification-digests/:id
+func GetNotificationDigestByIDCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid digest ID")
+		return
+	}
+	digest, err := service.GetNotificationDigestByIDServ(uint(id))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, digest)
+}
@@ -0,0 +1,222 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"nagare/internal/model"
+	"nagare/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchOnCallSchedulesCtrl handles GET /delivery/on-call-schedules
+func SearchOnCallSchedulesCtrl(c *gin.Context) {
+	enabled, err := parseOptionalInt(c, "enabled")
+	if err != nil {
+		respondBadRequest(c, "invalid enabled")
+		return
+	}
+	withTotal, _ := parseOptionalBool(c, "with_total")
+	limit := 100
+	if l, err := parseOptionalInt(c, "limit"); err == nil && l != nil {
+		limit = *l
+	}
+	offset := 0
+	if o, err := parseOptionalInt(c, "offset"); err == nil && o != nil {
+		offset = *o
+	}
+
+	filter := model.OnCallScheduleFilter{
+		Query:     c.Query("q"),
+		Enabled:   enabled,
+		Limit:     limit,
+		Offset:    offset,
+		SortBy:    c.Query("sort"),
+		SortOrder: c.Query("order"),
+	}
+	schedules, err := service.SearchOnCallSchedulesServ(filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if withTotal != nil && *withTotal {
+		total, err := service.CountOnCallSchedulesServ(filter)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		respondSuccess(c, http.StatusOK, gin.H{"items": schedules, "total": total})
+		return
+	}
+	respondSuccess(c, http.StatusOK, schedules)
+}
+
+// GetOnCallScheduleByIDCtrl handles GET /delivery/on-call-schedules/:id
+func GetOnCallScheduleByIDCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid schedule ID")
+		return
+	}
+	schedule, err := service.GetOnCallScheduleByIDServ(uint(id))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, schedule)
+}
+
+// AddOnCallScheduleCtrl handles POST /delivery/on-call-schedules
+func AddOnCallScheduleCtrl(c *gin.Context) {
+	var req service.OnCallScheduleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	schedule, err := service.AddOnCallScheduleServ(req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, schedule)
+}
+
+// UpdateOnCallScheduleCtrl handles PUT /delivery/on-call-schedules/:id
+func UpdateOnCallScheduleCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid schedule ID")
+		return
+	}
+	var req service.OnCallScheduleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	if err := service.UpdateOnCallScheduleServ(uint(id), req); err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccessMessage(c, http.StatusOK, "on-call schedule updated")
+}
+
+// DeleteOnCallScheduleByIDCtrl handles DELETE /delivery/on-call-schedules/:id
+func DeleteOnCallScheduleByIDCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid schedule ID")
+		return
+	}
+	if err := service.DeleteOnCallScheduleServ(uint(id)); err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccessMessage(c, http.StatusOK, "on-call schedule deleted")
+}
+
+// GetOnCallCtrl handles GET /delivery/on-call-schedules/:id/on-call?at=<unix seconds>
+func GetOnCallCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid schedule ID")
+		return
+	}
+	at, ok := parseOnCallTime(c)
+	if !ok {
+		return
+	}
+	onCall, err := service.GetOnCallServ(uint(id), at)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, onCall)
+}
+
+// GetAllOnCallCtrl handles GET /delivery/on-call?at=<unix seconds>
+func GetAllOnCallCtrl(c *gin.Context) {
+	at, ok := parseOnCallTime(c)
+	if !ok {
+		return
+	}
+	onCall, err := service.GetAllOnCallServ(at)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, onCall)
+}
+
+// GetOnCallOverridesCtrl handles GET /delivery/on-call-schedules/:id/overrides
+func GetOnCallOverridesCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid schedule ID")
+		return
+	}
+	includePast, err := parseOptionalBool(c, "include_past")
+	if err != nil {
+		respondBadRequest(c, "invalid include_past")
+		return
+	}
+	overrides, err := service.GetOnCallOverridesServ(uint(id), includePast != nil && *includePast)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, overrides)
+}
+
+// AddOnCallOverrideCtrl handles POST /delivery/on-call-schedules/:id/overrides
+func AddOnCallOverrideCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid schedule ID")
+		return
+	}
+	var req service.OnCallOverrideReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	override, err := service.AddOnCallOverrideServ(uint(id), req, requestUserID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, override)
+}
+
+// DeleteOnCallOverrideCtrl handles DELETE /delivery/on-call-schedules/:id/overrides/:override_id
+func DeleteOnCallOverrideCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid schedule ID")
+		return
+	}
+	overrideID, err := strconv.ParseUint(c.Param("override_id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid override ID")
+		return
+	}
+	if err := service.DeleteOnCallOverrideServ(uint(id), uint(overrideID)); err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccessMessage(c, http.StatusOK, "on-call override deleted")
+}
+
+// parseOnCallTime reads the optional "at" query (unix seconds), defaulting to now
+func parseOnCallTime(c *gin.Context) (time.Time, bool) {
+	at, err := parseOptionalUnixTime(c, "at")
+	if err != nil {
+		respondBadRequest(c, "invalid at")
+		return time.Time{}, false
+	}
+	if at == nil {
+		return time.Now(), true
+	}
+	return *at, true
+}
@@ -156,6 +156,11 @@ func CheckAllProvidersStatusCtrl(c *gin.Context) {
 	respondSuccess(c, http.StatusOK, results)
 }
 
+// GetProviderCircuitsCtrl handles GET /ai/providers/circuits
+func GetProviderCircuitsCtrl(c *gin.Context) {
+	respondSuccess(c, http.StatusOK, service.GetLLMCircuitStatesServ())
+}
+
 // FetchProviderModelsCtrl handles POST /ai/providers/:id/models
 func FetchProviderModelsCtrl(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -63,6 +63,9 @@ func respondError(c *gin.Context, err error) {
 	case errors.Is(err, model.ErrTimeout):
 		status = http.StatusGatewayTimeout
 		message = "operation timed out"
+	case errors.Is(err, model.ErrBudgetExceeded):
+		status = http.StatusTooManyRequests
+		message = err.Error()
 	}
 
 	c.JSON(status, APIResponse{
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"nagare/internal/model"
+	"nagare/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchSilencesCtrl handles GET /alert/silences
+func SearchSilencesCtrl(c *gin.Context) {
+	enabled, err := parseOptionalInt(c, "enabled")
+	if err != nil {
+		respondBadRequest(c, "invalid enabled")
+		return
+	}
+	current, err := parseOptionalBool(c, "current")
+	if err != nil {
+		respondBadRequest(c, "invalid current")
+		return
+	}
+	withTotal, _ := parseOptionalBool(c, "with_total")
+	limit := 100
+	if l, err := parseOptionalInt(c, "limit"); err == nil && l != nil {
+		limit = *l
+	}
+	offset := 0
+	if o, err := parseOptionalInt(c, "offset"); err == nil && o != nil {
+		offset = *o
+	}
+
+	filter := model.SilenceFilter{
+		Query:     c.Query("q"),
+		Kind:      c.Query("kind"),
+		Enabled:   enabled,
+		Current:   current != nil && *current,
+		Limit:     limit,
+		Offset:    offset,
+		SortBy:    c.Query("sort"),
+		SortOrder: c.Query("order"),
+	}
+	silences, err := service.SearchSilencesServ(filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if withTotal != nil && *withTotal {
+		total, err := service.CountSilencesServ(filter)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		respondSuccess(c, http.StatusOK, gin.H{"items": silences, "total": total})
+		return
+	}
+	respondSuccess(c, http.StatusOK, silences)
+}
+
+// GetSilenceByIDCtrl handles GET /alert/silences/:id
+func GetSilenceByIDCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid silence ID")
+		return
+	}
+	silence, err := service.GetSilenceByIDServ(uint(id))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, silence)
+}
+
+// AddSilenceCtrl handles POST /alert/silences
+func AddSilenceCtrl(c *gin.Context) {
+	var req service.SilenceReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	silence, err := service.AddSilenceServ(req, requestUserID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusCreated, silence)
+}
+
+// UpdateSilenceCtrl handles PUT /alert/silences/:id
+func UpdateSilenceCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid silence ID")
+		return
+	}
+	var req service.SilenceReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	silence, err := service.UpdateSilenceServ(uint(id), req, requestUserID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, silence)
+}
+
+// DeleteSilenceByIDCtrl handles DELETE /alert/silences/:id
+func DeleteSilenceByIDCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid silence ID")
+		return
+	}
+	if err := service.DeleteSilenceServ(uint(id), requestUserID(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccessMessage(c, http.StatusOK, "silence deleted")
+}
+
+// ExpireSilenceCtrl handles POST /alert/silences/:id/expirations
+func ExpireSilenceCtrl(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "invalid silence ID")
+		return
+	}
+	silence, err := service.ExpireSilenceServ(uint(id), requestUserID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondSuccess(c, http.StatusOK, silence)
+}
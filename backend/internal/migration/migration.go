@@ -64,6 +64,8 @@ func InitDBTables() error {
 		&model.LogEntry{},
 		&model.AuditLog{},
 		&model.Chat{},
+		&model.Conversation{},
+		&model.LLMUsage{},
 		&model.Provider{},
 		&model.RegisterApplication{},
 		&model.PasswordResetApplication{},
@@ -71,6 +73,16 @@ func InitDBTables() error {
 		&model.ReportConfig{},
 		&model.KnowledgeBase{},
 		&model.SiteMessage{},
+		&model.Incident{},
+		&model.IncidentEvent{},
+		&model.OnCallSchedule{},
+		&model.OnCallOverride{},
+		&model.EscalationPolicy{},
+		&model.AlertEscalation{},
+		&model.AlertEscalationEvent{},
+		&model.NotificationDelivery{},
+		&model.NotificationDigest{},
+		&model.Silence{},
 
 		&model.RetentionPolicy{},
 	); err != nil {
@@ -79,6 +91,9 @@ func InitDBTables() error {
 	if err := applySchemaUpdates(); err != nil {
 		return err
 	}
+	if err := ensureSNMPHostExternalIDs(); err != nil {
+		return err
+	}
 	if err := ensureDefaultAdmin(); err != nil {
 		return err
 	}
@@ -112,9 +127,11 @@ func ensureDefaultRetentionPolicies() error {
 		{DataType: "monitor_history", RetentionDays: 30, Description: "Monitor status history"},
 		{DataType: "network_history", RetentionDays: 90, Description: "Network health score history"},
 		{DataType: "chat", RetentionDays: 30, Description: "AI chat messages"},
+		{DataType: "llm_usage", RetentionDays: 180, Description: "LLM token usage records"},
 		{DataType: "ansible_jobs", RetentionDays: 30, Description: "Ansible execution logs"},
 		{DataType: "reports", RetentionDays: 30, Description: "Generated PDF reports"},
 		{DataType: "site_messages", RetentionDays: 30, Description: "User notifications"},
+		{DataType: "notification_deliveries", RetentionDays: 90, Description: "Notification outbox and delivery audit trail"},
 	}
 
 	enabled := 1
@@ -184,6 +201,12 @@ func ensureDefaultKnowledgeBase() error {
 	return nil
 }
 
+// ensureSNMPHostExternalIDs backfills the external IDs the SNMP poller addresses local hosts by;
+// new hosts get theirs when they are saved
+func ensureSNMPHostExternalIDs() error {
+	return database.DB.Exec("UPDATE hosts SET external_id = CONCAT('snmp-', id) WHERE (external_id IS NULL OR external_id = '') AND deleted_at IS NULL AND group_id IN (SELECT id FROM `groups` WHERE monitor_id IN (SELECT id FROM monitors WHERE type = 1))").Error
+}
+
 func ensureDefaultAdmin() error {
 	var count int64
 	if err := database.DB.Model(&model.User{}).Count(&count).Error; err != nil {
@@ -28,11 +28,18 @@ type Host struct {
 	// SNMP Configuration
 
 	// SNMP Configuration
-	SNMPCommunity string     `gorm:"column:snmp_community;type:varchar(100)" json:"snmp_community"`
-	SNMPVersion   string     `gorm:"column:snmp_version;type:varchar(20)" json:"snmp_version"` // "v1", "v2c"
-	SNMPPort      int        `gorm:"column:snmp_port;default:161" json:"snmp_port"`
-	LastSyncAt    *time.Time `json:"last_sync_at"`
-	HealthScore   int        `gorm:"column:health_score;default:100" json:"health_score"`
+	SNMPCommunity string `gorm:"column:snmp_community;type:varchar(100)" json:"snmp_community"`
+	SNMPVersion   string `gorm:"column:snmp_version;type:varchar(20)" json:"snmp_version"` // "v1", "v2c", "v3"
+	SNMPPort      int    `gorm:"column:snmp_port;default:161" json:"snmp_port"`
+	// SNMPv3 USM credentials; passwords are stored encrypted
+	SNMPV3User          string     `gorm:"column:snmp_v3_user;type:varchar(100)" json:"snmp_v3_user"`
+	SNMPV3SecurityLevel string     `gorm:"column:snmp_v3_security_level;type:varchar(20)" json:"snmp_v3_security_level"` // "noAuthNoPriv", "authNoPriv", "authPriv"
+	SNMPV3AuthProtocol  string     `gorm:"column:snmp_v3_auth_protocol;type:varchar(20)" json:"snmp_v3_auth_protocol"`
+	SNMPV3AuthPassword  string     `gorm:"column:snmp_v3_auth_password;type:varchar(255)" json:"-"`
+	SNMPV3PrivProtocol  string     `gorm:"column:snmp_v3_priv_protocol;type:varchar(20)" json:"snmp_v3_priv_protocol"`
+	SNMPV3PrivPassword  string     `gorm:"column:snmp_v3_priv_password;type:varchar(255)" json:"-"`
+	LastSyncAt          *time.Time `json:"last_sync_at"`
+	HealthScore         int        `gorm:"column:health_score;default:100" json:"health_score"`
 }
 
 // Group represents a logical group of hosts
@@ -60,7 +67,7 @@ type Monitor struct {
 	AuthToken         string `gorm:"type:varchar(255)" json:"auth_token"`
 	EventToken        string `gorm:"size:64;uniqueIndex" json:"event_token"`
 	Description       string `gorm:"type:varchar(1024)" json:"description"`
-	Type              int    `gorm:"type:tinyint" json:"type"`                    // 1 = snmp, 2 = zabbix, 3 = other
+	Type              int    `gorm:"type:tinyint" json:"type"`                    // 1 = snmp, 2 = zabbix, 3 = other, 5 = prometheus (4 is reserved)
 	Enabled           int    `gorm:"type:tinyint;default:1" json:"enabled"`       // 0 = disabled, 1 = enabled
 	Status            int    `gorm:"type:tinyint" json:"status"`                  // 0 = inactive, 1 = active, 2 = error, 3 = syncing
 	StatusDescription string `gorm:"type:varchar(512)" json:"status_description"` // Reason for error status (e.g., "connection timeout", "authentication failed")
@@ -70,19 +77,22 @@ type Monitor struct {
 // Alarm represents an external alert source (e.g., Zabbix)
 type Alarm struct {
 	gorm.Model
-	MonitorID         *uint   `gorm:"index;type:bigint unsigned" json:"monitor_id"`
-	Monitor           Monitor `gorm:"foreignKey:MonitorID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
-	Name              string  `gorm:"type:varchar(255)" json:"name"`
-	URL               string  `gorm:"type:varchar(512)" json:"url"`
-	Username          string  `gorm:"type:varchar(100)" json:"username"`
-	Password          string  `gorm:"type:varchar(255)" json:"password"`
-	AuthToken         string  `gorm:"type:varchar(255)" json:"auth_token"`
-	EventToken        string  `gorm:"size:64;uniqueIndex" json:"event_token"`
-	Description       string  `gorm:"type:varchar(1024)" json:"description"`
-	Type              int     `gorm:"type:tinyint" json:"type"`                    // 1 = snmp, 2 = zabbix, 3 = other
-	Enabled           int     `gorm:"type:tinyint;default:1" json:"enabled"`       // 0 = disabled, 1 = enabled
-	Status            int     `gorm:"type:tinyint" json:"status"`                  // 0 = inactive, 1 = active, 2 = error, 3 = syncing
-	StatusDescription string  `gorm:"type:varchar(512)" json:"status_description"` // Reason for error status (e.g., "connection timeout", "authentication failed")
+	MonitorID          *uint   `gorm:"index;type:bigint unsigned" json:"monitor_id"`
+	Monitor            Monitor `gorm:"foreignKey:MonitorID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
+	Name               string  `gorm:"type:varchar(255)" json:"name"`
+	URL                string  `gorm:"type:varchar(512)" json:"url"`
+	Username           string  `gorm:"type:varchar(100)" json:"username"`
+	Password           string  `gorm:"type:varchar(255)" json:"password"`
+	AuthToken          string  `gorm:"type:varchar(255)" json:"auth_token"`
+	EventToken         string  `gorm:"size:64;uniqueIndex" json:"event_token"`
+	Description        string  `gorm:"type:varchar(1024)" json:"description"`
+	Type               int     `gorm:"type:tinyint" json:"type"`                    // 1 = zabbix, 2/3 = other, 4 = alertmanager
+	Enabled            int     `gorm:"type:tinyint;default:1" json:"enabled"`       // 0 = disabled, 1 = enabled
+	Status             int     `gorm:"type:tinyint" json:"status"`                  // 0 = inactive, 1 = active, 2 = error, 3 = syncing
+	StatusDescription  string  `gorm:"type:varchar(512)" json:"status_description"` // Reason for error status (e.g., "connection timeout", "authentication failed")
+	PayloadMapping     string  `gorm:"type:text" json:"payload_mapping"`            // JSON mapping of generic webhook payloads to alert fields
+	SigningSecret      string  `gorm:"type:varchar(255)" json:"-"`                  // Encrypted HMAC-SHA256 webhook secret; empty disables signature checks
+	SignatureTolerance int     `gorm:"default:300" json:"signature_tolerance"`      // Seconds a signed timestamp stays valid
 }
 
 // Item represents a monitoring item/metric
@@ -91,7 +101,7 @@ type Item struct {
 	Name              string     `gorm:"type:varchar(255)" json:"name"`
 	HostID            uint       `gorm:"index;type:bigint unsigned" json:"host_id"` // Internal host ID (foreign key to hosts table)
 	Host              Host       `gorm:"foreignKey:HostID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
-	ExternalID        string     `gorm:"column:external_id;type:varchar(64)" json:"external_id"` // External ID from monitoring system
+	ExternalID        string     `gorm:"column:external_id;type:varchar(255)" json:"external_id"` // External ID from monitoring system (Prometheus series selectors can be long)
 	ValueType         string     `gorm:"type:varchar(100)" json:"value_type"`
 	LastValue         string     `gorm:"type:text" json:"last_value"`
 	Units             string     `gorm:"type:varchar(100)" json:"units"`
@@ -174,26 +184,110 @@ type NetworkStatusHistory struct {
 // Alert represents an alert/notification
 type Alert struct {
 	gorm.Model
-	Message    string `gorm:"type:varchar(2048)" json:"message"`
-	ExternalID string `gorm:"column:external_id;type:varchar(128);index" json:"external_id"`
-	Severity   int    `gorm:"type:tinyint" json:"severity"`              // 0=none, 1=info, 2=warn, 3=avg, 4=high, 5=crit
-	Status     int    `gorm:"type:tinyint" json:"status"`                // 0 = active, 1 = acknowledged, 2 = resolved
-	AlarmID    *uint  `gorm:"column:alarm_id;type:bigint unsigned" json:"alarm_id"`
-	Alarm      *Alarm `gorm:"foreignKey:AlarmID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
-	ItemID     *uint  `gorm:"type:bigint unsigned" json:"item_id"`
-	Item       *Item  `gorm:"foreignKey:ItemID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
-	Comment    string `gorm:"type:text" json:"comment"`
+	Message         string     `gorm:"type:varchar(2048)" json:"message"`
+	ExternalID      string     `gorm:"column:external_id;type:varchar(128);index" json:"external_id"`
+	Severity        int        `gorm:"type:tinyint" json:"severity"` // 0=none, 1=info, 2=warn, 3=avg, 4=high, 5=crit
+	Status          int        `gorm:"type:tinyint" json:"status"`   // 0 = active, 1 = acknowledged, 2 = resolved
+	AlarmID         *uint      `gorm:"column:alarm_id;type:bigint unsigned" json:"alarm_id"`
+	Alarm           *Alarm     `gorm:"foreignKey:AlarmID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
+	ItemID          *uint      `gorm:"type:bigint unsigned" json:"item_id"`
+	Item            *Item      `gorm:"foreignKey:ItemID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
+	Comment         string     `gorm:"type:text" json:"comment"`
+	Labels          string     `gorm:"type:text" json:"labels"`                // JSON object of source labels (e.g. Alertmanager)
+	Annotations     string     `gorm:"type:text" json:"annotations"`           // JSON object of source annotations
+	Fingerprint     string     `gorm:"type:char(64);index" json:"fingerprint"` // sha256 of alarm, external ID, item and normalized message
+	OccurrenceCount int        `gorm:"default:1" json:"occurrence_count"`      // Deliveries folded into this alert while unresolved
+	LastSeenAt      *time.Time `json:"last_seen_at"`
+	IncidentID      *uint      `gorm:"index;type:bigint unsigned" json:"incident_id"`     // Incident the alert was correlated into
+	EscalationLevel int        `gorm:"default:0" json:"escalation_level"`                 // Escalation steps already fired for this alert
+	SilenceID       *uint      `gorm:"index;type:bigint unsigned" json:"silence_id"`      // Silence that suppressed notifications for this alert
+	NotifiedActions []uint     `gorm:"type:json;serializer:json" json:"notified_actions"` // Actions that delivered the problem message
+}
+
+// Silence mutes notifications for matching alerts, either as a planned maintenance window or an ad-hoc silence.
+// Every matcher that is set must match; the window is StartAt..EndAt, or DurationMinutes after each CronExpr firing.
+type Silence struct {
+	gorm.Model
+	Name            string     `gorm:"type:varchar(255)" json:"name"`
+	Kind            string     `gorm:"type:varchar(16);default:'silence'" json:"kind"` // "maintenance" or "silence"
+	Enabled         int        `gorm:"type:tinyint;default:1" json:"enabled"`          // 0 = disabled, 1 = enabled
+	HostID          *uint      `gorm:"type:bigint unsigned" json:"host_id"`
+	GroupID         *uint      `gorm:"type:bigint unsigned" json:"group_id"`
+	ItemID          *uint      `gorm:"type:bigint unsigned" json:"item_id"`
+	Severities      []int      `gorm:"type:json;serializer:json" json:"severities"`
+	MessageRegex    string     `gorm:"type:varchar(512)" json:"message_regex"`
+	StartAt         *time.Time `json:"start_at"`
+	EndAt           *time.Time `gorm:"index" json:"end_at"`
+	CronExpr        string     `gorm:"type:varchar(128)" json:"cron_expr"`
+	DurationMinutes int        `json:"duration_minutes"`
+	Reason          string     `gorm:"type:varchar(1024)" json:"reason"`
+	CreatedByID     *uint      `gorm:"type:bigint unsigned" json:"created_by_id"`
+	SuppressedCount int        `gorm:"default:0" json:"suppressed_count"`
+	// Optional mirror as a Zabbix maintenance on the matched host or group's monitor
+	PushToZabbix        int    `gorm:"type:tinyint;default:0" json:"push_to_zabbix"`
+	ZabbixMonitorID     *uint  `gorm:"type:bigint unsigned" json:"zabbix_monitor_id"`
+	ZabbixMaintenanceID string `gorm:"type:varchar(64)" json:"zabbix_maintenance_id"`
+	ZabbixSyncError     string `gorm:"type:varchar(1024)" json:"zabbix_sync_error"`
+}
+
+// Incident groups correlated alerts into one problem with its own lifecycle
+type Incident struct {
+	gorm.Model
+	Title          string     `gorm:"type:varchar(255)" json:"title"`
+	Status         int        `gorm:"type:tinyint;index" json:"status"`           // 0 = open, 1 = acknowledged, 2 = mitigated, 3 = resolved
+	Severity       int        `gorm:"type:tinyint" json:"severity"`               // Highest severity among attached alerts
+	GroupID        *uint      `gorm:"index;type:bigint unsigned" json:"group_id"` // Host group the incident was correlated on
+	HostID         *uint      `gorm:"type:bigint unsigned" json:"host_id"`
+	Labels         string     `gorm:"type:text" json:"labels"` // JSON object of labels shared by the root alert
+	RootAlertID    *uint      `gorm:"type:bigint unsigned" json:"root_alert_id"`
+	CommanderID    *uint      `gorm:"type:bigint unsigned" json:"commander_id"`
+	Commander      *User      `gorm:"foreignKey:CommanderID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
+	Summary        string     `gorm:"type:text" json:"summary"`
+	AlertCount     int        `gorm:"default:0" json:"alert_count"`
+	LastAlertAt    *time.Time `json:"last_alert_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	MitigatedAt    *time.Time `json:"mitigated_at"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+}
+
+// IncidentEvent is one entry of an incident timeline
+type IncidentEvent struct {
+	gorm.Model
+	IncidentID uint   `gorm:"index;type:bigint unsigned" json:"incident_id"`
+	Kind       string `gorm:"type:varchar(50)" json:"kind"` // "created", "alert_attached", "status_changed", "commander_changed", "severity_changed", "note"
+	Message    string `gorm:"type:text" json:"message"`
+	UserID     *uint  `gorm:"type:bigint unsigned" json:"user_id"` // nil for system events
+	AlertID    *uint  `gorm:"type:bigint unsigned" json:"alert_id"`
 }
 
 // Media represents a notification delivery target
 type Media struct {
 	gorm.Model
 	Name        string `gorm:"type:varchar(100)" json:"name"`
-	Type        string `gorm:"type:varchar(50)" json:"type"`          // "email", "other", "qq", etc.
+	Type        string `gorm:"type:varchar(50)" json:"type"`          // "email", "qq", "dingtalk", "feishu", "lark", "wecom", "other", etc.
 	Target      string `gorm:"type:varchar(255)" json:"target"`       // address/endpoint/number
 	Enabled     int    `gorm:"type:tinyint;default:1" json:"enabled"` // 0 = disabled, 1 = enabled
 	Status      int    `gorm:"type:tinyint" json:"status"`            // 0 = inactive, 1 = active, 2 = error, 3 = syncing
 	Description string `gorm:"type:varchar(1024)" json:"description"`
+	// Webhook customizes the HTTP request of "webhook" and "other" media; nil posts {"message": ...}
+	Webhook *MediaWebhookConfig `gorm:"type:json;serializer:json" json:"webhook"`
+}
+
+// MediaWebhookConfig describes the HTTP request a webhook media sends and how its response is judged
+type MediaWebhookConfig struct {
+	Method           string            `json:"method"` // GET, POST (default), PUT, PATCH or DELETE
+	Headers          map[string]string `json:"headers"`
+	AuthType         string            `json:"auth_type"` // "", "bearer" or "basic"
+	Token            string            `json:"token"`
+	Username         string            `json:"username"`
+	Password         string            `json:"password"`
+	ContentType      string            `json:"content_type"`  // Defaults to application/json
+	BodyTemplate     string            `json:"body_template"` // Go template over the alert context; empty sends {"message": ...}
+	TimeoutSeconds   int               `json:"timeout_seconds"`
+	SkipTLSVerify    bool              `json:"skip_tls_verify"`
+	CACert           string            `json:"ca_cert"`           // PEM certificates trusted in addition to the system roots
+	SuccessStatuses  string            `json:"success_statuses"`  // e.g. "200-299,302"; empty accepts any 2xx
+	ResponseContains string            `json:"response_contains"` // Text the response body must contain
 }
 
 // Action represents an action executed for alerts
@@ -208,6 +302,141 @@ type Action struct {
 	SeverityMin *int   `gorm:"type:tinyint;default:0" json:"severity_min"` // Filter alerts with severity >= this
 	AlertStatus *int   `gorm:"type:tinyint;default:0" json:"alert_status"` // Filter by alert status (0=active, 1=ack, 2=resolved)
 	Users       []User `gorm:"many2many:action_users;" json:"-"`
+	// Send a recovery message through the same media when an alert this action notified resolves
+	NotifyRecovery int `gorm:"type:tinyint;default:0" json:"notify_recovery"`
+	// Go text/template subject and body; empty templates fall back to the built-in message
+	SubjectTemplate         string `gorm:"type:varchar(512)" json:"subject_template"`
+	BodyTemplate            string `gorm:"type:text" json:"body_template"`
+	RecoverySubjectTemplate string `gorm:"type:varchar(512)" json:"recovery_subject_template"`
+	RecoveryBodyTemplate    string `gorm:"type:text" json:"recovery_body_template"`
+	// Escalation policy started for alerts this action matches
+	EscalationPolicyID *uint             `gorm:"type:bigint unsigned" json:"escalation_policy_id"`
+	EscalationPolicy   *EscalationPolicy `gorm:"foreignKey:EscalationPolicyID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
+	// Whoever is on call for this schedule at send time is notified alongside Users
+	OnCallScheduleID *uint           `gorm:"type:bigint unsigned" json:"on_call_schedule_id"`
+	OnCallSchedule   *OnCallSchedule `gorm:"foreignKey:OnCallScheduleID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
+	// Messages held back by rate limits, or past DigestThreshold per recipient within the window, are sent as one digest per window
+	DigestEnabled       int `gorm:"type:tinyint;default:1" json:"digest_enabled"`
+	DigestWindowSeconds int `gorm:"default:300" json:"digest_window_seconds"`
+	DigestThreshold     int `gorm:"default:0" json:"digest_threshold"` // 0 = only digest messages the rate limiter holds back
+	DigestMaxMessages   int `gorm:"default:10" json:"digest_max_messages"`
+}
+
+// EscalationPolicy is an ordered list of notification steps for alerts nobody acknowledges
+type EscalationPolicy struct {
+	gorm.Model
+	Name        string           `gorm:"type:varchar(255)" json:"name"`
+	Description string           `gorm:"type:varchar(1024)" json:"description"`
+	Enabled     int              `gorm:"type:tinyint;default:1" json:"enabled"` // 0 = disabled, 1 = enabled
+	Steps       []EscalationStep `gorm:"type:json;serializer:json" json:"steps"`
+}
+
+// EscalationStep notifies a media and users once the previous step has waited DelayMinutes
+type EscalationStep struct {
+	DelayMinutes int    `json:"delay_minutes"` // Wait after the previous step (or alert creation) before firing
+	MediaID      uint   `json:"media_id"`
+	UserIDs      []uint `json:"user_ids"`
+	ScheduleID   uint   `json:"schedule_id"` // Optional on-call schedule resolved when the step fires
+	Repeat       int    `json:"repeat"`      // Extra times the step is re-sent, DelayMinutes apart, before moving on
+}
+
+// OnCallSchedule rotates users through on-call shifts; later layers take precedence over earlier ones
+type OnCallSchedule struct {
+	gorm.Model
+	Name        string        `gorm:"type:varchar(255)" json:"name"`
+	Description string        `gorm:"type:varchar(1024)" json:"description"`
+	TimeZone    string        `gorm:"type:varchar(64);default:'UTC'" json:"time_zone"` // IANA name, e.g. "Asia/Shanghai"
+	Enabled     int           `gorm:"type:tinyint;default:1" json:"enabled"`           // 0 = disabled, 1 = enabled
+	Layers      []OnCallLayer `gorm:"type:json;serializer:json" json:"layers"`
+}
+
+// OnCallLayer hands off to the next user every day or week at HandoffTime in the schedule's time zone
+type OnCallLayer struct {
+	Name        string `json:"name"`
+	Rotation    string `json:"rotation"`     // "daily" or "weekly"
+	StartDate   string `json:"start_date"`   // YYYY-MM-DD of the first shift; weekly handoffs happen on this weekday
+	HandoffTime string `json:"handoff_time"` // HH:MM, defaults to 00:00
+	UserIDs     []uint `json:"user_ids"`     // Rotation order
+}
+
+// OnCallOverride temporarily puts a user on call for a schedule
+type OnCallOverride struct {
+	gorm.Model
+	ScheduleID  uint      `gorm:"index;type:bigint unsigned" json:"schedule_id"`
+	UserID      uint      `gorm:"type:bigint unsigned" json:"user_id"`
+	User        User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	StartAt     time.Time `gorm:"index" json:"start_at"`
+	EndAt       time.Time `gorm:"index" json:"end_at"`
+	Reason      string    `gorm:"type:varchar(255)" json:"reason"`
+	CreatedByID *uint     `gorm:"type:bigint unsigned" json:"created_by_id"`
+}
+
+// AlertEscalation tracks an escalation policy running for one alert
+type AlertEscalation struct {
+	gorm.Model
+	AlertID     uint       `gorm:"uniqueIndex:idx_alert_escalation;type:bigint unsigned" json:"alert_id"`
+	PolicyID    uint       `gorm:"uniqueIndex:idx_alert_escalation;type:bigint unsigned" json:"policy_id"`
+	StepIndex   int        `json:"step_index"`                       // Next step to fire
+	StepRepeats int        `json:"step_repeats"`                     // Times the next step has already fired
+	Status      int        `gorm:"type:tinyint;index" json:"status"` // 0 = running, 1 = acknowledged, 2 = resolved, 3 = completed, 4 = cancelled
+	NextRunAt   *time.Time `gorm:"index" json:"next_run_at"`
+	LastRunAt   *time.Time `json:"last_run_at"`
+}
+
+// AlertEscalationEvent records one fired escalation step on an alert
+type AlertEscalationEvent struct {
+	gorm.Model
+	AlertID   uint   `gorm:"index;type:bigint unsigned" json:"alert_id"`
+	PolicyID  uint   `gorm:"type:bigint unsigned" json:"policy_id"`
+	StepIndex int    `json:"step_index"`
+	Attempt   int    `json:"attempt"` // 1 for the first send of a step, 2+ for repeats
+	Targets   string `gorm:"type:text" json:"targets"`
+	Sent      int    `json:"sent"` // Messages queued in the notification outbox
+	Failed    int    `json:"failed"`
+	Error     string `gorm:"type:text" json:"error"`
+}
+
+// NotificationDelivery is one outbound message in the notification outbox, kept as an audit trail once delivered
+type NotificationDelivery struct {
+	gorm.Model
+	Kind          string     `gorm:"type:varchar(16);index" json:"kind"` // "alert", "recovery", "escalation" or "incident"
+	AlertID       *uint      `gorm:"index;type:bigint unsigned" json:"alert_id"`
+	IncidentID    *uint      `gorm:"index;type:bigint unsigned" json:"incident_id"`
+	ActionID      *uint      `gorm:"type:bigint unsigned" json:"action_id"`
+	MediaID       uint       `gorm:"index;type:bigint unsigned" json:"media_id"`
+	MediaType     string     `gorm:"type:varchar(64)" json:"media_type"`
+	Target        string     `gorm:"type:varchar(512)" json:"target"`
+	UserID        *uint      `gorm:"type:bigint unsigned" json:"user_id"` // Recipient user when sent to a bound or on-call user
+	Message       string     `gorm:"type:text" json:"message"`
+	Status        int        `gorm:"type:tinyint;index:idx_delivery_due" json:"status"` // 0 = pending, 1 = sending, 2 = sent, 3 = failed, 4 = skipped, 5 = digested
+	Attempts      int        `gorm:"default:0" json:"attempts"`
+	MaxAttempts   int        `gorm:"default:5" json:"max_attempts"`
+	NextAttemptAt *time.Time `gorm:"index:idx_delivery_due" json:"next_attempt_at"`
+	LastAttemptAt *time.Time `json:"last_attempt_at"`
+	SentAt        *time.Time `json:"sent_at"`
+	LastError     string     `gorm:"type:text" json:"last_error"`
+	DigestID      *uint      `gorm:"index;type:bigint unsigned" json:"digest_id"` // Digest this message was folded into
+	ResponseCode  int        `json:"response_code"`                               // HTTP status of the last webhook attempt
+	ResponseBody  string     `gorm:"type:text" json:"response_body"`              // Start of the last webhook response, for debugging
+}
+
+// NotificationDigest collects the messages held back for one media target during a window and is sent as a single summary
+type NotificationDigest struct {
+	gorm.Model
+	MediaID        uint           `gorm:"index:idx_digest_open;type:bigint unsigned" json:"media_id"`
+	Target         string         `gorm:"type:varchar(512);index:idx_digest_open" json:"target"`
+	ActionID       *uint          `gorm:"type:bigint unsigned;index:idx_digest_open" json:"action_id"` // Action whose digest settings apply; nil for escalation steps
+	UserID         *uint          `gorm:"type:bigint unsigned" json:"user_id"`
+	Status         int            `gorm:"type:tinyint;index:idx_digest_open" json:"status"` // 0 = collecting, 1 = flushed
+	WindowStart    time.Time      `json:"window_start"`
+	FlushAt        time.Time      `gorm:"index" json:"flush_at"`
+	MaxMessages    int            `json:"max_messages"`
+	Count          int            `gorm:"default:0" json:"count"`
+	SeverityCounts map[string]int `gorm:"type:json;serializer:json" json:"severity_counts"` // Severity label -> messages
+	HostCounts     map[string]int `gorm:"type:json;serializer:json" json:"host_counts"`     // Host name -> messages
+	Messages       []string       `gorm:"type:json;serializer:json" json:"messages"`        // First MaxMessages subject lines
+	DeliveryID     *uint          `gorm:"type:bigint unsigned" json:"delivery_id"`          // Outbox delivery carrying the digest once flushed
+	FlushedAt      *time.Time     `json:"flushed_at"`
 }
 
 // Trigger represents a rule that filters alerts or logs to invoke an action
@@ -244,13 +473,44 @@ type Provider struct {
 // Chat represents a chat message
 type Chat struct {
 	gorm.Model
-	UserID     uint     `json:"user_id"`
-	User       User     `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
-	ProviderID uint     `json:"provider_id"`
-	Provider   Provider `gorm:"foreignKey:ProviderID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
-	LLMModel   string   `gorm:"column:model;type:varchar(100)" json:"model"`
-	Role       string   `gorm:"type:varchar(50)" json:"role"` // "user" or "assistant"
-	Content    string   `gorm:"type:text" json:"content"`
+	UserID         uint     `json:"user_id"`
+	User           User     `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	ConversationID *uint    `gorm:"index;type:bigint unsigned" json:"conversation_id"` // nil for messages sent before conversations existed
+	ProviderID     uint     `json:"provider_id"`
+	Provider       Provider `gorm:"foreignKey:ProviderID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	LLMModel       string   `gorm:"column:model;type:varchar(100)" json:"model"`
+	Role           string   `gorm:"type:varchar(50)" json:"role"` // "user" or "assistant"
+	Content        string   `gorm:"type:text" json:"content"`
+}
+
+// Conversation is a chat thread owned by one user; its messages are the Chat rows pointing at it
+type Conversation struct {
+	gorm.Model
+	UserID      uint       `gorm:"index" json:"user_id"`
+	User        User       `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Title       string     `gorm:"type:varchar(255)" json:"title"`
+	ProviderID  uint       `json:"provider_id"` // Provider and model of the latest answer
+	LLMModel    string     `gorm:"column:model;type:varchar(100)" json:"model"`
+	ContextType string     `gorm:"type:varchar(20)" json:"context_type"` // Pinned object: "", "alert", "host" or "item"
+	ContextID   *uint      `gorm:"type:bigint unsigned" json:"context_id"`
+	Summary     string     `gorm:"type:text" json:"summary"`       // Summary of the turns that left the history window
+	SummaryUpTo uint       `gorm:"default:0" json:"summary_up_to"` // ID of the last message covered by Summary
+	LastChatAt  *time.Time `json:"last_chat_at"`
+}
+
+// LLMUsage records one LLM call for token accounting and budgets
+type LLMUsage struct {
+	gorm.Model
+	ProviderID       uint   `gorm:"index:idx_llm_usage_provider,priority:1;type:bigint unsigned" json:"provider_id"`
+	LLMModel         string `gorm:"column:model;type:varchar(100)" json:"model"`
+	Feature          string `gorm:"type:varchar(50);index" json:"feature"`                                   // e.g. "chat", "tool_chat", "alert_analysis", "report_summary"
+	UserID           *uint  `gorm:"index:idx_llm_usage_user,priority:1;type:bigint unsigned" json:"user_id"` // Nil for background work
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+	TotalTokens      int    `json:"total_tokens"`
+	LatencyMs        int64  `json:"latency_ms"`
+	Outcome          string `gorm:"type:varchar(20);index" json:"outcome"` // "success", "error", "timeout" or "canceled"
+	Error            string `gorm:"type:varchar(512)" json:"error"`
 }
 
 // ChatMessage is used for AI interactions
@@ -304,6 +564,7 @@ type User struct {
 	Introduction string     `gorm:"type:text" json:"introduction"`
 	Nickname     string     `gorm:"size:100" json:"nickname"`
 	QQ           string     `gorm:"size:20" json:"qq"`
+	TelegramID   string     `gorm:"size:32" json:"telegram_id"`
 }
 
 // RegisterApplication represents a pending registration request from an unregistered user
@@ -42,4 +42,7 @@ var (
 
 	// ErrForbidden indicates a forbidden action
 	ErrForbidden = errors.New("forbidden")
+
+	// ErrBudgetExceeded indicates a usage budget is used up
+	ErrBudgetExceeded = errors.New("budget exceeded")
 )
@@ -1,18 +1,37 @@
 package model
 
+import "time"
+
 // AlertFilter represents search and filter options for alerts
 // Query matches alert message (LIKE)
 type AlertFilter struct {
-	Query     string
-	Severity  *int
-	Status    *int
-	AlarmID   *int
-	HostID    *int
-	ItemID    *int
-	Limit     int
-	Offset    int
-	SortBy    string
-	SortOrder string
+	Query      string
+	Severity   *int
+	Status     *int
+	AlarmID    *int
+	HostID     *int
+	ItemID     *int
+	IncidentID *int
+	Suppressed *bool
+	Limit      int
+	Offset     int
+	SortBy     string
+	SortOrder  string
+}
+
+// IncidentFilter represents search and filter options for incidents
+// Query matches title/summary (LIKE); Active limits results to unresolved incidents
+type IncidentFilter struct {
+	Query       string
+	Status      *int
+	Severity    *int
+	GroupID     *int
+	CommanderID *int
+	Active      bool
+	Limit       int
+	Offset      int
+	SortBy      string
+	SortOrder   string
 }
 
 // MediaFilter represents search and filter options for media
@@ -38,6 +57,41 @@ type ActionFilter struct {
 	SortOrder string
 }
 
+// EscalationPolicyFilter represents search and filter options for escalation policies
+// Query matches name/description (LIKE)
+type EscalationPolicyFilter struct {
+	Query     string
+	Enabled   *int
+	Limit     int
+	Offset    int
+	SortBy    string
+	SortOrder string
+}
+
+// OnCallScheduleFilter represents search and filter options for on-call schedules
+// Query matches name/description (LIKE)
+type OnCallScheduleFilter struct {
+	Query     string
+	Enabled   *int
+	Limit     int
+	Offset    int
+	SortBy    string
+	SortOrder string
+}
+
+// SilenceFilter represents search and filter options for silences and maintenance windows
+// Query matches name/reason (LIKE); Current limits results to enabled silences that have not ended
+type SilenceFilter struct {
+	Query     string
+	Kind      string
+	Enabled   *int
+	Current   bool
+	Limit     int
+	Offset    int
+	SortBy    string
+	SortOrder string
+}
+
 // TriggerFilter represents search and filter options for triggers
 // Query matches name (LIKE)
 type TriggerFilter struct {
@@ -171,13 +225,69 @@ type RegisterApplicationFilter struct {
 // ChatFilter represents search and filter options for chats
 // Query matches content (LIKE)
 type ChatFilter struct {
+	Query          string
+	Role           *string
+	ProviderID     *int
+	UserID         *int
+	ConversationID *int
+	Model          *string
+	Limit          int
+	Offset         int
+	SortBy         string
+	SortOrder      string
+}
+
+// ConversationFilter represents search and filter options for chat conversations
+// Query matches the title (LIKE)
+type ConversationFilter struct {
+	Query     string
+	UserID    *uint
+	Limit     int
+	Offset    int
+	SortBy    string
+	SortOrder string
+}
+
+// LLMUsageFilter represents search and filter options for LLM usage records
+type LLMUsageFilter struct {
+	ProviderID *uint
+	UserID     *uint
+	Feature    string
+	Outcome    string
+	Model      string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+	SortBy     string
+	SortOrder  string
+}
+
+// NotificationDeliveryFilter represents search and filter options for the notification outbox
+// Query matches target/message/last error (LIKE)
+type NotificationDeliveryFilter struct {
 	Query      string
-	Role       *string
-	ProviderID *int
-	UserID     *int
-	Model      *string
+	Kind       string
+	Status     *int
+	AlertID    *uint
+	IncidentID *uint
+	ActionID   *uint
+	MediaID    *uint
+	DigestID   *uint
+	From       *time.Time
+	To         *time.Time
 	Limit      int
 	Offset     int
 	SortBy     string
 	SortOrder  string
 }
+
+// NotificationDigestFilter represents search and filter options for notification digests
+type NotificationDigestFilter struct {
+	Status    *int
+	MediaID   *uint
+	Limit     int
+	Offset    int
+	SortBy    string
+	SortOrder string
+}
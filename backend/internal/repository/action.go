@@ -99,13 +99,24 @@ func UpdateActionDAO(id uint, action model.Action) error {
 	}()
 
 	if err := tx.Model(&model.Action{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"name":         action.Name,
-		"media_id":     action.MediaID,
-		"enabled":      action.Enabled,
-		"status":       action.Status,
-		"description":  action.Description,
-		"severity_min": action.SeverityMin,
-		"alert_status": action.AlertStatus,
+		"name":                      action.Name,
+		"media_id":                  action.MediaID,
+		"enabled":                   action.Enabled,
+		"status":                    action.Status,
+		"description":               action.Description,
+		"severity_min":              action.SeverityMin,
+		"alert_status":              action.AlertStatus,
+		"notify_recovery":           action.NotifyRecovery,
+		"subject_template":          action.SubjectTemplate,
+		"body_template":             action.BodyTemplate,
+		"recovery_subject_template": action.RecoverySubjectTemplate,
+		"recovery_body_template":    action.RecoveryBodyTemplate,
+		"escalation_policy_id":      action.EscalationPolicyID,
+		"on_call_schedule_id":       action.OnCallScheduleID,
+		"digest_enabled":            action.DigestEnabled,
+		"digest_window_seconds":     action.DigestWindowSeconds,
+		"digest_threshold":          action.DigestThreshold,
+		"digest_max_messages":       action.DigestMaxMessages,
 	}).Error; err != nil {
 		tx.Rollback()
 		return err
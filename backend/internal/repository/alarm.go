@@ -108,18 +108,20 @@ func DeleteAlarmByIDDAO(id int) error {
 // UpdateAlarmDAO updates an alarm by ID
 func UpdateAlarmDAO(id int, a model.Alarm) error {
 	return database.DB.Model(&model.Alarm{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"monitor_id":         a.MonitorID,
-		"name":               a.Name,
-		"url":                a.URL,
-		"username":           a.Username,
-		"password":           a.Password,
-		"auth_token":         a.AuthToken,
-		"event_token":        a.EventToken,
-		"description":        a.Description,
-		"type":               a.Type,
-		"enabled":            a.Enabled,
-		"status":             a.Status,
-		"status_description": a.StatusDescription,
+		"monitor_id":          a.MonitorID,
+		"name":                a.Name,
+		"url":                 a.URL,
+		"username":            a.Username,
+		"password":            a.Password,
+		"auth_token":          a.AuthToken,
+		"event_token":         a.EventToken,
+		"description":         a.Description,
+		"type":                a.Type,
+		"enabled":             a.Enabled,
+		"status":              a.Status,
+		"status_description":  a.StatusDescription,
+		"payload_mapping":     a.PayloadMapping,
+		"signature_tolerance": a.SignatureTolerance,
 	}).Error
 }
 
@@ -145,3 +147,8 @@ func UpdateAlarmAuthTokenDAO(id uint, authToken string) error {
 func UpdateAlarmEventTokenDAO(id uint, eventToken string) error {
 	return database.DB.Model(&model.Alarm{}).Where("id = ?", id).Update("event_token", eventToken).Error
 }
+
+// UpdateAlarmSigningSecretDAO updates only the (encrypted) webhook signing secret for an alarm
+func UpdateAlarmSigningSecretDAO(id uint, secret string) error {
+	return database.DB.Model(&model.Alarm{}).Where("id = ?", id).Update("signing_secret", secret).Error
+}
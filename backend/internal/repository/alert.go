@@ -4,6 +4,7 @@ import (
 	"nagare/internal/database"
 	"nagare/internal/model"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -38,18 +39,30 @@ func applyAlertCoreFilters(query *gorm.DB, filter model.AlertFilter) *gorm.DB {
 	if filter.ItemID != nil {
 		query = query.Where("alerts.item_id = ?", *filter.ItemID)
 	}
+	if filter.IncidentID != nil {
+		query = query.Where("alerts.incident_id = ?", *filter.IncidentID)
+	}
+	if filter.Suppressed != nil {
+		if *filter.Suppressed {
+			query = query.Where("alerts.silence_id IS NOT NULL")
+		} else {
+			query = query.Where("alerts.silence_id IS NULL")
+		}
+	}
 	return query
 }
 
 func applyAlertSort(query *gorm.DB, sortBy, sortOrder string) *gorm.DB {
 	return applySort(query, sortBy, sortOrder, map[string]string{
-		"name":       "alerts.message",
-		"message":    "alerts.message",
-		"severity":   "alerts.severity",
-		"status":     "alerts.status",
-		"created_at": "alerts.created_at",
-		"updated_at": "alerts.updated_at",
-		"id":         "alerts.id",
+		"name":             "alerts.message",
+		"message":          "alerts.message",
+		"severity":         "alerts.severity",
+		"status":           "alerts.status",
+		"created_at":       "alerts.created_at",
+		"updated_at":       "alerts.updated_at",
+		"id":               "alerts.id",
+		"occurrence_count": "alerts.occurrence_count",
+		"last_seen_at":     "alerts.last_seen_at",
 	}, "alerts.id desc")
 }
 
@@ -120,14 +133,26 @@ func SearchAlertsDAO(filter model.AlertFilter) ([]model.Alert, error) {
 	if filter.ItemID != nil {
 		query = query.Where("alerts.item_id = ?", *filter.ItemID)
 	}
+	if filter.IncidentID != nil {
+		query = query.Where("alerts.incident_id = ?", *filter.IncidentID)
+	}
+	if filter.Suppressed != nil {
+		if *filter.Suppressed {
+			query = query.Where("alerts.silence_id IS NOT NULL")
+		} else {
+			query = query.Where("alerts.silence_id IS NULL")
+		}
+	}
 	query = applySort(query, filter.SortBy, filter.SortOrder, map[string]string{
-		"name":       "alerts.message",
-		"message":    "alerts.message",
-		"severity":   "alerts.severity",
-		"status":     "alerts.status",
-		"created_at": "alerts.created_at",
-		"updated_at": "alerts.updated_at",
-		"id":         "alerts.id",
+		"name":             "alerts.message",
+		"message":          "alerts.message",
+		"severity":         "alerts.severity",
+		"status":           "alerts.status",
+		"created_at":       "alerts.created_at",
+		"updated_at":       "alerts.updated_at",
+		"id":               "alerts.id",
+		"occurrence_count": "alerts.occurrence_count",
+		"last_seen_at":     "alerts.last_seen_at",
 	}, "alerts.id desc")
 	if filter.Limit > 0 {
 		query = query.Limit(filter.Limit)
@@ -194,6 +219,16 @@ func CountAlertsDAO(filter model.AlertFilter) (int64, error) {
 	if filter.ItemID != nil {
 		query = query.Where("item_id = ?", *filter.ItemID)
 	}
+	if filter.IncidentID != nil {
+		query = query.Where("incident_id = ?", *filter.IncidentID)
+	}
+	if filter.Suppressed != nil {
+		if *filter.Suppressed {
+			query = query.Where("silence_id IS NOT NULL")
+		} else {
+			query = query.Where("silence_id IS NULL")
+		}
+	}
 	var total int64
 	if err := query.Count(&total).Error; err != nil {
 		return 0, err
@@ -317,14 +352,18 @@ func FindLatestUnresolvedAlertByEventDAO(alarmID uint, eventID string) (model.Al
 }
 
 // FindLatestUnresolvedAlertByExternalIDDAO finds newest unresolved alert by external_id.
-func FindLatestUnresolvedAlertByExternalIDDAO(externalID string) (model.Alert, error) {
+// A non-nil alarmID limits the search to alerts of that alarm, whose external IDs may collide with other sources.
+func FindLatestUnresolvedAlertByExternalIDDAO(externalID string, alarmID *uint) (model.Alert, error) {
 	var alerts []model.Alert
 	eid := strings.TrimSpace(externalID)
 	if eid == "" {
 		return model.Alert{}, nil
 	}
-	err := database.DB.Model(&model.Alert{}).
-		Where("external_id = ? AND status <> 2", eid).
+	query := database.DB.Model(&model.Alert{}).Where("external_id = ? AND status <> 2", eid)
+	if alarmID != nil {
+		query = query.Where("alarm_id = ?", *alarmID)
+	}
+	err := query.
 		Order("id desc").
 		Limit(1).
 		Find(&alerts).Error
@@ -359,3 +398,54 @@ func FindLatestUnresolvedAlertByFingerprintDAO(alarmID uint, itemID uint, messag
 	}
 	return alerts[0], nil
 }
+
+// FindUnresolvedAlertByFingerprintHashDAO finds the newest unresolved alert with the given dedup fingerprint.
+func FindUnresolvedAlertByFingerprintHashDAO(fingerprint string) (model.Alert, error) {
+	var alerts []model.Alert
+	if strings.TrimSpace(fingerprint) == "" {
+		return model.Alert{}, nil
+	}
+	err := database.DB.Model(&model.Alert{}).
+		Where("fingerprint = ? AND status <> 2", fingerprint).
+		Order("id desc").
+		Limit(1).
+		Find(&alerts).Error
+	if err != nil {
+		return model.Alert{}, err
+	}
+	if len(alerts) == 0 {
+		return model.Alert{}, nil
+	}
+	return alerts[0], nil
+}
+
+// SetAlertNotifiedActionsDAO records which actions delivered an alert's problem message
+func SetAlertNotifiedActionsDAO(id uint, actionIDs []uint) error {
+	return database.DB.Model(&model.Alert{}).Where("id = ?", id).Select("notified_actions").Updates(&model.Alert{NotifiedActions: actionIDs}).Error
+}
+
+// IncrementAlertOccurrenceDAO folds a repeated delivery into an existing alert.
+func IncrementAlertOccurrenceDAO(id uint, seenAt time.Time) error {
+	return database.DB.Model(&model.Alert{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"occurrence_count": gorm.Expr("COALESCE(occurrence_count, 1) + 1"),
+		"last_seen_at":     seenAt,
+	}).Error
+}
+
+// SetAlertIncidentDAO links an alert to the incident it was correlated into.
+func SetAlertIncidentDAO(id uint, incidentID uint) error {
+	return database.DB.Model(&model.Alert{}).Where("id = ?", id).Update("incident_id", incidentID).Error
+}
+
+// CountUnresolvedAlertsByIncidentDAO returns how many alerts of an incident are still unresolved.
+func CountUnresolvedAlertsByIncidentDAO(incidentID uint) (int64, error) {
+	var total int64
+	err := database.DB.Model(&model.Alert{}).Where("incident_id = ? AND status <> 2", incidentID).Count(&total).Error
+	return total, err
+}
+
+// ResolveAlertsByIncidentDAO resolves every unresolved alert of an incident.
+func ResolveAlertsByIncidentDAO(incidentID uint) (int64, error) {
+	result := database.DB.Model(&model.Alert{}).Where("incident_id = ? AND status <> 2", incidentID).Update("status", 2)
+	return result.RowsAffected, result.Error
+}
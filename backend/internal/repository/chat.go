@@ -40,6 +40,9 @@ func SearchChatsDAO(filter model.ChatFilter) ([]model.Chat, error) {
 	if filter.UserID != nil {
 		query = query.Where("user_id = ?", *filter.UserID)
 	}
+	if filter.ConversationID != nil {
+		query = query.Where("conversation_id = ?", *filter.ConversationID)
+	}
 	if filter.Model != nil {
 		query = query.Where("model = ?", *filter.Model)
 	}
@@ -84,10 +87,11 @@ func DeleteChatByIDDAO(id int) error {
 // UpdateChatDAO updates a chat by ID
 func UpdateChatDAO(id int, c model.Chat) error {
 	return database.DB.Model(&model.Chat{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"user_id":     c.UserID,
-		"provider_id": c.ProviderID,
-		"role":        c.Role,
-		"content":     c.Content,
-		"model":       c.LLMModel,
+		"user_id":         c.UserID,
+		"conversation_id": c.ConversationID,
+		"provider_id":     c.ProviderID,
+		"role":            c.Role,
+		"content":         c.Content,
+		"model":           c.LLMModel,
 	}).Error
 }
@@ -23,6 +23,10 @@ type Config struct {
 	SMTP           SMTPConfig           `yaml:"smtp" json:"smtp" mapstructure:"smtp"`
 	SiteMessage    SiteMessageConfig    `yaml:"site_message" json:"site_message" mapstructure:"site_message"`
 	MediaRateLimit MediaRateLimitConfig `yaml:"media_rate_limit" json:"media_rate_limit" mapstructure:"media_rate_limit"`
+	SNMPTrap       SNMPTrapConfig       `yaml:"snmp_trap" json:"snmp_trap" mapstructure:"snmp_trap"`
+	Incident       IncidentConfig       `yaml:"incident" json:"incident" mapstructure:"incident"`
+	Notification   NotificationConfig   `yaml:"notification" json:"notification" mapstructure:"notification"`
+	Telegram       TelegramConfig       `yaml:"telegram" json:"telegram" mapstructure:"telegram"`
 	External       []ExternalItemConfig `yaml:"external" json:"external" mapstructure:"external"`
 }
 
@@ -34,12 +38,14 @@ type SiteMessageConfig struct {
 
 // SMTPConfig holds SMTP server settings
 type SMTPConfig struct {
-	Enabled  bool   `yaml:"enabled" json:"enabled" mapstructure:"enabled"`
-	Host     string `yaml:"host" json:"host" mapstructure:"host"`
-	Port     int    `yaml:"port" json:"port" mapstructure:"port"`
-	Username string `yaml:"username" json:"username" mapstructure:"username"`
-	Password string `yaml:"password" json:"password" mapstructure:"password"`
-	From     string `yaml:"from" json:"from" mapstructure:"from"`
+	Enabled    bool   `yaml:"enabled" json:"enabled" mapstructure:"enabled"`
+	Host       string `yaml:"host" json:"host" mapstructure:"host"`
+	Port       int    `yaml:"port" json:"port" mapstructure:"port"`
+	Username   string `yaml:"username" json:"username" mapstructure:"username"`
+	Password   string `yaml:"password" json:"password" mapstructure:"password"`
+	From       string `yaml:"from" json:"from" mapstructure:"from"`
+	Security   string `yaml:"security" json:"security" mapstructure:"security"` // "starttls" (default), "tls" for implicit TLS, or "none"
+	SkipVerify bool   `yaml:"skip_verify" json:"skip_verify" mapstructure:"skip_verify"`
 }
 
 // GmailConfig holds Gmail API settings
@@ -100,13 +106,51 @@ type MCPConfig struct {
 
 // AIConfig holds AI settings
 type AIConfig struct {
-	AnalysisEnabled          bool   `yaml:"analysis_enabled" json:"analysis_enabled" mapstructure:"analysis_enabled"`
-	NotificationGuardEnabled bool   `yaml:"notification_guard_enabled" json:"notification_guard_enabled" mapstructure:"notification_guard_enabled"`
-	ProviderID               int    `yaml:"provider_id" json:"provider_id" mapstructure:"provider_id"`
-	Model                    string `yaml:"model" json:"model" mapstructure:"model"`
-	AnalysisTimeoutSeconds   int    `yaml:"analysis_timeout_seconds" json:"analysis_timeout_seconds" mapstructure:"analysis_timeout_seconds"`
-	AnalysisMinSeverity      int    `yaml:"analysis_min_severity" json:"analysis_min_severity" mapstructure:"analysis_min_severity"`
-	Language                 string `yaml:"language" json:"language" mapstructure:"language"`
+	AnalysisEnabled          bool             `yaml:"analysis_enabled" json:"analysis_enabled" mapstructure:"analysis_enabled"`
+	NotificationGuardEnabled bool             `yaml:"notification_guard_enabled" json:"notification_guard_enabled" mapstructure:"notification_guard_enabled"`
+	ProviderID               int              `yaml:"provider_id" json:"provider_id" mapstructure:"provider_id"`
+	Model                    string           `yaml:"model" json:"model" mapstructure:"model"`
+	AnalysisTimeoutSeconds   int              `yaml:"analysis_timeout_seconds" json:"analysis_timeout_seconds" mapstructure:"analysis_timeout_seconds"`
+	AnalysisMinSeverity      int              `yaml:"analysis_min_severity" json:"analysis_min_severity" mapstructure:"analysis_min_severity"`
+	Language                 string           `yaml:"language" json:"language" mapstructure:"language"`
+	ChatHistoryTokenBudget   int              `yaml:"chat_history_token_budget" json:"chat_history_token_budget" mapstructure:"chat_history_token_budget"` // Older chat turns are summarized beyond this
+	Routing                  *AIRoutingConfig `yaml:"routing" json:"routing,omitempty" mapstructure:"routing"`
+	Budget                   *AIBudgetConfig  `yaml:"budget" json:"budget,omitempty" mapstructure:"budget"`
+}
+
+// AIBudgetConfig holds LLM token budgets. A limit of 0 is unlimited; the day and month follow server time.
+type AIBudgetConfig struct {
+	ProviderDailyTokens   int             `yaml:"provider_daily_tokens" json:"provider_daily_tokens" mapstructure:"provider_daily_tokens"`       // Default for every provider
+	ProviderMonthlyTokens int             `yaml:"provider_monthly_tokens" json:"provider_monthly_tokens" mapstructure:"provider_monthly_tokens"` // Default for every provider
+	UserDailyTokens       int             `yaml:"user_daily_tokens" json:"user_daily_tokens" mapstructure:"user_daily_tokens"`                   // Default for every user
+	UserMonthlyTokens     int             `yaml:"user_monthly_tokens" json:"user_monthly_tokens" mapstructure:"user_monthly_tokens"`             // Default for every user
+	Providers             []AIBudgetLimit `yaml:"providers" json:"providers" mapstructure:"providers"`                                           // Per-provider overrides
+	Users                 []AIBudgetLimit `yaml:"users" json:"users" mapstructure:"users"`                                                       // Per-user overrides
+	DegradedMinSeverity   int             `yaml:"degraded_min_severity" json:"degraded_min_severity" mapstructure:"degraded_min_severity"`       // Lowest alert severity still analyzed while a provider budget is exceeded
+}
+
+// AIBudgetLimit overrides the default budget of one provider or user
+type AIBudgetLimit struct {
+	ID            uint `yaml:"id" json:"id" mapstructure:"id"`
+	DailyTokens   int  `yaml:"daily_tokens" json:"daily_tokens" mapstructure:"daily_tokens"`
+	MonthlyTokens int  `yaml:"monthly_tokens" json:"monthly_tokens" mapstructure:"monthly_tokens"`
+}
+
+// AIRoutingConfig holds the LLM failover chain. Without routes the chain is ai.provider_id with ai.model.
+type AIRoutingConfig struct {
+	Routes                 []AIRoute `yaml:"routes" json:"routes" mapstructure:"routes"`                                                       // Tried in order
+	Retries                int       `yaml:"retries" json:"retries" mapstructure:"retries"`                                                    // Default retries per route
+	TimeoutSeconds         int       `yaml:"timeout_seconds" json:"timeout_seconds" mapstructure:"timeout_seconds"`                            // Default per-attempt timeout; 0 = request deadline only
+	BreakerThreshold       int       `yaml:"breaker_threshold" json:"breaker_threshold" mapstructure:"breaker_threshold"`                      // Consecutive failures that open a provider's circuit; 0 = off
+	BreakerCooldownSeconds int       `yaml:"breaker_cooldown_seconds" json:"breaker_cooldown_seconds" mapstructure:"breaker_cooldown_seconds"` // How long an open circuit is skipped
+}
+
+// AIRoute is one provider/model step of the failover chain
+type AIRoute struct {
+	ProviderID     int    `yaml:"provider_id" json:"provider_id" mapstructure:"provider_id"`
+	Model          string `yaml:"model" json:"model" mapstructure:"model"`                               // Empty uses the provider's default model
+	Retries        *int   `yaml:"retries" json:"retries,omitempty" mapstructure:"retries"`               // Overrides the default retries
+	TimeoutSeconds int    `yaml:"timeout_seconds" json:"timeout_seconds" mapstructure:"timeout_seconds"` // Overrides the default timeout when set
 }
 
 // MediaRateLimitConfig holds notification rate limit settings
@@ -116,6 +160,64 @@ type MediaRateLimitConfig struct {
 	MediaIntervalSeconds    int `yaml:"media_interval_seconds" json:"media_interval_seconds" mapstructure:"media_interval_seconds"`
 }
 
+// IncidentConfig holds alert correlation settings
+type IncidentConfig struct {
+	Enabled         bool     `yaml:"enabled" json:"enabled" mapstructure:"enabled"`
+	WindowMinutes   int      `yaml:"window_minutes" json:"window_minutes" mapstructure:"window_minutes"`       // Alerts join an incident that received an alert this recently
+	MinSeverity     int      `yaml:"min_severity" json:"min_severity" mapstructure:"min_severity"`             // Alerts below this severity are not correlated
+	LabelKeys       []string `yaml:"label_keys" json:"label_keys" mapstructure:"label_keys"`                   // Labels compared for similarity; empty compares all labels
+	LabelSimilarity float64  `yaml:"label_similarity" json:"label_similarity" mapstructure:"label_similarity"` // Minimum Jaccard similarity of labels, 0-1
+}
+
+// NotificationConfig holds notification outbox settings
+type NotificationConfig struct {
+	Workers            int `yaml:"workers" json:"workers" mapstructure:"workers"`                                  // Concurrent senders draining the outbox
+	MaxAttempts        int `yaml:"max_attempts" json:"max_attempts" mapstructure:"max_attempts"`                   // Attempts before a delivery is marked failed
+	RetryBaseSeconds   int `yaml:"retry_base_seconds" json:"retry_base_seconds" mapstructure:"retry_base_seconds"` // First retry delay, doubled after each failure
+	RetryMaxSeconds    int `yaml:"retry_max_seconds" json:"retry_max_seconds" mapstructure:"retry_max_seconds"`    // Upper bound of the retry delay
+	SendTimeoutSeconds int `yaml:"send_timeout_seconds" json:"send_timeout_seconds" mapstructure:"send_timeout_seconds"`
+}
+
+// TelegramConfig holds Telegram bot settings for sending and inbound commands
+type TelegramConfig struct {
+	Enabled            bool   `yaml:"enabled" json:"enabled" mapstructure:"enabled"`
+	BotToken           string `yaml:"bot_token" json:"bot_token" mapstructure:"bot_token"`
+	APIBaseURL         string `yaml:"api_base_url" json:"api_base_url" mapstructure:"api_base_url"`       // e.g. a local Bot API server or proxy
+	Mode               string `yaml:"mode" json:"mode" mapstructure:"mode"`                               // "polling" (default) or "webhook"
+	WebhookURL         string `yaml:"webhook_url" json:"webhook_url" mapstructure:"webhook_url"`          // public URL of the updates endpoint, registered with setWebhook
+	WebhookSecret      string `yaml:"webhook_secret" json:"webhook_secret" mapstructure:"webhook_secret"` // required in webhook mode, sent back by Telegram in X-Telegram-Bot-Api-Secret-Token
+	PollTimeoutSeconds int    `yaml:"poll_timeout_seconds" json:"poll_timeout_seconds" mapstructure:"poll_timeout_seconds"`
+}
+
+// SNMPTrapConfig holds SNMP trap receiver settings
+type SNMPTrapConfig struct {
+	Enabled            bool             `yaml:"enabled" json:"enabled" mapstructure:"enabled"`
+	ListenAddress      string           `yaml:"listen_address" json:"listen_address" mapstructure:"listen_address"` // e.g. "0.0.0.0:162"
+	Community          string           `yaml:"community" json:"community" mapstructure:"community"`                // empty accepts any community
+	DefaultSeverity    int              `yaml:"default_severity" json:"default_severity" mapstructure:"default_severity"`
+	AlarmID            uint             `yaml:"alarm_id" json:"alarm_id" mapstructure:"alarm_id"` // optional alarm the alerts are attributed to
+	AcceptUnknownHosts bool             `yaml:"accept_unknown_hosts" json:"accept_unknown_hosts" mapstructure:"accept_unknown_hosts"`
+	V3                 SNMPTrapV3Config `yaml:"v3" json:"v3" mapstructure:"v3"`
+	Rules              []SNMPTrapRule   `yaml:"rules" json:"rules" mapstructure:"rules"`
+}
+
+// SNMPTrapV3Config holds the optional SNMPv3 USM user for receiving v3 traps
+type SNMPTrapV3Config struct {
+	Username     string `yaml:"username" json:"username" mapstructure:"username"`
+	AuthProtocol string `yaml:"auth_protocol" json:"auth_protocol" mapstructure:"auth_protocol"`
+	AuthPassword string `yaml:"auth_password" json:"auth_password" mapstructure:"auth_password"`
+	PrivProtocol string `yaml:"priv_protocol" json:"priv_protocol" mapstructure:"priv_protocol"`
+	PrivPassword string `yaml:"priv_password" json:"priv_password" mapstructure:"priv_password"`
+}
+
+// SNMPTrapRule maps a trap OID (prefix match, longest wins) to an alert severity
+type SNMPTrapRule struct {
+	OID      string `yaml:"oid" json:"oid" mapstructure:"oid"`
+	Name     string `yaml:"name" json:"name" mapstructure:"name"`
+	Severity int    `yaml:"severity" json:"severity" mapstructure:"severity"`
+	Resolves string `yaml:"resolves" json:"resolves" mapstructure:"resolves"` // trap OID whose alert this trap clears, e.g. linkUp clears linkDown
+}
+
 // ConfigRequest is used for modifying configuration
 type ConfigRequest struct {
 	System   SystemConfig `yaml:"system" json:"system" mapstructure:"system"`
@@ -135,6 +237,10 @@ type ConfigRequest struct {
 	SMTP           SMTPConfig           `yaml:"smtp" json:"smtp" mapstructure:"smtp"`
 	SiteMessage    SiteMessageConfig    `yaml:"site_message" json:"site_message" mapstructure:"site_message"`
 	MediaRateLimit MediaRateLimitConfig `yaml:"media_rate_limit" json:"media_rate_limit" mapstructure:"media_rate_limit"`
+	SNMPTrap       *SNMPTrapConfig      `yaml:"snmp_trap" json:"snmp_trap,omitempty" mapstructure:"snmp_trap"`          // nil keeps the current settings
+	Incident       *IncidentConfig      `yaml:"incident" json:"incident,omitempty" mapstructure:"incident"`             // nil keeps the current settings
+	Notification   *NotificationConfig  `yaml:"notification" json:"notification,omitempty" mapstructure:"notification"` // nil keeps the current settings
+	Telegram       *TelegramConfig      `yaml:"telegram" json:"telegram,omitempty" mapstructure:"telegram"`             // nil keeps the current settings
 	External       []ExternalItemConfig `yaml:"external" json:"external" mapstructure:"external"`
 }
 
@@ -150,6 +256,10 @@ type ConfigResponse struct {
 	SMTP           SMTPConfig           `yaml:"smtp" json:"smtp" mapstructure:"smtp"`
 	SiteMessage    SiteMessageConfig    `yaml:"site_message" json:"site_message" mapstructure:"site_message"`
 	MediaRateLimit MediaRateLimitConfig `yaml:"media_rate_limit" json:"media_rate_limit" mapstructure:"media_rate_limit"`
+	SNMPTrap       SNMPTrapConfig       `yaml:"snmp_trap" json:"snmp_trap" mapstructure:"snmp_trap"`
+	Incident       IncidentConfig       `yaml:"incident" json:"incident" mapstructure:"incident"`
+	Notification   NotificationConfig   `yaml:"notification" json:"notification" mapstructure:"notification"`
+	Telegram       TelegramConfig       `yaml:"telegram" json:"telegram" mapstructure:"telegram"`
 	External       []ExternalItemConfig `yaml:"external" json:"external" mapstructure:"external"`
 }
 
@@ -278,6 +388,19 @@ func ResetConfig() error {
 	viper.Set("ai.analysis_timeout_seconds", 60)
 	viper.Set("ai.analysis_min_severity", 2)
 	viper.Set("ai.language", "en")
+	viper.Set("ai.chat_history_token_budget", 4000)
+	viper.Set("ai.routing.routes", []map[string]interface{}{})
+	viper.Set("ai.routing.retries", 1)
+	viper.Set("ai.routing.timeout_seconds", 0)
+	viper.Set("ai.routing.breaker_threshold", 3)
+	viper.Set("ai.routing.breaker_cooldown_seconds", 120)
+	viper.Set("ai.budget.provider_daily_tokens", 0)
+	viper.Set("ai.budget.provider_monthly_tokens", 0)
+	viper.Set("ai.budget.user_daily_tokens", 0)
+	viper.Set("ai.budget.user_monthly_tokens", 0)
+	viper.Set("ai.budget.providers", []map[string]interface{}{})
+	viper.Set("ai.budget.users", []map[string]interface{}{})
+	viper.Set("ai.budget.degraded_min_severity", 4)
 
 	viper.Set("gmail.enabled", false)
 	viper.Set("gmail.credentials_file", "configs/gmail_credentials.json")
@@ -290,6 +413,8 @@ func ResetConfig() error {
 	viper.Set("smtp.username", "")
 	viper.Set("smtp.password", "")
 	viper.Set("smtp.from", "")
+	viper.Set("smtp.security", "starttls")
+	viper.Set("smtp.skip_verify", false)
 
 	viper.Set("site_message.min_alert_severity", 0)
 	viper.Set("site_message.min_log_severity", 4)
@@ -298,18 +423,54 @@ func ResetConfig() error {
 	viper.Set("media_rate_limit.protocol_interval_seconds", 30)
 	viper.Set("media_rate_limit.media_interval_seconds", 30)
 
+	viper.Set("snmp_trap.enabled", false)
+	viper.Set("snmp_trap.listen_address", "0.0.0.0:162")
+	viper.Set("snmp_trap.community", "")
+	viper.Set("snmp_trap.default_severity", 2)
+	viper.Set("snmp_trap.alarm_id", 0)
+	viper.Set("snmp_trap.accept_unknown_hosts", false)
+	viper.Set("snmp_trap.rules", DefaultSNMPTrapRules())
+
+	viper.Set("incident.enabled", true)
+	viper.Set("incident.window_minutes", 15)
+	viper.Set("incident.min_severity", 2)
+	viper.Set("incident.label_keys", []string{"alertname", "job", "service", "cluster"})
+	viper.Set("incident.label_similarity", 0.6)
+
+	viper.Set("notification.workers", 4)
+	viper.Set("notification.max_attempts", 5)
+	viper.Set("notification.retry_base_seconds", 30)
+	viper.Set("notification.retry_max_seconds", 1800)
+	viper.Set("notification.send_timeout_seconds", 30)
+
+	viper.Set("telegram.enabled", false)
+	viper.Set("telegram.bot_token", "")
+	viper.Set("telegram.api_base_url", "https://api.telegram.org")
+	viper.Set("telegram.mode", "polling")
+	viper.Set("telegram.webhook_url", "")
+	viper.Set("telegram.webhook_secret", "")
+	viper.Set("telegram.poll_timeout_seconds", 30)
+
 	viper.Set("external", []map[string]interface{}{
 		{"type": "monitor", "key": "snmp", "name": "SNMP", "id": 1},
 		{"type": "monitor", "key": "zabbix", "name": "Zabbix", "id": 2},
 		{"type": "monitor", "key": "other", "name": "Other", "id": 3},
+		{"type": "monitor", "key": "prometheus", "name": "Prometheus", "id": 5},
 		{"type": "alarm", "key": "zabbix", "name": "Zabbix", "id": 1},
 		{"type": "alarm", "key": "other", "name": "Other", "id": 2},
+		{"type": "alarm", "key": "alertmanager", "name": "Alertmanager", "id": 4},
 		{"type": "provider", "key": "gemini", "name": "Gemini", "id": 1},
 		{"type": "provider", "key": "openai", "name": "OpenAI", "id": 2},
 		{"type": "provider", "key": "other", "name": "Other", "id": 3},
 		{"type": "media", "key": "gmail", "name": "Gmail", "id": 1},
 		{"type": "media", "key": "qq", "name": "QQ", "id": 2},
 		{"type": "media", "key": "other", "name": "Other", "id": 3},
+		{"type": "media", "key": "smtp", "name": "Email (SMTP)", "id": 4},
+		{"type": "media", "key": "dingtalk", "name": "DingTalk", "id": 5},
+		{"type": "media", "key": "feishu", "name": "Feishu / Lark", "id": 6},
+		{"type": "media", "key": "wecom", "name": "WeCom", "id": 7},
+		{"type": "media", "key": "telegram", "name": "Telegram", "id": 8},
+		{"type": "media", "key": "slack", "name": "Slack", "id": 9},
 	})
 
 	return SaveConfig()
@@ -329,6 +490,35 @@ func GetMainConfig() (ConfigResponse, error) {
 	return config, nil
 }
 
+// GetSNMPTrapConfig returns the SNMP trap receiver configuration only.
+func GetSNMPTrapConfig() (SNMPTrapConfig, error) {
+	var config SNMPTrapConfig
+	if err := viper.UnmarshalKey("snmp_trap", &config); err != nil {
+		return SNMPTrapConfig{}, err
+	}
+	return config, nil
+}
+
+// GetIncidentConfig returns the alert correlation configuration only.
+func GetIncidentConfig() (IncidentConfig, error) {
+	var config IncidentConfig
+	if err := viper.UnmarshalKey("incident", &config); err != nil {
+		return IncidentConfig{}, err
+	}
+	return config, nil
+}
+
+// DefaultSNMPTrapRules returns severity rules for the standard SNMPv2-MIB traps
+func DefaultSNMPTrapRules() []SNMPTrapRule {
+	return []SNMPTrapRule{
+		{OID: "1.3.6.1.6.3.1.1.5.1", Name: "coldStart", Severity: 2},
+		{OID: "1.3.6.1.6.3.1.1.5.2", Name: "warmStart", Severity: 2},
+		{OID: "1.3.6.1.6.3.1.1.5.3", Name: "linkDown", Severity: 3},
+		{OID: "1.3.6.1.6.3.1.1.5.4", Name: "linkUp", Severity: 1, Resolves: "1.3.6.1.6.3.1.1.5.3"},
+		{OID: "1.3.6.1.6.3.1.1.5.5", Name: "authenticationFailure", Severity: 2},
+	}
+}
+
 // GetAIConfig returns the AI-related configuration only.
 func GetAIConfig() (AIConfig, error) {
 	var config AIConfig
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"errors"
+
+	"nagare/internal/database"
+	"nagare/internal/model"
+
+	"gorm.io/gorm"
+)
+
+func applyConversationFilters(query *gorm.DB, filter model.ConversationFilter) *gorm.DB {
+	if filter.Query != "" {
+		query = query.Where("title LIKE ?", "%"+filter.Query+"%")
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	return query
+}
+
+// SearchConversationsDAO retrieves chat conversations by filter, most recently active first by default
+func SearchConversationsDAO(filter model.ConversationFilter) ([]model.Conversation, error) {
+	query := applyConversationFilters(database.DB.Model(&model.Conversation{}), filter)
+	query = applySort(query, filter.SortBy, filter.SortOrder, map[string]string{
+		"title":        "title",
+		"last_chat_at": "last_chat_at",
+		"created_at":   "created_at",
+		"updated_at":   "updated_at",
+		"id":           "id",
+	}, "updated_at desc")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	var conversations []model.Conversation
+	if err := query.Find(&conversations).Error; err != nil {
+		return nil, err
+	}
+	return conversations, nil
+}
+
+// CountConversationsDAO returns total count for conversations by filter
+func CountConversationsDAO(filter model.ConversationFilter) (int64, error) {
+	var total int64
+	if err := applyConversationFilters(database.DB.Model(&model.Conversation{}), filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// GetConversationByIDDAO retrieves a conversation by ID
+func GetConversationByIDDAO(id uint) (model.Conversation, error) {
+	var conversation model.Conversation
+	err := database.DB.First(&conversation, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return conversation, model.ErrNotFound
+	}
+	return conversation, err
+}
+
+// AddConversationDAO creates a new conversation
+func AddConversationDAO(conversation *model.Conversation) error {
+	return database.DB.Create(conversation).Error
+}
+
+// UpdateConversationFieldsDAO updates the given columns of a conversation
+func UpdateConversationFieldsDAO(id uint, fields map[string]interface{}) error {
+	return database.DB.Model(&model.Conversation{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// DeleteConversationByIDDAO deletes a conversation with its messages
+func DeleteConversationByIDDAO(id uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("conversation_id = ?", id).Delete(&model.Chat{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Conversation{}, id).Error
+	})
+}
+
+// GetConversationChatsDAO returns the messages of a conversation newer than afterID, oldest first
+func GetConversationChatsDAO(conversationID, afterID uint) ([]model.Chat, error) {
+	var chats []model.Chat
+	err := database.DB.Where("conversation_id = ? AND id > ?", conversationID, afterID).Order("id asc").Find(&chats).Error
+	return chats, err
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"nagare/internal/database"
+	"nagare/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func applyEscalationPolicyFilters(query *gorm.DB, filter model.EscalationPolicyFilter) *gorm.DB {
+	if filter.Query != "" {
+		query = query.Where("name LIKE ? OR description LIKE ?", "%"+filter.Query+"%", "%"+filter.Query+"%")
+	}
+	if filter.Enabled != nil {
+		query = query.Where("enabled = ?", *filter.Enabled)
+	}
+	return query
+}
+
+// SearchEscalationPoliciesDAO retrieves escalation policies by filter
+func SearchEscalationPoliciesDAO(filter model.EscalationPolicyFilter) ([]model.EscalationPolicy, error) {
+	query := applyEscalationPolicyFilters(database.DB.Model(&model.EscalationPolicy{}), filter)
+	query = applySort(query, filter.SortBy, filter.SortOrder, map[string]string{
+		"name":       "name",
+		"enabled":    "enabled",
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+		"id":         "id",
+	}, "id desc")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	var policies []model.EscalationPolicy
+	if err := query.Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// CountEscalationPoliciesDAO returns total count for escalation policies by filter
+func CountEscalationPoliciesDAO(filter model.EscalationPolicyFilter) (int64, error) {
+	var total int64
+	if err := applyEscalationPolicyFilters(database.DB.Model(&model.EscalationPolicy{}), filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// GetEscalationPolicyByIDDAO retrieves an escalation policy by ID
+func GetEscalationPolicyByIDDAO(id uint) (model.EscalationPolicy, error) {
+	var policy model.EscalationPolicy
+	err := database.DB.First(&policy, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return policy, model.ErrNotFound
+	}
+	return policy, err
+}
+
+// AddEscalationPolicyDAO creates a new escalation policy
+func AddEscalationPolicyDAO(policy *model.EscalationPolicy) error {
+	return database.DB.Create(policy).Error
+}
+
+// UpdateEscalationPolicyDAO updates an escalation policy by ID
+func UpdateEscalationPolicyDAO(id uint, policy model.EscalationPolicy) error {
+	return database.DB.Model(&model.EscalationPolicy{}).Where("id = ?", id).Select("name", "description", "enabled", "steps").Updates(&policy).Error
+}
+
+// DeleteEscalationPolicyByIDDAO deletes an escalation policy and cancels escalations still running it
+func DeleteEscalationPolicyByIDDAO(id uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.AlertEscalation{}).Where("policy_id = ? AND status = 0", id).Updates(map[string]interface{}{
+			"status":      4,
+			"next_run_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.EscalationPolicy{}, id).Error
+	})
+}
+
+// StartAlertEscalationDAO records a new escalation; an alert runs each policy at most once
+func StartAlertEscalationDAO(escalation *model.AlertEscalation) (bool, error) {
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(escalation)
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetDueAlertEscalationsDAO returns running escalations whose next step is due
+func GetDueAlertEscalationsDAO(now time.Time, limit int) ([]model.AlertEscalation, error) {
+	var escalations []model.AlertEscalation
+	err := database.DB.Where("status = 0 AND next_run_at <= ?", now).
+		Order("next_run_at asc").
+		Limit(limit).
+		Find(&escalations).Error
+	return escalations, err
+}
+
+// UpdateAlertEscalationDAO updates the given columns of an escalation
+func UpdateAlertEscalationDAO(id uint, fields map[string]interface{}) error {
+	return database.DB.Model(&model.AlertEscalation{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// GetAlertEscalationsByAlertDAO returns every escalation started for an alert
+func GetAlertEscalationsByAlertDAO(alertID uint) ([]model.AlertEscalation, error) {
+	var escalations []model.AlertEscalation
+	err := database.DB.Where("alert_id = ?", alertID).Order("id asc").Find(&escalations).Error
+	return escalations, err
+}
+
+// CancelAlertEscalationsByAlertDAO stops every running escalation of an alert
+func CancelAlertEscalationsByAlertDAO(alertID uint) (int64, error) {
+	result := database.DB.Model(&model.AlertEscalation{}).Where("alert_id = ? AND status = 0", alertID).Updates(map[string]interface{}{
+		"status":      4,
+		"next_run_at": nil,
+	})
+	return result.RowsAffected, result.Error
+}
+
+// AddAlertEscalationEventDAO records a fired escalation step
+func AddAlertEscalationEventDAO(event *model.AlertEscalationEvent) error {
+	return database.DB.Create(event).Error
+}
+
+// GetAlertEscalationEventsDAO returns the escalation steps fired for an alert, oldest first
+func GetAlertEscalationEventsDAO(alertID uint) ([]model.AlertEscalationEvent, error) {
+	var events []model.AlertEscalationEvent
+	err := database.DB.Where("alert_id = ?", alertID).Order("id asc").Find(&events).Error
+	return events, err
+}
+
+// UpdateAlertEscalationLevelDAO raises the escalation level shown on an alert
+func UpdateAlertEscalationLevelDAO(alertID uint, level int) error {
+	return database.DB.Model(&model.Alert{}).Where("id = ? AND escalation_level < ?", alertID, level).Update("escalation_level", level).Error
+}
@@ -207,12 +207,22 @@ func UpdateHostDAO(id uint, h model.Host) error {
 		Update("snmp_community", h.SNMPCommunity).
 		Update("snmp_version", h.SNMPVersion).
 		Update("snmp_port", h.SNMPPort).
+		Update("snmp_v3_user", h.SNMPV3User).
+		Update("snmp_v3_security_level", h.SNMPV3SecurityLevel).
+		Update("snmp_v3_auth_protocol", h.SNMPV3AuthProtocol).
+		Update("snmp_v3_priv_protocol", h.SNMPV3PrivProtocol).
 		Update("last_sync_at", h.LastSyncAt).
 		Update("health_score", h.HealthScore)
 
 	if h.SSHPassword != "" {
 		db = db.Update("ssh_password", h.SSHPassword)
 	}
+	if h.SNMPV3AuthPassword != "" {
+		db = db.Update("snmp_v3_auth_password", h.SNMPV3AuthPassword)
+	}
+	if h.SNMPV3PrivPassword != "" {
+		db = db.Update("snmp_v3_priv_password", h.SNMPV3PrivPassword)
+	}
 
 	if traceEnabled {
 		traceHostStatusTransition("UpdateHostDAO", id, prevStatus, prevDesc, h.Status, h.StatusDescription)
@@ -295,6 +305,11 @@ func traceHostStatusTransition(op string, id uint, oldStatus int, oldDesc string
 	log.Printf("[HOST-STATUS-TRACE] op=%s host_id=%d caller=%s from=%d(%q) to=%d(%q)", op, id, caller, oldStatus, oldDesc, newStatus, newDesc)
 }
 
+// UpdateHostExternalIDDAO updates only the external_id for a host
+func UpdateHostExternalIDDAO(id uint, externalID string) error {
+	return database.DB.Model(&model.Host{}).Where("id = ?", id).Update("external_id", externalID).Error
+}
+
 // UpdateHostLastSyncAtDAO updates only the last_sync_at for a host
 func UpdateHostLastSyncAtDAO(id uint, lastSyncAt *time.Time) error {
 	return database.DB.Model(&model.Host{}).Where("id = ?", id).Update("last_sync_at", lastSyncAt).Error
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"nagare/internal/database"
+	"nagare/internal/model"
+
+	"gorm.io/gorm"
+)
+
+func applyIncidentFilters(query *gorm.DB, filter model.IncidentFilter) *gorm.DB {
+	if filter.Query != "" {
+		query = query.Where("title LIKE ? OR summary LIKE ?", "%"+filter.Query+"%", "%"+filter.Query+"%")
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	if filter.Active {
+		query = query.Where("status <> 3")
+	}
+	if filter.Severity != nil {
+		query = query.Where("severity = ?", *filter.Severity)
+	}
+	if filter.GroupID != nil {
+		query = query.Where("group_id = ?", *filter.GroupID)
+	}
+	if filter.CommanderID != nil {
+		query = query.Where("commander_id = ?", *filter.CommanderID)
+	}
+	return query
+}
+
+// SearchIncidentsDAO retrieves incidents by filter
+func SearchIncidentsDAO(filter model.IncidentFilter) ([]model.Incident, error) {
+	query := applyIncidentFilters(database.DB.Model(&model.Incident{}), filter)
+	query = applySort(query, filter.SortBy, filter.SortOrder, map[string]string{
+		"title":         "title",
+		"status":        "status",
+		"severity":      "severity",
+		"alert_count":   "alert_count",
+		"last_alert_at": "last_alert_at",
+		"created_at":    "created_at",
+		"updated_at":    "updated_at",
+		"id":            "id",
+	}, "id desc")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	var incidents []model.Incident
+	if err := query.Find(&incidents).Error; err != nil {
+		return nil, err
+	}
+	return incidents, nil
+}
+
+// CountIncidentsDAO returns total count for incidents by filter
+func CountIncidentsDAO(filter model.IncidentFilter) (int64, error) {
+	var total int64
+	if err := applyIncidentFilters(database.DB.Model(&model.Incident{}), filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// GetIncidentByIDDAO retrieves an incident by ID
+func GetIncidentByIDDAO(id uint) (model.Incident, error) {
+	var incident model.Incident
+	err := database.DB.First(&incident, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return incident, model.ErrNotFound
+	}
+	return incident, err
+}
+
+// AddIncidentDAO creates a new incident
+func AddIncidentDAO(incident *model.Incident) error {
+	return database.DB.Create(incident).Error
+}
+
+// UpdateIncidentFieldsDAO updates the given columns of an incident
+func UpdateIncidentFieldsDAO(id uint, fields map[string]interface{}) error {
+	return database.DB.Model(&model.Incident{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// DeleteIncidentByIDDAO deletes an incident with its timeline and detaches its alerts
+func DeleteIncidentByIDDAO(id uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Alert{}).Where("incident_id = ?", id).Update("incident_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("incident_id = ?", id).Delete(&model.IncidentEvent{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Incident{}, id).Error
+	})
+}
+
+// GetCorrelationCandidateIncidentsDAO returns unresolved incidents that received an alert since the given time
+func GetCorrelationCandidateIncidentsDAO(since time.Time) ([]model.Incident, error) {
+	var incidents []model.Incident
+	err := database.DB.Model(&model.Incident{}).
+		Where("status <> 3 AND last_alert_at >= ?", since).
+		Order("last_alert_at desc").
+		Find(&incidents).Error
+	return incidents, err
+}
+
+// AttachAlertToIncidentDAO links an alert to an incident and updates the incident's counters
+func AttachAlertToIncidentDAO(incidentID, alertID uint, severity int, at time.Time) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Alert{}).Where("id = ?", alertID).Update("incident_id", incidentID).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Incident{}).Where("id = ?", incidentID).Updates(map[string]interface{}{
+			"alert_count":   gorm.Expr("alert_count + 1"),
+			"last_alert_at": at,
+			"severity":      gorm.Expr("GREATEST(severity, ?)", severity),
+		}).Error
+	})
+}
+
+// RecountIncidentAlertsDAO recomputes the alert count of an incident after alerts were moved away
+func RecountIncidentAlertsDAO(incidentID uint) error {
+	var total int64
+	if err := database.DB.Model(&model.Alert{}).Where("incident_id = ?", incidentID).Count(&total).Error; err != nil {
+		return err
+	}
+	return database.DB.Model(&model.Incident{}).Where("id = ?", incidentID).Update("alert_count", total).Error
+}
+
+// AddIncidentEventDAO appends an entry to an incident timeline
+func AddIncidentEventDAO(event *model.IncidentEvent) error {
+	return database.DB.Create(event).Error
+}
+
+// GetIncidentEventsDAO returns the timeline of an incident, oldest first
+func GetIncidentEventsDAO(incidentID uint) ([]model.IncidentEvent, error) {
+	var events []model.IncidentEvent
+	err := database.DB.Where("incident_id = ?", incidentID).Order("id asc").Find(&events).Error
+	return events, err
+}
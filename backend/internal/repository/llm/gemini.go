@@ -2,6 +2,7 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
@@ -46,39 +47,201 @@ func NewGeminiProvider(cfg Config) (*GeminiProvider, error) {
 
 // Chat implements the Provider interface
 func (p *GeminiProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
-	// Build the prompt from messages
-	var builder strings.Builder
-	if req.SystemPrompt != "" {
-		builder.WriteString("System: ")
-		builder.WriteString(req.SystemPrompt)
-		builder.WriteString("\n\n")
+	contents, config := geminiRequest(req)
+	result, err := p.client.Models.GenerateContent(ctx, req.Model, contents, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	resp := &ChatResponse{Model: req.Model, FinishReason: "stop"}
+	if err := mergeGeminiResult(resp, result, nil); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChatStream implements the StreamingProvider interface
+func (p *GeminiProvider) ChatStream(ctx context.Context, req ChatRequest, onDelta DeltaHandler) (*ChatResponse, error) {
+	contents, config := geminiRequest(req)
+	resp := &ChatResponse{Model: req.Model, FinishReason: "stop"}
+	for result, err := range p.client.Models.GenerateContentStream(ctx, req.Model, contents, config) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate content: %w", err)
+		}
+		if err := mergeGeminiResult(resp, result, onDelta); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// geminiRequest builds the request. Plain conversations are flattened into a single prompt; conversations with
+// tools are sent as native contents with function declarations, where tool results become function responses
+// and consecutive results share one turn, as Gemini expects for parallel calls.
+func geminiRequest(req ChatRequest) ([]*genai.Content, *genai.GenerateContentConfig) {
+	if len(req.Tools) == 0 && !hasToolMessages(req.Messages) {
+		// Build the prompt from messages
+		var builder strings.Builder
+		if req.SystemPrompt != "" {
+			builder.WriteString("System: ")
+			builder.WriteString(req.SystemPrompt)
+			builder.WriteString("\n\n")
+		}
+		for _, msg := range req.Messages {
+			switch msg.Role {
+			case "system":
+				builder.WriteString("System: ")
+			case "user":
+				builder.WriteString("User: ")
+			case "assistant":
+				builder.WriteString("Assistant: ")
+			default:
+				continue
+			}
+			builder.WriteString(msg.Content)
+			builder.WriteString("\n\n")
+		}
+		return genai.Text(builder.String()), nil
+	}
+
+	config := &genai.GenerateContentConfig{}
+	systemPrompt := req.SystemPrompt
+	var contents []*genai.Content
+	appendParts := func(role string, parts ...*genai.Part) {
+		if len(parts) == 0 {
+			return
+		}
+		if last := len(contents) - 1; last >= 0 && contents[last].Role == role {
+			contents[last].Parts = append(contents[last].Parts, parts...)
+			return
+		}
+		contents = append(contents, genai.NewContentFromParts(parts, genai.Role(role)))
 	}
 	for _, msg := range req.Messages {
 		switch msg.Role {
-		case "system":
-			builder.WriteString("System: ")
-		case "user":
-			builder.WriteString("User: ")
-		case "assistant":
-			builder.WriteString("Assistant: ")
-		default:
+		case RoleSystem:
+			systemPrompt = strings.TrimSpace(systemPrompt + "\n\n" + msg.Content)
+		case RoleUser:
+			appendParts(genai.RoleUser, genai.NewPartFromText(msg.Content))
+		case RoleAssistant:
+			var parts []*genai.Part
+			if msg.Content != "" {
+				parts = append(parts, genai.NewPartFromText(msg.Content))
+			}
+			for _, call := range msg.ToolCalls {
+				var args map[string]any
+				_ = json.Unmarshal(call.Arguments, &args)
+				parts = append(parts, &genai.Part{
+					FunctionCall:     &genai.FunctionCall{ID: call.ID, Name: call.Name, Args: args},
+					ThoughtSignature: call.Signature,
+				})
+			}
+			appendParts(genai.RoleModel, parts...)
+		case RoleTool:
+			appendParts(genai.RoleUser, &genai.Part{FunctionResponse: &genai.FunctionResponse{
+				ID:       msg.ToolCallID,
+				Name:     msg.Name,
+				Response: geminiFunctionResponse(msg.Content),
+			}})
+		}
+	}
+	if systemPrompt != "" {
+		config.SystemInstruction = genai.NewContentFromText(systemPrompt, genai.RoleUser)
+	}
+	if req.MaxTokens > 0 {
+		config.MaxOutputTokens = int32(req.MaxTokens)
+	}
+	if req.Temperature > 0 {
+		temperature := float32(req.Temperature)
+		config.Temperature = &temperature
+	}
+	if len(req.Tools) > 0 {
+		declarations := make([]*genai.FunctionDeclaration, 0, len(req.Tools))
+		for _, tool := range req.Tools {
+			declarations = append(declarations, &genai.FunctionDeclaration{
+				Name:                 tool.Name,
+				Description:          tool.Description,
+				ParametersJsonSchema: tool.Parameters,
+			})
+		}
+		config.Tools = []*genai.Tool{{FunctionDeclarations: declarations}}
+		mode := genai.FunctionCallingConfigModeAuto
+		if req.ToolChoice == ToolChoiceNone {
+			mode = genai.FunctionCallingConfigModeNone
+		}
+		config.ToolConfig = &genai.ToolConfig{FunctionCallingConfig: &genai.FunctionCallingConfig{Mode: mode}}
+	}
+	return contents, config
+}
+
+// mergeGeminiResult adds a response, or one chunk of a streamed response, to resp. Parts are read directly
+// because result.Text() logs a warning for every response with function calls.
+func mergeGeminiResult(resp *ChatResponse, result *genai.GenerateContentResponse, onDelta DeltaHandler) error {
+	if result.UsageMetadata != nil {
+		resp.TokensUsed = int(result.UsageMetadata.TotalTokenCount)
+		resp.PromptTokens = int(result.UsageMetadata.PromptTokenCount)
+		resp.CompletionTokens = int(result.UsageMetadata.CandidatesTokenCount)
+	}
+	if len(result.Candidates) == 0 || result.Candidates[0].Content == nil {
+		return nil
+	}
+	if reason := result.Candidates[0].FinishReason; reason != "" {
+		resp.FinishReason = strings.ToLower(string(reason))
+	}
+	for _, part := range result.Candidates[0].Content.Parts {
+		if part.FunctionCall != nil {
+			args, _ := json.Marshal(part.FunctionCall.Args)
+			if part.FunctionCall.Args == nil {
+				args = []byte("{}")
+			}
+			resp.ToolCalls = append(resp.ToolCalls, ToolCall{
+				ID:        part.FunctionCall.ID,
+				Name:      part.FunctionCall.Name,
+				Arguments: args,
+				Signature: part.ThoughtSignature,
+			})
+			continue
+		}
+		if part.Thought || part.Text == "" {
 			continue
 		}
-		builder.WriteString(msg.Content)
-		builder.WriteString("\n\n")
+		resp.Content += part.Text
+		if onDelta != nil {
+			if err := onDelta(part.Text); err != nil {
+				return err
+			}
+		}
+	}
+	if len(resp.ToolCalls) > 0 {
+		resp.FinishReason = "tool_calls"
 	}
-	prompt := builder.String()
+	return nil
+}
 
-	result, err := p.client.Models.GenerateContent(ctx, req.Model, genai.Text(prompt), nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate content: %w", err)
+// geminiFunctionResponse wraps a tool result for a function response, which must be a JSON object
+func geminiFunctionResponse(content string) map[string]any {
+	var value any
+	if err := json.Unmarshal([]byte(content), &value); err != nil {
+		return map[string]any{"output": content}
+	}
+	if object, ok := value.(map[string]any); ok {
+		return object
 	}
+	return map[string]any{"output": value}
+}
 
-	return &ChatResponse{
-		Content:      fmt.Sprint(result.Text()),
-		Model:        req.Model,
-		FinishReason: "stop",
-	}, nil
+func hasToolMessages(messages []Message) bool {
+	for _, msg := range messages {
+		if msg.Role == RoleTool || len(msg.ToolCalls) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsTools reports native function calling through function declarations
+func (p *GeminiProvider) SupportsTools() bool {
+	return true
 }
 
 // Name returns the provider name
@@ -0,0 +1,231 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+// newGeminiTestServer points a Gemini provider at an httptest server answering every request with response
+func newGeminiTestServer(t *testing.T, contentType, response string, inspect func(r *http.Request, body map[string]any)) *GeminiProvider {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		if err := json.Unmarshal(raw, &body); err != nil {
+			t.Errorf("request body is not JSON: %v", err)
+		}
+		if inspect != nil {
+			inspect(r, body)
+		}
+		w.Header().Set("Content-Type", contentType)
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{
+		APIKey:      "test-key",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPClient:  srv.Client(),
+		HTTPOptions: genai.HTTPOptions{BaseURL: srv.URL},
+	})
+	if err != nil {
+		t.Fatalf("genai.NewClient: %v", err)
+	}
+	return &GeminiProvider{client: client, apiKey: "test-key"}
+}
+
+func TestGeminiRequest(t *testing.T) {
+	plainContents, plainConfig := geminiRequest(ChatRequest{SystemPrompt: "be brief", Messages: []Message{{Role: RoleUser, Content: "hi"}}})
+	if plainConfig != nil || len(plainContents) != 1 || !strings.Contains(plainContents[0].Parts[0].Text, "User: hi") {
+		t.Fatalf("plain chats should be flattened into one prompt, got %+v", plainContents)
+	}
+
+	cases := []struct {
+		name       string
+		req        ChatRequest
+		wantRoles  []string
+		wantParts  []int
+		wantTools  []string
+		wantMode   genai.FunctionCallingConfigMode
+		wantSystem string
+	}{
+		{
+			name:      "tool definitions",
+			req:       ChatRequest{Tools: testTools, Messages: []Message{{Role: RoleUser, Content: "hi"}}},
+			wantRoles: []string{genai.RoleUser},
+			wantParts: []int{1},
+			wantTools: []string{"get_host", "list_alerts"},
+			wantMode:  genai.FunctionCallingConfigModeAuto,
+		},
+		{
+			name:       "tool choice none",
+			req:        ChatRequest{Tools: testTools, ToolChoice: ToolChoiceNone, SystemPrompt: "be brief", Messages: []Message{{Role: RoleSystem, Content: "use UTC"}, {Role: RoleUser, Content: "hi"}}},
+			wantRoles:  []string{genai.RoleUser},
+			wantParts:  []int{1},
+			wantTools:  []string{"get_host", "list_alerts"},
+			wantMode:   genai.FunctionCallingConfigModeNone,
+			wantSystem: "be brief\n\nuse UTC",
+		},
+		{
+			name: "parallel results share one turn",
+			req: ChatRequest{Tools: testTools, Messages: []Message{
+				{Role: RoleUser, Content: "hosts 1 and 2"},
+				{Role: RoleAssistant, ToolCalls: []ToolCall{
+					{ID: "a", Name: "get_host", Arguments: json.RawMessage(`{"id":1}`)},
+					{ID: "b", Name: "get_host", Arguments: json.RawMessage(`{"id":2}`)},
+				}},
+				{Role: RoleTool, ToolCallID: "a", Name: "get_host", Content: `{"name":"web-1"}`},
+				{Role: RoleTool, ToolCallID: "b", Name: "get_host", Content: "not json"},
+			}},
+			wantRoles: []string{genai.RoleUser, genai.RoleModel, genai.RoleUser},
+			wantParts: []int{1, 2, 2},
+			wantTools: []string{"get_host", "list_alerts"},
+			wantMode:  genai.FunctionCallingConfigModeAuto,
+		},
+	}
+
+	for _, tc := range cases {
+		contents, config := geminiRequest(tc.req)
+		if len(contents) != len(tc.wantRoles) {
+			t.Fatalf("%s: got %d contents, want %d", tc.name, len(contents), len(tc.wantRoles))
+		}
+		for i, role := range tc.wantRoles {
+			if contents[i].Role != role || len(contents[i].Parts) != tc.wantParts[i] {
+				t.Fatalf("%s: content %d has role %s with %d parts, want %s with %d", tc.name, i, contents[i].Role, len(contents[i].Parts), role, tc.wantParts[i])
+			}
+		}
+		declarations := config.Tools[0].FunctionDeclarations
+		if len(declarations) != len(tc.wantTools) {
+			t.Fatalf("%s: got %d declarations, want %d", tc.name, len(declarations), len(tc.wantTools))
+		}
+		for i, name := range tc.wantTools {
+			if declarations[i].Name != name {
+				t.Fatalf("%s: declaration %d is %s, want %s", tc.name, i, declarations[i].Name, name)
+			}
+		}
+		if mode := config.ToolConfig.FunctionCallingConfig.Mode; mode != tc.wantMode {
+			t.Fatalf("%s: function calling mode %s, want %s", tc.name, mode, tc.wantMode)
+		}
+		system := ""
+		if config.SystemInstruction != nil {
+			system = config.SystemInstruction.Parts[0].Text
+		}
+		if system != tc.wantSystem {
+			t.Fatalf("%s: system instruction %q, want %q", tc.name, system, tc.wantSystem)
+		}
+	}
+
+	contents, _ := geminiRequest(cases[2].req)
+	results := contents[2].Parts
+	if results[0].FunctionResponse.ID != "a" || results[0].FunctionResponse.Response["name"] != "web-1" || results[1].FunctionResponse.Response["output"] != "not json" {
+		t.Fatalf("tool results not mapped to function responses: %+v %+v", results[0].FunctionResponse, results[1].FunctionResponse)
+	}
+}
+
+func TestGeminiChatParallelToolCalls(t *testing.T) {
+	response := `{
+		"candidates": [{
+			"content": {"role": "model", "parts": [
+				{"text": "Checking both hosts."},
+				{"functionCall": {"id": "fc_1", "name": "get_host", "args": {"id": 1}}, "thoughtSignature": "c2ln"},
+				{"functionCall": {"id": "fc_2", "name": "get_host", "args": {"id": 2}}},
+				{"functionCall": {"name": "list_alerts"}}
+			]},
+			"finishReason": "STOP"
+		}],
+		"usageMetadata": {"promptTokenCount": 30, "candidatesTokenCount": 12, "totalTokenCount": 50}
+	}`
+
+	p := newGeminiTestServer(t, "application/json", response, func(r *http.Request, body map[string]any) {
+		if !strings.HasSuffix(r.URL.Path, "/models/gemini-test:generateContent") {
+			t.Errorf("request sent to %s", r.URL.Path)
+		}
+		if r.Header.Get("x-goog-api-key") != "test-key" {
+			t.Errorf("API key header missing")
+		}
+		tools, _ := body["tools"].([]any)
+		if len(tools) != 1 {
+			t.Fatalf("tools not sent: %v", body["tools"])
+		}
+		declarations, _ := tools[0].(map[string]any)["functionDeclarations"].([]any)
+		if len(declarations) != 2 || declarations[0].(map[string]any)["name"] != "get_host" {
+			t.Errorf("function declarations not sent: %v", tools[0])
+		}
+	})
+
+	resp, err := p.Chat(context.Background(), ChatRequest{Model: "gemini-test", Tools: testTools, Messages: []Message{{Role: RoleUser, Content: "hosts 1 and 2"}}})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if resp.Content != "Checking both hosts." || resp.FinishReason != "tool_calls" || resp.TokensUsed != 50 || resp.PromptTokens != 30 || resp.CompletionTokens != 12 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	want := []struct{ id, name, args, signature string }{
+		{id: "fc_1", name: "get_host", args: `{"id":1}`, signature: "sig"},
+		{id: "fc_2", name: "get_host", args: `{"id":2}`},
+		{id: "", name: "list_alerts", args: `{}`},
+	}
+	if len(resp.ToolCalls) != len(want) {
+		t.Fatalf("got %d tool calls, want %d", len(resp.ToolCalls), len(want))
+	}
+	for i, w := range want {
+		call := resp.ToolCalls[i]
+		if call.ID != w.id || call.Name != w.name || string(call.Arguments) != w.args || string(call.Signature) != w.signature {
+			t.Fatalf("tool call %d = %s %s %s %q, want %s %s %s %q", i, call.ID, call.Name, call.Arguments, call.Signature, w.id, w.name, w.args, w.signature)
+		}
+	}
+}
+
+func TestGeminiChatStream(t *testing.T) {
+	chunks := []string{
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"thinking about it","thought":true}]}}]}`,
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"Host 1 "}]}}]}`,
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"looks fine."}]}}]}`,
+		`{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"id":"fc_1","name":"list_alerts","args":{"host":"web-1"}}}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":5,"totalTokenCount":15}}`,
+	}
+	stream := "data: " + strings.Join(chunks, "\n\ndata: ") + "\n\n"
+
+	p := newGeminiTestServer(t, "text/event-stream", stream, func(r *http.Request, body map[string]any) {
+		if !strings.HasSuffix(r.URL.Path, "/models/gemini-test:streamGenerateContent") || r.URL.Query().Get("alt") != "sse" {
+			t.Errorf("stream request sent to %s", r.URL.String())
+		}
+	})
+
+	var deltas []string
+	resp, err := p.ChatStream(context.Background(), ChatRequest{Model: "gemini-test", Tools: testTools, Messages: []Message{{Role: RoleUser, Content: "how is host 1?"}}}, func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	if resp.Content != "Host 1 looks fine." || strings.Join(deltas, "|") != "Host 1 |looks fine." {
+		t.Fatalf("content %q (deltas %q); thoughts must not be emitted", resp.Content, deltas)
+	}
+	if resp.FinishReason != "tool_calls" || resp.TokensUsed != 15 || resp.PromptTokens != 10 || resp.CompletionTokens != 5 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "list_alerts" || string(resp.ToolCalls[0].Arguments) != `{"host":"web-1"}` {
+		t.Fatalf("unexpected tool calls: %+v", resp.ToolCalls)
+	}
+}
+
+func TestGeminiChatStreamHandlerError(t *testing.T) {
+	stream := "data: {\"candidates\":[{\"content\":{\"role\":\"model\",\"parts\":[{\"text\":\"hello\"}]}}]}\n\n"
+	p := newGeminiTestServer(t, "text/event-stream", stream, nil)
+
+	stop := errors.New("client went away")
+	_, err := p.ChatStream(context.Background(), ChatRequest{Model: "gemini-test", Messages: []Message{{Role: RoleUser, Content: "hi"}}}, func(string) error { return stop })
+	if !errors.Is(err, stop) {
+		t.Fatalf("expected the handler error, got %v", err)
+	}
+}
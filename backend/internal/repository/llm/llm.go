@@ -3,6 +3,7 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 )
@@ -15,10 +16,42 @@ const (
 	ProviderOpenAI                         // 2 = openai
 )
 
+// Message roles
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+// Tool choices
+const (
+	ToolChoiceAuto = "auto" // The model decides whether to call tools (default)
+	ToolChoiceNone = "none" // The model must answer in text; tool history is still accepted
+)
+
 // Message represents a chat message
 type Message struct {
-	Role    string // "user", "assistant", "system"
-	Content string
+	Role       string // "user", "assistant", "system", "tool"
+	Content    string
+	ToolCalls  []ToolCall // Calls requested by an assistant message
+	ToolCallID string     // For tool messages, the ID of the call this is the result of
+	Name       string     // For tool messages, the name of the tool that was called
+}
+
+// Tool describes a function the model may call
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  map[string]interface{} // JSON schema of the arguments object
+}
+
+// ToolCall is a function call requested by the model
+type ToolCall struct {
+	ID        string // May be empty for providers that match results by name and order
+	Name      string
+	Arguments json.RawMessage
+	Signature []byte // Opaque provider data that must be sent back with the call, e.g. Gemini thought signatures
 }
 
 // ChatRequest represents a request to the LLM
@@ -28,14 +61,19 @@ type ChatRequest struct {
 	MaxTokens    int
 	Temperature  float64
 	SystemPrompt string
+	Tools        []Tool
+	ToolChoice   string // "" or "auto", "none"
 }
 
 // ChatResponse represents a response from the LLM
 type ChatResponse struct {
-	Content      string
-	Model        string
-	FinishReason string
-	TokensUsed   int
+	Content          string
+	Model            string
+	FinishReason     string
+	TokensUsed       int // Total tokens of the request; may exceed prompt plus completion, e.g. with thinking
+	PromptTokens     int
+	CompletionTokens int
+	ToolCalls        []ToolCall // Calls the model wants executed before it answers; may be several in one turn
 }
 
 // Provider defines the interface for LLM providers
@@ -50,6 +88,20 @@ type Provider interface {
 	FetchModels(ctx context.Context) ([]string, error)
 }
 
+// DeltaHandler receives answer text as it is generated; returning an error aborts the stream
+type DeltaHandler func(delta string) error
+
+// StreamingProvider is implemented by providers that can stream their answer. ChatStream calls onDelta for
+// every text fragment and returns the assembled response, including any tool calls, once the stream ends.
+type StreamingProvider interface {
+	ChatStream(ctx context.Context, req ChatRequest, onDelta DeltaHandler) (*ChatResponse, error)
+}
+
+// ToolCaller is implemented by providers that accept ChatRequest.Tools through a native function-calling API
+type ToolCaller interface {
+	SupportsTools() bool
+}
+
 // Config holds the configuration for an LLM provider
 type Config struct {
 	APIKey  string
@@ -93,6 +145,23 @@ func (c *Client) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, erro
 	return c.provider.Chat(ctx, req)
 }
 
+// ChatStream streams a chat request; providers without streaming deliver the whole answer as one delta
+func (c *Client) ChatStream(ctx context.Context, req ChatRequest, onDelta DeltaHandler) (*ChatResponse, error) {
+	if streamer, ok := c.provider.(StreamingProvider); ok {
+		return streamer.ChatStream(ctx, req, onDelta)
+	}
+	resp, err := c.provider.Chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Content != "" {
+		if err := onDelta(resp.Content); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
 // SimpleChat sends a simple text prompt and returns the response
 func (c *Client) SimpleChat(ctx context.Context, model, prompt string) (string, error) {
 	req := ChatRequest{
@@ -122,6 +191,12 @@ func (c *Client) ChatWithHistory(ctx context.Context, model string, history []Me
 	return c.Chat(ctx, req)
 }
 
+// SupportsTools reports whether the provider has native function calling
+func (c *Client) SupportsTools() bool {
+	caller, ok := c.provider.(ToolCaller)
+	return ok && caller.SupportsTools()
+}
+
 // ProviderName returns the name of the current provider
 func (c *Client) ProviderName() string {
 	return c.provider.Name()
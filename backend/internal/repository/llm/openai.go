@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -11,6 +12,9 @@ import (
 	"time"
 )
 
+// openAIMaxStreamToolCalls bounds the tool call index a stream chunk may reference
+const openAIMaxStreamToolCalls = 128
+
 // OpenAIProvider implements the Provider interface for OpenAI-compatible APIs
 type OpenAIProvider struct {
 	apiKey       string
@@ -25,12 +29,71 @@ type openAIRequest struct {
 	Messages    []openAIMessage `json:"messages"`
 	MaxTokens   int             `json:"max_tokens,omitempty"`
 	Temperature float64         `json:"temperature,omitempty"`
+	Tools       []openAITool    `json:"tools,omitempty"`
+	ToolChoice  string          `json:"tool_choice,omitempty"`
+	Stream      bool            `json:"stream,omitempty"`
+	// Asks for a final chunk with token usage
+	StreamOptions *openAIStreamOptions `json:"stream_options,omitempty"`
+}
+
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// openAIStreamChunk is one "data:" event of a streamed chat completion
+type openAIStreamChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content          string `json:"content"`
+			ReasoningContent string `json:"reasoning_content"`
+			ToolCalls        []struct {
+				Index    int    `json:"index"`
+				ID       string `json:"id"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
 }
 
 type openAIMessage struct {
-	Role             string `json:"role"`
-	Content          string `json:"content"`
-	ReasoningContent string `json:"reasoning_content,omitempty"`
+	Role             string           `json:"role"`
+	Content          string           `json:"content"`
+	ReasoningContent string           `json:"reasoning_content,omitempty"`
+	ToolCalls        []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID       string           `json:"tool_call_id,omitempty"`
+}
+
+type openAITool struct {
+	Type     string             `json:"type"`
+	Function openAIFunctionSpec `json:"function"`
+}
+
+type openAIFunctionSpec struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+type openAIToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
 }
 
 type openAIResponse struct {
@@ -90,8 +153,8 @@ func NewOpenAIProvider(cfg Config) (*OpenAIProvider, error) {
 	}, nil
 }
 
-// Chat implements the Provider interface
-func (p *OpenAIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+// buildRequest converts a chat request to the OpenAI wire format
+func (p *OpenAIProvider) buildRequest(req ChatRequest) openAIRequest {
 	// Convert messages to OpenAI format
 	messages := make([]openAIMessage, 0, len(req.Messages)+1)
 
@@ -104,10 +167,20 @@ func (p *OpenAIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 	}
 
 	for _, msg := range req.Messages {
-		messages = append(messages, openAIMessage{
-			Role:    msg.Role,
-			Content: msg.Content,
-		})
+		converted := openAIMessage{
+			Role:       msg.Role,
+			Content:    msg.Content,
+			ToolCallID: msg.ToolCallID,
+		}
+		for _, call := range msg.ToolCalls {
+			var tc openAIToolCall
+			tc.ID = call.ID
+			tc.Type = "function"
+			tc.Function.Name = call.Name
+			tc.Function.Arguments = string(call.Arguments)
+			converted.ToolCalls = append(converted.ToolCalls, tc)
+		}
+		messages = append(messages, converted)
 	}
 
 	openAIReq := openAIRequest{
@@ -116,11 +189,30 @@ func (p *OpenAIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 		MaxTokens:   req.MaxTokens,
 		Temperature: req.Temperature,
 	}
+	for _, tool := range req.Tools {
+		openAIReq.Tools = append(openAIReq.Tools, openAITool{
+			Type: "function",
+			Function: openAIFunctionSpec{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.Parameters,
+			},
+		})
+	}
+	if len(openAIReq.Tools) > 0 {
+		openAIReq.ToolChoice = req.ToolChoice
+	}
 
 	if openAIReq.MaxTokens == 0 {
 		openAIReq.MaxTokens = 4096
 	}
 
+	return openAIReq
+}
+
+// Chat implements the Provider interface
+func (p *OpenAIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	openAIReq := p.buildRequest(req)
 	body, err := json.Marshal(openAIReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
@@ -163,14 +255,177 @@ func (p *OpenAIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 		finalContent = "<think>\n" + openAIResp.Choices[0].Message.ReasoningContent + "\n</think>\n\n" + finalContent
 	}
 
+	toolCalls := make([]ToolCall, 0, len(openAIResp.Choices[0].Message.ToolCalls))
+	for _, call := range openAIResp.Choices[0].Message.ToolCalls {
+		toolCalls = append(toolCalls, ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: openAIToolArguments(call.Function.Arguments),
+		})
+	}
+
 	return &ChatResponse{
-		Content:      finalContent,
-		Model:        openAIResp.Model,
-		FinishReason: openAIResp.Choices[0].FinishReason,
-		TokensUsed:   openAIResp.Usage.TotalTokens,
+		Content:          finalContent,
+		Model:            openAIResp.Model,
+		FinishReason:     openAIResp.Choices[0].FinishReason,
+		TokensUsed:       openAIResp.Usage.TotalTokens,
+		PromptTokens:     openAIResp.Usage.PromptTokens,
+		CompletionTokens: openAIResp.Usage.CompletionTokens,
+		ToolCalls:        toolCalls,
 	}, nil
 }
 
+// openAIToolArguments turns the JSON-encoded arguments string into raw JSON. Arguments the model failed to
+// encode are passed on as a JSON string so the tool reports the decoding error back to the model.
+func openAIToolArguments(arguments string) json.RawMessage {
+	arguments = strings.TrimSpace(arguments)
+	if arguments == "" {
+		return json.RawMessage("{}")
+	}
+	if json.Valid([]byte(arguments)) {
+		return json.RawMessage(arguments)
+	}
+	quoted, _ := json.Marshal(arguments)
+	return quoted
+}
+
+// SupportsTools reports native function calling through the tools parameter
+func (p *OpenAIProvider) SupportsTools() bool {
+	return true
+}
+
+// ChatStream implements the StreamingProvider interface using server-sent events.
+// Reasoning deltas are wrapped in <think> tags the same way Chat reports reasoning content.
+func (p *OpenAIProvider) ChatStream(ctx context.Context, req ChatRequest, onDelta DeltaHandler) (*ChatResponse, error) {
+	openAIReq := p.buildRequest(req)
+	openAIReq.Stream = true
+	openAIReq.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+
+	body, err := json.Marshal(openAIReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	// The client timeout would cut long answers off mid-stream, so only the context bounds a streamed request
+	streamClient := &http.Client{Transport: p.client.Transport}
+	resp, err := streamClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		var openAIResp openAIResponse
+		if json.Unmarshal(respBody, &openAIResp) == nil && openAIResp.Error != nil {
+			return nil, fmt.Errorf("OpenAI API error: %s", openAIResp.Error.Message)
+		}
+		return nil, fmt.Errorf("OpenAI API status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	result := &ChatResponse{Model: req.Model}
+	var toolCalls []ToolCall
+	var arguments []string
+	thinking := false
+	emit := func(text string) error {
+		result.Content += text
+		return onDelta(text)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+		var chunk openAIStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return nil, fmt.Errorf("OpenAI API error: %s", chunk.Error.Message)
+		}
+		if chunk.Model != "" {
+			result.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			result.TokensUsed = chunk.Usage.TotalTokens
+			result.PromptTokens = chunk.Usage.PromptTokens
+			result.CompletionTokens = chunk.Usage.CompletionTokens
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		choice := chunk.Choices[0]
+		if choice.FinishReason != "" {
+			result.FinishReason = choice.FinishReason
+		}
+		if choice.Delta.ReasoningContent != "" {
+			text := choice.Delta.ReasoningContent
+			if !thinking {
+				thinking = true
+				text = "<think>\n" + text
+			}
+			if err := emit(text); err != nil {
+				return nil, err
+			}
+		}
+		if choice.Delta.Content != "" {
+			text := choice.Delta.Content
+			if thinking {
+				thinking = false
+				text = "\n</think>\n\n" + text
+			}
+			if err := emit(text); err != nil {
+				return nil, err
+			}
+		}
+		// Tool calls arrive in fragments keyed by index: the first carries the ID and name, the rest the arguments
+		for _, fragment := range choice.Delta.ToolCalls {
+			if fragment.Index < 0 || fragment.Index >= openAIMaxStreamToolCalls {
+				return nil, fmt.Errorf("invalid tool call index in stream chunk: %d", fragment.Index)
+			}
+			for len(toolCalls) <= fragment.Index {
+				toolCalls = append(toolCalls, ToolCall{})
+				arguments = append(arguments, "")
+			}
+			if fragment.ID != "" {
+				toolCalls[fragment.Index].ID = fragment.ID
+			}
+			if fragment.Function.Name != "" {
+				toolCalls[fragment.Index].Name = fragment.Function.Name
+			}
+			arguments[fragment.Index] += fragment.Function.Arguments
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+	if thinking {
+		if err := emit("\n</think>\n\n"); err != nil {
+			return nil, err
+		}
+	}
+	for i := range toolCalls {
+		toolCalls[i].Arguments = openAIToolArguments(arguments[i])
+		result.ToolCalls = append(result.ToolCalls, toolCalls[i])
+	}
+	return result, nil
+}
+
 // Name returns the provider name
 func (p *OpenAIProvider) Name() string {
 	return "openai"
@@ -0,0 +1,301 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newOpenAITestServer serves one canned response and hands the decoded request to inspect
+func newOpenAITestServer(t *testing.T, contentType, response string, inspect func(r *http.Request, body openAIRequest)) *OpenAIProvider {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		var body openAIRequest
+		if err := json.Unmarshal(raw, &body); err != nil {
+			t.Errorf("request body is not JSON: %v", err)
+		}
+		if inspect != nil {
+			inspect(r, body)
+		}
+		w.Header().Set("Content-Type", contentType)
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(srv.Close)
+
+	provider, err := NewOpenAIProvider(Config{APIKey: "test-key", BaseURL: srv.URL + "/v1/", Type: ProviderOpenAI})
+	if err != nil {
+		t.Fatalf("NewOpenAIProvider: %v", err)
+	}
+	return provider
+}
+
+var testTools = []Tool{
+	{Name: "get_host", Description: "Look up a host", Parameters: map[string]interface{}{"type": "object", "properties": map[string]interface{}{"id": map[string]interface{}{"type": "integer"}}}},
+	{Name: "list_alerts", Description: "List open alerts"},
+}
+
+func TestOpenAIBuildRequest(t *testing.T) {
+	p := &OpenAIProvider{}
+	cases := []struct {
+		name           string
+		req            ChatRequest
+		wantMessages   []string // role of each message
+		wantTools      []string
+		wantToolChoice string
+		wantMaxTokens  int
+	}{
+		{
+			name:          "plain chat",
+			req:           ChatRequest{Model: "m", SystemPrompt: "be brief", Messages: []Message{{Role: RoleUser, Content: "hi"}}},
+			wantMessages:  []string{"system", "user"},
+			wantMaxTokens: 4096,
+		},
+		{
+			name:           "tools with choice",
+			req:            ChatRequest{Model: "m", MaxTokens: 100, Tools: testTools, ToolChoice: ToolChoiceNone, Messages: []Message{{Role: RoleUser, Content: "hi"}}},
+			wantMessages:   []string{"user"},
+			wantTools:      []string{"get_host", "list_alerts"},
+			wantToolChoice: ToolChoiceNone,
+			wantMaxTokens:  100,
+		},
+		{
+			name:          "tool choice dropped without tools",
+			req:           ChatRequest{Model: "m", ToolChoice: ToolChoiceNone, Messages: []Message{{Role: RoleUser, Content: "hi"}}},
+			wantMessages:  []string{"user"},
+			wantMaxTokens: 4096,
+		},
+		{
+			name: "tool round trip",
+			req: ChatRequest{Model: "m", Tools: testTools, Messages: []Message{
+				{Role: RoleUser, Content: "check host 1"},
+				{Role: RoleAssistant, ToolCalls: []ToolCall{{ID: "call_1", Name: "get_host", Arguments: json.RawMessage(`{"id":1}`)}}},
+				{Role: RoleTool, ToolCallID: "call_1", Name: "get_host", Content: `{"name":"web-1"}`},
+			}},
+			wantMessages:  []string{"user", "assistant", "tool"},
+			wantTools:     []string{"get_host", "list_alerts"},
+			wantMaxTokens: 4096,
+		},
+	}
+
+	for _, tc := range cases {
+		got := p.buildRequest(tc.req)
+		if len(got.Messages) != len(tc.wantMessages) {
+			t.Fatalf("%s: got %d messages, want %d", tc.name, len(got.Messages), len(tc.wantMessages))
+		}
+		for i, role := range tc.wantMessages {
+			if got.Messages[i].Role != role {
+				t.Fatalf("%s: message %d has role %s, want %s", tc.name, i, got.Messages[i].Role, role)
+			}
+		}
+		if len(got.Tools) != len(tc.wantTools) {
+			t.Fatalf("%s: got %d tools, want %d", tc.name, len(got.Tools), len(tc.wantTools))
+		}
+		for i, name := range tc.wantTools {
+			if got.Tools[i].Type != "function" || got.Tools[i].Function.Name != name {
+				t.Fatalf("%s: tool %d = %+v, want function %s", tc.name, i, got.Tools[i], name)
+			}
+		}
+		if got.ToolChoice != tc.wantToolChoice || got.MaxTokens != tc.wantMaxTokens {
+			t.Fatalf("%s: tool_choice=%q max_tokens=%d, want %q %d", tc.name, got.ToolChoice, got.MaxTokens, tc.wantToolChoice, tc.wantMaxTokens)
+		}
+	}
+
+	roundTrip := p.buildRequest(cases[3].req)
+	call := roundTrip.Messages[1].ToolCalls
+	if len(call) != 1 || call[0].ID != "call_1" || call[0].Type != "function" || call[0].Function.Arguments != `{"id":1}` {
+		t.Fatalf("assistant tool call not encoded: %+v", call)
+	}
+	if roundTrip.Messages[2].ToolCallID != "call_1" {
+		t.Fatalf("tool result lost its call ID: %+v", roundTrip.Messages[2])
+	}
+}
+
+func TestOpenAIChatParallelToolCalls(t *testing.T) {
+	response := `{
+		"model": "gpt-test",
+		"choices": [{
+			"index": 0,
+			"finish_reason": "tool_calls",
+			"message": {
+				"role": "assistant",
+				"content": "",
+				"tool_calls": [
+					{"id": "call_1", "type": "function", "function": {"name": "get_host", "arguments": "{\"id\": 1}"}},
+					{"id": "call_2", "type": "function", "function": {"name": "get_host", "arguments": "{\"id\": 2}"}},
+					{"id": "call_3", "type": "function", "function": {"name": "list_alerts", "arguments": ""}},
+					{"id": "call_4", "type": "function", "function": {"name": "get_host", "arguments": "{\"id\": 4"}}
+				]
+			}
+		}],
+		"usage": {"prompt_tokens": 30, "completion_tokens": 12, "total_tokens": 42}
+	}`
+
+	p := newOpenAITestServer(t, "application/json", response, func(r *http.Request, body openAIRequest) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("request sent to %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("Authorization header = %q", got)
+		}
+		if len(body.Tools) != 2 || body.Tools[0].Function.Name != "get_host" || body.Tools[0].Function.Parameters["type"] != "object" {
+			t.Errorf("tool definitions not sent: %+v", body.Tools)
+		}
+		if body.Stream {
+			t.Errorf("Chat must not request a stream")
+		}
+	})
+
+	resp, err := p.Chat(context.Background(), ChatRequest{Model: "gpt-test", Tools: testTools, Messages: []Message{{Role: RoleUser, Content: "hosts 1, 2 and 4"}}})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if resp.FinishReason != "tool_calls" || resp.TokensUsed != 42 || resp.PromptTokens != 30 || resp.CompletionTokens != 12 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	want := []struct{ id, name, args string }{
+		{id: "call_1", name: "get_host", args: `{"id": 1}`},
+		{id: "call_2", name: "get_host", args: `{"id": 2}`},
+		{id: "call_3", name: "list_alerts", args: `{}`},
+		{id: "call_4", name: "get_host", args: `"{\"id\": 4"`},
+	}
+	if len(resp.ToolCalls) != len(want) {
+		t.Fatalf("got %d tool calls, want %d", len(resp.ToolCalls), len(want))
+	}
+	for i, w := range want {
+		call := resp.ToolCalls[i]
+		if call.ID != w.id || call.Name != w.name || string(call.Arguments) != w.args {
+			t.Fatalf("tool call %d = %s %s %s, want %s %s %s", i, call.ID, call.Name, call.Arguments, w.id, w.name, w.args)
+		}
+	}
+}
+
+func TestOpenAIChatAPIError(t *testing.T) {
+	p := newOpenAITestServer(t, "application/json", `{"error": {"message": "invalid tool schema", "type": "invalid_request_error"}}`, nil)
+	if _, err := p.Chat(context.Background(), ChatRequest{Model: "gpt-test", Tools: testTools}); err == nil {
+		t.Fatalf("expected the API error to be returned")
+	}
+}
+
+func TestOpenAIToolArguments(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "object", in: `{"id": 1}`, want: `{"id": 1}`},
+		{name: "surrounding whitespace", in: "  {\"id\":1}\n", want: `{"id":1}`},
+		{name: "empty", in: "", want: `{}`},
+		{name: "blank", in: "   ", want: `{}`},
+		{name: "truncated object", in: `{"id": 1`, want: `"{\"id\": 1"`},
+		{name: "single quotes", in: `{'id': 1}`, want: `"{'id': 1}"`},
+		{name: "plain text", in: `host 1`, want: `"host 1"`},
+	}
+
+	for _, tc := range cases {
+		got := openAIToolArguments(tc.in)
+		if string(got) != tc.want {
+			t.Fatalf("%s: openAIToolArguments(%q) = %s, want %s", tc.name, tc.in, got, tc.want)
+		}
+		if !json.Valid(got) {
+			t.Fatalf("%s: arguments %s are not valid JSON", tc.name, got)
+		}
+	}
+}
+
+func TestOpenAIChatStream(t *testing.T) {
+	stream := strings.Join([]string{
+		`: keep-alive`,
+		`data: {"model":"gpt-test","choices":[{"delta":{"reasoning_content":"Need host "}}]}`,
+		`data: {"choices":[{"delta":{"reasoning_content":"details."}}]}`,
+		`data: {"choices":[{"delta":{"content":"Checking."}}]}`,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","function":{"name":"get_host","arguments":""}}]}}]}`,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":1,"id":"call_2","function":{"name":"list_alerts","arguments":"{"}}]}}]}`,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"id\""}}]}}]}`,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":": 1}"}}]}}]}`,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":1,"function":{"arguments":"}"}}]}}]}`,
+		`data: {"choices":[{"delta":{},"finish_reason":"tool_calls"}]}`,
+		`data: {"choices":[],"usage":{"prompt_tokens":20,"completion_tokens":9,"total_tokens":29}}`,
+		`data: [DONE]`,
+		`data: this line is never parsed`,
+	}, "\n\n")
+
+	p := newOpenAITestServer(t, "text/event-stream", stream, func(r *http.Request, body openAIRequest) {
+		if !body.Stream || body.StreamOptions == nil || !body.StreamOptions.IncludeUsage {
+			t.Errorf("stream request should ask for a stream with usage: %+v", body)
+		}
+		if r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("Accept header = %q", r.Header.Get("Accept"))
+		}
+		if len(body.Tools) != 2 {
+			t.Errorf("tool definitions not sent: %+v", body.Tools)
+		}
+	})
+
+	var deltas []string
+	resp, err := p.ChatStream(context.Background(), ChatRequest{Model: "gpt-test", Tools: testTools}, func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+
+	wantContent := "<think>\nNeed host details.\n</think>\n\nChecking."
+	if resp.Content != wantContent || strings.Join(deltas, "") != wantContent {
+		t.Fatalf("content %q (deltas %q), want %q", resp.Content, deltas, wantContent)
+	}
+	if resp.Model != "gpt-test" || resp.FinishReason != "tool_calls" || resp.TokensUsed != 29 || resp.PromptTokens != 20 || resp.CompletionTokens != 9 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	want := []struct{ id, name, args string }{
+		{id: "call_1", name: "get_host", args: `{"id": 1}`},
+		{id: "call_2", name: "list_alerts", args: `{}`},
+	}
+	if len(resp.ToolCalls) != len(want) {
+		t.Fatalf("got %d tool calls, want %d", len(resp.ToolCalls), len(want))
+	}
+	for i, w := range want {
+		call := resp.ToolCalls[i]
+		if call.ID != w.id || call.Name != w.name || string(call.Arguments) != w.args {
+			t.Fatalf("tool call %d = %s %s %s, want %s %s %s", i, call.ID, call.Name, call.Arguments, w.id, w.name, w.args)
+		}
+	}
+}
+
+func TestOpenAIChatStreamClosesThinking(t *testing.T) {
+	stream := "data: {\"choices\":[{\"delta\":{\"reasoning_content\":\"only thoughts\"}}]}\n\ndata: [DONE]\n\n"
+	p := newOpenAITestServer(t, "text/event-stream", stream, nil)
+
+	resp, err := p.ChatStream(context.Background(), ChatRequest{Model: "gpt-test"}, func(string) error { return nil })
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	if resp.Content != "<think>\nonly thoughts\n</think>\n\n" {
+		t.Fatalf("unterminated reasoning should be closed, got %q", resp.Content)
+	}
+}
+
+func TestOpenAIChatStreamRejectsBadChunks(t *testing.T) {
+	cases := []struct {
+		name   string
+		stream string
+	}{
+		{name: "negative tool call index", stream: `data: {"choices":[{"delta":{"tool_calls":[{"index":-1,"id":"x","function":{"name":"get_host"}}]}}]}`},
+		{name: "huge tool call index", stream: `data: {"choices":[{"delta":{"tool_calls":[{"index":100000000,"function":{"arguments":"{}"}}]}}]}`},
+		{name: "error event", stream: `data: {"error":{"message":"rate limited"}}`},
+		{name: "malformed chunk", stream: `data: {"choices":[`},
+	}
+
+	for _, tc := range cases {
+		p := newOpenAITestServer(t, "text/event-stream", tc.stream+"\n\ndata: [DONE]\n\n", nil)
+		if _, err := p.ChatStream(context.Background(), ChatRequest{Model: "gpt-test"}, func(string) error { return nil }); err == nil {
+			t.Fatalf("%s: expected an error", tc.name)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const retryBackoff = 500 * time.Millisecond
+
+// ErrCircuitOpen is recorded for routes skipped because their provider failed too often recently
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// Route is one provider and model of a failover chain
+type Route struct {
+	ProviderID int
+	Model      string
+	Retries    int           // Attempts after the first before moving to the next route
+	Timeout    time.Duration // Per attempt; zero leaves only the caller's deadline
+}
+
+// BreakerConfig controls the per-provider circuit breaker
+type BreakerConfig struct {
+	Threshold int           // Consecutive failures that open the circuit; zero disables the breaker
+	Cooldown  time.Duration // How long an open circuit is skipped before a single trial request is let through
+}
+
+// Attempt records one try of a routed request
+type Attempt struct {
+	ProviderID int
+	Model      string
+	Duration   time.Duration
+	Err        error // nil for the attempt that answered; ErrCircuitOpen when the route was skipped
+}
+
+// CircuitState is the breaker state of one provider
+type CircuitState struct {
+	Open      bool      `json:"open"`
+	Failures  int       `json:"failures"`             // Consecutive failures
+	OpenUntil time.Time `json:"open_until,omitempty"` // When a trial request is let through again
+}
+
+// RouteFunc runs a request on one route with the route's client. The model to use is route.Model.
+type RouteFunc func(ctx context.Context, client *Client, route Route) error
+
+// FailoverError is returned when no route answered
+type FailoverError struct {
+	Attempts []Attempt
+}
+
+func (e *FailoverError) Error() string {
+	if len(e.Attempts) == 0 {
+		return "no LLM route configured"
+	}
+	parts := make([]string, 0, len(e.Attempts))
+	for _, attempt := range e.Attempts {
+		parts = append(parts, fmt.Sprintf("provider %d (%s): %v", attempt.ProviderID, attempt.Model, attempt.Err))
+	}
+	return "all LLM routes failed: " + strings.Join(parts, "; ")
+}
+
+// Unwrap returns the error of the last attempt, so callers can test for e.g. context.DeadlineExceeded
+func (e *FailoverError) Unwrap() error {
+	if len(e.Attempts) == 0 {
+		return nil
+	}
+	return e.Attempts[len(e.Attempts)-1].Err
+}
+
+type circuit struct {
+	failures  int
+	openUntil time.Time
+	probing   bool // A trial request of a half-open circuit is in flight
+}
+
+// SetBreakerConfig changes the circuit breaker settings; existing failure counts are kept
+func (s *Service) SetBreakerConfig(cfg BreakerConfig) {
+	s.mu.Lock()
+	s.breaker = cfg
+	s.mu.Unlock()
+}
+
+// ResetCircuit closes the circuit of a provider, e.g. after a successful health check
+func (s *Service) ResetCircuit(providerID int) {
+	s.mu.Lock()
+	delete(s.circuits, providerID)
+	s.mu.Unlock()
+}
+
+// CircuitStates returns the breaker state of every provider that failed since its last success
+func (s *Service) CircuitStates() map[int]CircuitState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := time.Now()
+	states := make(map[int]CircuitState, len(s.circuits))
+	for id, c := range s.circuits {
+		state := CircuitState{Failures: c.failures}
+		if s.breaker.Threshold > 0 && c.failures >= s.breaker.Threshold {
+			state.Open = now.Before(c.openUntil) || c.probing
+			state.OpenUntil = c.openUntil
+		}
+		states[id] = state
+	}
+	return states
+}
+
+// allow reports whether a request may be sent to the provider. After the cooldown of an open circuit one
+// trial request is allowed; its outcome closes or reopens the circuit.
+func (s *Service) allow(providerID int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.circuits[providerID]
+	if !ok || s.breaker.Threshold <= 0 || c.failures < s.breaker.Threshold {
+		return true
+	}
+	if time.Now().Before(c.openUntil) || c.probing {
+		return false
+	}
+	c.probing = true
+	return true
+}
+
+func (s *Service) recordOutcome(providerID int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		delete(s.circuits, providerID)
+		return
+	}
+	c, ok := s.circuits[providerID]
+	if !ok {
+		c = &circuit{}
+		s.circuits[providerID] = c
+	}
+	c.failures++
+	c.probing = false
+	if s.breaker.Threshold > 0 && c.failures >= s.breaker.Threshold {
+		c.openUntil = time.Now().Add(s.breaker.Cooldown)
+	}
+}
+
+// Failover runs fn on the routes in order until one succeeds. Each route is retried on error, every attempt
+// is bounded by the route's timeout, and routes whose provider circuit is open are skipped. It returns the
+// route that answered together with all attempts made. Cancelling ctx stops the chain without trying further
+// routes, and a cancelled attempt does not count against the provider.
+func (s *Service) Failover(ctx context.Context, routes []Route, fn RouteFunc) (Route, []Attempt, error) {
+	var attempts []Attempt
+	for _, route := range routes {
+		client, err := s.GetClient(route.ProviderID)
+		if err != nil {
+			attempts = append(attempts, Attempt{ProviderID: route.ProviderID, Model: route.Model, Err: err})
+			continue
+		}
+
+		for try := 0; try <= route.Retries; try++ {
+			if try > 0 {
+				// Back off a little more on every retry of the same route
+				select {
+				case <-ctx.Done():
+				case <-time.After(time.Duration(try) * retryBackoff):
+				}
+			}
+			if ctx.Err() != nil {
+				return Route{}, attempts, ctx.Err()
+			}
+			if !s.allow(route.ProviderID) {
+				attempts = append(attempts, Attempt{ProviderID: route.ProviderID, Model: route.Model, Err: ErrCircuitOpen})
+				break
+			}
+
+			attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+			if route.Timeout > 0 {
+				attemptCtx, cancel = context.WithTimeout(ctx, route.Timeout)
+			}
+			start := time.Now()
+			err := fn(attemptCtx, client, route)
+			cancel()
+			attempts = append(attempts, Attempt{ProviderID: route.ProviderID, Model: route.Model, Duration: time.Since(start), Err: err})
+			if ctx.Err() != nil {
+				// The caller gave up; this says nothing about the provider's health
+				s.mu.Lock()
+				if c, ok := s.circuits[route.ProviderID]; ok {
+					c.probing = false
+				}
+				s.mu.Unlock()
+				return Route{}, attempts, ctx.Err()
+			}
+			s.recordOutcome(route.ProviderID, err)
+			if err == nil {
+				return route, attempts, nil
+			}
+		}
+	}
+	return Route{}, attempts, &FailoverError{Attempts: attempts}
+}
@@ -0,0 +1,206 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errProvider = errors.New("provider failed")
+
+// newRouterTestService registers placeholder clients; requests never reach them because RouteFunc is faked
+func newRouterTestService(providerIDs ...int) *Service {
+	s := NewService()
+	for _, id := range providerIDs {
+		s.clients[id] = &Client{}
+	}
+	return s
+}
+
+func TestFailover(t *testing.T) {
+	cases := []struct {
+		name         string
+		routes       []Route
+		fail         map[int]int // provider ID -> calls that fail before it answers; -1 always fails
+		wantProvider int
+		wantAttempts []int
+		wantErr      bool
+	}{
+		{
+			name:         "first route answers",
+			routes:       []Route{{ProviderID: 1}, {ProviderID: 2}},
+			wantProvider: 1,
+			wantAttempts: []int{1},
+		},
+		{
+			name:         "falls through to the next route",
+			routes:       []Route{{ProviderID: 1}, {ProviderID: 2}},
+			fail:         map[int]int{1: -1},
+			wantProvider: 2,
+			wantAttempts: []int{1, 2},
+		},
+		{
+			name:         "retries the same route first",
+			routes:       []Route{{ProviderID: 1, Retries: 1}, {ProviderID: 2}},
+			fail:         map[int]int{1: 1},
+			wantProvider: 1,
+			wantAttempts: []int{1, 1},
+		},
+		{
+			name:         "unregistered provider is skipped",
+			routes:       []Route{{ProviderID: 9}, {ProviderID: 2}},
+			wantProvider: 2,
+			wantAttempts: []int{9, 2},
+		},
+		{
+			name:         "every route fails",
+			routes:       []Route{{ProviderID: 1}, {ProviderID: 2}},
+			fail:         map[int]int{1: -1, 2: -1},
+			wantAttempts: []int{1, 2},
+			wantErr:      true,
+		},
+		{
+			name:    "no routes",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		s := newRouterTestService(1, 2)
+		calls := map[int]int{}
+		route, attempts, err := s.Failover(context.Background(), tc.routes, func(ctx context.Context, client *Client, route Route) error {
+			calls[route.ProviderID]++
+			if n, ok := tc.fail[route.ProviderID]; ok && (n < 0 || calls[route.ProviderID] <= n) {
+				return errProvider
+			}
+			return nil
+		})
+
+		if tc.wantErr {
+			var failover *FailoverError
+			if !errors.As(err, &failover) {
+				t.Fatalf("%s: expected FailoverError, got %v", tc.name, err)
+			}
+			if len(tc.wantAttempts) > 0 && !errors.Is(err, errProvider) {
+				t.Fatalf("%s: FailoverError should unwrap to the last attempt's error, got %v", tc.name, err)
+			}
+		} else {
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tc.name, err)
+			}
+			if route.ProviderID != tc.wantProvider {
+				t.Fatalf("%s: answered by provider %d, want %d", tc.name, route.ProviderID, tc.wantProvider)
+			}
+		}
+		if len(attempts) != len(tc.wantAttempts) {
+			t.Fatalf("%s: %d attempts, want %d", tc.name, len(attempts), len(tc.wantAttempts))
+		}
+		for i, attempt := range attempts {
+			if attempt.ProviderID != tc.wantAttempts[i] {
+				t.Fatalf("%s: attempt %d went to provider %d, want %d", tc.name, i, attempt.ProviderID, tc.wantAttempts[i])
+			}
+		}
+	}
+}
+
+func TestFailoverSkipsOpenCircuit(t *testing.T) {
+	s := newRouterTestService(1, 2)
+	s.SetBreakerConfig(BreakerConfig{Threshold: 1, Cooldown: time.Hour})
+	routes := []Route{{ProviderID: 1}, {ProviderID: 2}}
+	fn := func(ctx context.Context, client *Client, route Route) error {
+		if route.ProviderID == 1 {
+			return errProvider
+		}
+		return nil
+	}
+
+	if _, _, err := s.Failover(context.Background(), routes, fn); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	route, attempts, err := s.Failover(context.Background(), routes, fn)
+	if err != nil || route.ProviderID != 2 {
+		t.Fatalf("second request answered by %d, err %v", route.ProviderID, err)
+	}
+	if len(attempts) != 2 || !errors.Is(attempts[0].Err, ErrCircuitOpen) {
+		t.Fatalf("expected provider 1 to be skipped with an open circuit, got %+v", attempts)
+	}
+}
+
+func TestFailoverCancelledContext(t *testing.T) {
+	s := newRouterTestService(1, 2)
+	s.SetBreakerConfig(BreakerConfig{Threshold: 1, Cooldown: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	_, attempts, err := s.Failover(ctx, []Route{{ProviderID: 1}, {ProviderID: 2}}, func(ctx context.Context, client *Client, route Route) error {
+		cancel()
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(attempts) != 1 {
+		t.Fatalf("cancelled chain should stop after one attempt, got %d", len(attempts))
+	}
+	if !s.allow(1) {
+		t.Fatalf("a cancelled attempt must not open the provider's circuit")
+	}
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	const cooldown = 20 * time.Millisecond
+	s := NewService()
+	s.SetBreakerConfig(BreakerConfig{Threshold: 2, Cooldown: cooldown})
+
+	steps := []struct {
+		name    string
+		do      func()
+		allowed bool
+		open    bool
+	}{
+		{name: "closed", do: func() {}, allowed: true},
+		{name: "below threshold", do: func() { s.recordOutcome(1, errProvider) }, allowed: true},
+		{name: "opens at threshold", do: func() { s.recordOutcome(1, errProvider) }, allowed: false, open: true},
+		{name: "half open after cooldown", do: func() { time.Sleep(2 * cooldown) }, allowed: true, open: true},
+		{name: "only one trial in flight", do: func() {}, allowed: false, open: true},
+		{name: "failed trial reopens", do: func() { s.recordOutcome(1, errProvider) }, allowed: false, open: true},
+		{name: "second trial", do: func() { time.Sleep(2 * cooldown) }, allowed: true, open: true},
+		{name: "successful trial closes", do: func() { s.recordOutcome(1, nil) }, allowed: true},
+	}
+
+	for _, step := range steps {
+		step.do()
+		if got := s.allow(1); got != step.allowed {
+			t.Fatalf("%s: allow = %v, want %v", step.name, got, step.allowed)
+		}
+		if got := s.CircuitStates()[1].Open; got != step.open {
+			t.Fatalf("%s: open = %v, want %v", step.name, got, step.open)
+		}
+	}
+	if _, ok := s.CircuitStates()[1]; ok {
+		t.Fatalf("a closed circuit should not be reported")
+	}
+}
+
+func TestCircuitBreakerDisabledAndReset(t *testing.T) {
+	s := NewService()
+	for i := 0; i < 5; i++ {
+		s.recordOutcome(1, errProvider)
+	}
+	if !s.allow(1) {
+		t.Fatalf("a zero threshold should never open the circuit")
+	}
+	if state := s.CircuitStates()[1]; state.Open || state.Failures != 5 {
+		t.Fatalf("unexpected state with the breaker disabled: %+v", state)
+	}
+
+	// Failures counted while disabled are kept, so enabling the breaker starts half open with one trial
+	s.SetBreakerConfig(BreakerConfig{Threshold: 3, Cooldown: time.Hour})
+	if !s.allow(1) || s.allow(1) {
+		t.Fatalf("enabling the breaker over kept failures should allow exactly one trial")
+	}
+	s.ResetCircuit(1)
+	if !s.allow(1) {
+		t.Fatalf("ResetCircuit should close the circuit")
+	}
+}
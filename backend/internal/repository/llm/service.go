@@ -6,16 +6,19 @@ import (
 	"sync"
 )
 
-// Service provides a high-level interface for LLM operations
+// Service provides a high-level interface for LLM operations and routes requests across providers
 type Service struct {
-	clients map[int]*Client // map of provider ID to client
-	mu      sync.RWMutex
+	clients  map[int]*Client // map of provider ID to client
+	circuits map[int]*circuit
+	breaker  BreakerConfig
+	mu       sync.RWMutex
 }
 
 // NewService creates a new LLM service
 func NewService() *Service {
 	return &Service{
-		clients: make(map[int]*Client),
+		clients:  make(map[int]*Client),
+		circuits: make(map[int]*circuit),
 	}
 }
 
@@ -45,6 +48,17 @@ func (s *Service) RegisterProvider(providerID int, cfg Config) error {
 	return nil
 }
 
+// EnsureProvider registers a provider unless it is already registered with the same configuration
+func (s *Service) EnsureProvider(providerID int, cfg Config) error {
+	s.mu.RLock()
+	client, ok := s.clients[providerID]
+	s.mu.RUnlock()
+	if ok && client.config == cfg {
+		return nil
+	}
+	return s.RegisterProvider(providerID, cfg)
+}
+
 // GetClient returns the client for a provider
 func (s *Service) GetClient(providerID int) (*Client, error) {
 	s.mu.RLock()
@@ -82,6 +96,7 @@ func (s *Service) FetchModels(ctx context.Context, providerID int) ([]string, er
 func (s *Service) RemoveProvider(providerID int) {
 	s.mu.Lock()
 	delete(s.clients, providerID)
+	delete(s.circuits, providerID)
 	s.mu.Unlock()
 }
 
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"nagare/internal/database"
+	"nagare/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// LLMUsageSummary is one group of aggregated LLM usage
+type LLMUsageSummary struct {
+	Key              string  `json:"key"`
+	Requests         int64   `json:"requests"`
+	Failures         int64   `json:"failures"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	AvgLatencyMs     float64 `json:"avg_latency_ms"`
+}
+
+// llmUsageGroups maps the supported group_by values to their column expression
+var llmUsageGroups = map[string]string{
+	"provider": "provider_id",
+	"model":    "model",
+	"feature":  "feature",
+	"user":     "COALESCE(user_id, 0)",
+	"outcome":  "outcome",
+	"day":      "DATE_FORMAT(created_at, '%Y-%m-%d')",
+	"month":    "DATE_FORMAT(created_at, '%Y-%m')",
+}
+
+func applyLLMUsageFilters(query *gorm.DB, filter model.LLMUsageFilter) *gorm.DB {
+	if filter.ProviderID != nil {
+		query = query.Where("provider_id = ?", *filter.ProviderID)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Feature != "" {
+		query = query.Where("feature = ?", filter.Feature)
+	}
+	if filter.Outcome != "" {
+		query = query.Where("outcome = ?", filter.Outcome)
+	}
+	if filter.Model != "" {
+		query = query.Where("model = ?", filter.Model)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+	return query
+}
+
+// AddLLMUsageDAO records an LLM call
+func AddLLMUsageDAO(usage *model.LLMUsage) error {
+	return database.DB.Create(usage).Error
+}
+
+// SearchLLMUsageDAO retrieves LLM usage records by filter, newest first by default
+func SearchLLMUsageDAO(filter model.LLMUsageFilter) ([]model.LLMUsage, error) {
+	query := applyLLMUsageFilters(database.DB.Model(&model.LLMUsage{}), filter)
+	query = applySort(query, filter.SortBy, filter.SortOrder, map[string]string{
+		"provider_id":  "provider_id",
+		"model":        "model",
+		"feature":      "feature",
+		"user_id":      "user_id",
+		"total_tokens": "total_tokens",
+		"latency_ms":   "latency_ms",
+		"outcome":      "outcome",
+		"created_at":   "created_at",
+		"id":           "id",
+	}, "id desc")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	var usages []model.LLMUsage
+	if err := query.Find(&usages).Error; err != nil {
+		return nil, err
+	}
+	return usages, nil
+}
+
+// CountLLMUsageDAO returns total count for LLM usage records by filter
+func CountLLMUsageDAO(filter model.LLMUsageFilter) (int64, error) {
+	var total int64
+	if err := applyLLMUsageFilters(database.DB.Model(&model.LLMUsage{}), filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// SumLLMUsageTokensDAO returns the total tokens of the records matching filter
+func SumLLMUsageTokensDAO(filter model.LLMUsageFilter) (int64, error) {
+	var total int64
+	err := applyLLMUsageFilters(database.DB.Model(&model.LLMUsage{}), filter).
+		Select("COALESCE(SUM(total_tokens), 0)").Scan(&total).Error
+	return total, err
+}
+
+// SummarizeLLMUsageDAO aggregates the records matching filter by one of provider, model, feature, user,
+// outcome, day or month
+func SummarizeLLMUsageDAO(filter model.LLMUsageFilter, groupBy string) ([]LLMUsageSummary, error) {
+	expr, ok := llmUsageGroups[groupBy]
+	if !ok {
+		return nil, model.ErrInvalidInput
+	}
+	var summaries []LLMUsageSummary
+	err := applyLLMUsageFilters(database.DB.Model(&model.LLMUsage{}), filter).
+		Select(expr + " AS `key`, COUNT(*) AS requests, " +
+			"SUM(CASE WHEN outcome = 'success' THEN 0 ELSE 1 END) AS failures, " +
+			"COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens, " +
+			"COALESCE(SUM(completion_tokens), 0) AS completion_tokens, " +
+			"COALESCE(SUM(total_tokens), 0) AS total_tokens, " +
+			"COALESCE(AVG(latency_ms), 0) AS avg_latency_ms").
+		Group(expr).
+		Order("total_tokens desc").
+		Scan(&summaries).Error
+	return summaries, err
+}
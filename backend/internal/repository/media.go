@@ -89,14 +89,9 @@ func AddMediaDAO(media model.Media) error {
 
 // UpdateMediaDAO updates media by ID
 func UpdateMediaDAO(id uint, media model.Media) error {
-	return database.DB.Model(&model.Media{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"name":        media.Name,
-		"type":        media.Type,
-		"target":      media.Target,
-		"enabled":     media.Enabled,
-		"status":      media.Status,
-		"description": media.Description,
-	}).Error
+	return database.DB.Model(&model.Media{}).Where("id = ?", id).
+		Select("name", "type", "target", "enabled", "status", "description", "webhook").
+		Updates(&media).Error
 }
 
 // DeleteMediaByIDDAO deletes media by ID
@@ -1,7 +1,6 @@
 package media
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -36,7 +35,17 @@ func GetService() *Service {
 		globalService.RegisterProvider("qq", NewQQProvider(DefaultQQBaseURL))
 		globalService.RegisterProvider("webhook", NewWebhookProvider())
 		globalService.RegisterProvider("other", NewWebhookProvider()) // 'other' is alias for webhook
-		globalService.RegisterProvider("wechat", NewWebhookProvider())
+		globalService.RegisterProvider("dingtalk", NewDingTalkProvider())
+		globalService.RegisterProvider("feishu", NewFeishuProvider(DefaultFeishuBaseURL))
+		globalService.RegisterProvider("lark", NewFeishuProvider(DefaultLarkBaseURL))
+		wecomProvider := NewWeComProvider()
+		globalService.RegisterProvider("wecom", wecomProvider)
+		globalService.RegisterProvider("wechat", wecomProvider) // 'wechat' is alias for wecom group robots
+		globalService.RegisterProvider("telegram", GlobalTelegramBot.Provider)
+		globalService.RegisterProvider("slack", NewSlackProvider())
+		smtpProvider := NewSMTPProvider()
+		globalService.RegisterProvider("smtp", smtpProvider)
+		globalService.RegisterProvider("email", smtpProvider) // 'email' is alias for smtp
 
 	})
 	return globalService
@@ -104,25 +113,9 @@ func NewWebhookProvider() *WebhookProvider {
 	return &WebhookProvider{Client: &http.Client{Timeout: 5 * time.Second}}
 }
 
-// SendMessage sends a webhook payload
+// SendMessage posts {"message": ...} to the target URL
 func (p *WebhookProvider) SendMessage(ctx context.Context, target, message string) error {
-	if target == "" {
-		return fmt.Errorf("webhook target is empty")
-	}
-	payload := map[string]string{"message": message}
-	body, _ := json.Marshal(payload)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewBuffer(body))
-	if err != nil {
-		return err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := p.Client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("webhook status %d", resp.StatusCode)
-	}
-	return nil
+	body, _ := json.Marshal(map[string]string{"message": message})
+	_, err := p.Send(ctx, target, body, WebhookOptions{})
+	return err
 }
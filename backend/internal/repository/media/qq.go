@@ -3,6 +3,7 @@ package media
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -107,6 +108,34 @@ func (p *QQProvider) SendMessage(ctx context.Context, target, message string) er
 	return nil
 }
 
+// SendRichMessage sends the rendered text with the chart appended as a CQ-code image
+func (p *QQProvider) SendRichMessage(ctx context.Context, target string, msg RichMessage) error {
+	message := escapeCQText(msg.PlainText())
+	if msg.Image != nil {
+		file := ""
+		switch {
+		case len(msg.Image.Data) > 0:
+			file = "base64://" + base64.StdEncoding.EncodeToString(msg.Image.Data)
+		case msg.Image.URL != "":
+			file = escapeCQParam(msg.Image.URL)
+		}
+		if file != "" {
+			message += "\n[CQ:image,file=" + file + "]"
+		}
+	}
+	return p.SendMessage(ctx, target, message)
+}
+
+// escapeCQText escapes text so OneBot does not read it as CQ codes
+func escapeCQText(s string) string {
+	return strings.NewReplacer("&", "&amp;", "[", "&#91;", "]", "&#93;").Replace(s)
+}
+
+// escapeCQParam escapes a CQ code parameter value
+func escapeCQParam(s string) string {
+	return strings.NewReplacer("&", "&amp;", "[", "&#91;", "]", "&#93;", ",", "&#44;").Replace(s)
+}
+
 func parseQQTarget(target string) (baseURL, messageType, userID, groupID string, err error) {
 	value := strings.TrimSpace(target)
 	if value == "" {
@@ -0,0 +1,140 @@
+package media
+
+import (
+	"context"
+	"strings"
+)
+
+// RichMessage is a structured notification. Text is the fully rendered message that text-only providers
+// receive unchanged; the other fields let rich providers lay the same content out natively.
+type RichMessage struct {
+	Title         string
+	Severity      int    // Alert severity 0-5; -1 when the message is not about an alert
+	SeverityLabel string // e.g. "High"; empty when Severity is -1
+	Resolved      bool   // The message reports a recovery
+	Text          string
+	Summary       string // Body shown below the title; defaults to Text without its first line
+	Fields        []RichField
+	Links         []RichLink
+	Buttons       []RichLink
+	Image         *RichImage
+}
+
+// RichField is a labelled value; Short fields may be laid out side by side
+type RichField struct {
+	Name  string
+	Value string
+	Short bool
+}
+
+// RichLink is a titled URL, shown as a link or as an action button
+type RichLink struct {
+	Text string
+	URL  string
+}
+
+// RichImage is an attached picture such as a metric chart; providers use Data or URL, whichever they can send
+type RichImage struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+	URL         string
+}
+
+// RichProvider is implemented by providers that can render a RichMessage natively
+type RichProvider interface {
+	SendRichMessage(ctx context.Context, target string, msg RichMessage) error
+}
+
+// PlainText returns the message as text-only providers send it
+func (m RichMessage) PlainText() string {
+	if strings.TrimSpace(m.Text) != "" {
+		return m.Text
+	}
+	lines := []string{m.Title}
+	if summary := m.SummaryText(); summary != "" {
+		lines = append(lines, summary)
+	}
+	for _, field := range m.Fields {
+		lines = append(lines, field.Name+": "+field.Value)
+	}
+	for _, link := range append(append([]RichLink{}, m.Links...), m.Buttons...) {
+		lines = append(lines, link.Text+": "+link.URL)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// SubjectText returns Title, or the first line of Text when no title is set
+func (m RichMessage) SubjectText() string {
+	if title := strings.TrimSpace(m.Title); title != "" {
+		return title
+	}
+	subject, _ := splitEmailSubject(m.Text)
+	return subject
+}
+
+// SummaryText returns Summary, or Text below its first line when no summary is set
+func (m RichMessage) SummaryText() string {
+	if summary := strings.TrimSpace(m.Summary); summary != "" {
+		return summary
+	}
+	_, body := splitEmailSubject(m.Text)
+	_, rest, _ := strings.Cut(body, "\n")
+	return strings.TrimSpace(rest)
+}
+
+// color is the accent for the message: green for recoveries, blue without severity, otherwise by severity
+func (m RichMessage) color() string {
+	switch {
+	case m.Resolved:
+		return "#67C23A"
+	case m.Severity < 0:
+		return "#409EFF"
+	case m.Severity >= 4:
+		return "#F56C6C"
+	case m.Severity >= 2:
+		return "#E6A23C"
+	default:
+		return "#909399"
+	}
+}
+
+// textFallback adapts a text-only provider to RichProvider by sending the rendered text
+type textFallback struct {
+	Provider
+}
+
+func (p textFallback) SendRichMessage(ctx context.Context, target string, msg RichMessage) error {
+	return p.SendMessage(ctx, target, msg.PlainText())
+}
+
+// AsRichProvider returns the provider's native rich implementation, or a fallback that sends plain text
+func AsRichProvider(provider Provider) RichProvider {
+	if rich, ok := provider.(RichProvider); ok {
+		return rich
+	}
+	return textFallback{provider}
+}
+
+// AcceptsImageData reports whether the provider of a media type can deliver RichImage.Data, such as an
+// email attachment or a QQ image; the others can only show images hosted at a URL
+func (s *Service) AcceptsImageData(mediaType string) bool {
+	provider, err := s.GetProvider(mediaType)
+	if err != nil {
+		return false
+	}
+	switch provider.(type) {
+	case *SMTPProvider, *QQProvider:
+		return true
+	}
+	return false
+}
+
+// SendRichMessage sends a structured message via the specified media type, falling back to text where needed
+func (s *Service) SendRichMessage(ctx context.Context, mediaType, target string, msg RichMessage) error {
+	provider, err := s.GetProvider(mediaType)
+	if err != nil {
+		return err
+	}
+	return AsRichProvider(provider).SendRichMessage(ctx, target, msg)
+}
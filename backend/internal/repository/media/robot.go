@@ -0,0 +1,364 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Default robot webhook endpoints; a bare token in the target is appended to these
+const (
+	DefaultDingTalkBaseURL = "https://oapi.dingtalk.com/robot/send?access_token="
+	DefaultFeishuBaseURL   = "https://open.feishu.cn/open-apis/bot/v2/hook/"
+	DefaultLarkBaseURL     = "https://open.larksuite.com/open-apis/bot/v2/hook/"
+	DefaultWeComBaseURL    = "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key="
+)
+
+const (
+	dingTalkMaxBytes = 20000
+	feishuMaxBytes   = 30000
+	wecomMaxBytes    = 4096
+)
+
+// robotTarget is a parsed robot media target.
+// Target formats: <webhook url> | <token> | <webhook url or token> secret:<signing secret>
+type robotTarget struct {
+	URL    string
+	Secret string
+}
+
+func parseRobotTarget(target, baseURL string) (robotTarget, error) {
+	fields := strings.Fields(target)
+	if len(fields) == 0 {
+		return robotTarget{}, fmt.Errorf("robot target is empty")
+	}
+	var parsed robotTarget
+	for _, field := range fields {
+		lower := strings.ToLower(field)
+		switch {
+		case strings.HasPrefix(lower, "secret:"), strings.HasPrefix(lower, "secret="):
+			parsed.Secret = field[len("secret:"):]
+		case parsed.URL == "":
+			parsed.URL = field
+		default:
+			return robotTarget{}, fmt.Errorf("unexpected robot target field %q; use '<webhook> secret:<secret>'", field)
+		}
+	}
+	if parsed.URL == "" {
+		return robotTarget{}, fmt.Errorf("robot webhook is missing in target")
+	}
+	lower := strings.ToLower(parsed.URL)
+	if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+		parsed.URL = baseURL + url.QueryEscape(parsed.URL)
+	}
+	return parsed, nil
+}
+
+// postRobotJSON posts a payload to a robot webhook and returns the response body
+func postRobotJSON(ctx context.Context, client *http.Client, endpoint string, payload interface{}) ([]byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return respBody, fmt.Errorf("robot api status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return respBody, nil
+}
+
+// truncateUTF8 cuts s to at most max bytes without splitting a character
+func truncateUTF8(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	const suffix = "\n..."
+	cut := max - len(suffix)
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + suffix
+}
+
+// robotMarkdownBody renders the message below its subject line, keeping line breaks in markdown
+func robotMarkdownBody(message, lineBreak string) string {
+	_, body := splitEmailSubject(message)
+	_, rest, _ := strings.Cut(body, "\n")
+	lines := strings.Split(strings.ReplaceAll(strings.TrimSpace(rest), "\r\n", "\n"), "\n")
+	return strings.Join(lines, lineBreak)
+}
+
+// DingTalkProvider sends markdown messages to a DingTalk group robot
+type DingTalkProvider struct {
+	BaseURL string
+	Client  *http.Client
+	Now     func() time.Time
+}
+
+// NewDingTalkProvider creates a DingTalk robot provider
+func NewDingTalkProvider() *DingTalkProvider {
+	return &DingTalkProvider{
+		BaseURL: DefaultDingTalkBaseURL,
+		Client:  &http.Client{Timeout: 5 * time.Second},
+		Now:     time.Now,
+	}
+}
+
+type dingTalkResponse struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+// SendMessage posts a markdown message; with a secret the request is signed with HmacSHA256(timestamp+"\n"+secret)
+func (p *DingTalkProvider) SendMessage(ctx context.Context, target, message string) error {
+	parsed, err := parseRobotTarget(target, p.BaseURL)
+	if err != nil {
+		return err
+	}
+	endpoint := parsed.URL
+	if parsed.Secret != "" {
+		timestamp := strconv.FormatInt(p.Now().UnixMilli(), 10)
+		mac := hmac.New(sha256.New, []byte(parsed.Secret))
+		mac.Write([]byte(timestamp + "\n" + parsed.Secret))
+		sign := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+		endpoint = appendQuery(endpoint, url.Values{"timestamp": {timestamp}, "sign": {sign}})
+	}
+
+	title, _ := splitEmailSubject(message)
+	// DingTalk markdown needs two trailing spaces for a hard line break
+	text := truncateUTF8("#### "+title+"\n\n"+robotMarkdownBody(message, "  \n"), dingTalkMaxBytes)
+	payload := map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": title,
+			"text":  text,
+		},
+	}
+	respBody, err := postRobotJSON(ctx, p.Client, endpoint, payload)
+	if err != nil {
+		return fmt.Errorf("dingtalk: %w", err)
+	}
+	var apiResp dingTalkResponse
+	if err := json.Unmarshal(respBody, &apiResp); err != nil {
+		return fmt.Errorf("dingtalk: invalid response: %w", err)
+	}
+	if apiResp.ErrCode != 0 {
+		return fmt.Errorf("dingtalk errcode %d: %s", apiResp.ErrCode, apiResp.ErrMsg)
+	}
+	return nil
+}
+
+// FeishuProvider sends interactive card messages to a Feishu or Lark custom bot
+type FeishuProvider struct {
+	BaseURL string
+	Client  *http.Client
+	Now     func() time.Time
+}
+
+// NewFeishuProvider creates a Feishu/Lark bot provider; baseURL selects the Feishu or Lark open platform
+func NewFeishuProvider(baseURL string) *FeishuProvider {
+	if strings.TrimSpace(baseURL) == "" {
+		baseURL = DefaultFeishuBaseURL
+	}
+	return &FeishuProvider{
+		BaseURL: baseURL,
+		Client:  &http.Client{Timeout: 5 * time.Second},
+		Now:     time.Now,
+	}
+}
+
+type feishuResponse struct {
+	Code          *int   `json:"code"`
+	Msg           string `json:"msg"`
+	StatusCode    *int   `json:"StatusCode"`
+	StatusMessage string `json:"StatusMessage"`
+}
+
+// SendMessage posts a card message; with a secret the body carries timestamp and sign as HmacSHA256 keyed by timestamp+"\n"+secret
+func (p *FeishuProvider) SendMessage(ctx context.Context, target, message string) error {
+	title, _ := splitEmailSubject(message)
+	content := truncateUTF8(robotMarkdownBody(message, "\n"), feishuMaxBytes)
+	elements := []interface{}{}
+	if strings.TrimSpace(content) != "" {
+		elements = append(elements, map[string]interface{}{"tag": "markdown", "content": content})
+	}
+	return p.sendCard(ctx, target, feishuCard("blue", title, elements))
+}
+
+// SendRichMessage posts a card coloured by severity, with fields in two columns, links and URL buttons.
+// Custom bots cannot upload images, so a chart is only shown when the message carries an image URL.
+func (p *FeishuProvider) SendRichMessage(ctx context.Context, target string, msg RichMessage) error {
+	elements := []interface{}{}
+	if summary := msg.SummaryText(); summary != "" {
+		elements = append(elements, map[string]interface{}{"tag": "markdown", "content": truncateUTF8(summary, feishuMaxBytes/2)})
+	}
+	if len(msg.Fields) > 0 {
+		fields := make([]interface{}, 0, len(msg.Fields))
+		for _, field := range msg.Fields {
+			fields = append(fields, map[string]interface{}{
+				"is_short": field.Short,
+				"text":     map[string]string{"tag": "lark_md", "content": "**" + field.Name + "**\n" + field.Value},
+			})
+		}
+		elements = append(elements, map[string]interface{}{"tag": "div", "fields": fields})
+	}
+	if len(msg.Links) > 0 {
+		lines := make([]string, 0, len(msg.Links))
+		for _, link := range msg.Links {
+			lines = append(lines, "["+link.Text+"]("+link.URL+")")
+		}
+		elements = append(elements, map[string]interface{}{"tag": "markdown", "content": strings.Join(lines, "  ")})
+	}
+	if msg.Image != nil && msg.Image.URL != "" {
+		elements = append(elements, map[string]interface{}{"tag": "markdown", "content": "[Chart](" + msg.Image.URL + ")"})
+	}
+	if len(msg.Buttons) > 0 {
+		actions := make([]interface{}, 0, len(msg.Buttons))
+		for i, button := range msg.Buttons {
+			buttonType := "default"
+			if i == 0 {
+				buttonType = "primary"
+			}
+			actions = append(actions, map[string]interface{}{
+				"tag":  "button",
+				"text": map[string]string{"tag": "plain_text", "content": button.Text},
+				"url":  button.URL,
+				"type": buttonType,
+			})
+		}
+		elements = append(elements, map[string]interface{}{"tag": "hr"}, map[string]interface{}{"tag": "action", "actions": actions})
+	}
+	return p.sendCard(ctx, target, feishuCard(feishuHeaderTemplate(msg), msg.SubjectText(), elements))
+}
+
+func feishuCard(template, title string, elements []interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"config": map[string]interface{}{"wide_screen_mode": true},
+		"header": map[string]interface{}{
+			"template": template,
+			"title":    map[string]string{"tag": "plain_text", "content": title},
+		},
+		"elements": elements,
+	}
+}
+
+// feishuHeaderTemplate picks the card header colour the same way RichMessage.color does
+func feishuHeaderTemplate(msg RichMessage) string {
+	switch {
+	case msg.Resolved:
+		return "green"
+	case msg.Severity < 0:
+		return "blue"
+	case msg.Severity >= 4:
+		return "red"
+	case msg.Severity >= 2:
+		return "orange"
+	default:
+		return "grey"
+	}
+}
+
+// sendCard signs and posts an interactive card
+func (p *FeishuProvider) sendCard(ctx context.Context, target string, card map[string]interface{}) error {
+	parsed, err := parseRobotTarget(target, p.BaseURL)
+	if err != nil {
+		return err
+	}
+	payload := map[string]interface{}{
+		"msg_type": "interactive",
+		"card":     card,
+	}
+	if parsed.Secret != "" {
+		timestamp := strconv.FormatInt(p.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(timestamp+"\n"+parsed.Secret))
+		payload["timestamp"] = timestamp
+		payload["sign"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+
+	respBody, err := postRobotJSON(ctx, p.Client, parsed.URL, payload)
+	if err != nil {
+		return fmt.Errorf("feishu: %w", err)
+	}
+	var apiResp feishuResponse
+	if err := json.Unmarshal(respBody, &apiResp); err != nil {
+		return fmt.Errorf("feishu: invalid response: %w", err)
+	}
+	// Newer bots answer with code/msg, older ones with StatusCode/StatusMessage
+	if apiResp.Code != nil && *apiResp.Code != 0 {
+		return fmt.Errorf("feishu code %d: %s", *apiResp.Code, apiResp.Msg)
+	}
+	if apiResp.StatusCode != nil && *apiResp.StatusCode != 0 {
+		return fmt.Errorf("feishu code %d: %s", *apiResp.StatusCode, apiResp.StatusMessage)
+	}
+	return nil
+}
+
+// WeComProvider sends markdown messages to a WeCom (WeChat Work) group robot.
+// WeCom robots authenticate by the key in the webhook URL and have no request signing, so a secret is ignored.
+type WeComProvider struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewWeComProvider creates a WeCom robot provider
+func NewWeComProvider() *WeComProvider {
+	return &WeComProvider{
+		BaseURL: DefaultWeComBaseURL,
+		Client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// SendMessage posts a markdown message
+func (p *WeComProvider) SendMessage(ctx context.Context, target, message string) error {
+	parsed, err := parseRobotTarget(target, p.BaseURL)
+	if err != nil {
+		return err
+	}
+	title, _ := splitEmailSubject(message)
+	content := truncateUTF8("**"+title+"**\n"+robotMarkdownBody(message, "\n"), wecomMaxBytes)
+	payload := map[string]interface{}{
+		"msgtype":  "markdown",
+		"markdown": map[string]string{"content": content},
+	}
+	respBody, err := postRobotJSON(ctx, p.Client, parsed.URL, payload)
+	if err != nil {
+		return fmt.Errorf("wecom: %w", err)
+	}
+	var apiResp dingTalkResponse
+	if err := json.Unmarshal(respBody, &apiResp); err != nil {
+		return fmt.Errorf("wecom: invalid response: %w", err)
+	}
+	if apiResp.ErrCode != 0 {
+		return fmt.Errorf("wecom errcode %d: %s", apiResp.ErrCode, apiResp.ErrMsg)
+	}
+	return nil
+}
+
+func appendQuery(endpoint string, values url.Values) string {
+	sep := "?"
+	if strings.Contains(endpoint, "?") {
+		sep = "&"
+	}
+	return endpoint + sep + values.Encode()
+}
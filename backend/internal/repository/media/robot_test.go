@@ -0,0 +1,191 @@
+package media
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Signatures below were computed independently with Python's hmac module for secret "SECtest123"
+const (
+	robotTestSecret   = "SECtest123"
+	dingTalkTestSign  = "w3RMHXzixTMdzr8OHJUmVLS4IoPJVdu+Ut1LE48MePE="
+	feishuTestSign    = "eHRRCyLH7Z4IJQSJlfwertHZThRUYVu2hTUH02xPXYU="
+	robotTestMessage  = "CPU high on web-1\nusage 95%\nsince 10:00"
+	robotTestUnixTime = 1700000000
+)
+
+func robotTestNow() time.Time { return time.Unix(robotTestUnixTime, 0) }
+
+// robotCapture records the last request a robot test server received
+type robotCapture struct {
+	query url.Values
+	body  map[string]interface{}
+}
+
+func newRobotTestServer(t *testing.T, response string) (*httptest.Server, *robotCapture) {
+	t.Helper()
+	capture := &robotCapture{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capture.query = r.URL.Query()
+		raw, _ := io.ReadAll(r.Body)
+		capture.body = map[string]interface{}{}
+		if err := json.Unmarshal(raw, &capture.body); err != nil {
+			t.Errorf("request body is not JSON: %v", err)
+		}
+		if ct := r.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, capture
+}
+
+func TestDingTalkSendMessage(t *testing.T) {
+	cases := []struct {
+		name     string
+		response string
+		secret   bool
+		wantErr  string
+	}{
+		{name: "signed", response: `{"errcode":0,"errmsg":"ok"}`, secret: true},
+		{name: "unsigned", response: `{"errcode":0,"errmsg":"ok"}`},
+		{name: "api error", response: `{"errcode":310000,"errmsg":"sign not match"}`, secret: true, wantErr: "errcode 310000"},
+		{name: "invalid response", response: `<html>`, wantErr: "invalid response"},
+	}
+
+	for _, tc := range cases {
+		srv, capture := newRobotTestServer(t, tc.response)
+		p := &DingTalkProvider{BaseURL: srv.URL + "/robot/send?access_token=", Client: srv.Client(), Now: robotTestNow}
+		target := "tok123"
+		if tc.secret {
+			target += " secret:" + robotTestSecret
+		}
+
+		err := p.SendMessage(context.Background(), target, robotTestMessage)
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("%s: error %v, want %q", tc.name, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if capture.query.Get("access_token") != "tok123" {
+			t.Fatalf("%s: access token lost: %v", tc.name, capture.query)
+		}
+		if tc.secret {
+			if capture.query.Get("timestamp") != "1700000000000" || capture.query.Get("sign") != dingTalkTestSign {
+				t.Fatalf("%s: timestamp=%q sign=%q, want 1700000000000 %s", tc.name, capture.query.Get("timestamp"), capture.query.Get("sign"), dingTalkTestSign)
+			}
+		} else if capture.query.Has("sign") {
+			t.Fatalf("%s: unsigned request carries a sign", tc.name)
+		}
+		markdown, _ := capture.body["markdown"].(map[string]interface{})
+		if capture.body["msgtype"] != "markdown" || markdown["title"] != "CPU high on web-1" || markdown["text"] != "#### CPU high on web-1\n\nusage 95%  \nsince 10:00" {
+			t.Fatalf("%s: unexpected payload %v", tc.name, capture.body)
+		}
+	}
+}
+
+func TestFeishuSendMessage(t *testing.T) {
+	cases := []struct {
+		name     string
+		response string
+		secret   bool
+		wantErr  string
+	}{
+		{name: "signed", response: `{"code":0,"msg":"success"}`, secret: true},
+		{name: "legacy status", response: `{"StatusCode":0,"StatusMessage":"success"}`},
+		{name: "api error", response: `{"code":19021,"msg":"sign match fail or timestamp is not within one hour from current time"}`, secret: true, wantErr: "code 19021"},
+		{name: "legacy api error", response: `{"StatusCode":9499,"StatusMessage":"Bad Request"}`, wantErr: "code 9499"},
+	}
+
+	for _, tc := range cases {
+		srv, capture := newRobotTestServer(t, tc.response)
+		p := &FeishuProvider{BaseURL: srv.URL + "/open-apis/bot/v2/hook/", Client: srv.Client(), Now: robotTestNow}
+		target := "hook-id"
+		if tc.secret {
+			target += " secret:" + robotTestSecret
+		}
+
+		err := p.SendMessage(context.Background(), target, robotTestMessage)
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("%s: error %v, want %q", tc.name, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if tc.secret {
+			if capture.body["timestamp"] != "1700000000" || capture.body["sign"] != feishuTestSign {
+				t.Fatalf("%s: timestamp=%v sign=%v, want 1700000000 %s", tc.name, capture.body["timestamp"], capture.body["sign"], feishuTestSign)
+			}
+		} else if _, ok := capture.body["sign"]; ok {
+			t.Fatalf("%s: unsigned request carries a sign", tc.name)
+		}
+		card, _ := capture.body["card"].(map[string]interface{})
+		header, _ := card["header"].(map[string]interface{})
+		title, _ := header["title"].(map[string]interface{})
+		if capture.body["msg_type"] != "interactive" || title["content"] != "CPU high on web-1" {
+			t.Fatalf("%s: unexpected payload %v", tc.name, capture.body)
+		}
+	}
+}
+
+func TestWeComSendMessage(t *testing.T) {
+	cases := []struct {
+		name     string
+		response string
+		wantErr  string
+	}{
+		{name: "ok", response: `{"errcode":0,"errmsg":"ok"}`},
+		{name: "api error", response: `{"errcode":93000,"errmsg":"invalid webhook url"}`, wantErr: "errcode 93000"},
+	}
+
+	for _, tc := range cases {
+		srv, capture := newRobotTestServer(t, tc.response)
+		p := &WeComProvider{BaseURL: srv.URL + "/cgi-bin/webhook/send?key=", Client: srv.Client()}
+
+		// WeCom has no signing, so a configured secret must not change the request
+		err := p.SendMessage(context.Background(), "key-1 secret:"+robotTestSecret, robotTestMessage)
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("%s: error %v, want %q", tc.name, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if capture.query.Get("key") != "key-1" || capture.query.Has("sign") {
+			t.Fatalf("%s: unexpected query %v", tc.name, capture.query)
+		}
+		markdown, _ := capture.body["markdown"].(map[string]interface{})
+		if capture.body["msgtype"] != "markdown" || markdown["content"] != "**CPU high on web-1**\nusage 95%\nsince 10:00" {
+			t.Fatalf("%s: unexpected payload %v", tc.name, capture.body)
+		}
+	}
+}
+
+func TestRobotHTTPStatusError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream unavailable", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	p := &DingTalkProvider{BaseURL: srv.URL + "/?access_token=", Client: srv.Client(), Now: robotTestNow}
+	if err := p.SendMessage(context.Background(), "tok", robotTestMessage); err == nil || !strings.Contains(err.Error(), "status 502") {
+		t.Fatalf("expected the HTTP status to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultSlackBaseURL is the incoming webhook prefix; a bare "T000/B000/XXXX" target is appended to it
+const DefaultSlackBaseURL = "https://hooks.slack.com/services/"
+
+// Block Kit limits
+const (
+	slackMaxHeaderRunes  = 150
+	slackMaxSectionRunes = 3000
+	slackMaxFieldRunes   = 2000
+	slackMaxFields       = 10
+	slackMaxButtonRunes  = 75
+	slackMaxButtons      = 25
+)
+
+// SlackProvider posts messages to a Slack incoming webhook
+type SlackProvider struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewSlackProvider creates a Slack incoming webhook provider
+func NewSlackProvider() *SlackProvider {
+	return &SlackProvider{
+		BaseURL: DefaultSlackBaseURL,
+		Client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// SendMessage posts the message as text with a bold first line
+func (p *SlackProvider) SendMessage(ctx context.Context, target, message string) error {
+	title, _ := splitEmailSubject(message)
+	text := "*" + escapeSlackText(title) + "*"
+	if body := robotMarkdownBody(message, "\n"); body != "" {
+		text += "\n" + escapeSlackText(body)
+	}
+	return p.post(ctx, target, map[string]interface{}{"text": text})
+}
+
+// SendRichMessage posts Block Kit blocks inside an attachment whose side bar is coloured by severity.
+// Incoming webhooks cannot upload files, so a chart is only shown when the message carries an image URL.
+func (p *SlackProvider) SendRichMessage(ctx context.Context, target string, msg RichMessage) error {
+	blocks := []interface{}{
+		map[string]interface{}{
+			"type": "header",
+			"text": slackPlainText(msg.SubjectText(), slackMaxHeaderRunes),
+		},
+	}
+	if msg.SeverityLabel != "" {
+		blocks = append(blocks, map[string]interface{}{
+			"type":     "context",
+			"elements": []interface{}{slackMarkdown("*Severity:* "+escapeSlackText(msg.SeverityLabel), slackMaxSectionRunes)},
+		})
+	}
+	if summary := msg.SummaryText(); summary != "" {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "section",
+			"text": slackMarkdown(escapeSlackText(summary), slackMaxSectionRunes),
+		})
+	}
+	// A section holds at most ten fields, so longer lists are split over several sections
+	for start := 0; start < len(msg.Fields); start += slackMaxFields {
+		end := start + slackMaxFields
+		if end > len(msg.Fields) {
+			end = len(msg.Fields)
+		}
+		fields := make([]interface{}, 0, end-start)
+		for _, field := range msg.Fields[start:end] {
+			fields = append(fields, slackMarkdown("*"+escapeSlackText(field.Name)+"*\n"+escapeSlackText(field.Value), slackMaxFieldRunes))
+		}
+		blocks = append(blocks, map[string]interface{}{"type": "section", "fields": fields})
+	}
+	if msg.Image != nil && msg.Image.URL != "" {
+		blocks = append(blocks, map[string]interface{}{
+			"type":      "image",
+			"image_url": msg.Image.URL,
+			"alt_text":  "chart",
+		})
+	}
+	if len(msg.Links) > 0 {
+		links := make([]string, 0, len(msg.Links))
+		for _, link := range msg.Links {
+			links = append(links, "<"+link.URL+"|"+escapeSlackText(link.Text)+">")
+		}
+		blocks = append(blocks, map[string]interface{}{
+			"type":     "context",
+			"elements": []interface{}{slackMarkdown(strings.Join(links, "  |  "), slackMaxSectionRunes)},
+		})
+	}
+	if len(msg.Buttons) > 0 {
+		buttons := make([]interface{}, 0, len(msg.Buttons))
+		for i, button := range msg.Buttons {
+			if i == slackMaxButtons {
+				break
+			}
+			element := map[string]interface{}{
+				"type": "button",
+				"text": slackPlainText(button.Text, slackMaxButtonRunes),
+				"url":  button.URL,
+			}
+			if i == 0 {
+				element["style"] = "primary"
+			}
+			buttons = append(buttons, element)
+		}
+		blocks = append(blocks, map[string]interface{}{"type": "actions", "elements": buttons})
+	}
+
+	return p.post(ctx, target, map[string]interface{}{
+		// Shown in notifications and by clients that cannot render blocks
+		"text": escapeSlackText(msg.SubjectText()),
+		"attachments": []interface{}{
+			map[string]interface{}{"color": msg.color(), "blocks": blocks},
+		},
+	})
+}
+
+func (p *SlackProvider) post(ctx context.Context, target string, payload map[string]interface{}) error {
+	endpoint := strings.TrimSpace(target)
+	if endpoint == "" {
+		return fmt.Errorf("slack webhook is missing in target")
+	}
+	lower := strings.ToLower(endpoint)
+	if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+		endpoint = p.BaseURL + strings.TrimPrefix(endpoint, "/")
+	}
+	// Incoming webhooks answer "ok" on success and a plain-text error code with a 4xx status otherwise
+	if _, err := postRobotJSON(ctx, p.Client, endpoint, payload); err != nil {
+		return fmt.Errorf("slack: %w", err)
+	}
+	return nil
+}
+
+func slackPlainText(text string, maxRunes int) map[string]interface{} {
+	return map[string]interface{}{"type": "plain_text", "text": truncateRunes(text, maxRunes), "emoji": true}
+}
+
+func slackMarkdown(text string, maxRunes int) map[string]interface{} {
+	return map[string]interface{}{"type": "mrkdwn", "text": truncateRunes(text, maxRunes)}
+}
+
+// escapeSlackText escapes the three characters Slack treats as control sequences
+func escapeSlackText(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:max-3]) + "..."
+}
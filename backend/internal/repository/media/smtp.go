@@ -0,0 +1,510 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// SMTP connection security modes
+const (
+	SMTPSecurityStartTLS = "starttls" // Plain connection upgraded with STARTTLS; fails if the server does not offer it
+	SMTPSecurityTLS      = "tls"      // Implicit TLS from the first byte, usually port 465
+	SMTPSecurityNone     = "none"     // No encryption; only for trusted relays
+)
+
+const smtpDefaultTimeout = 30 * time.Second
+
+// SMTPSettings holds the outgoing mail server settings
+type SMTPSettings struct {
+	Host       string
+	Port       int
+	Username   string
+	Password   string
+	From       string
+	Security   string
+	SkipVerify bool
+}
+
+// EmailAttachment is a file attached to an email
+type EmailAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// EmailMessage is a multipart email with a plain-text body, an optional HTML alternative and attachments
+type EmailMessage struct {
+	To          []string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []EmailAttachment
+}
+
+// SMTPProvider sends email through an SMTP server
+type SMTPProvider struct {
+	// Settings is read on every send so configuration changes apply without a restart
+	Settings func() SMTPSettings
+}
+
+// NewSMTPProvider creates an SMTP provider reading the "smtp" configuration section
+func NewSMTPProvider() *SMTPProvider {
+	return &SMTPProvider{Settings: SMTPSettingsFromConfig}
+}
+
+// SMTPSettingsFromConfig reads SMTP settings from the application configuration
+func SMTPSettingsFromConfig() SMTPSettings {
+	return SMTPSettings{
+		Host:       strings.TrimSpace(viper.GetString("smtp.host")),
+		Port:       viper.GetInt("smtp.port"),
+		Username:   viper.GetString("smtp.username"),
+		Password:   viper.GetString("smtp.password"),
+		From:       strings.TrimSpace(viper.GetString("smtp.from")),
+		Security:   strings.ToLower(strings.TrimSpace(viper.GetString("smtp.security"))),
+		SkipVerify: viper.GetBool("smtp.skip_verify"),
+	}
+}
+
+// SMTPEnabled reports whether email delivery is switched on in the configuration
+func SMTPEnabled() bool {
+	return viper.GetBool("smtp.enabled")
+}
+
+// SendMessage emails a notification. Target is one or more addresses separated by commas, semicolons or spaces;
+// the first line of the message becomes the subject.
+func (p *SMTPProvider) SendMessage(ctx context.Context, target, message string) error {
+	recipients := ParseEmailRecipients(target)
+	if len(recipients) == 0 {
+		return fmt.Errorf("email target is empty")
+	}
+	subject, body := splitEmailSubject(message)
+	return p.Send(ctx, EmailMessage{
+		To:      recipients,
+		Subject: subject,
+		Text:    body,
+		HTML:    "<pre style=\"font-family:inherit;white-space:pre-wrap\">" + html.EscapeString(body) + "</pre>",
+	})
+}
+
+// SendRichMessage emails a structured notification as HTML with a fields table and buttons; the plain-text
+// part carries the rendered message and a chart image is attached
+func (p *SMTPProvider) SendRichMessage(ctx context.Context, target string, msg RichMessage) error {
+	recipients := ParseEmailRecipients(target)
+	if len(recipients) == 0 {
+		return fmt.Errorf("email target is empty")
+	}
+	subject, text := splitEmailSubject(msg.PlainText())
+	if title := strings.TrimSpace(msg.Title); title != "" {
+		subject = title
+	}
+	email := EmailMessage{
+		To:      recipients,
+		Subject: subject,
+		Text:    text,
+		HTML:    richEmailHTML(msg),
+	}
+	if msg.Image != nil && len(msg.Image.Data) > 0 {
+		filename := msg.Image.Filename
+		if filename == "" {
+			filename = "chart.png"
+		}
+		email.Attachments = append(email.Attachments, EmailAttachment{
+			Filename:    filename,
+			ContentType: msg.Image.ContentType,
+			Data:        msg.Image.Data,
+		})
+	}
+	return p.Send(ctx, email)
+}
+
+// richEmailHTML lays a rich message out with inline styles, which is all most mail clients honour
+func richEmailHTML(msg RichMessage) string {
+	var b strings.Builder
+	b.WriteString(`<div style="font-family:Arial,Helvetica,sans-serif;font-size:14px;color:#303133;max-width:680px">`)
+	fmt.Fprintf(&b, `<div style="border-left:4px solid %s;padding:4px 12px;margin-bottom:12px">`, msg.color())
+	fmt.Fprintf(&b, `<h2 style="margin:0;font-size:18px">%s</h2>`, html.EscapeString(msg.SubjectText()))
+	if msg.SeverityLabel != "" {
+		fmt.Fprintf(&b, `<div style="color:%s;font-weight:bold;margin-top:4px">%s</div>`, msg.color(), html.EscapeString(msg.SeverityLabel))
+	}
+	b.WriteString(`</div>`)
+	if summary := msg.SummaryText(); summary != "" {
+		fmt.Fprintf(&b, `<p style="white-space:pre-wrap;margin:0 0 12px">%s</p>`, html.EscapeString(summary))
+	}
+	if len(msg.Fields) > 0 {
+		b.WriteString(`<table style="border-collapse:collapse;margin-bottom:12px">`)
+		for _, field := range msg.Fields {
+			fmt.Fprintf(&b, `<tr><td style="padding:4px 12px 4px 0;color:#909399;vertical-align:top;white-space:nowrap">%s</td><td style="padding:4px 0;white-space:pre-wrap">%s</td></tr>`,
+				html.EscapeString(field.Name), html.EscapeString(field.Value))
+		}
+		b.WriteString(`</table>`)
+	}
+	if len(msg.Buttons) > 0 {
+		b.WriteString(`<div style="margin-bottom:12px">`)
+		for _, button := range msg.Buttons {
+			fmt.Fprintf(&b, `<a href="%s" style="display:inline-block;padding:6px 14px;margin:0 8px 8px 0;background:%s;color:#ffffff;text-decoration:none;border-radius:4px">%s</a>`,
+				html.EscapeString(button.URL), msg.color(), html.EscapeString(button.Text))
+		}
+		b.WriteString(`</div>`)
+	}
+	if len(msg.Links) > 0 {
+		b.WriteString(`<ul style="padding-left:18px;margin:0 0 12px">`)
+		for _, link := range msg.Links {
+			fmt.Fprintf(&b, `<li><a href="%s">%s</a></li>`, html.EscapeString(link.URL), html.EscapeString(link.Text))
+		}
+		b.WriteString(`</ul>`)
+	}
+	if msg.Image != nil && len(msg.Image.Data) == 0 && msg.Image.URL != "" {
+		fmt.Fprintf(&b, `<img src="%s" alt="chart" style="max-width:100%%">`, html.EscapeString(msg.Image.URL))
+	}
+	b.WriteString(`</div>`)
+	return b.String()
+}
+
+// Send delivers an email to all of its recipients in one SMTP transaction
+func (p *SMTPProvider) Send(ctx context.Context, msg EmailMessage) error {
+	if !SMTPEnabled() {
+		return fmt.Errorf("smtp is disabled in configuration")
+	}
+	settings := p.Settings()
+	if settings.Host == "" {
+		return fmt.Errorf("smtp host is not configured")
+	}
+	if len(msg.To) == 0 {
+		return fmt.Errorf("email has no recipients")
+	}
+	from := settings.From
+	if from == "" {
+		from = settings.Username
+	}
+	fromAddr, err := mail.ParseAddress(from)
+	if err != nil {
+		return fmt.Errorf("invalid smtp from address %q: %w", from, err)
+	}
+	recipients := make([]string, 0, len(msg.To))
+	for _, to := range msg.To {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %w", to, err)
+		}
+		recipients = append(recipients, addr.Address)
+	}
+
+	data, err := buildEmail(fromAddr, recipients, msg)
+	if err != nil {
+		return err
+	}
+
+	client, err := dialSMTP(ctx, settings)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if settings.Username != "" {
+		if ok, mechanisms := client.Extension("AUTH"); ok {
+			if err := client.Auth(smtpAuth(settings, mechanisms)); err != nil {
+				return fmt.Errorf("smtp auth failed: %w", err)
+			}
+		} else {
+			return fmt.Errorf("smtp server does not support authentication")
+		}
+	}
+	if err := client.Mail(fromAddr.Address); err != nil {
+		return fmt.Errorf("smtp MAIL FROM rejected: %w", err)
+	}
+	for _, rcpt := range recipients {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("smtp recipient %s rejected: %w", rcpt, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA rejected: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		_ = w.Close()
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp server rejected email: %w", err)
+	}
+	return client.Quit()
+}
+
+// dialSMTP connects, greets and secures the session according to the security mode
+func dialSMTP(ctx context.Context, settings SMTPSettings) (*smtp.Client, error) {
+	security := settings.Security
+	if security == "" {
+		security = SMTPSecurityStartTLS
+	}
+	port := settings.Port
+	if port <= 0 {
+		port = 587
+		if security == SMTPSecurityTLS {
+			port = 465
+		}
+	}
+	addr := net.JoinHostPort(settings.Host, strconv.Itoa(port))
+	tlsConfig := &tls.Config{ServerName: settings.Host, InsecureSkipVerify: settings.SkipVerify}
+
+	dialer := &net.Dialer{Timeout: smtpDefaultTimeout}
+	var conn net.Conn
+	var err error
+	switch security {
+	case SMTPSecurityTLS:
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	case SMTPSecurityStartTLS, SMTPSecurityNone:
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	default:
+		return nil, fmt.Errorf("unsupported smtp security mode %q", settings.Security)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to smtp server %s: %w", addr, err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpDefaultTimeout)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, settings.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("smtp handshake failed: %w", err)
+	}
+	if security == SMTPSecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("smtp server does not offer STARTTLS; use security \"tls\" or \"none\"")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("smtp STARTTLS failed: %w", err)
+		}
+	}
+	return client, nil
+}
+
+// smtpAuth prefers PLAIN and falls back to LOGIN, which some servers offer exclusively
+func smtpAuth(settings SMTPSettings, mechanisms string) smtp.Auth {
+	for _, mechanism := range strings.Fields(strings.ToUpper(mechanisms)) {
+		if mechanism == "PLAIN" {
+			return smtp.PlainAuth("", settings.Username, settings.Password, settings.Host)
+		}
+	}
+	return &loginAuth{username: settings.Username, password: settings.Password}
+}
+
+// loginAuth implements the LOGIN SASL mechanism
+type loginAuth struct {
+	username string
+	password string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && server.Name != "localhost" && server.Name != "127.0.0.1" {
+		return "", nil, errors.New("refusing LOGIN authentication over an unencrypted connection")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	prompt := strings.ToLower(strings.TrimSpace(string(fromServer)))
+	switch {
+	case strings.HasPrefix(prompt, "user"):
+		return []byte(a.username), nil
+	case strings.HasPrefix(prompt, "pass"):
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected LOGIN challenge %q", fromServer)
+	}
+}
+
+// buildEmail renders the RFC 5322 message: multipart/alternative for text and HTML, wrapped in multipart/mixed with attachments
+func buildEmail(from *mail.Address, to []string, msg EmailMessage) ([]byte, error) {
+	var buf bytes.Buffer
+	headers := []string{
+		"From: " + from.String(),
+		"To: " + strings.Join(to, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"Message-ID: " + newMessageID(from.Address),
+		"MIME-Version: 1.0",
+	}
+	for _, h := range headers {
+		buf.WriteString(h + "\r\n")
+	}
+
+	bodyHeader, body, err := emailBodyPart(msg)
+	if err != nil {
+		return nil, err
+	}
+	if len(msg.Attachments) == 0 {
+		for _, key := range []string{"Content-Type", "Content-Transfer-Encoding"} {
+			buf.WriteString(key + ": " + bodyHeader.Get(key) + "\r\n")
+		}
+		buf.WriteString("\r\n")
+		buf.Write(body)
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	buf.WriteString("Content-Type: multipart/mixed; boundary=" + mixed.Boundary() + "\r\n\r\n")
+	part, err := mixed.CreatePart(bodyHeader)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(body); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range msg.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		filename := mime.QEncoding.Encode("utf-8", attachment.Filename)
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", fmt.Sprintf("%s; name=%q", contentType, filename))
+		header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		header.Set("Content-Transfer-Encoding", "base64")
+		part, err := mixed.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64Lines(part, attachment.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// emailBodyPart renders the text body, or text and HTML alternatives, returning the part's headers and content
+func emailBodyPart(msg EmailMessage) (textproto.MIMEHeader, []byte, error) {
+	header := textproto.MIMEHeader{}
+	var buf bytes.Buffer
+	if msg.HTML == "" {
+		header.Set("Content-Type", "text/plain; charset=utf-8")
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		if err := writeQuotedPrintable(&buf, msg.Text); err != nil {
+			return nil, nil, err
+		}
+		return header, buf.Bytes(), nil
+	}
+
+	alt := multipart.NewWriter(&buf)
+	header.Set("Content-Type", "multipart/alternative; boundary="+alt.Boundary())
+	header.Set("Content-Transfer-Encoding", "7bit")
+	for _, body := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		partHeader := textproto.MIMEHeader{}
+		partHeader.Set("Content-Type", body.contentType)
+		partHeader.Set("Content-Transfer-Encoding", "quoted-printable")
+		part, err := alt.CreatePart(partHeader)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := writeQuotedPrintable(part, body.content); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := alt.Close(); err != nil {
+		return nil, nil, err
+	}
+	return header, buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(dst io.Writer, content string) error {
+	w := quotedprintable.NewWriter(dst)
+	if _, err := w.Write([]byte(normalizeCRLF(content))); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// writeBase64Lines writes base64 wrapped at 76 characters as MIME requires
+func writeBase64Lines(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := w.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err := w.Write([]byte(encoded + "\r\n"))
+	return err
+}
+
+func normalizeCRLF(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", "\r\n")
+}
+
+func newMessageID(from string) string {
+	domain := "nagare.local"
+	if _, host, ok := strings.Cut(from, "@"); ok && host != "" {
+		domain = host
+	}
+	random := make([]byte, 12)
+	_, _ = rand.Read(random)
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(random), domain)
+}
+
+// splitEmailSubject uses the first non-empty line as the subject and the whole message as the body
+func splitEmailSubject(message string) (string, string) {
+	trimmed := strings.TrimSpace(message)
+	subject, _, _ := strings.Cut(trimmed, "\n")
+	subject = strings.TrimSpace(subject)
+	if subject == "" {
+		subject = "Nagare notification"
+	}
+	if runes := []rune(subject); len(runes) > 150 {
+		subject = string(runes[:147]) + "..."
+	}
+	return subject, trimmed
+}
+
+// ParseEmailRecipients splits a recipient list on commas, semicolons and whitespace
+func ParseEmailRecipients(target string) []string {
+	fields := strings.FieldsFunc(target, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+	recipients := make([]string, 0, len(fields))
+	seen := map[string]bool{}
+	for _, field := range fields {
+		key := strings.ToLower(field)
+		if field == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		recipients = append(recipients, field)
+	}
+	return recipients
+}
@@ -0,0 +1,225 @@
+package media
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// emailPart is a decoded leaf part of a parsed message
+type emailPart struct {
+	contentType string
+	filename    string
+	body        string
+}
+
+// parseEmail parses a rendered message with net/mail and mime/multipart and flattens its leaf parts
+func parseEmail(t *testing.T, raw []byte) (*mail.Message, []emailPart) {
+	t.Helper()
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("mail.ReadMessage: %v", err)
+	}
+
+	var parts []emailPart
+	var walk func(contentType, encoding, filename string, body io.Reader)
+	walk = func(contentType, encoding, filename string, body io.Reader) {
+		mediaType, params, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			t.Fatalf("invalid Content-Type %q: %v", contentType, err)
+		}
+		if strings.HasPrefix(mediaType, "multipart/") {
+			reader := multipart.NewReader(body, params["boundary"])
+			for {
+				part, err := reader.NextRawPart()
+				if err == io.EOF {
+					return
+				}
+				if err != nil {
+					t.Fatalf("multipart: %v", err)
+				}
+				walk(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part.FileName(), part)
+			}
+		}
+
+		switch strings.ToLower(encoding) {
+		case "quoted-printable":
+			body = quotedprintable.NewReader(body)
+		case "base64":
+			body = base64.NewDecoder(base64.StdEncoding, body)
+		}
+		data, err := io.ReadAll(body)
+		if err != nil {
+			t.Fatalf("decode %s part: %v", mediaType, err)
+		}
+		parts = append(parts, emailPart{contentType: mediaType, filename: filename, body: string(data)})
+	}
+	walk(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), "", msg.Body)
+	return msg, parts
+}
+
+func TestBuildEmail(t *testing.T) {
+	from := &mail.Address{Name: "Nagare", Address: "alerts@example.com"}
+	pdf := bytes.Repeat([]byte{0x25, 0x50, 0x44, 0x46, 0x00, 0xff}, 40)
+	longLine := strings.Repeat("disk usage above threshold; ", 10)
+
+	cases := []struct {
+		name      string
+		msg       EmailMessage
+		wantType  string
+		wantParts []emailPart
+	}{
+		{
+			name:      "plain text only",
+			msg:       EmailMessage{Subject: "CPU high", Text: "line one\nline two\n" + longLine},
+			wantType:  "text/plain",
+			wantParts: []emailPart{{contentType: "text/plain", body: "line one\r\nline two\r\n" + longLine}},
+		},
+		{
+			name:     "text and html alternatives",
+			msg:      EmailMessage{Subject: "CPU high", Text: "CPU at 95%", HTML: "<p>CPU at <b>95%</b></p>"},
+			wantType: "multipart/alternative",
+			wantParts: []emailPart{
+				{contentType: "text/plain", body: "CPU at 95%"},
+				{contentType: "text/html", body: "<p>CPU at <b>95%</b></p>"},
+			},
+		},
+		{
+			name: "alternatives with attachments",
+			msg: EmailMessage{Subject: "Weekly report", Text: "See attached", HTML: "<p>See attached</p>", Attachments: []EmailAttachment{
+				{Filename: "report.pdf", ContentType: "application/pdf", Data: pdf},
+				{Filename: "hosts.csv", Data: []byte("id,name\n1,web-1\n")},
+			}},
+			wantType: "multipart/mixed",
+			wantParts: []emailPart{
+				{contentType: "text/plain", body: "See attached"},
+				{contentType: "text/html", body: "<p>See attached</p>"},
+				{contentType: "application/pdf", filename: "report.pdf", body: string(pdf)},
+				{contentType: "application/octet-stream", filename: "hosts.csv", body: "id,name\n1,web-1\n"},
+			},
+		},
+		{
+			name: "text with attachment",
+			msg: EmailMessage{Subject: "Export", Text: "Attached", Attachments: []EmailAttachment{
+				{Filename: "data.json", ContentType: "application/json", Data: []byte(`{"ok":true}`)},
+			}},
+			wantType: "multipart/mixed",
+			wantParts: []emailPart{
+				{contentType: "text/plain", body: "Attached"},
+				{contentType: "application/json", filename: "data.json", body: `{"ok":true}`},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		raw, err := buildEmail(from, []string{"ops@example.com", "oncall@example.com"}, tc.msg)
+		if err != nil {
+			t.Fatalf("%s: buildEmail: %v", tc.name, err)
+		}
+		msg, parts := parseEmail(t, raw)
+
+		if got := msg.Header.Get("MIME-Version"); got != "1.0" {
+			t.Fatalf("%s: MIME-Version = %q", tc.name, got)
+		}
+		if sender, err := mail.ParseAddress(msg.Header.Get("From")); err != nil || sender.Address != "alerts@example.com" || sender.Name != "Nagare" {
+			t.Fatalf("%s: From = %q (%v)", tc.name, msg.Header.Get("From"), err)
+		}
+		recipients, err := msg.Header.AddressList("To")
+		if err != nil || len(recipients) != 2 || recipients[1].Address != "oncall@example.com" {
+			t.Fatalf("%s: To = %q (%v)", tc.name, msg.Header.Get("To"), err)
+		}
+		if _, err := msg.Header.Date(); err != nil {
+			t.Fatalf("%s: Date: %v", tc.name, err)
+		}
+		if id := msg.Header.Get("Message-ID"); !strings.HasSuffix(id, "@example.com>") {
+			t.Fatalf("%s: Message-ID = %q", tc.name, id)
+		}
+		if mediaType, _, _ := mime.ParseMediaType(msg.Header.Get("Content-Type")); mediaType != tc.wantType {
+			t.Fatalf("%s: Content-Type %s, want %s", tc.name, mediaType, tc.wantType)
+		}
+		if !reflect.DeepEqual(parts, tc.wantParts) {
+			t.Fatalf("%s: parts\n got %+v\nwant %+v", tc.name, parts, tc.wantParts)
+		}
+	}
+}
+
+func TestBuildEmailNonASCII(t *testing.T) {
+	subject := "告警: 主机 web-1 CPU 使用率过高 — Überlastung erkannt auf dem Produktionsserver"
+	raw, err := buildEmail(&mail.Address{Address: "alerts@example.com"}, []string{"ops@example.com"}, EmailMessage{
+		Subject:     subject,
+		Text:        "CPU 使用率 95%",
+		Attachments: []EmailAttachment{{Filename: "报告.txt", ContentType: "text/plain", Data: []byte("内容")}},
+	})
+	if err != nil {
+		t.Fatalf("buildEmail: %v", err)
+	}
+	for _, line := range strings.Split(string(raw), "\r\n") {
+		if strings.HasPrefix(line, "Subject:") {
+			for _, r := range line {
+				if r > 127 {
+					t.Fatalf("Subject header must be 7-bit, got %q", line)
+				}
+			}
+		}
+	}
+
+	msg, parts := parseEmail(t, raw)
+	decoder := new(mime.WordDecoder)
+	if got, err := decoder.DecodeHeader(msg.Header.Get("Subject")); err != nil || got != subject {
+		t.Fatalf("Subject decodes to %q (%v), want %q", got, err, subject)
+	}
+	if len(parts) != 2 || parts[0].body != "CPU 使用率 95%" {
+		t.Fatalf("unexpected parts: %+v", parts)
+	}
+	if name, err := decoder.DecodeHeader(parts[1].filename); err != nil || name != "报告.txt" || parts[1].body != "内容" {
+		t.Fatalf("attachment %q (%v) body %q", name, err, parts[1].body)
+	}
+}
+
+func TestParseEmailRecipients(t *testing.T) {
+	cases := []struct {
+		target string
+		want   []string
+	}{
+		{target: "", want: []string{}},
+		{target: "ops@example.com", want: []string{"ops@example.com"}},
+		{target: "a@example.com,b@example.com", want: []string{"a@example.com", "b@example.com"}},
+		{target: " a@example.com ; b@example.com\n\tc@example.com ", want: []string{"a@example.com", "b@example.com", "c@example.com"}},
+		{target: "a@example.com,,;b@example.com", want: []string{"a@example.com", "b@example.com"}},
+		{target: "Ops@Example.com, ops@example.com", want: []string{"Ops@Example.com"}},
+		{target: " , ; ", want: []string{}},
+	}
+
+	for _, tc := range cases {
+		if got := ParseEmailRecipients(tc.target); !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("ParseEmailRecipients(%q) = %q, want %q", tc.target, got, tc.want)
+		}
+	}
+}
+
+func TestSplitEmailSubject(t *testing.T) {
+	cases := []struct {
+		message     string
+		wantSubject string
+		wantBody    string
+	}{
+		{message: "CPU high\nhost web-1 at 95%", wantSubject: "CPU high", wantBody: "CPU high\nhost web-1 at 95%"},
+		{message: "\n\n  Disk full  \nmore", wantSubject: "Disk full", wantBody: "Disk full  \nmore"},
+		{message: "   ", wantSubject: "Nagare notification", wantBody: ""},
+		{message: strings.Repeat("告", 200), wantSubject: strings.Repeat("告", 147) + "...", wantBody: strings.Repeat("告", 200)},
+	}
+
+	for _, tc := range cases {
+		subject, body := splitEmailSubject(tc.message)
+		if subject != tc.wantSubject || body != tc.wantBody {
+			t.Fatalf("splitEmailSubject(%q) = (%q, %q), want (%q, %q)", tc.message, subject, body, tc.wantSubject, tc.wantBody)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// DefaultTelegramAPIBaseURL is the public Bot API endpoint
+const DefaultTelegramAPIBaseURL = "https://api.telegram.org"
+
+// Telegram update delivery modes
+const (
+	TelegramModePolling = "polling"
+	TelegramModeWebhook = "webhook"
+)
+
+const (
+	telegramMaxMessageRunes = 4000 // Bot API limit is 4096 characters after entity parsing
+	telegramRequestTimeout  = 15 * time.Second
+	telegramRetryDelay      = 5 * time.Second
+)
+
+// TelegramSettings holds the bot settings
+type TelegramSettings struct {
+	Enabled       bool
+	BotToken      string
+	APIBaseURL    string
+	Mode          string
+	WebhookURL    string
+	WebhookSecret string
+	PollTimeout   time.Duration
+}
+
+// TelegramSettingsFromConfig reads Telegram settings from the application configuration
+func TelegramSettingsFromConfig() TelegramSettings {
+	settings := TelegramSettings{
+		Enabled:       viper.GetBool("telegram.enabled"),
+		BotToken:      strings.TrimSpace(viper.GetString("telegram.bot_token")),
+		APIBaseURL:    strings.TrimRight(strings.TrimSpace(viper.GetString("telegram.api_base_url")), "/"),
+		Mode:          strings.ToLower(strings.TrimSpace(viper.GetString("telegram.mode"))),
+		WebhookURL:    strings.TrimSpace(viper.GetString("telegram.webhook_url")),
+		WebhookSecret: viper.GetString("telegram.webhook_secret"),
+		PollTimeout:   time.Duration(viper.GetInt("telegram.poll_timeout_seconds")) * time.Second,
+	}
+	if settings.APIBaseURL == "" {
+		settings.APIBaseURL = DefaultTelegramAPIBaseURL
+	}
+	if settings.Mode != TelegramModeWebhook {
+		settings.Mode = TelegramModePolling
+	}
+	if settings.PollTimeout <= 0 || settings.PollTimeout > 50*time.Second {
+		settings.PollTimeout = 30 * time.Second
+	}
+	return settings
+}
+
+// TelegramUpdate is an incoming Bot API update; only messages are handled
+type TelegramUpdate struct {
+	UpdateID int64            `json:"update_id"`
+	Message  *TelegramMessage `json:"message"`
+}
+
+// TelegramMessage is a Bot API message
+type TelegramMessage struct {
+	MessageID int64         `json:"message_id"`
+	From      *TelegramUser `json:"from"`
+	Chat      TelegramChat  `json:"chat"`
+	Text      string        `json:"text"`
+}
+
+// TelegramUser is the sender of a message
+type TelegramUser struct {
+	ID       int64  `json:"id"`
+	IsBot    bool   `json:"is_bot"`
+	Username string `json:"username"`
+}
+
+// TelegramChat is the chat a message was sent in
+type TelegramChat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"` // "private", "group", "supergroup" or "channel"
+}
+
+// IsGroup reports whether the chat is shared with other users
+func (c TelegramChat) IsGroup() bool {
+	return c.Type != "" && c.Type != "private"
+}
+
+type telegramAPIResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+	Parameters  *struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+// TelegramProvider sends messages through the Telegram Bot API
+type TelegramProvider struct {
+	// Settings is read on every call so configuration changes apply without a restart
+	Settings func() TelegramSettings
+	Client   *http.Client
+}
+
+// NewTelegramProvider creates a Telegram provider reading the "telegram" configuration section
+func NewTelegramProvider() *TelegramProvider {
+	return &TelegramProvider{
+		Settings: TelegramSettingsFromConfig,
+		Client:   &http.Client{},
+	}
+}
+
+// call invokes a Bot API method and decodes its result
+func (p *TelegramProvider) call(ctx context.Context, method string, payload interface{}, result interface{}) error {
+	settings := p.Settings()
+	if settings.BotToken == "" {
+		return fmt.Errorf("telegram bot token is not configured")
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, telegramRequestTimeout)
+		defer cancel()
+	}
+	endpoint := settings.APIBaseURL + "/bot" + settings.BotToken + "/" + method
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		// The URL carries the bot token, so keep it out of the error
+		return fmt.Errorf("telegram %s: %w", method, unwrapURLError(err))
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	var apiResp telegramAPIResponse
+	if err := json.Unmarshal(raw, &apiResp); err != nil {
+		return fmt.Errorf("telegram %s: status %d: invalid response", method, resp.StatusCode)
+	}
+	if !apiResp.OK {
+		if apiResp.Parameters != nil && apiResp.Parameters.RetryAfter > 0 {
+			return fmt.Errorf("telegram %s: %s (retry after %ds)", method, apiResp.Description, apiResp.Parameters.RetryAfter)
+		}
+		return fmt.Errorf("telegram %s: error %d: %s", method, apiResp.ErrorCode, apiResp.Description)
+	}
+	if result != nil && len(apiResp.Result) > 0 {
+		return json.Unmarshal(apiResp.Result, result)
+	}
+	return nil
+}
+
+func unwrapURLError(err error) error {
+	type urlError interface{ Unwrap() error }
+	if u, ok := err.(urlError); ok && u.Unwrap() != nil {
+		return u.Unwrap()
+	}
+	return err
+}
+
+// SendMessage sends a markdown formatted message. Target is a chat ID, an @channel name or "chat:<id>";
+// long messages are split, and a part Telegram cannot parse as markdown is resent as plain text.
+func (p *TelegramProvider) SendMessage(ctx context.Context, target, message string) error {
+	chatID := strings.TrimSpace(target)
+	chatID = strings.TrimPrefix(strings.TrimPrefix(chatID, "chat:"), "chat_id=")
+	if chatID == "" {
+		return fmt.Errorf("telegram chat id is empty")
+	}
+	for i, part := range splitTelegramMessage(strings.TrimSpace(message)) {
+		text := escapeTelegramMarkdown(part)
+		if i == 0 {
+			text = formatTelegramMarkdown(part)
+		}
+		err := p.call(ctx, "sendMessage", map[string]interface{}{
+			"chat_id":                  chatID,
+			"text":                     text,
+			"parse_mode":               "MarkdownV2",
+			"disable_web_page_preview": true,
+		}, nil)
+		if err != nil && strings.Contains(err.Error(), "can't parse entities") {
+			err = p.call(ctx, "sendMessage", map[string]interface{}{
+				"chat_id": chatID,
+				"text":    part,
+			}, nil)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetUpdates long-polls for new updates after offset
+func (p *TelegramProvider) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]TelegramUpdate, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout+telegramRequestTimeout)
+	defer cancel()
+	var updates []TelegramUpdate
+	err := p.call(ctx, "getUpdates", map[string]interface{}{
+		"offset":          offset,
+		"timeout":         int(timeout.Seconds()),
+		"allowed_updates": []string{"message"},
+	}, &updates)
+	return updates, err
+}
+
+// SetWebhook registers the URL Telegram posts updates to; secret is echoed in X-Telegram-Bot-Api-Secret-Token
+func (p *TelegramProvider) SetWebhook(ctx context.Context, url, secret string) error {
+	payload := map[string]interface{}{
+		"url":             url,
+		"allowed_updates": []string{"message"},
+	}
+	if secret != "" {
+		payload["secret_token"] = secret
+	}
+	return p.call(ctx, "setWebhook", payload, nil)
+}
+
+// DeleteWebhook removes the webhook so getUpdates can be used
+func (p *TelegramProvider) DeleteWebhook(ctx context.Context) error {
+	return p.call(ctx, "deleteWebhook", map[string]interface{}{}, nil)
+}
+
+// telegramMarkdownSpecial lists the characters MarkdownV2 requires to be escaped outside entities
+const telegramMarkdownSpecial = "_*[]()~`>#+-=|{}.!\\"
+
+func escapeTelegramMarkdown(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if strings.ContainsRune(telegramMarkdownSpecial, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// formatTelegramMarkdown renders the first line of a message in bold and escapes the rest
+func formatTelegramMarkdown(message string) string {
+	subject, rest, _ := strings.Cut(message, "\n")
+	if strings.TrimSpace(subject) == "" {
+		return escapeTelegramMarkdown(message)
+	}
+	formatted := "*" + escapeTelegramMarkdown(strings.TrimSpace(subject)) + "*"
+	if rest != "" {
+		formatted += "\n" + escapeTelegramMarkdown(rest)
+	}
+	return formatted
+}
+
+// splitTelegramMessage cuts a message into parts under the Bot API length limit, preferring line breaks
+func splitTelegramMessage(message string) []string {
+	runes := []rune(message)
+	if len(runes) <= telegramMaxMessageRunes {
+		return []string{message}
+	}
+	var parts []string
+	for len(runes) > telegramMaxMessageRunes {
+		cut := telegramMaxMessageRunes
+		for i := cut; i > telegramMaxMessageRunes/2; i-- {
+			if runes[i] == '\n' {
+				cut = i
+				break
+			}
+		}
+		parts = append(parts, strings.TrimSpace(string(runes[:cut])))
+		runes = runes[cut:]
+	}
+	if rest := strings.TrimSpace(string(runes)); rest != "" {
+		parts = append(parts, rest)
+	}
+	return parts
+}
+
+// TelegramCommandHandler processes an incoming message and returns the reply text
+type TelegramCommandHandler func(message TelegramMessage) (reply string, err error)
+
+// TelegramBot receives updates by long polling or webhook and answers them through the provider
+type TelegramBot struct {
+	Provider       *TelegramProvider
+	CommandHandler TelegramCommandHandler
+	mu             sync.Mutex
+	cancel         context.CancelFunc
+}
+
+// GlobalTelegramBot is the shared bot instance
+var GlobalTelegramBot = &TelegramBot{Provider: NewTelegramProvider()}
+
+// Start applies the current settings: polling starts a receive loop, webhook mode registers the webhook URL
+func (b *TelegramBot) Start() error {
+	b.Stop()
+	settings := b.Provider.Settings()
+	if !settings.Enabled || settings.BotToken == "" {
+		return nil
+	}
+
+	if settings.Mode == TelegramModeWebhook {
+		if settings.WebhookURL == "" {
+			return fmt.Errorf("telegram webhook mode requires webhook_url")
+		}
+		// The updates endpoint is public; the secret is the only thing proving a request came from Telegram
+		if settings.WebhookSecret == "" {
+			return fmt.Errorf("telegram webhook mode requires webhook_secret")
+		}
+		return b.Provider.SetWebhook(context.Background(), settings.WebhookURL, settings.WebhookSecret)
+	}
+
+	// getUpdates is rejected while a webhook is set
+	if err := b.Provider.DeleteWebhook(context.Background()); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	b.mu.Lock()
+	b.cancel = cancel
+	b.mu.Unlock()
+	go b.poll(ctx, settings.PollTimeout)
+	return nil
+}
+
+// Stop ends the polling loop if it is running
+func (b *TelegramBot) Stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.cancel != nil {
+		b.cancel()
+		b.cancel = nil
+	}
+}
+
+func (b *TelegramBot) poll(ctx context.Context, timeout time.Duration) {
+	log.Printf("[Telegram] Starting long polling")
+	var offset int64
+	for {
+		updates, err := b.Provider.GetUpdates(ctx, offset, timeout)
+		if ctx.Err() != nil {
+			log.Printf("[Telegram] Long polling stopped")
+			return
+		}
+		if err != nil {
+			log.Printf("[Telegram] getUpdates failed: %v, retrying in %s", err, telegramRetryDelay)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(telegramRetryDelay):
+			}
+			continue
+		}
+		for _, update := range updates {
+			if update.UpdateID >= offset {
+				offset = update.UpdateID + 1
+			}
+			go b.HandleUpdate(update)
+		}
+	}
+}
+
+// HandleUpdate runs the command handler for a message update and sends its reply to the chat
+func (b *TelegramBot) HandleUpdate(update TelegramUpdate) {
+	if update.Message == nil || strings.TrimSpace(update.Message.Text) == "" || b.CommandHandler == nil {
+		return
+	}
+	if update.Message.From != nil && update.Message.From.IsBot {
+		return
+	}
+	reply, err := b.CommandHandler(*update.Message)
+	if err != nil {
+		reply = "Command processing failed: " + err.Error()
+	}
+	if strings.TrimSpace(reply) == "" {
+		return
+	}
+	chatID := strconv.FormatInt(update.Message.Chat.ID, 10)
+	if err := b.Provider.SendMessage(context.Background(), chatID, reply); err != nil {
+		log.Printf("[Telegram] Failed to send reply to chat %s: %v", chatID, err)
+	}
+}
@@ -0,0 +1,189 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	webhookDefaultTimeout = 5 * time.Second
+	webhookMaxTimeout     = 120 * time.Second
+	webhookMaxReadBytes   = 64 << 10
+)
+
+// WebhookMethods lists the HTTP methods a webhook media may use
+var WebhookMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// WebhookOptions customizes the request a webhook sends and how its response is judged
+type WebhookOptions struct {
+	Method           string
+	Headers          map[string]string
+	AuthType         string // "", "bearer" or "basic"
+	Token            string
+	Username         string
+	Password         string
+	ContentType      string
+	Timeout          time.Duration
+	SkipTLSVerify    bool
+	CACert           string
+	SuccessStatuses  string // e.g. "200-299,302"; empty accepts any 2xx
+	ResponseContains string
+}
+
+// WebhookResponse is what the endpoint answered
+type WebhookResponse struct {
+	StatusCode int
+	Body       string
+	Duration   time.Duration
+}
+
+// Send performs one webhook request and checks the response against the success status and body assertions
+func (p *WebhookProvider) Send(ctx context.Context, target string, body []byte, opts WebhookOptions) (WebhookResponse, error) {
+	var result WebhookResponse
+	if strings.TrimSpace(target) == "" {
+		return result, fmt.Errorf("webhook target is empty")
+	}
+	method := strings.ToUpper(strings.TrimSpace(opts.Method))
+	if method == "" {
+		method = http.MethodPost
+	}
+	statuses, err := ParseWebhookStatuses(opts.SuccessStatuses)
+	if err != nil {
+		return result, err
+	}
+	client, err := p.clientFor(opts)
+	if err != nil {
+		return result, err
+	}
+	if client != p.Client {
+		defer client.CloseIdleConnections()
+	}
+
+	var reader io.Reader
+	if method != http.MethodGet && len(body) > 0 {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSpace(target), reader)
+	if err != nil {
+		return result, err
+	}
+	if reader != nil {
+		contentType := strings.TrimSpace(opts.ContentType)
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		req.Header.Set("Content-Type", contentType)
+	}
+	for key, value := range opts.Headers {
+		if strings.TrimSpace(key) != "" {
+			req.Header.Set(key, value)
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(opts.AuthType)) {
+	case "bearer":
+		req.Header.Set("Authorization", "Bearer "+opts.Token)
+	case "basic":
+		req.SetBasicAuth(opts.Username, opts.Password)
+	}
+
+	started := time.Now()
+	resp, err := client.Do(req)
+	result.Duration = time.Since(started)
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxReadBytes))
+	result.StatusCode = resp.StatusCode
+	result.Body = string(raw)
+
+	if !webhookStatusAccepted(resp.StatusCode, statuses) {
+		return result, fmt.Errorf("webhook status %d", resp.StatusCode)
+	}
+	if opts.ResponseContains != "" && !strings.Contains(result.Body, opts.ResponseContains) {
+		return result, fmt.Errorf("webhook response does not contain %q", opts.ResponseContains)
+	}
+	return result, nil
+}
+
+// clientFor returns the shared client, or a dedicated one when the options change the timeout or TLS settings
+func (p *WebhookProvider) clientFor(opts WebhookOptions) (*http.Client, error) {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = webhookDefaultTimeout
+	}
+	if timeout > webhookMaxTimeout {
+		timeout = webhookMaxTimeout
+	}
+	if !opts.SkipTLSVerify && strings.TrimSpace(opts.CACert) == "" && timeout == p.Client.Timeout {
+		return p.Client, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.SkipTLSVerify || strings.TrimSpace(opts.CACert) != "" {
+		tlsConfig := &tls.Config{InsecureSkipVerify: opts.SkipTLSVerify}
+		if strings.TrimSpace(opts.CACert) != "" {
+			pool, err := x509.SystemCertPool()
+			if err != nil || pool == nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM([]byte(opts.CACert)) {
+				return nil, fmt.Errorf("webhook ca_cert contains no valid PEM certificate")
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
+}
+
+// ParseWebhookStatuses parses a list like "200-299,302" into inclusive ranges; empty means any 2xx
+func ParseWebhookStatuses(spec string) ([][2]int, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return [][2]int{{200, 299}}, nil
+	}
+	var ranges [][2]int
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lowText, highText, isRange := strings.Cut(part, "-")
+		low, err := strconv.Atoi(strings.TrimSpace(lowText))
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook success status %q", part)
+		}
+		high := low
+		if isRange {
+			if high, err = strconv.Atoi(strings.TrimSpace(highText)); err != nil {
+				return nil, fmt.Errorf("invalid webhook success status %q", part)
+			}
+		}
+		if low < 100 || high > 599 || low > high {
+			return nil, fmt.Errorf("invalid webhook success status %q", part)
+		}
+		ranges = append(ranges, [2]int{low, high})
+	}
+	if len(ranges) == 0 {
+		return [][2]int{{200, 299}}, nil
+	}
+	return ranges, nil
+}
+
+func webhookStatusAccepted(status int, ranges [][2]int) bool {
+	for _, r := range ranges {
+		if status >= r[0] && status <= r[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package media
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseWebhookStatuses(t *testing.T) {
+	cases := []struct {
+		spec    string
+		want    [][2]int
+		wantErr bool
+	}{
+		{spec: "", want: [][2]int{{200, 299}}},
+		{spec: "  ", want: [][2]int{{200, 299}}},
+		{spec: ",,", want: [][2]int{{200, 299}}},
+		{spec: "200", want: [][2]int{{200, 200}}},
+		{spec: "200-299,302", want: [][2]int{{200, 299}, {302, 302}}},
+		{spec: " 201 - 204 , 409 ", want: [][2]int{{201, 204}, {409, 409}}},
+		{spec: "100-599", want: [][2]int{{100, 599}}},
+		{spec: "abc", wantErr: true},
+		{spec: "200-", wantErr: true},
+		{spec: "299-200", wantErr: true},
+		{spec: "99", wantErr: true},
+		{spec: "200-600", wantErr: true},
+		{spec: "200,x", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		got, err := ParseWebhookStatuses(tc.spec)
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("ParseWebhookStatuses(%q) expected error, got %v", tc.spec, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ParseWebhookStatuses(%q) unexpected error: %v", tc.spec, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("ParseWebhookStatuses(%q) = %v, want %v", tc.spec, got, tc.want)
+		}
+	}
+}
+
+func TestWebhookStatusAccepted(t *testing.T) {
+	ranges := [][2]int{{200, 299}, {302, 302}}
+
+	cases := []struct {
+		status int
+		want   bool
+	}{
+		{status: 200, want: true},
+		{status: 299, want: true},
+		{status: 302, want: true},
+		{status: 301, want: false},
+		{status: 500, want: false},
+	}
+
+	for _, tc := range cases {
+		if got := webhookStatusAccepted(tc.status, ranges); got != tc.want {
+			t.Fatalf("webhookStatusAccepted(%d) = %v, want %v", tc.status, got, tc.want)
+		}
+	}
+}
@@ -11,18 +11,24 @@ import (
 type MonitorType int
 
 const (
+	MonitorSNMP   MonitorType = 1 // 1 = snmp (built-in poller, reserved for Nagare Internal)
 	MonitorZabbix MonitorType = 2 // 2 = zabbix
 	MonitorOther  MonitorType = 3 // 3 = other
+	// 4 is skipped: legacy rows with type 4 are remapped by the startup migration
+	MonitorPrometheus MonitorType = 5 // 5 = prometheus
 )
 
 // String returns the string representation of the monitor type
 func (m MonitorType) String() string {
 	switch m {
-
+	case MonitorSNMP:
+		return "snmp"
 	case MonitorZabbix:
 		return "zabbix"
 	case MonitorOther:
 		return "other"
+	case MonitorPrometheus:
+		return "prometheus"
 	default:
 		return "unknown"
 	}
@@ -31,11 +37,14 @@ func (m MonitorType) String() string {
 // ParseMonitorType parses an integer to MonitorType
 func ParseMonitorType(s int) MonitorType {
 	switch s {
-
+	case 1:
+		return MonitorSNMP
 	case 2:
 		return MonitorZabbix
 	case 3:
 		return MonitorOther
+	case 5:
+		return MonitorPrometheus
 	default:
 		return MonitorZabbix
 	}
@@ -169,9 +178,15 @@ func NewClient(cfg Config) (*Client, error) {
 	var err error
 
 	switch cfg.Type {
+	case MonitorSNMP:
+		provider, err = NewSNMPProvider(cfg)
+
 	case MonitorZabbix:
 		provider, err = NewZabbixProvider(cfg)
 
+	case MonitorPrometheus:
+		provider, err = NewPrometheusProvider(cfg)
+
 	case MonitorOther:
 		provider, err = NewGenericProvider(cfg)
 	default:
@@ -360,6 +375,11 @@ func (c *Client) ProviderName() string {
 	return c.provider.Name()
 }
 
+// Provider returns the underlying provider for provider-specific configuration
+func (c *Client) Provider() Provider {
+	return c.provider
+}
+
 // ProviderType returns the type of the current provider
 func (c *Client) ProviderType() MonitorType {
 	return c.provider.Type()
@@ -1,5 +1,812 @@
 package monitors
 
-// DEPRECATED: Prometheus support has been removed from Nagare.
-// Use MonitorOther (type 3) for generic monitoring providers including Prometheus if needed.
-// This file is kept for reference only and is not functional.
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPrometheusItemIDLength matches the width of the items.external_id column.
+// Series whose selector does not fit are skipped instead of being truncated.
+const maxPrometheusItemIDLength = 255
+
+// maxPrometheusHistoryPoints mirrors the history.get limit used by the Zabbix provider
+const maxPrometheusHistoryPoints = 1000
+
+var errPrometheusReadOnly = errors.New("prometheus provider is read-only: targets and series are managed by Prometheus")
+
+// PrometheusProvider implements the Provider interface for Prometheus.
+// Scrape targets are exposed as hosts, jobs as host groups and series as items.
+type PrometheusProvider struct {
+	name     string
+	url      string
+	username string
+	password string
+	token    string
+	client   *http.Client
+}
+
+type prometheusResponse struct {
+	Status    string          `json:"status"`
+	Data      json.RawMessage `json:"data"`
+	ErrorType string          `json:"errorType"`
+	Error     string          `json:"error"`
+}
+
+type prometheusTarget struct {
+	Labels             map[string]string `json:"labels"`
+	ScrapePool         string            `json:"scrapePool"`
+	ScrapeURL          string            `json:"scrapeUrl"`
+	LastError          string            `json:"lastError"`
+	LastScrape         string            `json:"lastScrape"`
+	Health             string            `json:"health"`
+	ScrapeInterval     string            `json:"scrapeInterval"`
+	DiscoveredLabelSet map[string]string `json:"discoveredLabels"`
+}
+
+type prometheusSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  [2]interface{}    `json:"value"`
+}
+
+type prometheusRangeSeries struct {
+	Metric map[string]string `json:"metric"`
+	Values [][2]interface{}  `json:"values"`
+}
+
+type prometheusAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	State       string            `json:"state"`
+	ActiveAt    string            `json:"activeAt"`
+	Value       string            `json:"value"`
+}
+
+type prometheusRuleGroup struct {
+	Name  string `json:"name"`
+	File  string `json:"file"`
+	Rules []struct {
+		Name        string            `json:"name"`
+		Query       string            `json:"query"`
+		Labels      map[string]string `json:"labels"`
+		Annotations map[string]string `json:"annotations"`
+		State       string            `json:"state"`
+		Health      string            `json:"health"`
+		Type        string            `json:"type"`
+		Alerts      []prometheusAlert `json:"alerts"`
+	} `json:"rules"`
+}
+
+// NewPrometheusProvider creates a new Prometheus provider
+func NewPrometheusProvider(cfg Config) (*PrometheusProvider, error) {
+	if cfg.Auth.URL == "" {
+		return nil, fmt.Errorf("URL is required for Prometheus provider")
+	}
+
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = 30
+	}
+
+	baseURL := strings.TrimSpace(cfg.Auth.URL)
+	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
+		baseURL = "http://" + baseURL
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	baseURL = strings.TrimSuffix(baseURL, "/api/v1")
+
+	return &PrometheusProvider{
+		name:     cfg.Name,
+		url:      baseURL,
+		username: cfg.Auth.Username,
+		password: cfg.Auth.Password,
+		token:    cfg.Auth.Token,
+		client:   &http.Client{Timeout: time.Duration(timeout) * time.Second},
+	}, nil
+}
+
+// get performs a GET request against the Prometheus HTTP API and returns the data field
+func (p *PrometheusProvider) get(ctx context.Context, path string, query url.Values) (json.RawMessage, error) {
+	endpoint := p.url + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	} else if p.username != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var promResp prometheusResponse
+	if err := json.Unmarshal(body, &promResp); err != nil {
+		snippet := string(body)
+		if len(snippet) > 100 {
+			snippet = snippet[:100] + "..."
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("prometheus API request failed (status %d): %s", resp.StatusCode, snippet)
+		}
+		return nil, fmt.Errorf("failed to unmarshal response: %w (body snippet: %s)", err, snippet)
+	}
+
+	if promResp.Status != "success" {
+		return nil, fmt.Errorf("prometheus API error (status %d): %s: %s", resp.StatusCode, promResp.ErrorType, promResp.Error)
+	}
+
+	return promResp.Data, nil
+}
+
+// Authenticate implements the Provider interface.
+// Prometheus has no session concept, so a trivial query is used to validate URL and credentials.
+func (p *PrometheusProvider) Authenticate(ctx context.Context) error {
+	if _, err := p.get(ctx, "/api/v1/query", url.Values{"query": {"time()"}}); err != nil {
+		return fmt.Errorf("prometheus authentication failed: %w", err)
+	}
+	return nil
+}
+
+// GetAuthToken implements the Provider interface
+func (p *PrometheusProvider) GetAuthToken() string {
+	return p.token
+}
+
+// SetAuthToken implements the Provider interface
+func (p *PrometheusProvider) SetAuthToken(token string) {
+	p.token = token
+}
+
+func (p *PrometheusProvider) getActiveTargets(ctx context.Context) ([]prometheusTarget, error) {
+	data, err := p.get(ctx, "/api/v1/targets", url.Values{"state": {"active"}})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		ActiveTargets []prometheusTarget `json:"activeTargets"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse targets: %w", err)
+	}
+	return result.ActiveTargets, nil
+}
+
+// targetsToHosts merges targets by instance label; every job scraping an instance becomes one of its groups
+func targetsToHosts(targets []prometheusTarget) []Host {
+	type hostAcc struct {
+		host   Host
+		jobs   []string
+		up     int
+		down   int
+		errors []string
+	}
+
+	order := make([]string, 0)
+	byInstance := make(map[string]*hostAcc)
+	for _, t := range targets {
+		instance := strings.TrimSpace(t.Labels["instance"])
+		if instance == "" {
+			instance = t.ScrapeURL
+		}
+		if instance == "" {
+			continue
+		}
+		job := t.Labels["job"]
+		if job == "" {
+			job = t.ScrapePool
+		}
+
+		acc, ok := byInstance[instance]
+		if !ok {
+			acc = &hostAcc{host: Host{
+				ID:        instance,
+				Name:      instance,
+				Enabled:   1,
+				IPAddress: prometheusInstanceIP(instance),
+				Metadata: map[string]string{
+					"host":       instance,
+					"scrape_url": t.ScrapeURL,
+				},
+			}}
+			byInstance[instance] = acc
+			order = append(order, instance)
+		}
+		if job != "" && !containsString(acc.jobs, job) {
+			acc.jobs = append(acc.jobs, job)
+		}
+		switch t.Health {
+		case "up":
+			acc.up++
+		case "down":
+			acc.down++
+			if t.LastError != "" {
+				acc.errors = append(acc.errors, fmt.Sprintf("%s: %s", job, t.LastError))
+			}
+		}
+	}
+
+	hosts := make([]Host, 0, len(order))
+	for _, instance := range order {
+		acc := byInstance[instance]
+		host := acc.host
+
+		// Zabbix-compatible availability: 1 = available, 2 = unavailable, 0 = unknown
+		switch {
+		case acc.down > 0 && acc.up == 0:
+			host.Status = "down"
+			host.Metadata["active_available"] = "2"
+		case acc.up > 0:
+			host.Status = "up"
+			host.Metadata["active_available"] = "1"
+		default:
+			host.Status = "unknown"
+			host.Metadata["active_available"] = "0"
+		}
+		if len(acc.errors) > 0 {
+			host.Metadata["status_description"] = truncateRunes(strings.Join(acc.errors, "; "), 512)
+		}
+
+		if len(acc.jobs) > 0 {
+			host.Description = "Prometheus jobs: " + strings.Join(acc.jobs, ", ")
+			host.Metadata["groupid"] = acc.jobs[0]
+			host.Metadata["groupname"] = acc.jobs[0]
+			host.Metadata["groupids"] = strings.Join(acc.jobs, ",")
+			host.Metadata["groupnames"] = strings.Join(acc.jobs, ",")
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// prometheusInstanceIP extracts the IP address from an instance label, if it holds one
+func prometheusInstanceIP(instance string) string {
+	hostPart := instance
+	if h, _, err := net.SplitHostPort(instance); err == nil {
+		hostPart = h
+	}
+	if ip := net.ParseIP(strings.Trim(hostPart, "[]")); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// GetHosts implements the Provider interface
+func (p *PrometheusProvider) GetHosts(ctx context.Context) ([]Host, error) {
+	targets, err := p.getActiveTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return targetsToHosts(targets), nil
+}
+
+// GetHostsByGroupID implements the Provider interface. Group IDs are job names.
+func (p *PrometheusProvider) GetHostsByGroupID(ctx context.Context, groupID string) ([]Host, error) {
+	hosts, err := p.GetHosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]Host, 0)
+	for _, h := range hosts {
+		for _, job := range strings.Split(h.Metadata["groupids"], ",") {
+			if job == groupID {
+				filtered = append(filtered, h)
+				break
+			}
+		}
+	}
+	return filtered, nil
+}
+
+// GetHostByName implements the Provider interface
+func (p *PrometheusProvider) GetHostByName(ctx context.Context, name string) (*Host, error) {
+	hosts, err := p.GetHosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range hosts {
+		if h.Name == name {
+			host := h
+			return &host, nil
+		}
+	}
+	return nil, fmt.Errorf("host not found: %s", name)
+}
+
+// GetHostByID implements the Provider interface. Host IDs are instance labels.
+func (p *PrometheusProvider) GetHostByID(ctx context.Context, hostID string) (*Host, error) {
+	hosts, err := p.GetHosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range hosts {
+		if h.ID == hostID {
+			host := h
+			return &host, nil
+		}
+	}
+	return nil, fmt.Errorf("host not found: %s", hostID)
+}
+
+// CreateHost implements the Provider interface
+func (p *PrometheusProvider) CreateHost(ctx context.Context, host Host) (Host, error) {
+	return Host{}, errPrometheusReadOnly
+}
+
+// UpdateHost implements the Provider interface
+func (p *PrometheusProvider) UpdateHost(ctx context.Context, host Host) (Host, error) {
+	return Host{}, errPrometheusReadOnly
+}
+
+// DeleteHost implements the Provider interface
+func (p *PrometheusProvider) DeleteHost(ctx context.Context, hostID string) error {
+	return errPrometheusReadOnly
+}
+
+// GetItems implements the Provider interface. Every current series of the instance becomes an item.
+func (p *PrometheusProvider) GetItems(ctx context.Context, hostID string) ([]Item, error) {
+	if hostID == "" {
+		return nil, fmt.Errorf("host ID is required")
+	}
+	samples, err := p.instantQuery(ctx, fmt.Sprintf(`{instance=%s}`, strconv.Quote(hostID)))
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(samples))
+	for _, s := range samples {
+		name := s.Metric["__name__"]
+		// Histogram buckets are not meaningful as standalone items and would flood the item list
+		if name == "" || strings.HasSuffix(name, "_bucket") {
+			continue
+		}
+		item, ok := sampleToItem(s, hostID)
+		if !ok {
+			continue
+		}
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items, nil
+}
+
+// GetItemByID implements the Provider interface. Item IDs are full series selectors.
+func (p *PrometheusProvider) GetItemByID(ctx context.Context, itemID string) (*Item, error) {
+	samples, err := p.instantQuery(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("item not found: %s", itemID)
+	}
+	item, ok := sampleToItem(samples[0], samples[0].Metric["instance"])
+	if !ok {
+		return nil, fmt.Errorf("item not found: %s", itemID)
+	}
+	item.ID = itemID
+	return &item, nil
+}
+
+// GetItemHistory implements the Provider interface using query_range
+func (p *PrometheusProvider) GetItemHistory(ctx context.Context, itemID string, from, to int64) ([]Item, error) {
+	if to <= from {
+		return nil, fmt.Errorf("invalid time range: from=%d to=%d", from, to)
+	}
+	step := int64(math.Ceil(float64(to-from) / maxPrometheusHistoryPoints))
+	if step < 15 {
+		step = 15
+	}
+
+	data, err := p.get(ctx, "/api/v1/query_range", url.Values{
+		"query": {itemID},
+		"start": {strconv.FormatInt(from, 10)},
+		"end":   {strconv.FormatInt(to, 10)},
+		"step":  {strconv.FormatInt(step, 10)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		ResultType string                  `json:"resultType"`
+		Result     []prometheusRangeSeries `json:"result"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse history: %w", err)
+	}
+	if len(result.Result) == 0 {
+		return []Item{}, nil
+	}
+
+	series := result.Result[0]
+	name := prometheusItemName(series.Metric)
+	units := prometheusUnits(series.Metric["__name__"])
+	items := make([]Item, 0, len(series.Values))
+	// Newest first, matching the Zabbix provider's history ordering
+	for i := len(series.Values) - 1; i >= 0; i-- {
+		ts, value := parsePrometheusValue(series.Values[i])
+		items = append(items, Item{
+			ID:        itemID,
+			HostID:    series.Metric["instance"],
+			Name:      name,
+			Value:     value,
+			Units:     units,
+			Timestamp: ts,
+		})
+	}
+	return items, nil
+}
+
+// CreateItem implements the Provider interface
+func (p *PrometheusProvider) CreateItem(ctx context.Context, item Item) (Item, error) {
+	return Item{}, errPrometheusReadOnly
+}
+
+// UpdateItem implements the Provider interface
+func (p *PrometheusProvider) UpdateItem(ctx context.Context, item Item) (Item, error) {
+	return Item{}, errPrometheusReadOnly
+}
+
+// DeleteItem implements the Provider interface
+func (p *PrometheusProvider) DeleteItem(ctx context.Context, itemID string) error {
+	return errPrometheusReadOnly
+}
+
+func (p *PrometheusProvider) instantQuery(ctx context.Context, query string) ([]prometheusSample, error) {
+	data, err := p.get(ctx, "/api/v1/query", url.Values{"query": {query}})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		ResultType string             `json:"resultType"`
+		Result     []prometheusSample `json:"result"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse query result: %w", err)
+	}
+	if result.ResultType != "vector" {
+		return nil, fmt.Errorf("unexpected query result type: %s", result.ResultType)
+	}
+	return result.Result, nil
+}
+
+func sampleToItem(s prometheusSample, hostID string) (Item, bool) {
+	selector := prometheusSelector(s.Metric, nil)
+	if len(selector) > maxPrometheusItemIDLength {
+		return Item{}, false
+	}
+	metricName := s.Metric["__name__"]
+	ts, value := parsePrometheusValue(s.Value)
+	return Item{
+		ID:        selector,
+		HostID:    hostID,
+		Name:      prometheusItemName(s.Metric),
+		Key:       metricName,
+		Type:      "prometheus",
+		Value:     value,
+		Units:     prometheusUnits(metricName),
+		ValueType: "0", // numeric float, same code Zabbix uses
+		Status:    "0",
+		Timestamp: ts,
+		Metadata:  s.Metric,
+	}, true
+}
+
+// prometheusSelector renders a metric as an exact series selector: name{label="value",...}
+func prometheusSelector(metric map[string]string, skip map[string]bool) string {
+	keys := make([]string, 0, len(metric))
+	for k := range metric {
+		if k == "__name__" || skip[k] {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+strconv.Quote(metric[k]))
+	}
+	if len(parts) == 0 {
+		return metric["__name__"]
+	}
+	return metric["__name__"] + "{" + strings.Join(parts, ",") + "}"
+}
+
+// prometheusItemName drops the labels already implied by the host
+func prometheusItemName(metric map[string]string) string {
+	return prometheusSelector(metric, map[string]bool{"instance": true, "job": true})
+}
+
+func prometheusUnits(metricName string) string {
+	switch {
+	case strings.HasSuffix(metricName, "_bytes"), strings.HasSuffix(metricName, "_bytes_total"):
+		return "B"
+	case strings.HasSuffix(metricName, "_seconds"), strings.HasSuffix(metricName, "_seconds_total"):
+		return "s"
+	case strings.HasSuffix(metricName, "_celsius"):
+		return "°C"
+	case strings.HasSuffix(metricName, "_percent"):
+		return "%"
+	case strings.HasSuffix(metricName, "_bits_per_second"), strings.HasSuffix(metricName, "_bps"):
+		return "bps"
+	default:
+		return ""
+	}
+}
+
+// parsePrometheusValue decodes a [<unix_time>, "<value>"] pair
+func parsePrometheusValue(pair [2]interface{}) (int64, string) {
+	var ts int64
+	if f, ok := pair[0].(float64); ok {
+		ts = int64(f)
+	}
+	value, _ := pair[1].(string)
+	return ts, value
+}
+
+// GetAlerts implements the Provider interface using /api/v1/alerts. Pending alerts are skipped.
+func (p *PrometheusProvider) GetAlerts(ctx context.Context) ([]Alert, error) {
+	data, err := p.get(ctx, "/api/v1/alerts", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Alerts []prometheusAlert `json:"alerts"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse alerts: %w", err)
+	}
+
+	alerts := make([]Alert, 0, len(result.Alerts))
+	for _, a := range result.Alerts {
+		if a.State != "firing" {
+			continue
+		}
+		alerts = append(alerts, prometheusAlertToAlert(a))
+	}
+	return alerts, nil
+}
+
+// GetAlertsByHost implements the Provider interface
+func (p *PrometheusProvider) GetAlertsByHost(ctx context.Context, hostID string) ([]Alert, error) {
+	alerts, err := p.GetAlerts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]Alert, 0)
+	for _, a := range alerts {
+		if a.HostID == hostID {
+			filtered = append(filtered, a)
+		}
+	}
+	return filtered, nil
+}
+
+func prometheusAlertToAlert(a prometheusAlert) Alert {
+	name := a.Labels["alertname"]
+	if summary := strings.TrimSpace(a.Annotations["summary"]); summary != "" {
+		name = name + ": " + summary
+	}
+
+	var timestamp int64
+	if activeAt, err := time.Parse(time.RFC3339Nano, a.ActiveAt); err == nil {
+		timestamp = activeAt.Unix()
+	}
+
+	return Alert{
+		ID:          prometheusSelector(a.Labels, nil),
+		HostID:      a.Labels["instance"],
+		Name:        name,
+		Severity:    mapPrometheusSeverity(a.Labels["severity"]),
+		Status:      "problem",
+		Description: a.Annotations["description"],
+		Timestamp:   timestamp,
+	}
+}
+
+// mapPrometheusSeverity maps the conventional severity label onto Zabbix severity names
+func mapPrometheusSeverity(label string) string {
+	switch strings.ToLower(strings.TrimSpace(label)) {
+	case "critical", "disaster", "page", "emergency":
+		return "disaster"
+	case "high", "error", "major":
+		return "high"
+	case "average", "minor":
+		return "average"
+	case "warning", "warn":
+		return "warning"
+	default:
+		return "information"
+	}
+}
+
+func (p *PrometheusProvider) getAlertingRules(ctx context.Context) ([]prometheusRuleGroup, error) {
+	data, err := p.get(ctx, "/api/v1/rules", url.Values{"type": {"alert"}})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Groups []prometheusRuleGroup `json:"groups"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+	return result.Groups, nil
+}
+
+// GetTriggers implements the Provider interface. Alerting rules are exposed as triggers.
+func (p *PrometheusProvider) GetTriggers(ctx context.Context) ([]Trigger, error) {
+	return p.getTriggers(ctx, "")
+}
+
+// GetTriggersByHost implements the Provider interface.
+// Rules are not bound to hosts, so only rules with an active alert for the instance are returned.
+func (p *PrometheusProvider) GetTriggersByHost(ctx context.Context, hostID string) ([]Trigger, error) {
+	return p.getTriggers(ctx, hostID)
+}
+
+func (p *PrometheusProvider) getTriggers(ctx context.Context, hostID string) ([]Trigger, error) {
+	groups, err := p.getAlertingRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	triggers := make([]Trigger, 0)
+	for _, g := range groups {
+		for _, r := range g.Rules {
+			if r.Type != "" && r.Type != "alerting" {
+				continue
+			}
+			if hostID != "" {
+				matched := false
+				for _, a := range r.Alerts {
+					if a.Labels["instance"] == hostID {
+						matched = true
+						break
+					}
+				}
+				if !matched {
+					continue
+				}
+			}
+			triggers = append(triggers, Trigger{
+				ID:          g.Name + "/" + r.Name,
+				Name:        r.Name,
+				Expression:  r.Query,
+				Priority:    mapPrometheusSeverity(r.Labels["severity"]),
+				Status:      r.State,
+				Description: r.Annotations["summary"],
+			})
+		}
+	}
+	return triggers, nil
+}
+
+// GetTemplateidByName implements the Provider interface. Prometheus has no templates.
+func (p *PrometheusProvider) GetTemplateidByName(ctx context.Context, name string) ([]string, error) {
+	return []string{}, nil
+}
+
+// GetHostGroups implements the Provider interface. Host groups are scrape jobs.
+func (p *PrometheusProvider) GetHostGroups(ctx context.Context) ([]string, error) {
+	details, err := p.GetHostGroupsDetails(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(details))
+	for _, d := range details {
+		names = append(names, d.Name)
+	}
+	return names, nil
+}
+
+// GetHostGroupsDetails implements the Provider interface
+func (p *PrometheusProvider) GetHostGroupsDetails(ctx context.Context) ([]struct{ ID, Name string }, error) {
+	targets, err := p.getActiveTargets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get host groups: %w", err)
+	}
+
+	jobs := make([]string, 0)
+	for _, t := range targets {
+		job := t.Labels["job"]
+		if job == "" {
+			job = t.ScrapePool
+		}
+		if job != "" && !containsString(jobs, job) {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Strings(jobs)
+
+	result := make([]struct{ ID, Name string }, 0, len(jobs))
+	for _, job := range jobs {
+		result = append(result, struct{ ID, Name string }{ID: job, Name: job})
+	}
+	return result, nil
+}
+
+// GetHostGroupByName implements the Provider interface
+func (p *PrometheusProvider) GetHostGroupByName(ctx context.Context, name string) (string, error) {
+	groups, err := p.GetHostGroups(ctx)
+	if err != nil {
+		return "", err
+	}
+	if !containsString(groups, name) {
+		return "", fmt.Errorf("host group not found: %s", name)
+	}
+	return name, nil
+}
+
+// CreateHostGroup implements the Provider interface
+func (p *PrometheusProvider) CreateHostGroup(ctx context.Context, name string) (string, error) {
+	return "", errPrometheusReadOnly
+}
+
+// UpdateHostGroup implements the Provider interface
+func (p *PrometheusProvider) UpdateHostGroup(ctx context.Context, id, name string) error {
+	return errPrometheusReadOnly
+}
+
+// DeleteHostGroup implements the Provider interface
+func (p *PrometheusProvider) DeleteHostGroup(ctx context.Context, id string) error {
+	return errPrometheusReadOnly
+}
+
+// Name implements the Provider interface
+func (p *PrometheusProvider) Name() string {
+	return p.name
+}
+
+// Type implements the Provider interface
+func (p *PrometheusProvider) Type() MonitorType {
+	return MonitorPrometheus
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateRunes cuts s to at most limit characters without splitting a multi-byte rune
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit])
+}
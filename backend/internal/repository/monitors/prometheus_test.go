@@ -0,0 +1,181 @@
+package monitors
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTargetsToHosts(t *testing.T) {
+	targets := []prometheusTarget{
+		{Labels: map[string]string{"instance": "10.0.0.1:9100", "job": "node"}, Health: "up", ScrapeURL: "http://10.0.0.1:9100/metrics"},
+		{Labels: map[string]string{"instance": "10.0.0.1:9100", "job": "blackbox"}, Health: "down", LastError: "timeout"},
+		{Labels: map[string]string{"instance": "db.internal:9187", "job": "postgres"}, Health: "down", LastError: "connection refused"},
+		{Labels: map[string]string{"job": "pushgateway"}, ScrapePool: "pushgateway", ScrapeURL: "http://push:9091/metrics", Health: "unknown"},
+		{Labels: map[string]string{"instance": "10.0.0.1:9100", "job": "node"}, Health: "up"},
+		{Labels: map[string]string{}},
+	}
+
+	hosts := targetsToHosts(targets)
+	if len(hosts) != 3 {
+		t.Fatalf("got %d hosts, want 3", len(hosts))
+	}
+
+	cases := []struct {
+		id         string
+		status     string
+		available  string
+		ip         string
+		groupIDs   string
+		statusDesc string
+	}{
+		{id: "10.0.0.1:9100", status: "up", available: "1", ip: "10.0.0.1", groupIDs: "node,blackbox", statusDesc: "blackbox: timeout"},
+		{id: "db.internal:9187", status: "down", available: "2", ip: "", groupIDs: "postgres", statusDesc: "postgres: connection refused"},
+		{id: "http://push:9091/metrics", status: "unknown", available: "0", ip: "", groupIDs: "pushgateway"},
+	}
+
+	for i, tc := range cases {
+		host := hosts[i]
+		if host.ID != tc.id || host.Status != tc.status || host.IPAddress != tc.ip {
+			t.Fatalf("host %d: got id=%q status=%q ip=%q, want id=%q status=%q ip=%q", i, host.ID, host.Status, host.IPAddress, tc.id, tc.status, tc.ip)
+		}
+		if host.Metadata["active_available"] != tc.available || host.Metadata["groupids"] != tc.groupIDs || host.Metadata["status_description"] != tc.statusDesc {
+			t.Fatalf("host %s: unexpected metadata %v", tc.id, host.Metadata)
+		}
+	}
+}
+
+func TestTargetsToHostsTruncatesStatusByRune(t *testing.T) {
+	targets := []prometheusTarget{{Labels: map[string]string{"instance": "h:1", "job": "j"}, Health: "down", LastError: strings.Repeat("错", 600)}}
+	desc := targetsToHosts(targets)[0].Metadata["status_description"]
+	if !utf8.ValidString(desc) || utf8.RuneCountInString(desc) != 512 {
+		t.Fatalf("status description should be cut to 512 whole runes, got %d runes (valid %v)", utf8.RuneCountInString(desc), utf8.ValidString(desc))
+	}
+}
+
+func TestPrometheusSelector(t *testing.T) {
+	cases := []struct {
+		name   string
+		metric map[string]string
+		skip   map[string]bool
+		want   string
+	}{
+		{name: "name only", metric: map[string]string{"__name__": "up"}, want: "up"},
+		{name: "labels sorted", metric: map[string]string{"__name__": "up", "job": "node", "instance": "a:1"}, want: `up{instance="a:1",job="node"}`},
+		{name: "quotes and backslashes escaped", metric: map[string]string{"__name__": "m", "path": `C:\tmp "x"`}, want: `m{path="C:\\tmp \"x\""}`},
+		{name: "newline escaped", metric: map[string]string{"__name__": "m", "msg": "a\nb"}, want: `m{msg="a\nb"}`},
+		{name: "skipped labels", metric: map[string]string{"__name__": "m", "job": "node", "mode": "idle"}, skip: map[string]bool{"job": true}, want: `m{mode="idle"}`},
+		{name: "all labels skipped", metric: map[string]string{"__name__": "m", "job": "node"}, skip: map[string]bool{"job": true}, want: "m"},
+	}
+
+	for _, tc := range cases {
+		if got := prometheusSelector(tc.metric, tc.skip); got != tc.want {
+			t.Fatalf("%s: prometheusSelector = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestPrometheusItemName(t *testing.T) {
+	cases := []struct {
+		metric map[string]string
+		want   string
+	}{
+		{metric: map[string]string{"__name__": "node_load1", "instance": "a:9100", "job": "node"}, want: "node_load1"},
+		{metric: map[string]string{"__name__": "node_cpu_seconds_total", "instance": "a:9100", "job": "node", "cpu": "0", "mode": "idle"}, want: `node_cpu_seconds_total{cpu="0",mode="idle"}`},
+	}
+
+	for _, tc := range cases {
+		if got := prometheusItemName(tc.metric); got != tc.want {
+			t.Fatalf("prometheusItemName(%v) = %s, want %s", tc.metric, got, tc.want)
+		}
+	}
+}
+
+func TestPrometheusUnits(t *testing.T) {
+	cases := []struct {
+		metric string
+		want   string
+	}{
+		{metric: "node_memory_MemFree_bytes", want: "B"},
+		{metric: "node_network_receive_bytes_total", want: "B"},
+		{metric: "process_cpu_seconds_total", want: "s"},
+		{metric: "node_hwmon_temp_celsius", want: "°C"},
+		{metric: "disk_used_percent", want: "%"},
+		{metric: "link_bits_per_second", want: "bps"},
+		{metric: "up", want: ""},
+	}
+
+	for _, tc := range cases {
+		if got := prometheusUnits(tc.metric); got != tc.want {
+			t.Fatalf("prometheusUnits(%s) = %q, want %q", tc.metric, got, tc.want)
+		}
+	}
+}
+
+func TestParsePrometheusValue(t *testing.T) {
+	cases := []struct {
+		name      string
+		pair      [2]interface{}
+		wantTS    int64
+		wantValue string
+	}{
+		{name: "number", pair: [2]interface{}{1700000000.123, "42.5"}, wantTS: 1700000000, wantValue: "42.5"},
+		{name: "NaN", pair: [2]interface{}{1700000000.0, "NaN"}, wantTS: 1700000000, wantValue: "NaN"},
+		{name: "positive infinity", pair: [2]interface{}{1700000000.0, "+Inf"}, wantTS: 1700000000, wantValue: "+Inf"},
+		{name: "negative infinity", pair: [2]interface{}{1700000000.0, "-Inf"}, wantTS: 1700000000, wantValue: "-Inf"},
+		{name: "malformed pair", pair: [2]interface{}{"soon", math.Pi}, wantTS: 0, wantValue: ""},
+		{name: "empty pair", wantTS: 0, wantValue: ""},
+	}
+
+	for _, tc := range cases {
+		ts, value := parsePrometheusValue(tc.pair)
+		if ts != tc.wantTS || value != tc.wantValue {
+			t.Fatalf("%s: got (%d, %q), want (%d, %q)", tc.name, ts, value, tc.wantTS, tc.wantValue)
+		}
+	}
+}
+
+func TestMapPrometheusSeverity(t *testing.T) {
+	cases := []struct {
+		label string
+		want  string
+	}{
+		{label: "critical", want: "disaster"},
+		{label: " Page ", want: "disaster"},
+		{label: "ERROR", want: "high"},
+		{label: "minor", want: "average"},
+		{label: "warn", want: "warning"},
+		{label: "info", want: "information"},
+		{label: "", want: "information"},
+		{label: "sev-0", want: "information"},
+	}
+
+	for _, tc := range cases {
+		if got := mapPrometheusSeverity(tc.label); got != tc.want {
+			t.Fatalf("mapPrometheusSeverity(%q) = %s, want %s", tc.label, got, tc.want)
+		}
+	}
+}
+
+func TestPrometheusInstanceIP(t *testing.T) {
+	cases := []struct {
+		instance string
+		want     string
+	}{
+		{instance: "10.0.0.1:9100", want: "10.0.0.1"},
+		{instance: "10.0.0.1", want: "10.0.0.1"},
+		{instance: "[2001:db8::1]:9100", want: "2001:db8::1"},
+		{instance: "[2001:db8::1]", want: "2001:db8::1"},
+		{instance: "2001:db8::1", want: "2001:db8::1"},
+		{instance: "node-1.example.com:9100", want: ""},
+		{instance: "localhost", want: ""},
+		{instance: "", want: ""},
+	}
+
+	for _, tc := range cases {
+		if got := prometheusInstanceIP(tc.instance); got != tc.want {
+			t.Fatalf("prometheusInstanceIP(%q) = %q, want %q", tc.instance, got, tc.want)
+		}
+	}
+}
@@ -148,9 +148,15 @@ func (s *Service) HealthCheck(ctx context.Context) map[string]bool {
 // CreateProviderFromConfig creates a provider from configuration
 func CreateProviderFromConfig(cfg Config) (Provider, error) {
 	switch cfg.Type {
+	case MonitorSNMP:
+		return NewSNMPProvider(cfg)
+
 	case MonitorZabbix:
 		return NewZabbixProvider(cfg)
 
+	case MonitorPrometheus:
+		return NewPrometheusProvider(cfg)
+
 	case MonitorOther:
 		return nil, fmt.Errorf("other provider not implemented yet")
 	default:
@@ -0,0 +1,767 @@
+package monitors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+const (
+	defaultSNMPTimeout     = 3 * time.Second
+	defaultSNMPRetries     = 1
+	defaultSNMPConcurrency = 8
+
+	// sysUpTime.0 is answered by every agent and is used as the reachability probe
+	snmpProbeOID = "1.3.6.1.2.1.1.3.0"
+
+	snmpWalkPrefix = "walk:"
+)
+
+var errSNMPLocalOnly = errors.New("SNMP hosts and items are managed locally in Nagare")
+
+// SNMPTarget describes one SNMP agent polled by the built-in poller
+type SNMPTarget struct {
+	HostID      string
+	Name        string
+	Description string
+	Enabled     int
+	Address     string
+	Port        int
+	Version     string // "v1", "v2c", "v3"
+	Community   string
+	V3          SNMPv3Credentials
+	Items       []SNMPItem
+}
+
+// SNMPv3Credentials holds the USM parameters for SNMPv3 agents
+type SNMPv3Credentials struct {
+	Username      string
+	SecurityLevel string // "noAuthNoPriv", "authNoPriv", "authPriv"
+	AuthProtocol  string // "MD5", "SHA", "SHA224", "SHA256", "SHA384", "SHA512"
+	AuthPassword  string
+	PrivProtocol  string // "DES", "AES", "AES192", "AES256", "AES192C", "AES256C"
+	PrivPassword  string
+}
+
+// SNMPItem is an item configured on a target. ID is the OID spec:
+// "<oid>" for GET, or "walk:[max|min|sum|avg|count|first:]<oid>" for WALK with aggregation (default max).
+type SNMPItem struct {
+	ID        string
+	Name      string
+	Units     string
+	Enabled   int
+	LastValue string
+}
+
+// SNMPTargetSource supplies targets to the SNMP poller. Hosts live in the Nagare database,
+// so the service layer provides them instead of the poller discovering them.
+type SNMPTargetSource interface {
+	// SNMPTargets returns all targets of the monitor; Items may be left empty
+	SNMPTargets(ctx context.Context) ([]SNMPTarget, error)
+	// SNMPTarget returns a single target including its configured items
+	SNMPTarget(ctx context.Context, hostID string) (SNMPTarget, error)
+}
+
+// defaultSNMPItems are offered when a host has no OID items configured yet.
+// They cover MIB-II basics and the Huawei entity MIB used by the threshold checks.
+var defaultSNMPItems = []SNMPItem{
+	{ID: "1.3.6.1.2.1.1.3.0", Name: "sysUpTime", Units: "s"},
+	{ID: "1.3.6.1.2.1.2.1.0", Name: "ifNumber"},
+	{ID: "walk:max:1.3.6.1.4.1.2011.6.3.4.1.2", Name: "hwCpuDevDuty", Units: "%"},
+	{ID: "walk:max:1.3.6.1.4.1.2011.5.25.31.1.1.1.1.5", Name: "hwEntityCpuUsage", Units: "%"},
+	{ID: "walk:max:1.3.6.1.4.1.2011.5.25.31.1.1.1.1.7", Name: "hwEntityMemUsage", Units: "%"},
+	{ID: "walk:max:1.3.6.1.4.1.2011.5.25.31.1.1.1.1.11", Name: "hwEntityTemperature", Units: "°C"},
+}
+
+// SNMPProvider implements the Provider interface with a built-in SNMP v1/v2c/v3 poller
+type SNMPProvider struct {
+	name    string
+	timeout time.Duration
+	retries int
+
+	mu     sync.RWMutex
+	source SNMPTargetSource
+}
+
+type snmpOIDSpec struct {
+	oid       string
+	walk      bool
+	aggregate string
+}
+
+// NewSNMPProvider creates a new SNMP provider.
+// The per-request timeout is fixed because Config.Timeout is sized for HTTP APIs.
+func NewSNMPProvider(cfg Config) (*SNMPProvider, error) {
+	return &SNMPProvider{
+		name:    cfg.Name,
+		timeout: defaultSNMPTimeout,
+		retries: defaultSNMPRetries,
+	}, nil
+}
+
+// SetTargetSource sets where the poller reads its hosts and items from
+func (p *SNMPProvider) SetTargetSource(source SNMPTargetSource) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.source = source
+}
+
+func (p *SNMPProvider) targetSource() SNMPTargetSource {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.source
+}
+
+// Authenticate implements the Provider interface. SNMP has no central session.
+func (p *SNMPProvider) Authenticate(ctx context.Context) error {
+	return nil
+}
+
+// GetAuthToken implements the Provider interface
+func (p *SNMPProvider) GetAuthToken() string {
+	return ""
+}
+
+// SetAuthToken implements the Provider interface
+func (p *SNMPProvider) SetAuthToken(token string) {}
+
+// GetHosts implements the Provider interface. Every target is probed to report reachability.
+func (p *SNMPProvider) GetHosts(ctx context.Context) ([]Host, error) {
+	source := p.targetSource()
+	if source == nil {
+		return []Host{}, nil
+	}
+	targets, err := source.SNMPTargets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SNMP targets: %w", err)
+	}
+
+	hosts := make([]Host, len(targets))
+	sem := make(chan struct{}, defaultSNMPConcurrency)
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target SNMPTarget) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			hosts[i] = p.probeHost(ctx, target)
+		}(i, target)
+	}
+	wg.Wait()
+	return hosts, nil
+}
+
+// GetHostsByGroupID implements the Provider interface. Groups are local, so no hosts are returned.
+func (p *SNMPProvider) GetHostsByGroupID(ctx context.Context, groupID string) ([]Host, error) {
+	return []Host{}, nil
+}
+
+// GetHostByName implements the Provider interface
+func (p *SNMPProvider) GetHostByName(ctx context.Context, name string) (*Host, error) {
+	source := p.targetSource()
+	if source == nil {
+		return nil, fmt.Errorf("host not found: %s", name)
+	}
+	targets, err := source.SNMPTargets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SNMP targets: %w", err)
+	}
+	for _, target := range targets {
+		if target.Name == name {
+			host := p.probeHost(ctx, target)
+			return &host, nil
+		}
+	}
+	return nil, fmt.Errorf("host not found: %s", name)
+}
+
+// GetHostByID implements the Provider interface
+func (p *SNMPProvider) GetHostByID(ctx context.Context, hostID string) (*Host, error) {
+	target, err := p.getTarget(ctx, hostID)
+	if err != nil {
+		return nil, err
+	}
+	host := p.probeHost(ctx, target)
+	return &host, nil
+}
+
+// CreateHost implements the Provider interface. Hosts are stored locally, so this is a no-op.
+func (p *SNMPProvider) CreateHost(ctx context.Context, host Host) (Host, error) {
+	return host, nil
+}
+
+// UpdateHost implements the Provider interface
+func (p *SNMPProvider) UpdateHost(ctx context.Context, host Host) (Host, error) {
+	return host, nil
+}
+
+// DeleteHost implements the Provider interface
+func (p *SNMPProvider) DeleteHost(ctx context.Context, hostID string) error {
+	return nil
+}
+
+// GetItems implements the Provider interface by polling every configured OID of the host.
+// Items whose OID does not exist on the agent are omitted so the caller marks them missing.
+func (p *SNMPProvider) GetItems(ctx context.Context, hostID string) ([]Item, error) {
+	target, err := p.getTarget(ctx, hostID)
+	if err != nil {
+		return nil, err
+	}
+
+	configured := make([]SNMPItem, 0, len(target.Items))
+	for _, item := range target.Items {
+		if _, err := parseSNMPItemID(item.ID); err == nil {
+			configured = append(configured, item)
+		}
+	}
+	useDefaults := len(configured) == 0
+	if useDefaults {
+		configured = defaultSNMPItems
+	}
+
+	session, err := p.connect(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Conn.Close()
+
+	items := make([]Item, 0, len(configured))
+	for _, cfgItem := range configured {
+		spec, _ := parseSNMPItemID(cfgItem.ID)
+		if cfgItem.Enabled == 0 && !useDefaults {
+			// Disabled items are reported without polling so they keep their last value
+			items = append(items, snmpItem(hostID, spec, cfgItem, cfgItem.LastValue, "1"))
+			continue
+		}
+
+		value, found, err := pollSNMPValue(session, spec)
+		if err != nil {
+			if len(items) == 0 && isSNMPTimeout(err) {
+				return nil, fmt.Errorf("SNMP agent %s not responding: %w", target.Address, err)
+			}
+			continue
+		}
+		if !found {
+			continue
+		}
+		items = append(items, snmpItem(hostID, spec, cfgItem, value, "0"))
+	}
+	return items, nil
+}
+
+// PollItem polls a single configured item of a host
+func (p *SNMPProvider) PollItem(ctx context.Context, hostID, itemID string) (*Item, error) {
+	spec, err := parseSNMPItemID(itemID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := p.getTarget(ctx, hostID)
+	if err != nil {
+		return nil, err
+	}
+
+	cfgItem := SNMPItem{ID: itemID, Enabled: 1}
+	for _, item := range append(append([]SNMPItem{}, target.Items...), defaultSNMPItems...) {
+		if normalizeSNMPItemID(item.ID) == formatSNMPItemID(spec) {
+			cfgItem = item
+			break
+		}
+	}
+
+	session, err := p.connect(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Conn.Close()
+
+	value, found, err := pollSNMPValue(session, spec)
+	if err != nil {
+		return nil, fmt.Errorf("SNMP poll failed: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("item not found: %s", itemID)
+	}
+	item := snmpItem(hostID, spec, cfgItem, value, "0")
+	return &item, nil
+}
+
+// GetItemByID implements the Provider interface.
+// SNMP item IDs are OIDs that are only unique per host; use PollItem instead.
+func (p *SNMPProvider) GetItemByID(ctx context.Context, itemID string) (*Item, error) {
+	return nil, fmt.Errorf("SNMP items must be polled with their host: %s", itemID)
+}
+
+// GetItemHistory implements the Provider interface. Agents keep no history; it is stored locally.
+func (p *SNMPProvider) GetItemHistory(ctx context.Context, itemID string, from, to int64) ([]Item, error) {
+	return []Item{}, nil
+}
+
+// CreateItem implements the Provider interface. Items are stored locally, so this is a no-op.
+func (p *SNMPProvider) CreateItem(ctx context.Context, item Item) (Item, error) {
+	if _, err := parseSNMPItemID(item.ID); err != nil {
+		return Item{}, err
+	}
+	return item, nil
+}
+
+// UpdateItem implements the Provider interface
+func (p *SNMPProvider) UpdateItem(ctx context.Context, item Item) (Item, error) {
+	return p.CreateItem(ctx, item)
+}
+
+// DeleteItem implements the Provider interface
+func (p *SNMPProvider) DeleteItem(ctx context.Context, itemID string) error {
+	return nil
+}
+
+// GetAlerts implements the Provider interface. Alerts are raised by local triggers and traps.
+func (p *SNMPProvider) GetAlerts(ctx context.Context) ([]Alert, error) {
+	return []Alert{}, nil
+}
+
+// GetAlertsByHost implements the Provider interface
+func (p *SNMPProvider) GetAlertsByHost(ctx context.Context, hostID string) ([]Alert, error) {
+	return []Alert{}, nil
+}
+
+// GetTriggers implements the Provider interface
+func (p *SNMPProvider) GetTriggers(ctx context.Context) ([]Trigger, error) {
+	return []Trigger{}, nil
+}
+
+// GetTriggersByHost implements the Provider interface
+func (p *SNMPProvider) GetTriggersByHost(ctx context.Context, hostID string) ([]Trigger, error) {
+	return []Trigger{}, nil
+}
+
+// GetTemplateidByName implements the Provider interface
+func (p *SNMPProvider) GetTemplateidByName(ctx context.Context, name string) ([]string, error) {
+	return []string{}, nil
+}
+
+// GetHostGroups implements the Provider interface. Groups are local to Nagare.
+func (p *SNMPProvider) GetHostGroups(ctx context.Context) ([]string, error) {
+	return []string{}, nil
+}
+
+// GetHostGroupsDetails implements the Provider interface
+func (p *SNMPProvider) GetHostGroupsDetails(ctx context.Context) ([]struct{ ID, Name string }, error) {
+	return []struct{ ID, Name string }{}, nil
+}
+
+// GetHostGroupByName implements the Provider interface
+func (p *SNMPProvider) GetHostGroupByName(ctx context.Context, name string) (string, error) {
+	return "", errSNMPLocalOnly
+}
+
+// CreateHostGroup implements the Provider interface
+func (p *SNMPProvider) CreateHostGroup(ctx context.Context, name string) (string, error) {
+	return "", errSNMPLocalOnly
+}
+
+// UpdateHostGroup implements the Provider interface
+func (p *SNMPProvider) UpdateHostGroup(ctx context.Context, id, name string) error {
+	return errSNMPLocalOnly
+}
+
+// DeleteHostGroup implements the Provider interface
+func (p *SNMPProvider) DeleteHostGroup(ctx context.Context, id string) error {
+	return errSNMPLocalOnly
+}
+
+// Name implements the Provider interface
+func (p *SNMPProvider) Name() string {
+	return p.name
+}
+
+// Type implements the Provider interface
+func (p *SNMPProvider) Type() MonitorType {
+	return MonitorSNMP
+}
+
+func (p *SNMPProvider) getTarget(ctx context.Context, hostID string) (SNMPTarget, error) {
+	source := p.targetSource()
+	if source == nil {
+		return SNMPTarget{}, fmt.Errorf("host not found: %s", hostID)
+	}
+	target, err := source.SNMPTarget(ctx, hostID)
+	if err != nil {
+		return SNMPTarget{}, fmt.Errorf("failed to load SNMP target %s: %w", hostID, err)
+	}
+	return target, nil
+}
+
+func (p *SNMPProvider) probeHost(ctx context.Context, target SNMPTarget) Host {
+	host := Host{
+		ID:          target.HostID,
+		Name:        target.Name,
+		Description: target.Description,
+		Enabled:     target.Enabled,
+		IPAddress:   target.Address,
+		Metadata: map[string]string{
+			"host":           target.Name,
+			"interface_type": "snmp",
+			"snmp_community": target.Community,
+			"snmp_version":   target.Version,
+			"snmp_port":      strconv.Itoa(target.Port),
+		},
+	}
+
+	if target.Enabled == 0 {
+		host.Status = "unknown"
+		host.Metadata["active_available"] = "0"
+		return host
+	}
+
+	err := p.probe(ctx, target)
+	if err != nil {
+		host.Status = "down"
+		host.Metadata["active_available"] = "2"
+		host.Metadata["status_description"] = err.Error()
+		return host
+	}
+	host.Status = "up"
+	host.Metadata["active_available"] = "1"
+	return host
+}
+
+func (p *SNMPProvider) probe(ctx context.Context, target SNMPTarget) error {
+	session, err := p.connect(ctx, target)
+	if err != nil {
+		return err
+	}
+	defer session.Conn.Close()
+
+	if _, err := session.Get([]string{snmpProbeOID}); err != nil {
+		return fmt.Errorf("SNMP agent %s not responding: %w", target.Address, err)
+	}
+	return nil
+}
+
+// connect opens a session; gosnmp sessions are not safe for concurrent use, so callers own it
+func (p *SNMPProvider) connect(ctx context.Context, target SNMPTarget) (*gosnmp.GoSNMP, error) {
+	if strings.TrimSpace(target.Address) == "" {
+		return nil, fmt.Errorf("host %s has no IP address for SNMP", target.Name)
+	}
+	port := target.Port
+	if port <= 0 || port > 65535 {
+		port = 161
+	}
+
+	session := &gosnmp.GoSNMP{
+		Target:             strings.TrimSpace(target.Address),
+		Port:               uint16(port),
+		Transport:          "udp",
+		Community:          target.Community,
+		Timeout:            p.timeout,
+		Retries:            p.retries,
+		ExponentialTimeout: true,
+		MaxOids:            gosnmp.MaxOids,
+		Context:            ctx,
+	}
+
+	switch strings.ToLower(strings.TrimSpace(target.Version)) {
+	case "v1", "1":
+		session.Version = gosnmp.Version1
+	case "v3", "3":
+		if err := applySNMPv3(session, target.V3); err != nil {
+			return nil, err
+		}
+	default:
+		session.Version = gosnmp.Version2c
+	}
+	if session.Community == "" && session.Version != gosnmp.Version3 {
+		session.Community = "public"
+	}
+
+	if err := session.Connect(); err != nil {
+		return nil, fmt.Errorf("failed to connect to SNMP agent %s: %w", target.Address, err)
+	}
+	return session, nil
+}
+
+func applySNMPv3(session *gosnmp.GoSNMP, creds SNMPv3Credentials) error {
+	if strings.TrimSpace(creds.Username) == "" {
+		return fmt.Errorf("SNMPv3 requires a username")
+	}
+
+	usm := &gosnmp.UsmSecurityParameters{UserName: creds.Username}
+	var flags gosnmp.SnmpV3MsgFlags
+	switch strings.ToLower(strings.TrimSpace(creds.SecurityLevel)) {
+	case "authpriv":
+		flags = gosnmp.AuthPriv
+	case "authnopriv":
+		flags = gosnmp.AuthNoPriv
+	case "noauthnopriv":
+		flags = gosnmp.NoAuthNoPriv
+	default:
+		// Infer the level from the configured secrets
+		switch {
+		case creds.AuthPassword != "" && creds.PrivPassword != "":
+			flags = gosnmp.AuthPriv
+		case creds.AuthPassword != "":
+			flags = gosnmp.AuthNoPriv
+		default:
+			flags = gosnmp.NoAuthNoPriv
+		}
+	}
+
+	if flags&gosnmp.AuthNoPriv != 0 {
+		protocol, err := parseSNMPAuthProtocol(creds.AuthProtocol)
+		if err != nil {
+			return err
+		}
+		usm.AuthenticationProtocol = protocol
+		usm.AuthenticationPassphrase = creds.AuthPassword
+	}
+	if flags == gosnmp.AuthPriv {
+		protocol, err := parseSNMPPrivProtocol(creds.PrivProtocol)
+		if err != nil {
+			return err
+		}
+		usm.PrivacyProtocol = protocol
+		usm.PrivacyPassphrase = creds.PrivPassword
+	}
+
+	session.Version = gosnmp.Version3
+	session.SecurityModel = gosnmp.UserSecurityModel
+	session.MsgFlags = flags
+	session.SecurityParameters = usm
+	return nil
+}
+
+func parseSNMPAuthProtocol(raw string) (gosnmp.SnmpV3AuthProtocol, error) {
+	switch strings.ToUpper(strings.TrimSpace(raw)) {
+	case "MD5":
+		return gosnmp.MD5, nil
+	case "", "SHA", "SHA1":
+		return gosnmp.SHA, nil
+	case "SHA224":
+		return gosnmp.SHA224, nil
+	case "SHA256":
+		return gosnmp.SHA256, nil
+	case "SHA384":
+		return gosnmp.SHA384, nil
+	case "SHA512":
+		return gosnmp.SHA512, nil
+	default:
+		return gosnmp.NoAuth, fmt.Errorf("unsupported SNMPv3 auth protocol: %s", raw)
+	}
+}
+
+func parseSNMPPrivProtocol(raw string) (gosnmp.SnmpV3PrivProtocol, error) {
+	switch strings.ToUpper(strings.TrimSpace(raw)) {
+	case "DES":
+		return gosnmp.DES, nil
+	case "", "AES", "AES128":
+		return gosnmp.AES, nil
+	case "AES192":
+		return gosnmp.AES192, nil
+	case "AES256":
+		return gosnmp.AES256, nil
+	case "AES192C":
+		return gosnmp.AES192C, nil
+	case "AES256C":
+		return gosnmp.AES256C, nil
+	default:
+		return gosnmp.NoPriv, fmt.Errorf("unsupported SNMPv3 privacy protocol: %s", raw)
+	}
+}
+
+// pollSNMPValue runs the GET or WALK described by spec; found is false when the agent has no such OID
+func pollSNMPValue(session *gosnmp.GoSNMP, spec snmpOIDSpec) (string, bool, error) {
+	if !spec.walk {
+		packet, err := session.Get([]string{spec.oid})
+		if err != nil {
+			return "", false, err
+		}
+		if len(packet.Variables) == 0 {
+			return "", false, nil
+		}
+		return formatSNMPValue(packet.Variables[0])
+	}
+
+	var pdus []gosnmp.SnmpPDU
+	var err error
+	if session.Version == gosnmp.Version1 {
+		pdus, err = session.WalkAll(spec.oid)
+	} else {
+		pdus, err = session.BulkWalkAll(spec.oid)
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return aggregateSNMPValues(pdus, spec.aggregate)
+}
+
+func formatSNMPValue(pdu gosnmp.SnmpPDU) (string, bool, error) {
+	switch pdu.Type {
+	case gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView, gosnmp.Null:
+		return "", false, nil
+	case gosnmp.OctetString:
+		raw, _ := pdu.Value.([]byte)
+		if utf8.Valid(raw) {
+			return strings.TrimRight(string(raw), "\x00"), true, nil
+		}
+		return fmt.Sprintf("%x", raw), true, nil
+	case gosnmp.TimeTicks:
+		// Hundredths of a second; reported in seconds to match the "s" unit
+		ticks := gosnmp.ToBigInt(pdu.Value)
+		return new(big.Int).Div(ticks, big.NewInt(100)).String(), true, nil
+	case gosnmp.ObjectIdentifier, gosnmp.IPAddress:
+		return strings.TrimPrefix(fmt.Sprint(pdu.Value), "."), true, nil
+	case gosnmp.OpaqueFloat:
+		if value, ok := pdu.Value.(float32); ok {
+			return strconv.FormatFloat(float64(value), 'f', -1, 32), true, nil
+		}
+		return fmt.Sprint(pdu.Value), true, nil
+	case gosnmp.OpaqueDouble:
+		if value, ok := pdu.Value.(float64); ok {
+			return strconv.FormatFloat(value, 'f', -1, 64), true, nil
+		}
+		return fmt.Sprint(pdu.Value), true, nil
+	default:
+		return gosnmp.ToBigInt(pdu.Value).String(), true, nil
+	}
+}
+
+func aggregateSNMPValues(pdus []gosnmp.SnmpPDU, aggregate string) (string, bool, error) {
+	values := make([]string, 0, len(pdus))
+	for _, pdu := range pdus {
+		value, found, err := formatSNMPValue(pdu)
+		if err != nil {
+			return "", false, err
+		}
+		if found {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return "", false, nil
+	}
+
+	switch aggregate {
+	case "count":
+		return strconv.Itoa(len(values)), true, nil
+	case "first":
+		return values[0], true, nil
+	}
+
+	numbers := make([]float64, 0, len(values))
+	for _, v := range values {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			// Non-numeric tables cannot be aggregated, report the first row instead
+			return values[0], true, nil
+		}
+		numbers = append(numbers, f)
+	}
+
+	result := numbers[0]
+	switch aggregate {
+	case "min":
+		for _, n := range numbers[1:] {
+			if n < result {
+				result = n
+			}
+		}
+	case "sum", "avg":
+		result = 0
+		for _, n := range numbers {
+			result += n
+		}
+		if aggregate == "avg" {
+			result /= float64(len(numbers))
+		}
+	default:
+		for _, n := range numbers[1:] {
+			if n > result {
+				result = n
+			}
+		}
+	}
+	return strconv.FormatFloat(result, 'f', -1, 64), true, nil
+}
+
+func snmpItem(hostID string, spec snmpOIDSpec, cfg SNMPItem, value, status string) Item {
+	name := cfg.Name
+	if name == "" {
+		name = spec.oid
+	}
+	valueType := "0"
+	if _, err := strconv.ParseFloat(value, 64); err != nil {
+		valueType = "1"
+	}
+	return Item{
+		ID:        formatSNMPItemID(spec),
+		HostID:    hostID,
+		Name:      name,
+		Key:       spec.oid,
+		Type:      "snmp",
+		Value:     value,
+		Units:     cfg.Units,
+		ValueType: valueType,
+		Status:    status,
+		Timestamp: time.Now().Unix(),
+	}
+}
+
+// parseSNMPItemID parses "<oid>" or "walk:[aggregate:]<oid>"
+func parseSNMPItemID(id string) (snmpOIDSpec, error) {
+	spec := snmpOIDSpec{}
+	raw := strings.TrimSpace(id)
+	if strings.HasPrefix(strings.ToLower(raw), snmpWalkPrefix) {
+		spec.walk = true
+		spec.aggregate = "max"
+		raw = raw[len(snmpWalkPrefix):]
+		if idx := strings.Index(raw, ":"); idx >= 0 {
+			spec.aggregate = strings.ToLower(raw[:idx])
+			raw = raw[idx+1:]
+		}
+		switch spec.aggregate {
+		case "max", "min", "sum", "avg", "count", "first":
+		default:
+			return snmpOIDSpec{}, fmt.Errorf("unsupported SNMP walk aggregate: %s", spec.aggregate)
+		}
+	}
+
+	raw = strings.TrimPrefix(raw, ".")
+	if raw == "" {
+		return snmpOIDSpec{}, fmt.Errorf("invalid SNMP OID: %q", id)
+	}
+	for _, part := range strings.Split(raw, ".") {
+		if _, err := strconv.ParseUint(part, 10, 32); err != nil {
+			return snmpOIDSpec{}, fmt.Errorf("invalid SNMP OID: %q", id)
+		}
+	}
+	spec.oid = raw
+	return spec, nil
+}
+
+func formatSNMPItemID(spec snmpOIDSpec) string {
+	if !spec.walk {
+		return spec.oid
+	}
+	return snmpWalkPrefix + spec.aggregate + ":" + spec.oid
+}
+
+func normalizeSNMPItemID(id string) string {
+	spec, err := parseSNMPItemID(id)
+	if err != nil {
+		return id
+	}
+	return formatSNMPItemID(spec)
+}
+
+func isSNMPTimeout(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "timeout")
+}
@@ -0,0 +1,127 @@
+package monitors
+
+import (
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+func TestParseSNMPItemID(t *testing.T) {
+	cases := []struct {
+		id      string
+		want    snmpOIDSpec
+		wantErr bool
+	}{
+		{id: "1.3.6.1.2.1.1.3.0", want: snmpOIDSpec{oid: "1.3.6.1.2.1.1.3.0"}},
+		{id: " .1.3.6.1.2.1.1.3.0 ", want: snmpOIDSpec{oid: "1.3.6.1.2.1.1.3.0"}},
+		{id: "walk:1.3.6.1.2.1.2.2.1.10", want: snmpOIDSpec{oid: "1.3.6.1.2.1.2.2.1.10", walk: true, aggregate: "max"}},
+		{id: "WALK:SUM:.1.3.6.1.2.1.2.2.1.10", want: snmpOIDSpec{oid: "1.3.6.1.2.1.2.2.1.10", walk: true, aggregate: "sum"}},
+		{id: "walk:count:1.3.6.1.2.1.2.2.1.1", want: snmpOIDSpec{oid: "1.3.6.1.2.1.2.2.1.1", walk: true, aggregate: "count"}},
+		{id: "walk:median:1.3.6.1", wantErr: true},
+		{id: "walk:", wantErr: true},
+		{id: "", wantErr: true},
+		{id: "1.3.six.1", wantErr: true},
+		{id: "1.3..6", wantErr: true},
+		{id: "sysUpTime.0", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		got, err := parseSNMPItemID(tc.id)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("parseSNMPItemID(%q) error = %v, want error %v", tc.id, err, tc.wantErr)
+		}
+		if !tc.wantErr && got != tc.want {
+			t.Fatalf("parseSNMPItemID(%q) = %+v, want %+v", tc.id, got, tc.want)
+		}
+	}
+}
+
+func TestNormalizeSNMPItemID(t *testing.T) {
+	cases := []struct {
+		id   string
+		want string
+	}{
+		{id: ".1.3.6.1.2.1.1.3.0", want: "1.3.6.1.2.1.1.3.0"},
+		{id: "walk:1.3.6.1.2.1.2.2.1.10", want: "walk:max:1.3.6.1.2.1.2.2.1.10"},
+		{id: "Walk:AVG:.1.3.6.1", want: "walk:avg:1.3.6.1"},
+		{id: "not-an-oid", want: "not-an-oid"},
+	}
+
+	for _, tc := range cases {
+		if got := normalizeSNMPItemID(tc.id); got != tc.want {
+			t.Fatalf("normalizeSNMPItemID(%q) = %q, want %q", tc.id, got, tc.want)
+		}
+	}
+}
+
+func TestAggregateSNMPValues(t *testing.T) {
+	counters := []gosnmp.SnmpPDU{
+		{Type: gosnmp.Counter32, Value: uint(10)},
+		{Type: gosnmp.Counter32, Value: uint(40)},
+		{Type: gosnmp.NoSuchInstance},
+		{Type: gosnmp.Counter32, Value: uint(25)},
+	}
+	names := []gosnmp.SnmpPDU{
+		{Type: gosnmp.OctetString, Value: []byte("eth0")},
+		{Type: gosnmp.OctetString, Value: []byte("eth1")},
+	}
+
+	cases := []struct {
+		name      string
+		pdus      []gosnmp.SnmpPDU
+		aggregate string
+		want      string
+		wantFound bool
+	}{
+		{name: "max", pdus: counters, aggregate: "max", want: "40", wantFound: true},
+		{name: "unknown aggregate falls back to max", pdus: counters, aggregate: "", want: "40", wantFound: true},
+		{name: "min", pdus: counters, aggregate: "min", want: "10", wantFound: true},
+		{name: "sum", pdus: counters, aggregate: "sum", want: "75", wantFound: true},
+		{name: "avg", pdus: counters, aggregate: "avg", want: "25", wantFound: true},
+		{name: "count skips missing rows", pdus: counters, aggregate: "count", want: "3", wantFound: true},
+		{name: "first", pdus: counters, aggregate: "first", want: "10", wantFound: true},
+		{name: "non-numeric reports the first row", pdus: names, aggregate: "sum", want: "eth0", wantFound: true},
+		{name: "only missing rows", pdus: []gosnmp.SnmpPDU{{Type: gosnmp.NoSuchObject}, {Type: gosnmp.EndOfMibView}}, aggregate: "max", wantFound: false},
+		{name: "empty walk", aggregate: "count", wantFound: false},
+	}
+
+	for _, tc := range cases {
+		got, found, err := aggregateSNMPValues(tc.pdus, tc.aggregate)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if found != tc.wantFound || got != tc.want {
+			t.Fatalf("%s: got (%q, %v), want (%q, %v)", tc.name, got, found, tc.want, tc.wantFound)
+		}
+	}
+}
+
+func TestFormatSNMPValue(t *testing.T) {
+	cases := []struct {
+		name      string
+		pdu       gosnmp.SnmpPDU
+		want      string
+		wantFound bool
+	}{
+		{name: "octet string", pdu: gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte("router\x00")}, want: "router", wantFound: true},
+		{name: "binary octet string", pdu: gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte{0x00, 0x1a, 0xff}}, want: "001aff", wantFound: true},
+		{name: "timeticks in seconds", pdu: gosnmp.SnmpPDU{Type: gosnmp.TimeTicks, Value: uint32(12345)}, want: "123", wantFound: true},
+		{name: "object identifier", pdu: gosnmp.SnmpPDU{Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.9"}, want: "1.3.6.1.4.1.9", wantFound: true},
+		{name: "opaque float", pdu: gosnmp.SnmpPDU{Type: gosnmp.OpaqueFloat, Value: float32(1.5)}, want: "1.5", wantFound: true},
+		{name: "opaque double", pdu: gosnmp.SnmpPDU{Type: gosnmp.OpaqueDouble, Value: 2.25}, want: "2.25", wantFound: true},
+		{name: "opaque float with unexpected value", pdu: gosnmp.SnmpPDU{Type: gosnmp.OpaqueFloat, Value: "3.5"}, want: "3.5", wantFound: true},
+		{name: "opaque double with unexpected value", pdu: gosnmp.SnmpPDU{Type: gosnmp.OpaqueDouble, Value: float32(4)}, want: "4", wantFound: true},
+		{name: "counter64", pdu: gosnmp.SnmpPDU{Type: gosnmp.Counter64, Value: uint64(1 << 40)}, want: "1099511627776", wantFound: true},
+		{name: "no such instance", pdu: gosnmp.SnmpPDU{Type: gosnmp.NoSuchInstance}, wantFound: false},
+	}
+
+	for _, tc := range cases {
+		got, found, err := formatSNMPValue(tc.pdu)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if found != tc.wantFound || got != tc.want {
+			t.Fatalf("%s: got (%q, %v), want (%q, %v)", tc.name, got, found, tc.want, tc.wantFound)
+		}
+	}
+}
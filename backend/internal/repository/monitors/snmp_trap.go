@@ -0,0 +1,140 @@
+package monitors
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+const (
+	snmpTrapOIDVarBind     = "1.3.6.1.6.3.1.1.4.1.0"
+	snmpSysUpTimeVarBind   = "1.3.6.1.2.1.1.3.0"
+	snmpGenericTrapPrefix  = "1.3.6.1.6.3.1.1.5."
+	snmpTrapListenDeadline = 5 * time.Second
+)
+
+// SNMPTrap is a decoded trap or inform, independent of the SNMP version it arrived with
+type SNMPTrap struct {
+	SourceIP     string
+	AgentAddress string // v1 agent-addr field, empty for v2c/v3
+	Version      string // "v1", "v2c", "v3"
+	Community    string
+	TrapOID      string
+	Variables    []SNMPVarBind
+}
+
+// SNMPVarBind is a rendered variable binding of a trap
+type SNMPVarBind struct {
+	OID   string
+	Value string
+}
+
+// SNMPTrapHandler is called for every received trap
+type SNMPTrapHandler func(trap SNMPTrap)
+
+// SNMPTrapReceiver listens for SNMP traps on a UDP address
+type SNMPTrapReceiver struct {
+	addr     string
+	listener *gosnmp.TrapListener
+}
+
+// NewSNMPTrapReceiver creates a receiver; v3 may be nil when only v1/v2c traps are expected
+func NewSNMPTrapReceiver(addr string, v3 *SNMPv3Credentials, handler SNMPTrapHandler) (*SNMPTrapReceiver, error) {
+	if strings.TrimSpace(addr) == "" {
+		return nil, fmt.Errorf("listen address is required")
+	}
+	if handler == nil {
+		return nil, fmt.Errorf("trap handler is required")
+	}
+
+	params := &gosnmp.GoSNMP{
+		Transport:          "udp",
+		Version:            gosnmp.Version2c,
+		Timeout:            2 * time.Second,
+		Retries:            3,
+		ExponentialTimeout: true,
+		MaxOids:            gosnmp.MaxOids,
+	}
+	if v3 != nil && strings.TrimSpace(v3.Username) != "" {
+		if err := applySNMPv3(params, *v3); err != nil {
+			return nil, err
+		}
+	}
+
+	listener := gosnmp.NewTrapListener()
+	listener.Params = params
+	listener.OnNewTrap = func(packet *gosnmp.SnmpPacket, addr *net.UDPAddr) {
+		handler(decodeSNMPTrap(packet, addr))
+	}
+
+	return &SNMPTrapReceiver{addr: addr, listener: listener}, nil
+}
+
+// Start binds the UDP socket and returns once the receiver is listening
+func (r *SNMPTrapReceiver) Start() error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- r.listener.Listen(r.addr)
+	}()
+
+	select {
+	case <-r.listener.Listening():
+		return nil
+	case err := <-errCh:
+		if err == nil {
+			err = fmt.Errorf("trap listener stopped unexpectedly")
+		}
+		return fmt.Errorf("failed to listen for SNMP traps on %s: %w", r.addr, err)
+	case <-time.After(snmpTrapListenDeadline):
+		return fmt.Errorf("timed out starting SNMP trap listener on %s", r.addr)
+	}
+}
+
+// Close stops the receiver
+func (r *SNMPTrapReceiver) Close() {
+	r.listener.Close()
+}
+
+func decodeSNMPTrap(packet *gosnmp.SnmpPacket, addr *net.UDPAddr) SNMPTrap {
+	trap := SNMPTrap{
+		Community: packet.Community,
+		Version:   "v" + packet.Version.String(),
+	}
+	if addr != nil {
+		trap.SourceIP = addr.IP.String()
+	}
+
+	if packet.Version == gosnmp.Version1 {
+		trap.AgentAddress = packet.AgentAddress
+		trap.TrapOID = snmpV1TrapOID(packet.Enterprise, packet.GenericTrap, packet.SpecificTrap)
+	}
+
+	for _, pdu := range packet.Variables {
+		oid := strings.TrimPrefix(pdu.Name, ".")
+		if oid == snmpTrapOIDVarBind {
+			trap.TrapOID = strings.TrimPrefix(fmt.Sprint(pdu.Value), ".")
+			continue
+		}
+		if oid == snmpSysUpTimeVarBind {
+			continue
+		}
+		value, found, err := formatSNMPValue(pdu)
+		if err != nil || !found {
+			value = ""
+		}
+		trap.Variables = append(trap.Variables, SNMPVarBind{OID: oid, Value: value})
+	}
+	return trap
+}
+
+// snmpV1TrapOID converts a v1 trap header to the equivalent v2 trap OID (RFC 3584 section 3.1)
+func snmpV1TrapOID(enterprise string, generic, specific int) string {
+	if generic >= 0 && generic < 6 {
+		return snmpGenericTrapPrefix + strconv.Itoa(generic+1)
+	}
+	return strings.TrimPrefix(enterprise, ".") + ".0." + strconv.Itoa(specific)
+}
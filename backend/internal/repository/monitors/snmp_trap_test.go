@@ -0,0 +1,86 @@
+package monitors
+
+import (
+	"net"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+func TestSNMPV1TrapOID(t *testing.T) {
+	cases := []struct {
+		name       string
+		enterprise string
+		generic    int
+		specific   int
+		want       string
+	}{
+		{name: "coldStart", enterprise: ".1.3.6.1.4.1.9", generic: 0, want: "1.3.6.1.6.3.1.1.5.1"},
+		{name: "linkDown", enterprise: "1.3.6.1.4.1.9", generic: 2, want: "1.3.6.1.6.3.1.1.5.3"},
+		{name: "linkUp ignores specific", enterprise: "1.3.6.1.4.1.9", generic: 3, specific: 7, want: "1.3.6.1.6.3.1.1.5.4"},
+		{name: "egpNeighborLoss", enterprise: "1.3.6.1.4.1.9", generic: 5, want: "1.3.6.1.6.3.1.1.5.6"},
+		{name: "enterprise specific", enterprise: ".1.3.6.1.4.1.9.9.41.2", generic: 6, specific: 1, want: "1.3.6.1.4.1.9.9.41.2.0.1"},
+		{name: "enterprise specific without leading dot", enterprise: "1.3.6.1.4.1.2636", generic: 6, specific: 42, want: "1.3.6.1.4.1.2636.0.42"},
+	}
+
+	for _, tc := range cases {
+		if got := snmpV1TrapOID(tc.enterprise, tc.generic, tc.specific); got != tc.want {
+			t.Fatalf("%s: snmpV1TrapOID = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestDecodeSNMPTrap(t *testing.T) {
+	addr := &net.UDPAddr{IP: net.ParseIP("192.0.2.10"), Port: 162}
+	ifIndex := gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.2.2.1.1.3", Type: gosnmp.Integer, Value: 3}
+
+	cases := []struct {
+		name      string
+		packet    *gosnmp.SnmpPacket
+		wantOID   string
+		wantAgent string
+		wantVars  []SNMPVarBind
+	}{
+		{
+			name: "v1 generic",
+			packet: &gosnmp.SnmpPacket{Version: gosnmp.Version1, Community: "public", Variables: []gosnmp.SnmpPDU{ifIndex}, SnmpTrap: gosnmp.SnmpTrap{
+				Enterprise: ".1.3.6.1.4.1.9", AgentAddress: "192.0.2.1", GenericTrap: 2,
+			}},
+			wantOID:   "1.3.6.1.6.3.1.1.5.3",
+			wantAgent: "192.0.2.1",
+			wantVars:  []SNMPVarBind{{OID: "1.3.6.1.2.1.2.2.1.1.3", Value: "3"}},
+		},
+		{
+			name: "v1 enterprise specific",
+			packet: &gosnmp.SnmpPacket{Version: gosnmp.Version1, SnmpTrap: gosnmp.SnmpTrap{
+				Enterprise: ".1.3.6.1.4.1.9.9.41.2", GenericTrap: 6, SpecificTrap: 1,
+			}},
+			wantOID: "1.3.6.1.4.1.9.9.41.2.0.1",
+		},
+		{
+			name: "v2c trap OID varbind, sysUpTime dropped",
+			packet: &gosnmp.SnmpPacket{Version: gosnmp.Version2c, Variables: []gosnmp.SnmpPDU{
+				{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(100)},
+				{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.4"},
+				ifIndex,
+			}},
+			wantOID:  "1.3.6.1.6.3.1.1.5.4",
+			wantVars: []SNMPVarBind{{OID: "1.3.6.1.2.1.2.2.1.1.3", Value: "3"}},
+		},
+	}
+
+	for _, tc := range cases {
+		trap := decodeSNMPTrap(tc.packet, addr)
+		if trap.TrapOID != tc.wantOID || trap.AgentAddress != tc.wantAgent || trap.SourceIP != "192.0.2.10" {
+			t.Fatalf("%s: got oid=%s agent=%q source=%s", tc.name, trap.TrapOID, trap.AgentAddress, trap.SourceIP)
+		}
+		if len(trap.Variables) != len(tc.wantVars) {
+			t.Fatalf("%s: got %d varbinds, want %d", tc.name, len(trap.Variables), len(tc.wantVars))
+		}
+		for i, vb := range trap.Variables {
+			if vb != tc.wantVars[i] {
+				t.Fatalf("%s: varbind %d = %+v, want %+v", tc.name, i, vb, tc.wantVars[i])
+			}
+		}
+	}
+}
@@ -2412,6 +2412,95 @@ func (p *ZabbixProvider) CreateHostGroup(ctx context.Context, name string) (stri
 	return createResult.GroupIDs[0], nil
 }
 
+// ZabbixMaintenance describes a one-time maintenance period covering hosts and host groups
+type ZabbixMaintenance struct {
+	Name        string
+	Description string
+	HostIDs     []string
+	GroupIDs    []string
+	ActiveSince time.Time
+	ActiveTill  time.Time
+}
+
+func (m ZabbixMaintenance) params() map[string]interface{} {
+	hosts := make([]map[string]string, 0, len(m.HostIDs))
+	for _, id := range m.HostIDs {
+		hosts = append(hosts, map[string]string{"hostid": id})
+	}
+	groups := make([]map[string]string, 0, len(m.GroupIDs))
+	for _, id := range m.GroupIDs {
+		groups = append(groups, map[string]string{"groupid": id})
+	}
+	return map[string]interface{}{
+		"name":             m.Name,
+		"description":      m.Description,
+		"active_since":     m.ActiveSince.Unix(),
+		"active_till":      m.ActiveTill.Unix(),
+		"maintenance_type": 0, // Keep collecting data
+		"hosts":            hosts,
+		"groups":           groups,
+		"timeperiods": []map[string]interface{}{{
+			"timeperiod_type": 0, // One time only
+			"start_date":      m.ActiveSince.Unix(),
+			"period":          int64(m.ActiveTill.Sub(m.ActiveSince).Seconds()),
+		}},
+	}
+}
+
+// CreateMaintenance creates a maintenance period and returns its ID
+func (p *ZabbixProvider) CreateMaintenance(ctx context.Context, maintenance ZabbixMaintenance) (string, error) {
+	if len(maintenance.HostIDs) == 0 && len(maintenance.GroupIDs) == 0 {
+		return "", fmt.Errorf("maintenance requires at least one host or host group")
+	}
+	if strings.TrimSpace(p.GetAuthToken()) == "" {
+		if err := p.Authenticate(ctx); err != nil {
+			return "", fmt.Errorf("failed to authenticate with zabbix: %w", err)
+		}
+	}
+	resp, err := p.sendRequest(ctx, "maintenance.create", maintenance.params())
+	if err != nil {
+		return "", fmt.Errorf("failed to create maintenance: %w", err)
+	}
+	var result struct {
+		MaintenanceIDs []string `json:"maintenanceids"`
+	}
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return "", fmt.Errorf("failed to parse create maintenance response: %w", err)
+	}
+	if len(result.MaintenanceIDs) == 0 {
+		return "", fmt.Errorf("no maintenance ID returned after creation")
+	}
+	return result.MaintenanceIDs[0], nil
+}
+
+// UpdateMaintenance replaces the hosts, groups and period of a maintenance
+func (p *ZabbixProvider) UpdateMaintenance(ctx context.Context, id string, maintenance ZabbixMaintenance) error {
+	if strings.TrimSpace(p.GetAuthToken()) == "" {
+		if err := p.Authenticate(ctx); err != nil {
+			return fmt.Errorf("failed to authenticate with zabbix: %w", err)
+		}
+	}
+	params := maintenance.params()
+	params["maintenanceid"] = id
+	if _, err := p.sendRequest(ctx, "maintenance.update", params); err != nil {
+		return fmt.Errorf("failed to update maintenance: %w", err)
+	}
+	return nil
+}
+
+// DeleteMaintenance deletes a maintenance period
+func (p *ZabbixProvider) DeleteMaintenance(ctx context.Context, id string) error {
+	if strings.TrimSpace(p.GetAuthToken()) == "" {
+		if err := p.Authenticate(ctx); err != nil {
+			return fmt.Errorf("failed to authenticate with zabbix: %w", err)
+		}
+	}
+	if _, err := p.sendRequest(ctx, "maintenance.delete", []string{id}); err != nil {
+		return fmt.Errorf("failed to delete maintenance: %w", err)
+	}
+	return nil
+}
+
 func toString(value interface{}) string {
 	if value == nil {
 		return "0"
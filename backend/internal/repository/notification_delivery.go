@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"nagare/internal/database"
+	"nagare/internal/model"
+
+	"gorm.io/gorm"
+)
+
+func applyNotificationDeliveryFilters(query *gorm.DB, filter model.NotificationDeliveryFilter) *gorm.DB {
+	if filter.Query != "" {
+		like := "%" + filter.Query + "%"
+		query = query.Where("target LIKE ? OR message LIKE ? OR last_error LIKE ?", like, like, like)
+	}
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	if filter.AlertID != nil {
+		query = query.Where("alert_id = ?", *filter.AlertID)
+	}
+	if filter.IncidentID != nil {
+		query = query.Where("incident_id = ?", *filter.IncidentID)
+	}
+	if filter.ActionID != nil {
+		query = query.Where("action_id = ?", *filter.ActionID)
+	}
+	if filter.MediaID != nil {
+		query = query.Where("media_id = ?", *filter.MediaID)
+	}
+	if filter.DigestID != nil {
+		query = query.Where("digest_id = ?", *filter.DigestID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+	return query
+}
+
+// SearchNotificationDeliveriesDAO retrieves outbox deliveries by filter
+func SearchNotificationDeliveriesDAO(filter model.NotificationDeliveryFilter) ([]model.NotificationDelivery, error) {
+	query := applyNotificationDeliveryFilters(database.DB.Model(&model.NotificationDelivery{}), filter)
+	query = applySort(query, filter.SortBy, filter.SortOrder, map[string]string{
+		"kind":            "kind",
+		"status":          "status",
+		"attempts":        "attempts",
+		"media_id":        "media_id",
+		"target":          "target",
+		"next_attempt_at": "next_attempt_at",
+		"sent_at":         "sent_at",
+		"created_at":      "created_at",
+		"updated_at":      "updated_at",
+		"id":              "id",
+	}, "id desc")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	var deliveries []model.NotificationDelivery
+	if err := query.Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// CountNotificationDeliveriesDAO returns total count for outbox deliveries by filter
+func CountNotificationDeliveriesDAO(filter model.NotificationDeliveryFilter) (int64, error) {
+	var total int64
+	if err := applyNotificationDeliveryFilters(database.DB.Model(&model.NotificationDelivery{}), filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// GetNotificationDeliveryByIDDAO retrieves an outbox delivery by ID
+func GetNotificationDeliveryByIDDAO(id uint) (model.NotificationDelivery, error) {
+	var delivery model.NotificationDelivery
+	err := database.DB.First(&delivery, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return delivery, model.ErrNotFound
+	}
+	return delivery, err
+}
+
+// AddNotificationDeliveryDAO queues a new delivery
+func AddNotificationDeliveryDAO(delivery *model.NotificationDelivery) error {
+	return database.DB.Create(delivery).Error
+}
+
+// GetDueNotificationDeliveriesDAO returns pending deliveries whose next attempt is due, oldest first
+func GetDueNotificationDeliveriesDAO(now time.Time, limit int) ([]model.NotificationDelivery, error) {
+	var deliveries []model.NotificationDelivery
+	err := database.DB.Where("status = ? AND next_attempt_at <= ?", 0, now).
+		Order("next_attempt_at asc, id asc").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// ClaimNotificationDeliveryDAO moves a pending delivery to sending; false means another worker got it first
+func ClaimNotificationDeliveryDAO(id uint, at time.Time) (bool, error) {
+	res := database.DB.Model(&model.NotificationDelivery{}).
+		Where("id = ? AND status = ?", id, 0).
+		Updates(map[string]interface{}{
+			"status":          1,
+			"attempts":        gorm.Expr("attempts + 1"),
+			"last_attempt_at": at,
+		})
+	return res.RowsAffected == 1, res.Error
+}
+
+// UpdateNotificationDeliveryFieldsDAO records the outcome of an attempt
+func UpdateNotificationDeliveryFieldsDAO(id uint, fields map[string]interface{}) error {
+	return database.DB.Model(&model.NotificationDelivery{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// ReleaseSendingNotificationDeliveriesDAO puts deliveries left in sending by a previous process back in the queue
+func ReleaseSendingNotificationDeliveriesDAO(now time.Time) (int64, error) {
+	res := database.DB.Model(&model.NotificationDelivery{}).
+		Where("status = ?", 1).
+		Updates(map[string]interface{}{"status": 0, "next_attempt_at": now})
+	return res.RowsAffected, res.Error
+}
+
+// RetryNotificationDeliveryDAO re-queues a failed or skipped delivery with a fresh attempt budget
+func RetryNotificationDeliveryDAO(id uint, now time.Time, attempts int) (bool, error) {
+	res := database.DB.Model(&model.NotificationDelivery{}).
+		Where("id = ? AND status IN ?", id, []int{3, 4}).
+		Updates(map[string]interface{}{
+			"status":          0,
+			"max_attempts":    gorm.Expr("attempts + ?", attempts),
+			"next_attempt_at": now,
+		})
+	return res.RowsAffected == 1, res.Error
+}
+
+// CountRecentNotificationDeliveriesDAO counts messages queued for a media target since the given time
+func CountRecentNotificationDeliveriesDAO(mediaID uint, target string, since time.Time) (int64, error) {
+	var total int64
+	err := database.DB.Model(&model.NotificationDelivery{}).
+		Where("media_id = ? AND target = ? AND kind <> ? AND created_at >= ?", mediaID, target, "digest", since).
+		Count(&total).Error
+	return total, err
+}
+
+// HoldNotificationDeliveryDAO marks a delivery as folded into a digest
+func HoldNotificationDeliveryDAO(id uint, digestID uint, reason string) error {
+	return database.DB.Model(&model.NotificationDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          5,
+		"digest_id":       digestID,
+		"next_attempt_at": nil,
+		"last_error":      reason,
+	}).Error
+}
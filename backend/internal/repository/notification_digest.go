@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"nagare/internal/database"
+	"nagare/internal/model"
+
+	"gorm.io/gorm"
+)
+
+func applyNotificationDigestFilters(query *gorm.DB, filter model.NotificationDigestFilter) *gorm.DB {
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	if filter.MediaID != nil {
+		query = query.Where("media_id = ?", *filter.MediaID)
+	}
+	return query
+}
+
+// SearchNotificationDigestsDAO retrieves notification digests by filter
+func SearchNotificationDigestsDAO(filter model.NotificationDigestFilter) ([]model.NotificationDigest, error) {
+	query := applyNotificationDigestFilters(database.DB.Model(&model.NotificationDigest{}), filter)
+	query = applySort(query, filter.SortBy, filter.SortOrder, map[string]string{
+		"status":       "status",
+		"media_id":     "media_id",
+		"count":        "count",
+		"window_start": "window_start",
+		"flush_at":     "flush_at",
+		"created_at":   "created_at",
+		"id":           "id",
+	}, "id desc")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	var digests []model.NotificationDigest
+	if err := query.Find(&digests).Error; err != nil {
+		return nil, err
+	}
+	return digests, nil
+}
+
+// CountNotificationDigestsDAO returns total count for notification digests by filter
+func CountNotificationDigestsDAO(filter model.NotificationDigestFilter) (int64, error) {
+	var total int64
+	if err := applyNotificationDigestFilters(database.DB.Model(&model.NotificationDigest{}), filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// GetNotificationDigestByIDDAO retrieves a notification digest by ID
+func GetNotificationDigestByIDDAO(id uint) (model.NotificationDigest, error) {
+	var digest model.NotificationDigest
+	err := database.DB.First(&digest, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return digest, model.ErrNotFound
+	}
+	return digest, err
+}
+
+// GetOpenNotificationDigestDAO returns the digest still collecting messages of an action for a media target;
+// a nil actionID matches digests of messages that do not come from an action
+func GetOpenNotificationDigestDAO(mediaID uint, target string, actionID *uint) (model.NotificationDigest, error) {
+	var digest model.NotificationDigest
+	query := database.DB.Where("media_id = ? AND target = ? AND status = ?", mediaID, target, 0)
+	if actionID != nil {
+		query = query.Where("action_id = ?", *actionID)
+	} else {
+		query = query.Where("action_id IS NULL")
+	}
+	err := query.Order("id desc").
+		First(&digest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return digest, model.ErrNotFound
+	}
+	return digest, err
+}
+
+// AddNotificationDigestDAO creates a new notification digest
+func AddNotificationDigestDAO(digest *model.NotificationDigest) error {
+	return database.DB.Create(digest).Error
+}
+
+// UpdateNotificationDigestContentDAO saves the collected summary of a digest
+func UpdateNotificationDigestContentDAO(digest model.NotificationDigest) error {
+	return database.DB.Model(&model.NotificationDigest{}).Where("id = ?", digest.ID).
+		Select("count", "severity_counts", "host_counts", "messages").
+		Updates(&digest).Error
+}
+
+// GetDueNotificationDigestsDAO returns collecting digests whose window has ended
+func GetDueNotificationDigestsDAO(now time.Time) ([]model.NotificationDigest, error) {
+	var digests []model.NotificationDigest
+	err := database.DB.Where("status = ? AND flush_at <= ?", 0, now).Order("flush_at asc").Find(&digests).Error
+	return digests, err
+}
+
+// MarkNotificationDigestFlushedDAO closes a digest and links the delivery that carries it
+func MarkNotificationDigestFlushedDAO(id uint, deliveryID *uint, at time.Time) error {
+	return database.DB.Model(&model.NotificationDigest{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":      1,
+		"delivery_id": deliveryID,
+		"flushed_at":  at,
+	}).Error
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"nagare/internal/database"
+	"nagare/internal/model"
+
+	"gorm.io/gorm"
+)
+
+func applyOnCallScheduleFilters(query *gorm.DB, filter model.OnCallScheduleFilter) *gorm.DB {
+	if filter.Query != "" {
+		query = query.Where("name LIKE ? OR description LIKE ?", "%"+filter.Query+"%", "%"+filter.Query+"%")
+	}
+	if filter.Enabled != nil {
+		query = query.Where("enabled = ?", *filter.Enabled)
+	}
+	return query
+}
+
+// SearchOnCallSchedulesDAO retrieves on-call schedules by filter
+func SearchOnCallSchedulesDAO(filter model.OnCallScheduleFilter) ([]model.OnCallSchedule, error) {
+	query := applyOnCallScheduleFilters(database.DB.Model(&model.OnCallSchedule{}), filter)
+	query = applySort(query, filter.SortBy, filter.SortOrder, map[string]string{
+		"name":       "name",
+		"enabled":    "enabled",
+		"time_zone":  "time_zone",
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+		"id":         "id",
+	}, "id desc")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	var schedules []model.OnCallSchedule
+	if err := query.Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// CountOnCallSchedulesDAO returns total count for on-call schedules by filter
+func CountOnCallSchedulesDAO(filter model.OnCallScheduleFilter) (int64, error) {
+	var total int64
+	if err := applyOnCallScheduleFilters(database.DB.Model(&model.OnCallSchedule{}), filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// GetOnCallScheduleByIDDAO retrieves an on-call schedule by ID
+func GetOnCallScheduleByIDDAO(id uint) (model.OnCallSchedule, error) {
+	var schedule model.OnCallSchedule
+	err := database.DB.First(&schedule, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return schedule, model.ErrNotFound
+	}
+	return schedule, err
+}
+
+// AddOnCallScheduleDAO creates a new on-call schedule
+func AddOnCallScheduleDAO(schedule *model.OnCallSchedule) error {
+	return database.DB.Create(schedule).Error
+}
+
+// UpdateOnCallScheduleDAO updates an on-call schedule by ID
+func UpdateOnCallScheduleDAO(id uint, schedule model.OnCallSchedule) error {
+	return database.DB.Model(&model.OnCallSchedule{}).Where("id = ?", id).Select("name", "description", "time_zone", "enabled", "layers").Updates(&schedule).Error
+}
+
+// DeleteOnCallScheduleByIDDAO deletes an on-call schedule with its overrides
+func DeleteOnCallScheduleByIDDAO(id uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Action{}).Where("on_call_schedule_id = ?", id).Update("on_call_schedule_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("schedule_id = ?", id).Delete(&model.OnCallOverride{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.OnCallSchedule{}, id).Error
+	})
+}
+
+// GetOnCallOverridesDAO returns the overrides of a schedule ending after the given time, earliest first
+func GetOnCallOverridesDAO(scheduleID uint, endAfter time.Time) ([]model.OnCallOverride, error) {
+	var overrides []model.OnCallOverride
+	err := database.DB.Where("schedule_id = ? AND end_at > ?", scheduleID, endAfter).
+		Order("start_at asc").
+		Find(&overrides).Error
+	return overrides, err
+}
+
+// GetActiveOnCallOverrideDAO returns the most recently created override covering the given time
+func GetActiveOnCallOverrideDAO(scheduleID uint, at time.Time) (model.OnCallOverride, error) {
+	var override model.OnCallOverride
+	err := database.DB.Where("schedule_id = ? AND start_at <= ? AND end_at > ?", scheduleID, at, at).
+		Order("id desc").
+		First(&override).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return override, model.ErrNotFound
+	}
+	return override, err
+}
+
+// GetOnCallOverrideByIDDAO retrieves an override by ID
+func GetOnCallOverrideByIDDAO(id uint) (model.OnCallOverride, error) {
+	var override model.OnCallOverride
+	err := database.DB.First(&override, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return override, model.ErrNotFound
+	}
+	return override, err
+}
+
+// AddOnCallOverrideDAO creates a new override
+func AddOnCallOverrideDAO(override *model.OnCallOverride) error {
+	return database.DB.Create(override).Error
+}
+
+// DeleteOnCallOverrideByIDDAO deletes an override by ID
+func DeleteOnCallOverrideByIDDAO(id uint) error {
+	return database.DB.Delete(&model.OnCallOverride{}, id).Error
+}
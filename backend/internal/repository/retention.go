@@ -89,13 +89,24 @@ func CleanOldDataDAO(dataType string, days int) (int64, error) {
 	case "chat":
 		res := database.DB.Unscoped().Where("created_at < ?", cutoff).Delete(&model.Chat{})
 		result = res.RowsAffected
+		// Conversations go once their last message has expired
+		database.DB.Unscoped().Where("COALESCE(last_chat_at, created_at) < ?", cutoff).Delete(&model.Conversation{})
 
+	case "llm_usage":
+		res := database.DB.Unscoped().Where("created_at < ?", cutoff).Delete(&model.LLMUsage{})
+		result = res.RowsAffected
 	case "reports":
 		res := database.DB.Unscoped().Where("created_at < ?", cutoff).Delete(&model.Report{})
 		result = res.RowsAffected
 	case "site_messages":
 		res := database.DB.Unscoped().Where("created_at < ?", cutoff).Delete(&model.SiteMessage{})
 		result = res.RowsAffected
+	case "notification_deliveries":
+		// Queued and in-flight deliveries are kept until they finish
+		res := database.DB.Unscoped().Where("created_at < ? AND status IN ?", cutoff, []int{2, 3, 4, 5}).Delete(&model.NotificationDelivery{})
+		result = res.RowsAffected
+		res = database.DB.Unscoped().Where("created_at < ? AND status = ?", cutoff, 1).Delete(&model.NotificationDigest{})
+		result += res.RowsAffected
 	}
 
 	return result, nil
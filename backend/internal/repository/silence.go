@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"nagare/internal/database"
+	"nagare/internal/model"
+
+	"gorm.io/gorm"
+)
+
+func applySilenceFilters(query *gorm.DB, filter model.SilenceFilter) *gorm.DB {
+	if filter.Query != "" {
+		query = query.Where("name LIKE ? OR reason LIKE ?", "%"+filter.Query+"%", "%"+filter.Query+"%")
+	}
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	if filter.Enabled != nil {
+		query = query.Where("enabled = ?", *filter.Enabled)
+	}
+	if filter.Current {
+		query = query.Where("enabled = 1 AND (end_at IS NULL OR end_at > ?)", time.Now())
+	}
+	return query
+}
+
+// SearchSilencesDAO retrieves silences by filter
+func SearchSilencesDAO(filter model.SilenceFilter) ([]model.Silence, error) {
+	query := applySilenceFilters(database.DB.Model(&model.Silence{}), filter)
+	query = applySort(query, filter.SortBy, filter.SortOrder, map[string]string{
+		"name":             "name",
+		"kind":             "kind",
+		"enabled":          "enabled",
+		"start_at":         "start_at",
+		"end_at":           "end_at",
+		"suppressed_count": "suppressed_count",
+		"created_at":       "created_at",
+		"updated_at":       "updated_at",
+		"id":               "id",
+	}, "id desc")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	var silences []model.Silence
+	if err := query.Find(&silences).Error; err != nil {
+		return nil, err
+	}
+	return silences, nil
+}
+
+// CountSilencesDAO returns total count for silences by filter
+func CountSilencesDAO(filter model.SilenceFilter) (int64, error) {
+	var total int64
+	if err := applySilenceFilters(database.DB.Model(&model.Silence{}), filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// GetSilenceByIDDAO retrieves a silence by ID
+func GetSilenceByIDDAO(id uint) (model.Silence, error) {
+	var silence model.Silence
+	err := database.DB.First(&silence, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return silence, model.ErrNotFound
+	}
+	return silence, err
+}
+
+// AddSilenceDAO creates a new silence
+func AddSilenceDAO(silence *model.Silence) error {
+	return database.DB.Create(silence).Error
+}
+
+// UpdateSilenceDAO updates the user-editable columns of a silence
+func UpdateSilenceDAO(id uint, silence model.Silence) error {
+	return database.DB.Model(&model.Silence{}).Where("id = ?", id).Select(
+		"name", "kind", "enabled", "host_id", "group_id", "item_id", "severities", "message_regex",
+		"start_at", "end_at", "cron_expr", "duration_minutes", "reason", "push_to_zabbix",
+	).Updates(&silence).Error
+}
+
+// UpdateSilenceFieldsDAO updates the given columns of a silence
+func UpdateSilenceFieldsDAO(id uint, fields map[string]interface{}) error {
+	return database.DB.Model(&model.Silence{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// DeleteSilenceByIDDAO deletes a silence; alerts it suppressed keep their reference for auditing
+func DeleteSilenceByIDDAO(id uint) error {
+	return database.DB.Delete(&model.Silence{}, id).Error
+}
+
+// SuppressAlertDAO marks an alert as suppressed by a silence and counts it on the silence
+func SuppressAlertDAO(silenceID, alertID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Alert{}).Where("id = ?", alertID).Update("silence_id", silenceID).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Silence{}).Where("id = ?", silenceID).Update("suppressed_count", gorm.Expr("suppressed_count + 1")).Error
+	})
+}
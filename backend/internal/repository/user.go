@@ -120,6 +120,16 @@ func GetUserByQQDAO(qq string) (model.User, error) {
 	return user, err
 }
 
+// GetUserByTelegramIDDAO retrieves a user by Telegram user ID
+func GetUserByTelegramIDDAO(telegramID string) (model.User, error) {
+	var user model.User
+	err := database.DB.Where("telegram_id = ?", telegramID).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return user, model.ErrNotFound
+	}
+	return user, err
+}
+
 // AddUserDAO creates a new user
 func AddUserDAO(user model.User) error {
 	return database.DB.Create(&user).Error
@@ -143,6 +153,7 @@ func UpdateUserDAO(id int, user model.User) error {
 		"introduction": user.Introduction,
 		"nickname":     user.Nickname,
 		"qq":           user.QQ,
+		"telegram_id":  user.TelegramID,
 	}
 	if user.Password != "" {
 		updates["password"] = user.Password
@@ -14,6 +14,19 @@ import (
 
 var ErrMediaSendSkipped = errors.New("media send skipped")
 
+// mediaRateLimitError is the ErrMediaSendSkipped returned when the rate limiter holds a message back
+type mediaRateLimitError struct {
+	wait time.Duration
+}
+
+func (e *mediaRateLimitError) Error() string {
+	return fmt.Sprintf("%v: rate limit, retry in %ds", ErrMediaSendSkipped, int(e.wait.Seconds()))
+}
+
+func (e *mediaRateLimitError) Unwrap() error {
+	return ErrMediaSendSkipped
+}
+
 // ActionReq represents an action request
 type ActionReq struct {
 	Name        string `json:"name" binding:"required"`
@@ -24,6 +37,22 @@ type ActionReq struct {
 	SeverityMin *int   `json:"severity_min"`
 	AlertStatus *int   `json:"alert_status"`
 	UserIDs     []uint `json:"user_ids"`
+	// Also send a recovery message when a notified alert resolves
+	NotifyRecovery int `json:"notify_recovery"`
+	// Go text/template message templates; empty ones keep the built-in text
+	SubjectTemplate         string `json:"subject_template"`
+	BodyTemplate            string `json:"body_template"`
+	RecoverySubjectTemplate string `json:"recovery_subject_template"`
+	RecoveryBodyTemplate    string `json:"recovery_body_template"`
+	// Escalation policy started for matched alerts that stay unacknowledged
+	EscalationPolicyID *uint `json:"escalation_policy_id"`
+	// On-call schedule whose current user is notified at send time
+	OnCallScheduleID *uint `json:"on_call_schedule_id"`
+	// Storm digest; nil DigestEnabled keeps the current setting (enabled for new actions), zero values use defaults
+	DigestEnabled       *int `json:"digest_enabled"`
+	DigestWindowSeconds int  `json:"digest_window_seconds"`
+	DigestThreshold     int  `json:"digest_threshold"`
+	DigestMaxMessages   int  `json:"digest_max_messages"`
 }
 
 // ActionResp represents an action response
@@ -35,9 +64,22 @@ type ActionResp struct {
 	Status      int    `json:"status"`
 	Description string `json:"description"`
 	// Filter conditions
-	SeverityMin *int           `json:"severity_min"`
-	AlertStatus *int           `json:"alert_status"`
-	Users       []UserResponse `json:"users,omitempty"`
+	SeverityMin        *int           `json:"severity_min"`
+	AlertStatus        *int           `json:"alert_status"`
+	Users              []UserResponse `json:"users,omitempty"`
+	NotifyRecovery     int            `json:"notify_recovery"`
+	EscalationPolicyID *uint          `json:"escalation_policy_id"`
+	OnCallScheduleID   *uint          `json:"on_call_schedule_id"`
+	// Message templates
+	SubjectTemplate         string `json:"subject_template"`
+	BodyTemplate            string `json:"body_template"`
+	RecoverySubjectTemplate string `json:"recovery_subject_template"`
+	RecoveryBodyTemplate    string `json:"recovery_body_template"`
+	// Storm digest
+	DigestEnabled       int `json:"digest_enabled"`
+	DigestWindowSeconds int `json:"digest_window_seconds"`
+	DigestThreshold     int `json:"digest_threshold"`
+	DigestMaxMessages   int `json:"digest_max_messages"`
 }
 
 func GetAllActionsServ() ([]ActionResp, error) {
@@ -79,13 +121,29 @@ func GetActionByIDServ(id uint) (ActionResp, error) {
 
 func AddActionServ(req ActionReq) (ActionResp, error) {
 	action := model.Action{
-		Name:        req.Name,
-		MediaID:     req.MediaID,
-		Enabled:     req.Enabled,
-		Description: req.Description,
-		SeverityMin: req.SeverityMin,
-		AlertStatus: req.AlertStatus,
+		Name:           req.Name,
+		MediaID:        req.MediaID,
+		Enabled:        req.Enabled,
+		Description:    req.Description,
+		SeverityMin:    req.SeverityMin,
+		AlertStatus:    req.AlertStatus,
+		NotifyRecovery: req.NotifyRecovery,
+	}
+	if err := applyActionTemplates(&action, req); err != nil {
+		return ActionResp{}, err
+	}
+	action.DigestEnabled = 1
+	if err := applyActionDigest(&action, req); err != nil {
+		return ActionResp{}, err
+	}
+	if err := validateActionEscalationPolicy(req.EscalationPolicyID); err != nil {
+		return ActionResp{}, err
 	}
+	action.EscalationPolicyID = normalizeOptionalID(req.EscalationPolicyID)
+	if err := validateOnCallSchedule(req.OnCallScheduleID); err != nil {
+		return ActionResp{}, err
+	}
+	action.OnCallScheduleID = normalizeOptionalID(req.OnCallScheduleID)
 
 	// Bind users
 	for _, uid := range req.UserIDs {
@@ -113,15 +171,31 @@ func UpdateActionServ(id uint, req ActionReq) error {
 	}
 
 	updated := model.Action{
-		Name:        req.Name,
-		MediaID:     req.MediaID,
-		Enabled:     req.Enabled,
-		Description: req.Description,
-		Status:      existing.Status,
-		SeverityMin: req.SeverityMin,
-		AlertStatus: req.AlertStatus,
+		Name:           req.Name,
+		MediaID:        req.MediaID,
+		Enabled:        req.Enabled,
+		Description:    req.Description,
+		Status:         existing.Status,
+		SeverityMin:    req.SeverityMin,
+		AlertStatus:    req.AlertStatus,
+		NotifyRecovery: req.NotifyRecovery,
 	}
 	updated.ID = id
+	if err := applyActionTemplates(&updated, req); err != nil {
+		return err
+	}
+	updated.DigestEnabled = existing.DigestEnabled
+	if err := applyActionDigest(&updated, req); err != nil {
+		return err
+	}
+	if err := validateActionEscalationPolicy(req.EscalationPolicyID); err != nil {
+		return err
+	}
+	updated.EscalationPolicyID = normalizeOptionalID(req.EscalationPolicyID)
+	if err := validateOnCallSchedule(req.OnCallScheduleID); err != nil {
+		return err
+	}
+	updated.OnCallScheduleID = normalizeOptionalID(req.OnCallScheduleID)
 
 	for _, uid := range req.UserIDs {
 		user, err := repository.GetUserByIDDAO(int(uid))
@@ -156,18 +230,78 @@ func actionToResp(action model.Action) ActionResp {
 	}
 
 	return ActionResp{
-		ID:          int(action.ID),
-		Name:        action.Name,
-		MediaID:     action.MediaID,
-		Enabled:     action.Enabled,
-		Status:      action.Status,
-		Description: action.Description,
-		SeverityMin: action.SeverityMin,
-		AlertStatus: action.AlertStatus,
-		Users:       usersResp,
+		ID:                 int(action.ID),
+		Name:               action.Name,
+		MediaID:            action.MediaID,
+		Enabled:            action.Enabled,
+		Status:             action.Status,
+		Description:        action.Description,
+		SeverityMin:        action.SeverityMin,
+		AlertStatus:        action.AlertStatus,
+		Users:              usersResp,
+		NotifyRecovery:     action.NotifyRecovery,
+		EscalationPolicyID: action.EscalationPolicyID,
+		OnCallScheduleID:   action.OnCallScheduleID,
+
+		SubjectTemplate:         action.SubjectTemplate,
+		BodyTemplate:            action.BodyTemplate,
+		RecoverySubjectTemplate: action.RecoverySubjectTemplate,
+		RecoveryBodyTemplate:    action.RecoveryBodyTemplate,
+
+		DigestEnabled:       action.DigestEnabled,
+		DigestWindowSeconds: action.DigestWindowSeconds,
+		DigestThreshold:     action.DigestThreshold,
+		DigestMaxMessages:   action.DigestMaxMessages,
 	}
 }
 
+// applyActionDigest validates the request's storm digest settings and copies them onto the action
+func applyActionDigest(action *model.Action, req ActionReq) error {
+	if req.DigestEnabled != nil {
+		action.DigestEnabled = *req.DigestEnabled
+	}
+	if action.DigestEnabled != 0 && action.DigestEnabled != 1 {
+		return fmt.Errorf("%w: digest_enabled must be 0 or 1", model.ErrInvalidInput)
+	}
+	window, threshold, maxMessages := req.DigestWindowSeconds, req.DigestThreshold, req.DigestMaxMessages
+	if window == 0 {
+		window = int(defaultDigestWindow.Seconds())
+	}
+	if maxMessages == 0 {
+		maxMessages = defaultDigestMaxMessages
+	}
+	if window < 30 || window > 86400 {
+		return fmt.Errorf("%w: digest_window_seconds must be between 30 and 86400", model.ErrInvalidInput)
+	}
+	if threshold < 0 || threshold > 10000 {
+		return fmt.Errorf("%w: digest_threshold must be between 0 and 10000", model.ErrInvalidInput)
+	}
+	if maxMessages < 1 || maxMessages > 100 {
+		return fmt.Errorf("%w: digest_max_messages must be between 1 and 100", model.ErrInvalidInput)
+	}
+	action.DigestWindowSeconds = window
+	action.DigestThreshold = threshold
+	action.DigestMaxMessages = maxMessages
+	return nil
+}
+
+// applyActionTemplates validates the request's message templates and copies them onto the action
+func applyActionTemplates(action *model.Action, req ActionReq) error {
+	if err := validateActionTemplates(map[string]string{
+		"subject":          req.SubjectTemplate,
+		"body":             req.BodyTemplate,
+		"recovery subject": req.RecoverySubjectTemplate,
+		"recovery body":    req.RecoveryBodyTemplate,
+	}); err != nil {
+		return err
+	}
+	action.SubjectTemplate = req.SubjectTemplate
+	action.BodyTemplate = req.BodyTemplate
+	action.RecoverySubjectTemplate = req.RecoverySubjectTemplate
+	action.RecoveryBodyTemplate = req.RecoveryBodyTemplate
+	return nil
+}
+
 // ExecuteActionsForAlert evaluates all active actions against the alert and executes matching ones
 func ExecuteActionsForAlert(alert model.Alert) {
 	// Fetch all enabled actions
@@ -202,10 +336,28 @@ func ExecuteActionsForAlert(alert model.Alert) {
 		return
 	}
 
+	if alert.SilenceID != nil {
+		LogService("info", "action evaluation skipped: alert suppressed by silence", map[string]interface{}{
+			"alert_id":   alert.ID,
+			"silence_id": *alert.SilenceID,
+		}, nil, "")
+		return
+	}
+
+	// Alerts correlated into an existing incident are covered by the incident's own notifications
+	if incidentSuppressesAlertNotification(alert) {
+		LogService("info", "action evaluation skipped: alert folded into incident", map[string]interface{}{
+			"alert_id":    alert.ID,
+			"incident_id": *alert.IncidentID,
+		}, nil, "")
+		return
+	}
+
 	// Prepare context for matching
 	matchCtx := buildAlertMatchContext(alert)
-	replacements := buildAlertReplacements(matchCtx)
+	message := buildAlertMessage(matchCtx, nil)
 
+	notified := make([]uint, 0)
 	for _, action := range actions {
 		if action.Enabled == 0 {
 			LogService("debug", "action skipped: disabled", map[string]interface{}{"action_id": action.ID, "action_name": action.Name}, nil, "")
@@ -218,116 +370,165 @@ func ExecuteActionsForAlert(alert model.Alert) {
 				"alert_id":    alert.ID,
 			}, nil, "")
 
-			// Get Media
-			media, err := repository.GetMediaByIDDAO(action.MediaID)
-			if err != nil {
-				LogService("error", "failed to load media for action", map[string]interface{}{
-					"action_id": action.ID,
-					"media_id":  action.MediaID,
-					"error":     err.Error(),
-				}, nil, "")
-				continue
-			}
-			if media.Enabled == 0 {
-				LogService("warn", "media disabled for action", map[string]interface{}{
-					"action_id":  action.ID,
-					"media_id":   action.MediaID,
-					"media_name": media.Name,
-				}, nil, "")
-				continue
+			deliverActionMessage(action, func(media model.Media, recipientID *uint) error {
+				return ExecuteAction(action, media, recipientID, message)
+			})
+			notified = append(notified, action.ID)
+			if action.EscalationPolicyID != nil {
+				startAlertEscalation(alert, *action.EscalationPolicyID)
 			}
+		}
+	}
+
+	// Remembered so recovery messages reach the same channels
+	if len(notified) > 0 {
+		if err := repository.SetAlertNotifiedActionsDAO(alert.ID, notified); err != nil {
+			LogService("warn", "failed to record notified actions", map[string]interface{}{"alert_id": alert.ID, "error": err.Error()}, nil, "")
+		}
+	}
+}
 
-			lowerType := strings.ToLower(media.Type)
-			endpointOnlyQQTarget := (lowerType == "qq" || lowerType == "qrobot") && isQQEndpointOnlyTargetForAction(media.Target)
+// notifyAlertRecovery sends a recovery message through every action that delivered the alert's problem message and opted in
+func notifyAlertRecovery(alert model.Alert) {
+	if len(alert.NotifiedActions) == 0 || alert.SilenceID != nil {
+		return
+	}
+	resolvedAt := time.Now()
+	message := buildAlertMessage(buildAlertMatchContext(alert), &resolvedAt)
 
-			// Execute default target
-			if media.Target != "" {
-				if endpointOnlyQQTarget {
-					LogService("info", "action default qq target skipped (endpoint-only target requires user-bound recipients)", map[string]interface{}{
-						"action_id": action.ID,
-						"media_id":  media.ID,
-						"target":    media.Target,
-					}, nil, "")
-				} else if err := ExecuteAction(action, media, replacements); err != nil {
-					if errors.Is(err, ErrMediaSendSkipped) {
-						LogService("info", "action execution skipped", map[string]interface{}{
-							"action_id": action.ID,
-							"media_id":  media.ID,
-							"target":    media.Target,
-							"reason":    err.Error(),
-						}, nil, "")
-					} else {
-						LogService("error", "action execution failed", map[string]interface{}{
-							"action_id": action.ID,
-							"media_id":  media.ID,
-							"target":    media.Target,
-							"error":     err.Error(),
-						}, nil, "")
-					}
-				} else {
-					LogService("info", "action execution succeeded", map[string]interface{}{
-						"action_id": action.ID,
-						"media_id":  media.ID,
-						"target":    media.Target,
-					}, nil, "")
-				}
+	for _, actionID := range alert.NotifiedActions {
+		action, err := repository.GetActionByIDDAO(actionID)
+		if err != nil || action.Enabled == 0 || action.NotifyRecovery == 0 {
+			continue
+		}
+		LogService("info", "sending recovery message for alert", map[string]interface{}{
+			"action_id": action.ID,
+			"alert_id":  alert.ID,
+			"duration":  message.data.Alert.Duration,
+		}, nil, "")
+		deliverActionMessage(action, func(media model.Media, recipientID *uint) error {
+			return ExecuteAction(action, media, recipientID, message)
+		})
+	}
+}
+
+// deliverActionMessage sends through the action's media to its default target and to every bound user.
+// send receives the media with its target set, and the recipient user for user targets.
+func deliverActionMessage(action model.Action, send func(media model.Media, recipientID *uint) error) {
+	// Get Media
+	media, err := repository.GetMediaByIDDAO(action.MediaID)
+	if err != nil {
+		LogService("error", "failed to load media for action", map[string]interface{}{
+			"action_id": action.ID,
+			"media_id":  action.MediaID,
+			"error":     err.Error(),
+		}, nil, "")
+		return
+	}
+	if media.Enabled == 0 {
+		LogService("warn", "media disabled for action", map[string]interface{}{
+			"action_id":  action.ID,
+			"media_id":   action.MediaID,
+			"media_name": media.Name,
+		}, nil, "")
+		return
+	}
+
+	lowerType := strings.ToLower(media.Type)
+	endpointOnlyQQTarget := (lowerType == "qq" || lowerType == "qrobot") && isQQEndpointOnlyTargetForAction(media.Target)
+
+	// Execute default target
+	if media.Target != "" {
+		if endpointOnlyQQTarget {
+			LogService("info", "action default qq target skipped (endpoint-only target requires user-bound recipients)", map[string]interface{}{
+				"action_id": action.ID,
+				"media_id":  media.ID,
+				"target":    media.Target,
+			}, nil, "")
+		} else if err := send(media, nil); err != nil {
+			if errors.Is(err, ErrMediaSendSkipped) {
+				LogService("info", "action execution skipped", map[string]interface{}{
+					"action_id": action.ID,
+					"media_id":  media.ID,
+					"target":    media.Target,
+					"reason":    err.Error(),
+				}, nil, "")
 			} else {
-				LogService("debug", "action default target empty", map[string]interface{}{
+				LogService("error", "action execution failed", map[string]interface{}{
 					"action_id": action.ID,
 					"media_id":  media.ID,
+					"target":    media.Target,
+					"error":     err.Error(),
 				}, nil, "")
 			}
+		} else {
+			LogService("info", "action notification queued", map[string]interface{}{
+				"action_id": action.ID,
+				"media_id":  media.ID,
+				"target":    media.Target,
+			}, nil, "")
+		}
+	} else {
+		LogService("debug", "action default target empty", map[string]interface{}{
+			"action_id": action.ID,
+			"media_id":  media.ID,
+		}, nil, "")
+	}
 
-			// Also send to specifically associated users
-			for _, user := range action.Users {
-				userTarget := ""
-				if (lowerType == "qq" || lowerType == "qrobot") && user.QQ != "" {
-					if endpointOnlyQQTarget {
-						userTarget = strings.TrimSpace(media.Target) + " user:" + user.QQ
-					} else {
-						userTarget = "user:" + user.QQ
-					}
-				} else if (lowerType == "smtp" || lowerType == "email") && user.Email != "" {
-					userTarget = user.Email
-				}
+	// Also send to specifically associated users and whoever is on call right now
+	for _, user := range actionRecipients(action) {
+		userTarget := ""
+		if (lowerType == "qq" || lowerType == "qrobot") && user.QQ != "" {
+			if endpointOnlyQQTarget {
+				userTarget = strings.TrimSpace(media.Target) + " user:" + user.QQ
+			} else {
+				userTarget = "user:" + user.QQ
+			}
+		} else if (lowerType == "smtp" || lowerType == "email") && user.Email != "" {
+			userTarget = user.Email
+		} else if lowerType == "telegram" && user.TelegramID != "" {
+			// A private chat with the bot has the same ID as the user
+			userTarget = user.TelegramID
+		}
 
-				if userTarget != "" {
-					userMedia := media
-					userMedia.Target = userTarget
-					LogService("debug", "sending alert to associated user", map[string]interface{}{
+		if userTarget != "" {
+			userMedia := media
+			userMedia.Target = userTarget
+			LogService("debug", "sending alert to associated user", map[string]interface{}{
+				"action_id": action.ID,
+				"user_id":   user.ID,
+				"target":    userTarget,
+			}, nil, "")
+			recipientID := user.ID
+			if err := send(userMedia, &recipientID); err != nil {
+				if errors.Is(err, ErrMediaSendSkipped) {
+					LogService("info", "user action execution skipped", map[string]interface{}{
 						"action_id": action.ID,
 						"user_id":   user.ID,
 						"target":    userTarget,
+						"reason":    err.Error(),
+					}, nil, "")
+				} else {
+					LogService("error", "user action execution failed", map[string]interface{}{
+						"action_id": action.ID,
+						"user_id":   user.ID,
+						"target":    userTarget,
+						"error":     err.Error(),
 					}, nil, "")
-					if err := ExecuteAction(action, userMedia, replacements); err != nil {
-						if errors.Is(err, ErrMediaSendSkipped) {
-							LogService("info", "user action execution skipped", map[string]interface{}{
-								"action_id": action.ID,
-								"user_id":   user.ID,
-								"target":    userTarget,
-								"reason":    err.Error(),
-							}, nil, "")
-						} else {
-							LogService("error", "user action execution failed", map[string]interface{}{
-								"action_id": action.ID,
-								"user_id":   user.ID,
-								"target":    userTarget,
-								"error":     err.Error(),
-							}, nil, "")
-						}
-					}
 				}
 			}
 		}
 	}
 }
 
-// ExecuteAction sends a message via the action's media
-func ExecuteAction(action model.Action, media model.Media, replacements map[string]string) error {
-	msg := "Alert: {{message}}"
-	msg = renderMessageTemplate(msg, replacements)
-	msg = appendAlertDetails(msg, replacements)
-	return sendMediaMessage(media, msg)
+// ExecuteAction queues an alert's problem or recovery message for delivery via the action's media
+func ExecuteAction(action model.Action, media model.Media, recipientID *uint, message alertMessage) error {
+	alertID, actionID := message.data.Alert.ID, action.ID
+	source := notificationSource{Kind: NotificationKindAlert, AlertID: &alertID, ActionID: &actionID, Digest: actionDigestSettings(action)}
+	if message.data.Recovery {
+		source.Kind = NotificationKindRecovery
+	}
+	return enqueueNotification(source, media, recipientID, renderActionMessage(action, message))
 }
 
 type alertMatchContext struct {
@@ -383,6 +584,10 @@ func buildAlertReplacements(ctx alertMatchContext) map[string]string {
 	if ctx.host != nil {
 		hostIDStr = fmt.Sprintf("%d", ctx.host.ID)
 	}
+	incidentIDStr := "0"
+	if alert.IncidentID != nil {
+		incidentIDStr = fmt.Sprintf("%d", *alert.IncidentID)
+	}
 
 	return map[string]string{
 		"{{alert_id}}":       fmt.Sprintf("%d", alert.ID),
@@ -396,6 +601,7 @@ func buildAlertReplacements(ctx alertMatchContext) map[string]string {
 		"{{group_id}}":       "0",
 		"{{analysis}}":       alert.Comment,
 		"{{created_at}}":     alert.CreatedAt.Format(time.RFC3339),
+		"{{incident_id}}":    incidentIDStr,
 	}
 }
 
@@ -419,17 +625,30 @@ func appendAlertDetails(message string, replacements map[string]string) string {
 		"item_id={{item_id}}",
 		"created_at={{created_at}}",
 		"group_id={{group_id}}",
+		"incident_id={{incident_id}}",
 		"analysis={{analysis}}",
 	}, "\n")
 	return strings.TrimSpace(message + "\n" + renderMessageTemplate(detailsTemplate, replacements))
 }
 
 func sendMediaMessage(media model.Media, msg string) error {
+	return sendMediaMessageContext(context.Background(), media, msg)
+}
+
+func sendMediaMessageContext(ctx context.Context, media model.Media, msg string) error {
+	_, err := sendMediaPayload(ctx, media, webhookMessageData(msg, "message"))
+	return err
+}
+
+// sendMediaPayload sends a message through the media provider in the richest form it supports; webhook media
+// render data into their request and return the endpoint's response
+func sendMediaPayload(ctx context.Context, media model.Media, data WebhookTemplateData) (*mediaSvc.WebhookResponse, error) {
+	msg := data.Message
 	resolvedType := resolveMediaTypeKeyForSend(media)
 	if strings.TrimSpace(resolvedType) == "" {
 		err := fmt.Errorf("media type key is empty")
 		LogService("error", "send message failed", map[string]interface{}{"media": media.Type, "media_id": media.ID, "target": media.Target, "error": err.Error(), "skip_trigger": true}, nil, "")
-		return err
+		return nil, err
 	}
 
 	lowerType := strings.ToLower(resolvedType)
@@ -442,14 +661,25 @@ func sendMediaMessage(media model.Media, msg string) error {
 			"wait_seconds": int(wait.Seconds()),
 			"skip_trigger": true,
 		}, nil, "")
-		return fmt.Errorf("%w: rate limit, retry in %ds", ErrMediaSendSkipped, int(wait.Seconds()))
+		return nil, &mediaRateLimitError{wait: wait}
 	}
-	if err := mediaSvc.GetService().SendMessage(context.Background(), lowerType, media.Target, msg); err != nil {
+	if isWebhookMediaType(lowerType) {
+		resp, err := sendWebhookMedia(ctx, media, data)
+		if err != nil {
+			LogService("error", "send message failed", map[string]interface{}{"media": media.Type, "target": media.Target, "status_code": resp.StatusCode, "error": err.Error(), "skip_trigger": true}, nil, "")
+			return &resp, err
+		}
+		LogService("info", "send message", map[string]interface{}{"media": media.Type, "target": media.Target, "message": msg, "status_code": resp.StatusCode, "skip_trigger": true}, nil, "")
+		return &resp, nil
+	}
+	// Only render the chart for providers that can deliver it
+	rich := buildRichMessage(data, mediaSvc.GetService().AcceptsImageData(lowerType))
+	if err := mediaSvc.GetService().SendRichMessage(ctx, lowerType, media.Target, rich); err != nil {
 		LogService("error", "send message failed", map[string]interface{}{"media": media.Type, "target": media.Target, "error": err.Error(), "skip_trigger": true}, nil, "")
-		return err
+		return nil, err
 	}
 	LogService("info", "send message", map[string]interface{}{"media": media.Type, "target": media.Target, "message": msg, "skip_trigger": true}, nil, "")
-	return nil
+	return nil, nil
 }
 
 // TestActionServ manually triggers an action execution for testing purposes
@@ -475,8 +705,8 @@ func TestActionServ(id uint) error {
 	lowerType := strings.ToLower(media.Type)
 	endpointOnlyQQTarget := (lowerType == "qq" || lowerType == "qrobot") && isQQEndpointOnlyTargetForAction(media.Target)
 
-	// Send to specifically associated users
-	for _, user := range action.Users {
+	// Send to specifically associated users and whoever is on call right now
+	for _, user := range actionRecipients(action) {
 		userTarget := ""
 		if (lowerType == "qq" || lowerType == "qrobot") && user.QQ != "" {
 			if endpointOnlyQQTarget {
@@ -486,6 +716,9 @@ func TestActionServ(id uint) error {
 			}
 		} else if (lowerType == "smtp" || lowerType == "email") && user.Email != "" {
 			userTarget = user.Email
+		} else if lowerType == "telegram" && user.TelegramID != "" {
+			// A private chat with the bot has the same ID as the user
+			userTarget = user.TelegramID
 		}
 
 		if userTarget != "" {
@@ -546,6 +779,28 @@ func isQQEndpointOnlyTargetForAction(target string) bool {
 	return !strings.ContainsAny(trimmed, " \t\r\n")
 }
 
+// formatProblemDuration renders how long a problem lasted, e.g. "2h 5m" or "45s"
+func formatProblemDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	d = d.Round(time.Second)
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	seconds := int(d.Seconds()) % 60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm %ds", minutes, seconds)
+	default:
+		return fmt.Sprintf("%ds", seconds)
+	}
+}
+
 func severityLabel(severity int) string {
 	switch severity {
 	case 5:
@@ -11,39 +11,44 @@ import (
 	"nagare/internal/model"
 	"nagare/internal/repository"
 	"nagare/internal/repository/monitors"
-
-	"github.com/spf13/viper"
 )
 
 // AlarmReq represents an alarm request
 type AlarmReq struct {
-	MonitorID   uint   `json:"monitor_id" binding:"required"`
-	Name        string `json:"name" binding:"required"`
-	URL         string `json:"url" binding:"required"`
-	Username    string `json:"username"`
-	Password    string `json:"password"`
-	AuthToken   string `json:"auth_token"`
-	EventToken  string `json:"event_token"`
-	Description string `json:"description"`
-	Type        int    `json:"type" binding:"required,oneof=1 2 3"`
-	Enabled     int    `json:"enabled"`
+	MonitorID           uint                 `json:"monitor_id" binding:"required"`
+	Name                string               `json:"name" binding:"required"`
+	URL                 string               `json:"url" binding:"required"`
+	Username            string               `json:"username"`
+	Password            string               `json:"password"`
+	AuthToken           string               `json:"auth_token"`
+	EventToken          string               `json:"event_token"`
+	Description         string               `json:"description"`
+	Type                int                  `json:"type" binding:"required,oneof=1 2 3 4"`
+	Enabled             int                  `json:"enabled"`
+	PayloadMapping      *AlarmPayloadMapping `json:"payload_mapping"`                 // optional mapping for generic webhook payloads; omitted on update keeps the stored one
+	ClearPayloadMapping bool                 `json:"clear_payload_mapping,omitempty"` // removes the stored mapping on update
+	SignatureTolerance  int                  `json:"signature_tolerance"`             // seconds; 0 uses the default on create and keeps the stored value on update
 }
 
 // AlarmResp represents an alarm response
 type AlarmResp struct {
-	ID          int    `json:"id"`
-	MonitorID   uint   `json:"monitor_id"`
-	Name        string `json:"name"`
-	URL         string `json:"url"`
-	Username    string `json:"username"`
-	Password    string `json:"password"`
-	AuthToken   string `json:"auth_token"`
-	EventToken  string `json:"event_token"`
-	Description string `json:"description"`
-	Type        int    `json:"type"`
-	Enabled     int    `json:"enabled"`
-	Status      int    `json:"status"`
-	StatusDesc  string `json:"status_description"`
+	ID                 int                  `json:"id"`
+	MonitorID          uint                 `json:"monitor_id"`
+	Name               string               `json:"name"`
+	URL                string               `json:"url"`
+	Username           string               `json:"username"`
+	Password           string               `json:"password"`
+	AuthToken          string               `json:"auth_token"`
+	EventToken         string               `json:"event_token"`
+	Description        string               `json:"description"`
+	Type               int                  `json:"type"`
+	Enabled            int                  `json:"enabled"`
+	Status             int                  `json:"status"`
+	StatusDesc         string               `json:"status_description"`
+	PayloadMapping     *AlarmPayloadMapping `json:"payload_mapping,omitempty"`
+	SigningEnabled     bool                 `json:"signing_enabled"`
+	SigningSecret      string               `json:"signing_secret,omitempty"` // only returned right after generation
+	SignatureTolerance int                  `json:"signature_tolerance"`
 }
 
 type AlarmSetupMediaResp struct {
@@ -71,22 +76,7 @@ func mapAlarmTypeToMonitorType(alarmType int) monitors.MonitorType {
 }
 
 func buildAlarmWebhookURL() string {
-	host := strings.TrimSpace(viper.GetString("system.ip_address"))
-	port := viper.GetInt("system.port")
-	if port == 0 {
-		port = 8080
-	}
-
-	if host == "" {
-		host = "localhost"
-	}
-
-	base := strings.TrimRight(host, "/")
-	if strings.HasPrefix(base, "http://") || strings.HasPrefix(base, "https://") {
-		return base + "/api/v1/alert/webhooks"
-	}
-
-	return fmt.Sprintf("http://%s:%d/api/v1/alert/webhooks", base, port)
+	return webBaseURL() + "/api/v1/alert/webhooks"
 }
 
 func generateAlarmEventToken() (string, error) {
@@ -158,6 +148,9 @@ func AddAlarmServ(a AlarmReq) (AlarmResp, error) {
 		return AlarmResp{}, fmt.Errorf("failed to validate monitor: %w", err)
 	}
 	monitorID := a.MonitorID
+	if err := validateAlarmPayloadMapping(a.PayloadMapping); err != nil {
+		return AlarmResp{}, err
+	}
 
 	eventToken := strings.TrimSpace(a.EventToken)
 	if eventToken == "" {
@@ -169,17 +162,19 @@ func AddAlarmServ(a AlarmReq) (AlarmResp, error) {
 	}
 
 	alarm := model.Alarm{
-		MonitorID:   &monitorID,
-		Name:        a.Name,
-		URL:         a.URL,
-		Username:    a.Username,
-		Password:    a.Password,
-		AuthToken:   a.AuthToken,
-		EventToken:  eventToken,
-		Description: a.Description,
-		Type:        a.Type,
-		Enabled:     a.Enabled,
-		Status:      0, // Default to inactive on creation
+		MonitorID:          &monitorID,
+		Name:               a.Name,
+		URL:                a.URL,
+		Username:           a.Username,
+		Password:           a.Password,
+		AuthToken:          a.AuthToken,
+		EventToken:         eventToken,
+		Description:        a.Description,
+		Type:               a.Type,
+		Enabled:            a.Enabled,
+		Status:             0, // Default to inactive on creation
+		PayloadMapping:     encodeAlarmPayloadMapping(a.PayloadMapping),
+		SignatureTolerance: normalizeSignatureTolerance(a.SignatureTolerance),
 	}
 
 	if err := repository.AddAlarmDAO(alarm); err != nil {
@@ -222,6 +217,9 @@ func UpdateAlarmServ(id int, a AlarmReq) error {
 		return fmt.Errorf("failed to validate monitor: %w", err)
 	}
 	monitorID := a.MonitorID
+	if err := validateAlarmPayloadMapping(a.PayloadMapping); err != nil {
+		return err
+	}
 
 	existing, err := GetAlarmByIDServ(uint(id))
 	if err != nil {
@@ -231,19 +229,31 @@ func UpdateAlarmServ(id int, a AlarmReq) error {
 	if eventToken == "" {
 		eventToken = existing.EventToken
 	}
+	payloadMapping := a.PayloadMapping
+	if a.ClearPayloadMapping {
+		payloadMapping = nil
+	} else if payloadMapping == nil {
+		payloadMapping = existing.PayloadMapping
+	}
+	signatureTolerance := a.SignatureTolerance
+	if signatureTolerance == 0 {
+		signatureTolerance = existing.SignatureTolerance
+	}
 	updated := model.Alarm{
-		MonitorID:         &monitorID,
-		Name:              a.Name,
-		URL:               a.URL,
-		Username:          a.Username,
-		Password:          a.Password,
-		AuthToken:         a.AuthToken,
-		EventToken:        eventToken,
-		Description:       a.Description,
-		Type:              a.Type,
-		Enabled:           a.Enabled,
-		Status:            existing.Status,
-		StatusDescription: existing.StatusDesc,
+		MonitorID:          &monitorID,
+		Name:               a.Name,
+		URL:                a.URL,
+		Username:           a.Username,
+		Password:           a.Password,
+		AuthToken:          a.AuthToken,
+		EventToken:         eventToken,
+		Description:        a.Description,
+		Type:               a.Type,
+		Enabled:            a.Enabled,
+		Status:             existing.Status,
+		StatusDescription:  existing.StatusDesc,
+		PayloadMapping:     encodeAlarmPayloadMapping(payloadMapping),
+		SignatureTolerance: normalizeSignatureTolerance(signatureTolerance),
 	}
 	if err := repository.UpdateAlarmDAO(id, updated); err != nil {
 		return err
@@ -400,18 +410,21 @@ func alarmToResp(a model.Alarm) AlarmResp {
 		monitorID = *a.MonitorID
 	}
 	return AlarmResp{
-		ID:          int(a.ID),
-		MonitorID:   monitorID,
-		Name:        a.Name,
-		URL:         a.URL,
-		Username:    a.Username,
-		Password:    a.Password,
-		AuthToken:   a.AuthToken,
-		EventToken:  a.EventToken,
-		Description: a.Description,
-		Type:        a.Type,
-		Enabled:     a.Enabled,
-		Status:      a.Status,
-		StatusDesc:  a.StatusDescription,
+		ID:                 int(a.ID),
+		MonitorID:          monitorID,
+		Name:               a.Name,
+		URL:                a.URL,
+		Username:           a.Username,
+		Password:           a.Password,
+		AuthToken:          a.AuthToken,
+		EventToken:         a.EventToken,
+		Description:        a.Description,
+		Type:               a.Type,
+		Enabled:            a.Enabled,
+		Status:             a.Status,
+		StatusDesc:         a.StatusDescription,
+		PayloadMapping:     decodeAlarmPayloadMapping(a.PayloadMapping),
+		SigningEnabled:     a.SigningSecret != "",
+		SignatureTolerance: a.SignatureTolerance,
 	}
 }
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"nagare/internal/model"
+)
+
+// AlarmPayloadMapping describes how a generic webhook payload becomes an alert.
+// Every field is a JSONPath-style expression such as "$.alert.title" or "$.tags[0]['host name']";
+// values that do not start with "$" are used as literals.
+type AlarmPayloadMapping struct {
+	Message     string         `json:"message"`
+	Severity    string         `json:"severity"`
+	ExternalID  string         `json:"external_id"`
+	Status      string         `json:"status"`
+	Host        string         `json:"host"`
+	Item        string         `json:"item"`
+	Comment     string         `json:"comment"`
+	SeverityMap map[string]int `json:"severity_map"` // source value -> 0-5, e.g. {"down": 5}
+	StatusMap   map[string]int `json:"status_map"`   // source value -> 0 active, 1 acknowledged, 2 resolved
+}
+
+// AlarmMappingTestReq is the body of a mapping preview
+type AlarmMappingTestReq struct {
+	Payload interface{}          `json:"payload" binding:"required"`
+	Mapping *AlarmPayloadMapping `json:"mapping"` // optional; defaults to the alarm's saved mapping
+}
+
+// AlarmMappingResult is the alert a payload maps to
+type AlarmMappingResult struct {
+	Alert    AlertReq `json:"alert"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ApplyAlarmPayloadMappingServ evaluates a mapping against a decoded webhook payload
+func ApplyAlarmPayloadMappingServ(mapping AlarmPayloadMapping, payload interface{}, alarmID uint) (AlarmMappingResult, error) {
+	result := AlarmMappingResult{}
+	resolve := func(field, expr string) string {
+		if strings.TrimSpace(expr) == "" {
+			return ""
+		}
+		value, ok, err := evaluatePayloadPath(payload, expr)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", field, err))
+			return ""
+		}
+		if !ok {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %s did not match the payload", field, expr))
+			return ""
+		}
+		return value
+	}
+
+	message := resolve("message", mapping.Message)
+	if message == "" {
+		return result, fmt.Errorf("%w: mapped message is empty", model.ErrInvalidInput)
+	}
+	message = truncateRunes(message, 2048)
+
+	severity := 0
+	if raw := resolve("severity", mapping.Severity); raw != "" {
+		mapped, ok := mapSeverityValue(raw, mapping.SeverityMap)
+		if !ok {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("severity: unrecognized value %q", raw))
+		}
+		severity = mapped
+	}
+
+	status := 0
+	if raw := resolve("status", mapping.Status); raw != "" {
+		status = mapStatusValue(raw, mapping.StatusMap)
+	}
+
+	result.Alert = AlertReq{
+		Message:    message,
+		ExternalID: resolve("external_id", mapping.ExternalID),
+		Severity:   severity,
+		Status:     status,
+		AlarmID:    alarmID,
+		HostName:   resolve("host", mapping.Host),
+		ItemName:   resolve("item", mapping.Item),
+		Comment:    resolve("comment", mapping.Comment),
+	}
+	return result, nil
+}
+
+// TestAlarmPayloadMappingServ previews the alert a sample payload would create without persisting it
+func TestAlarmPayloadMappingServ(alarmID uint, req AlarmMappingTestReq) (AlarmMappingResult, error) {
+	alarm, err := GetAlarmByIDServ(alarmID)
+	if err != nil {
+		return AlarmMappingResult{}, err
+	}
+	mapping := req.Mapping
+	if mapping == nil {
+		mapping = alarm.PayloadMapping
+	}
+	if mapping == nil {
+		return AlarmMappingResult{}, fmt.Errorf("%w: alarm has no payload mapping", model.ErrInvalidInput)
+	}
+	if err := validateAlarmPayloadMapping(mapping); err != nil {
+		return AlarmMappingResult{}, err
+	}
+	return ApplyAlarmPayloadMappingServ(*mapping, req.Payload, alarmID)
+}
+
+func validateAlarmPayloadMapping(mapping *AlarmPayloadMapping) error {
+	if mapping == nil {
+		return nil
+	}
+	if strings.TrimSpace(mapping.Message) == "" {
+		return fmt.Errorf("%w: payload mapping requires a message expression", model.ErrInvalidInput)
+	}
+	fields := map[string]string{
+		"message":     mapping.Message,
+		"severity":    mapping.Severity,
+		"external_id": mapping.ExternalID,
+		"status":      mapping.Status,
+		"host":        mapping.Host,
+		"item":        mapping.Item,
+		"comment":     mapping.Comment,
+	}
+	for field, expr := range fields {
+		if !strings.HasPrefix(strings.TrimSpace(expr), "$") {
+			continue
+		}
+		if _, err := parsePayloadPath(expr); err != nil {
+			return fmt.Errorf("%w: %s: %v", model.ErrInvalidInput, field, err)
+		}
+	}
+	for value, severity := range mapping.SeverityMap {
+		if severity < 0 || severity > 5 {
+			return fmt.Errorf("%w: severity_map[%q] must be between 0 and 5", model.ErrInvalidInput, value)
+		}
+	}
+	for value, status := range mapping.StatusMap {
+		if status < 0 || status > 2 {
+			return fmt.Errorf("%w: status_map[%q] must be 0, 1 or 2", model.ErrInvalidInput, value)
+		}
+	}
+	return nil
+}
+
+func encodeAlarmPayloadMapping(mapping *AlarmPayloadMapping) string {
+	if mapping == nil {
+		return ""
+	}
+	data, err := json.Marshal(mapping)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func decodeAlarmPayloadMapping(raw string) *AlarmPayloadMapping {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	var mapping AlarmPayloadMapping
+	if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+		return nil
+	}
+	return &mapping
+}
+
+func mapSeverityValue(raw string, severityMap map[string]int) (int, bool) {
+	for value, severity := range severityMap {
+		if strings.EqualFold(strings.TrimSpace(value), strings.TrimSpace(raw)) {
+			return severity, true
+		}
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(raw)); err == nil && n >= 0 && n <= 5 {
+		return n, true
+	}
+	return parseSeverityName(raw)
+}
+
+func mapStatusValue(raw string, statusMap map[string]int) int {
+	for value, status := range statusMap {
+		if strings.EqualFold(strings.TrimSpace(value), strings.TrimSpace(raw)) {
+			return status
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "resolved", "ok", "recovered", "up", "closed":
+		return 2
+	case "acknowledged", "ack":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// payloadPathStep is one segment of a parsed path: an object key or an array index
+type payloadPathStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// evaluatePayloadPath resolves an expression against a decoded JSON document and renders the value as text
+func evaluatePayloadPath(doc interface{}, expr string) (string, bool, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "$") {
+		return expr, true, nil
+	}
+	steps, err := parsePayloadPath(expr)
+	if err != nil {
+		return "", false, err
+	}
+
+	current := doc
+	for _, step := range steps {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			if step.isIndex {
+				return "", false, nil
+			}
+			value, ok := node[step.key]
+			if !ok {
+				return "", false, nil
+			}
+			current = value
+		case []interface{}:
+			if !step.isIndex {
+				return "", false, nil
+			}
+			idx := step.index
+			if idx < 0 {
+				idx += len(node)
+			}
+			if idx < 0 || idx >= len(node) {
+				return "", false, nil
+			}
+			current = node[idx]
+		default:
+			return "", false, nil
+		}
+	}
+	return payloadValueString(current), true, nil
+}
+
+// parsePayloadPath supports $, .key, ['key'], ["key"] and [n] (negative n counts from the end)
+func parsePayloadPath(expr string) ([]payloadPathStep, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("path must start with $")
+	}
+	rest := expr[1:]
+	steps := make([]payloadPathStep, 0, 4)
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			if key == "" {
+				return nil, fmt.Errorf("empty key in %q", expr)
+			}
+			steps = append(steps, payloadPathStep{key: key})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed bracket in %q", expr)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				steps = append(steps, payloadPathStep{key: inner[1 : len(inner)-1]})
+				continue
+			}
+			idx, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("invalid index %q in %q", inner, expr)
+			}
+			steps = append(steps, payloadPathStep{index: idx, isIndex: true})
+		default:
+			return nil, fmt.Errorf("unexpected %q in %q", rest[0], expr)
+		}
+	}
+	return steps, nil
+}
+
+func payloadValueString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
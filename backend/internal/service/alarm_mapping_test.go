@@ -0,0 +1,127 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParsePayloadPath(t *testing.T) {
+	cases := []struct {
+		expr    string
+		want    []payloadPathStep
+		wantErr bool
+	}{
+		{expr: "$", want: nil},
+		{expr: "$.alert.title", want: []payloadPathStep{{key: "alert"}, {key: "title"}}},
+		{expr: "$.tags[0]['host name']", want: []payloadPathStep{{key: "tags"}, {index: 0, isIndex: true}, {key: "host name"}}},
+		{expr: `$["a.b"][-1]`, want: []payloadPathStep{{key: "a.b"}, {index: -1, isIndex: true}}},
+		{expr: "alert.title", wantErr: true},
+		{expr: "$.", wantErr: true},
+		{expr: "$..a", wantErr: true},
+		{expr: "$[0", wantErr: true},
+		{expr: "$['a", wantErr: true},
+		{expr: "$[abc]", wantErr: true},
+		{expr: "$a", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		got, err := parsePayloadPath(tc.expr)
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("parsePayloadPath(%q) expected error, got %+v", tc.expr, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("parsePayloadPath(%q) unexpected error: %v", tc.expr, err)
+		}
+		if len(got) != len(tc.want) || (len(got) > 0 && !reflect.DeepEqual(got, tc.want)) {
+			t.Fatalf("parsePayloadPath(%q) = %+v, want %+v", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestEvaluatePayloadPath(t *testing.T) {
+	var doc interface{}
+	raw := `{"alert":{"title":" Disk full ","level":3,"ok":true,"labels":{"host":"db01"}},"tags":["a","b"],"empty":null}`
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		t.Fatalf("decode fixture: %v", err)
+	}
+
+	cases := []struct {
+		expr   string
+		want   string
+		wantOK bool
+	}{
+		{expr: "$.alert.title", want: "Disk full", wantOK: true},
+		{expr: "$.alert.level", want: "3", wantOK: true},
+		{expr: "$.alert.ok", want: "true", wantOK: true},
+		{expr: "$.alert.labels", want: `{"host":"db01"}`, wantOK: true},
+		{expr: "$.tags[1]", want: "b", wantOK: true},
+		{expr: "$.tags[-1]", want: "b", wantOK: true},
+		{expr: "$.tags[5]", want: "", wantOK: false},
+		{expr: "$.alert.missing", want: "", wantOK: false},
+		{expr: "$.empty", want: "", wantOK: true},
+		{expr: "static text", want: "static text", wantOK: true},
+	}
+
+	for _, tc := range cases {
+		got, ok, err := evaluatePayloadPath(doc, tc.expr)
+		if err != nil {
+			t.Fatalf("evaluatePayloadPath(%q) unexpected error: %v", tc.expr, err)
+		}
+		if got != tc.want || ok != tc.wantOK {
+			t.Fatalf("evaluatePayloadPath(%q) = (%q, %v), want (%q, %v)", tc.expr, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestMapSeverityValue(t *testing.T) {
+	mapping := map[string]int{"Sev1": 5, "minor": 1}
+
+	cases := []struct {
+		raw    string
+		want   int
+		wantOK bool
+	}{
+		{raw: "sev1", want: 5, wantOK: true},
+		{raw: " MINOR ", want: 1, wantOK: true},
+		{raw: "4", want: 4, wantOK: true},
+		{raw: "9", want: 0, wantOK: false},
+		{raw: "critical", want: 5, wantOK: true},
+		{raw: "warning", want: 2, wantOK: true},
+		{raw: "unknown", want: 0, wantOK: false},
+	}
+
+	for _, tc := range cases {
+		got, ok := mapSeverityValue(tc.raw, mapping)
+		if got != tc.want || ok != tc.wantOK {
+			t.Fatalf("mapSeverityValue(%q) = (%d, %v), want (%d, %v)", tc.raw, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestMapStatusValue(t *testing.T) {
+	mapping := map[string]int{"done": 2, "ok": 0}
+
+	cases := []struct {
+		raw  string
+		want int
+	}{
+		{raw: "Done", want: 2},
+		{raw: "ok", want: 0},
+		{raw: "resolved", want: 2},
+		{raw: "RECOVERED", want: 2},
+		{raw: "ack", want: 1},
+		{raw: "acknowledged", want: 1},
+		{raw: "firing", want: 0},
+		{raw: "", want: 0},
+	}
+
+	for _, tc := range cases {
+		if got := mapStatusValue(tc.raw, mapping); got != tc.want {
+			t.Fatalf("mapStatusValue(%q) = %d, want %d", tc.raw, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"nagare/internal/model"
+	"nagare/internal/repository"
+	"nagare/internal/service/utils"
+)
+
+const (
+	// AlarmSignatureHeader carries "sha256=<hex HMAC-SHA256 of timestamp.body>"
+	AlarmSignatureHeader = "X-Nagare-Signature"
+	// AlarmTimestampHeader carries the unix time the sender signed the request at
+	AlarmTimestampHeader = "X-Nagare-Timestamp"
+
+	defaultSignatureToleranceSeconds = 300
+	maxSignatureToleranceSeconds     = 86400
+)
+
+func normalizeSignatureTolerance(seconds int) int {
+	if seconds <= 0 {
+		return defaultSignatureToleranceSeconds
+	}
+	if seconds > maxSignatureToleranceSeconds {
+		return maxSignatureToleranceSeconds
+	}
+	return seconds
+}
+
+// GenerateAlarmSigningSecretServ creates a new webhook signing secret; the plain secret is only returned here
+func GenerateAlarmSigningSecretServ(id uint) (AlarmResp, error) {
+	if _, err := repository.GetAlarmByIDDAO(id); err != nil {
+		return AlarmResp{}, err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return AlarmResp{}, fmt.Errorf("failed to generate signing secret: %w", err)
+	}
+	secret := hex.EncodeToString(b)
+	encrypted, err := utils.Encrypt(secret)
+	if err != nil {
+		return AlarmResp{}, fmt.Errorf("failed to encrypt signing secret: %w", err)
+	}
+	if err := repository.UpdateAlarmSigningSecretDAO(id, encrypted); err != nil {
+		return AlarmResp{}, fmt.Errorf("failed to save signing secret: %w", err)
+	}
+
+	alarm, err := GetAlarmByIDServ(id)
+	if err != nil {
+		return AlarmResp{}, fmt.Errorf("failed to retrieve updated alarm: %w", err)
+	}
+	alarm.SigningSecret = secret
+	return alarm, nil
+}
+
+// DisableAlarmSigningServ removes the signing secret so the alarm falls back to event token authentication
+func DisableAlarmSigningServ(id uint) error {
+	if _, err := repository.GetAlarmByIDDAO(id); err != nil {
+		return err
+	}
+	return repository.UpdateAlarmSigningSecretDAO(id, "")
+}
+
+// VerifyAlarmWebhookSignatureServ checks the HMAC-SHA256 signature of a webhook body.
+// Alarms without a signing secret are accepted on their event token alone.
+func VerifyAlarmWebhookSignatureServ(alarmID uint, body []byte, signature, timestamp string) error {
+	if alarmID == 0 {
+		return nil
+	}
+	alarm, err := repository.GetAlarmByIDDAO(alarmID)
+	if errors.Is(err, model.ErrNotFound) {
+		// Tokens may resolve to a monitor instead of an alarm; those have no signing secret
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load alarm for signature check: %w", err)
+	}
+	if alarm.SigningSecret == "" {
+		return nil
+	}
+
+	secret, err := utils.Decrypt(alarm.SigningSecret)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt signing secret: %w", err)
+	}
+	return checkAlarmSignature(secret, alarm.SignatureTolerance, body, signature, timestamp, time.Now())
+}
+
+// checkAlarmSignature validates the signature headers against secret at the given time
+func checkAlarmSignature(secret string, toleranceSeconds int, body []byte, signature, timestamp string, now time.Time) error {
+	signature = strings.TrimSpace(signature)
+	timestamp = strings.TrimSpace(timestamp)
+	if signature == "" || timestamp == "" {
+		return fmt.Errorf("%w: missing webhook signature", model.ErrUnauthorized)
+	}
+
+	signedAt, err := parseSignatureTimestamp(timestamp)
+	if err != nil {
+		return fmt.Errorf("%w: invalid signature timestamp", model.ErrUnauthorized)
+	}
+	tolerance := time.Duration(normalizeSignatureTolerance(toleranceSeconds)) * time.Second
+	if skew := now.Sub(signedAt); skew > tolerance || skew < -tolerance {
+		return fmt.Errorf("%w: signature timestamp outside tolerance", model.ErrUnauthorized)
+	}
+
+	provided, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return fmt.Errorf("%w: malformed webhook signature", model.ErrUnauthorized)
+	}
+	if !hmac.Equal(provided, computeAlarmSignature(secret, timestamp, body)) {
+		return fmt.Errorf("%w: webhook signature mismatch", model.ErrUnauthorized)
+	}
+	return nil
+}
+
+// computeAlarmSignature signs "<timestamp>.<body>" so a captured body cannot be replayed with a new timestamp
+func computeAlarmSignature(secret, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// parseSignatureTimestamp accepts unix seconds or milliseconds
+func parseSignatureTimestamp(raw string) (time.Time, error) {
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if value > 1_000_000_000_000 {
+		return time.UnixMilli(value), nil
+	}
+	return time.Unix(value, 0), nil
+}
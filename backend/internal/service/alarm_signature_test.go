@@ -0,0 +1,103 @@
+package service
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"nagare/internal/model"
+)
+
+func TestComputeAlarmSignature(t *testing.T) {
+	// HMAC-SHA256 of "1700000000.{}" keyed with "secret"
+	const want = "b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163"
+	got := hex.EncodeToString(computeAlarmSignature("secret", "1700000000", []byte("{}")))
+	if got != want {
+		t.Fatalf("computeAlarmSignature = %s, want %s", got, want)
+	}
+
+	cases := []struct {
+		name      string
+		secret    string
+		timestamp string
+		body      string
+	}{
+		{name: "different secret", secret: "other", timestamp: "1700000000", body: "{}"},
+		{name: "different timestamp", secret: "secret", timestamp: "1700000001", body: "{}"},
+		{name: "different body", secret: "secret", timestamp: "1700000000", body: `{"a":1}`},
+		{name: "separator moved", secret: "secret", timestamp: "170000000", body: "0.{}"},
+	}
+
+	for _, tc := range cases {
+		other := hex.EncodeToString(computeAlarmSignature(tc.secret, tc.timestamp, []byte(tc.body)))
+		if other == got {
+			t.Fatalf("%s: expected a different signature", tc.name)
+		}
+	}
+}
+
+func TestCheckAlarmSignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"message":"disk full"}`)
+	sign := func(ts string) string {
+		return "sha256=" + hex.EncodeToString(computeAlarmSignature("secret", ts, body))
+	}
+	at := func(offset time.Duration) string {
+		return strconv.FormatInt(now.Add(offset).Unix(), 10)
+	}
+
+	cases := []struct {
+		name      string
+		tolerance int
+		timestamp string
+		signature string
+		wantErr   bool
+	}{
+		{name: "fresh", timestamp: at(0), signature: sign(at(0))},
+		{name: "without prefix", timestamp: at(0), signature: sign(at(0))[len("sha256="):]},
+		{name: "milliseconds", timestamp: strconv.FormatInt(now.UnixMilli(), 10), signature: sign(strconv.FormatInt(now.UnixMilli(), 10))},
+		{name: "inside default window", timestamp: at(-299 * time.Second), signature: sign(at(-299 * time.Second))},
+		{name: "outside default window", timestamp: at(-301 * time.Second), signature: sign(at(-301 * time.Second)), wantErr: true},
+		{name: "future outside window", timestamp: at(301 * time.Second), signature: sign(at(301 * time.Second)), wantErr: true},
+		{name: "custom window", tolerance: 30, timestamp: at(-31 * time.Second), signature: sign(at(-31 * time.Second)), wantErr: true},
+		{name: "window capped", tolerance: 10 * maxSignatureToleranceSeconds, timestamp: at(-(maxSignatureToleranceSeconds + 1) * time.Second), signature: sign(at(-(maxSignatureToleranceSeconds + 1) * time.Second)), wantErr: true},
+		{name: "missing signature", timestamp: at(0), signature: "", wantErr: true},
+		{name: "missing timestamp", timestamp: "", signature: sign(at(0)), wantErr: true},
+		{name: "invalid timestamp", timestamp: "yesterday", signature: sign("yesterday"), wantErr: true},
+		{name: "malformed signature", timestamp: at(0), signature: "sha256=zz", wantErr: true},
+		{name: "replayed with new timestamp", timestamp: at(time.Second), signature: sign(at(0)), wantErr: true},
+	}
+
+	for _, tc := range cases {
+		err := checkAlarmSignature("secret", tc.tolerance, body, tc.signature, tc.timestamp, now)
+		if tc.wantErr {
+			if !errors.Is(err, model.ErrUnauthorized) {
+				t.Fatalf("%s: expected ErrUnauthorized, got %v", tc.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+	}
+}
+
+func TestNormalizeSignatureTolerance(t *testing.T) {
+	cases := []struct {
+		in   int
+		want int
+	}{
+		{in: -5, want: defaultSignatureToleranceSeconds},
+		{in: 0, want: defaultSignatureToleranceSeconds},
+		{in: 60, want: 60},
+		{in: maxSignatureToleranceSeconds + 1, want: maxSignatureToleranceSeconds},
+	}
+
+	for _, tc := range cases {
+		if got := normalizeSignatureTolerance(tc.in); got != tc.want {
+			t.Fatalf("normalizeSignatureTolerance(%d) = %d, want %d", tc.in, got, tc.want)
+		}
+	}
+}
@@ -1,9 +1,13 @@
 package service
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"math"
 	"math/rand"
+	"regexp"
 	"strings"
 	"time"
 
@@ -27,53 +31,68 @@ const (
 
 // AlertReq represents an alert request
 type AlertReq struct {
-	Message    string `json:"message" binding:"required"`
-	ExternalID string `json:"external_id"`
-	Severity   int    `json:"severity"`
-	Status     int    `json:"status"`
-	ItemID     uint   `json:"item_id"`
-	AlarmID    uint   `json:"alarm_id"`
-	Comment    string `json:"comment"`
-	HostName   string `json:"host_name"`
-	GroupName  string `json:"group_name"`
-	ItemName   string `json:"item_name"`
+	Message     string            `json:"message" binding:"required"`
+	ExternalID  string            `json:"external_id"`
+	Severity    int               `json:"severity"`
+	Status      int               `json:"status"`
+	ItemID      uint              `json:"item_id"`
+	AlarmID     uint              `json:"alarm_id"`
+	Comment     string            `json:"comment"`
+	HostName    string            `json:"host_name"`
+	GroupName   string            `json:"group_name"`
+	ItemName    string            `json:"item_name"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // AlertRes represents an alert response
 type AlertRes struct {
-	ID          int       `json:"id"`
-	Message     string    `json:"message"`
-	ExternalID  string    `json:"external_id"`
-	Severity    int       `json:"severity"`
-	Status      int       `json:"status"`
-	ItemID      uint      `json:"item_id"`
-	AlarmID     uint      `json:"alarm_id"`
-	HostID      uint      `json:"host_id,omitempty"`
-	GroupID     uint      `json:"group_id,omitempty"`
-	MonitorID   uint      `json:"monitor_id,omitempty"`
-	Comment     string    `json:"comment"`
-	HostName    string    `json:"host_name"`
-	GroupName   string    `json:"group_name"`
-	MonitorName string    `json:"monitor_name"`
-	ItemName    string    `json:"item_name"`
-	AlarmName   string    `json:"alarm_name"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID              int               `json:"id"`
+	Message         string            `json:"message"`
+	ExternalID      string            `json:"external_id"`
+	Severity        int               `json:"severity"`
+	Status          int               `json:"status"`
+	ItemID          uint              `json:"item_id"`
+	AlarmID         uint              `json:"alarm_id"`
+	HostID          uint              `json:"host_id,omitempty"`
+	GroupID         uint              `json:"group_id,omitempty"`
+	MonitorID       uint              `json:"monitor_id,omitempty"`
+	Comment         string            `json:"comment"`
+	HostName        string            `json:"host_name"`
+	GroupName       string            `json:"group_name"`
+	MonitorName     string            `json:"monitor_name"`
+	ItemName        string            `json:"item_name"`
+	AlarmName       string            `json:"alarm_name"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Annotations     map[string]string `json:"annotations,omitempty"`
+	OccurrenceCount int               `json:"occurrence_count"`
+	LastSeenAt      *time.Time        `json:"last_seen_at,omitempty"`
+	IncidentID      uint              `json:"incident_id,omitempty"`
+	EscalationLevel int               `json:"escalation_level"`
+	SilenceID       uint              `json:"silence_id,omitempty"`
+	Suppressed      bool              `json:"suppressed"`
+	CreatedAt       time.Time         `json:"created_at"`
 }
 
 func buildAlertRes(alert repository.AlertWithContext) AlertRes {
 	alertRes := AlertRes{
-		ID:          int(alert.ID),
-		Message:     alert.Message,
-		ExternalID:  alert.ExternalID,
-		Severity:    alert.Severity,
-		Status:      alert.Status,
-		Comment:     alert.Comment,
-		HostName:    alert.HostName,
-		GroupName:   alert.GroupName,
-		MonitorName: alert.MonitorName,
-		ItemName:    alert.ItemName,
-		AlarmName:   alert.AlarmName,
-		CreatedAt:   alert.CreatedAt,
+		ID:              int(alert.ID),
+		Message:         alert.Message,
+		ExternalID:      alert.ExternalID,
+		Severity:        alert.Severity,
+		Status:          alert.Status,
+		Comment:         alert.Comment,
+		HostName:        alert.HostName,
+		GroupName:       alert.GroupName,
+		MonitorName:     alert.MonitorName,
+		ItemName:        alert.ItemName,
+		AlarmName:       alert.AlarmName,
+		Labels:          decodeAlertStringMap(alert.Labels),
+		Annotations:     decodeAlertStringMap(alert.Annotations),
+		OccurrenceCount: alert.OccurrenceCount,
+		LastSeenAt:      alert.LastSeenAt,
+		EscalationLevel: alert.EscalationLevel,
+		CreatedAt:       alert.CreatedAt,
 	}
 	if alert.HostID != nil {
 		alertRes.HostID = *alert.HostID
@@ -90,6 +109,13 @@ func buildAlertRes(alert repository.AlertWithContext) AlertRes {
 	if alert.AlarmID != nil {
 		alertRes.AlarmID = *alert.AlarmID
 	}
+	if alert.IncidentID != nil {
+		alertRes.IncidentID = *alert.IncidentID
+	}
+	if alert.SilenceID != nil {
+		alertRes.SilenceID = *alert.SilenceID
+		alertRes.Suppressed = true
+	}
 	return alertRes
 }
 
@@ -167,12 +193,36 @@ func AddAlertServ(req AlertReq) error {
 		}
 	}
 
+	fingerprint := alertFingerprint(req.AlarmID, req.ExternalID, itemID, req.Message)
+	now := time.Now()
+	if req.Status != 2 {
+		existing, err := repository.FindUnresolvedAlertByFingerprintHashDAO(fingerprint)
+		if err != nil {
+			return err
+		}
+		if existing.ID != 0 {
+			if err := repository.IncrementAlertOccurrenceDAO(existing.ID, now); err != nil {
+				return err
+			}
+			LogService("info", "repeated alert folded into active alert", map[string]interface{}{
+				"alert_id":    existing.ID,
+				"occurrences": existing.OccurrenceCount + 1,
+			}, nil, "")
+			return nil
+		}
+	}
+
 	alert := model.Alert{
-		Message:    req.Message,
-		ExternalID: strings.TrimSpace(req.ExternalID),
-		Severity:   req.Severity,
-		Status:     req.Status,
-		Comment:    req.Comment,
+		Message:         req.Message,
+		ExternalID:      strings.TrimSpace(req.ExternalID),
+		Severity:        req.Severity,
+		Status:          req.Status,
+		Comment:         req.Comment,
+		Labels:          encodeAlertStringMap(req.Labels),
+		Annotations:     encodeAlertStringMap(req.Annotations),
+		Fingerprint:     fingerprint,
+		OccurrenceCount: 1,
+		LastSeenAt:      &now,
 	}
 	if req.AlarmID > 0 {
 		alarmID := req.AlarmID
@@ -193,6 +243,14 @@ func AddAlertServ(req AlertReq) error {
 		"item_id":  alert.ItemID,
 	}, nil, "")
 
+	// Alerts inside a maintenance window or silence are kept but page nobody
+	alert = applyAlertSilences(alert, req.HostName)
+	if alert.SilenceID != nil {
+		return nil
+	}
+
+	alert = correlateAlert(alert, req.HostName)
+
 	_ = CreateSiteMessageServ(alert.Message, alert.Comment, "alert", alert.Severity, nil)
 
 	LogService("info", "triggering async analysis and notification", map[string]interface{}{"alert_id": alert.ID}, nil, "")
@@ -234,19 +292,23 @@ func ResolveLatestAlertByEventServ(alarmID uint, eventID string, comment string)
 		"alarm_id": alarmID,
 		"event_id": strings.TrimSpace(eventID),
 	}, nil, "")
+	alert.Status = 2
+	alert.Comment = mergedComment
+	onAlertResolved(alert)
 
 	return true, nil
 }
 
-// ResolveActiveAlertByExternalIDServ resolves the newest unresolved alert by external_id.
+// ResolveActiveAlertByExternalIDServ resolves the newest unresolved alert by external_id, limited to the
+// alerts of alarmID when it is not nil.
 // Returns true when an alert was resolved, false when no unresolved match exists.
-func ResolveActiveAlertByExternalIDServ(externalID string, comment string) (bool, error) {
+func ResolveActiveAlertByExternalIDServ(externalID string, alarmID *uint, comment string) (bool, error) {
 	externalID = strings.TrimSpace(externalID)
 	if externalID == "" {
 		return false, nil
 	}
 
-	alert, err := repository.FindLatestUnresolvedAlertByExternalIDDAO(externalID)
+	alert, err := repository.FindLatestUnresolvedAlertByExternalIDDAO(externalID, alarmID)
 	if err != nil {
 		return false, err
 	}
@@ -272,10 +334,51 @@ func ResolveActiveAlertByExternalIDServ(externalID string, comment string) (bool
 		"alert_id":    alert.ID,
 		"external_id": externalID,
 	}, nil, "")
+	alert.Status = 2
+	alert.Comment = mergedComment
+	onAlertResolved(alert)
 
 	return true, nil
 }
 
+var (
+	alertFingerprintWhitespace = regexp.MustCompile(`\s+`)
+	// Timestamps and measured values change between deliveries of the same problem
+	alertFingerprintVolatile = regexp.MustCompile(`\d{4}[-./]\d{2}[-./]\d{2}([ t]\d{2}:\d{2}(:\d{2})?)?|\b\d{1,2}:\d{2}(:\d{2})?\b|\b\d+\.\d+\b`)
+)
+
+// alertFingerprint identifies repeated deliveries of the same problem
+func alertFingerprint(alarmID uint, externalID string, itemID uint, message string) string {
+	normalized := strings.ToLower(strings.TrimSpace(message))
+	normalized = alertFingerprintVolatile.ReplaceAllString(normalized, "#")
+	normalized = alertFingerprintWhitespace.ReplaceAllString(normalized, " ")
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d|%s|%d|%s", alarmID, strings.TrimSpace(externalID), itemID, normalized)))
+	return hex.EncodeToString(sum[:])
+}
+
+// encodeAlertStringMap stores source labels/annotations as a JSON object; empty maps are stored as ""
+func encodeAlertStringMap(values map[string]string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func decodeAlertStringMap(raw string) map[string]string {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	values := map[string]string{}
+	if err := json.Unmarshal([]byte(raw), &values); err != nil {
+		return nil
+	}
+	return values
+}
+
 func analyzeAndNotifyAlert(alert model.Alert) {
 	LogService("info", "starting alert analysis and notification", map[string]interface{}{"alert_id": alert.ID}, nil, "")
 
@@ -289,6 +392,11 @@ func analyzeAndNotifyAlert(alert model.Alert) {
 		ExecuteActionsForAlert(alert)
 		return
 	}
+	if skipAlertAnalysisForBudget(alert.Severity) {
+		LogService("info", "alert analysis skipped (AI token budget exceeded)", map[string]interface{}{"alert_id": alert.ID, "severity": alert.Severity, "min_severity": llmBudgetConfig().DegradedMinSeverity}, nil, "")
+		ExecuteActionsForAlert(alert)
+		return
+	}
 
 	LogService("info", "performing AI alert analysis", map[string]interface{}{"alert_id": alert.ID}, nil, "")
 	analysis, err := analyzeAlertWithAI(alert)
@@ -348,10 +456,8 @@ func parseAIAlertDecision(analysis string) (bool, string) {
 }
 
 func analyzeAlertWithAI(alert model.Alert) (string, error) {
-	providerID, model := aiProviderConfig()
-	client, resolvedModel, err := createLLMClient(providerID, model)
+	routes, err := resolveLLMRoutes(nil, 0, "")
 	if err != nil {
-		_ = repository.UpdateProviderStatusDAO(providerID, 2)
 		return "", err
 	}
 
@@ -370,7 +476,6 @@ func analyzeAlertWithAI(alert model.Alert) (string, error) {
 
 	ctx, cancel := aiAnalysisContext()
 	defer cancel()
-	start := time.Now()
 
 	// Use RAG to fetch context from local knowledge base
 	localContext := RetrieveContext(alert.Message)
@@ -396,19 +501,15 @@ func analyzeAlertWithAI(alert model.Alert) (string, error) {
 	lang := aiLanguage()
 	isCn := isChinese(lang)
 
-	resp, err := client.Chat(ctx, llm.ChatRequest{
-		Model:        resolvedModel,
+	resp, _, err := routedChat(ctx, "alert_analysis", nil, routes, llm.ChatRequest{
 		SystemPrompt: alertAnalysisPrompt(isCn),
 		Messages: []llm.Message{
 			{Role: "user", Content: alertData},
 		},
 	})
-	logLLMRequest("alert_analysis", providerID, resolvedModel, time.Since(start), err)
 	if err != nil {
-		_ = repository.UpdateProviderStatusDAO(providerID, 2)
 		return "", err
 	}
-	_ = repository.UpdateProviderStatusDAO(providerID, 1)
 	return strings.TrimSpace(resp.Content), nil
 }
 
@@ -510,7 +611,20 @@ func UpdateAlertServ(id int, req AlertReq) error {
 		updatedAlert.ItemID = &iID
 	}
 
-	return repository.UpdateAlertDAO(id, updatedAlert)
+	if err := repository.UpdateAlertDAO(id, updatedAlert); err != nil {
+		return err
+	}
+	if status == 2 && alert.Status != 2 {
+		alert.Status = 2
+		onAlertResolved(alert)
+	}
+	return nil
+}
+
+// onAlertResolved runs the follow-ups of an alert transitioning to resolved
+func onAlertResolved(alert model.Alert) {
+	syncIncidentAfterAlertResolved(alert.IncidentID)
+	go notifyAlertRecovery(alert)
 }
 
 // GenerateTestAlerts generates simulated alerts for testing
@@ -0,0 +1,40 @@
+package service
+
+import "testing"
+
+func TestAlertFingerprint(t *testing.T) {
+	base := alertFingerprint(1, "ext-1", 7, "High CPU Usage: 91.25% on web01")
+	if len(base) != 64 {
+		t.Fatalf("expected a hex sha256 fingerprint, got %q", base)
+	}
+
+	cases := []struct {
+		name       string
+		alarmID    uint
+		externalID string
+		itemID     uint
+		message    string
+		same       bool
+	}{
+		{name: "identical", alarmID: 1, externalID: "ext-1", itemID: 7, message: "High CPU Usage: 91.25% on web01", same: true},
+		{name: "measured value changes", alarmID: 1, externalID: "ext-1", itemID: 7, message: "High CPU Usage: 97.03% on web01", same: true},
+		{name: "case and whitespace", alarmID: 1, externalID: " ext-1 ", itemID: 7, message: "  high cpu   usage: 91.25%  ON web01 ", same: true},
+		{name: "different alarm", alarmID: 2, externalID: "ext-1", itemID: 7, message: "High CPU Usage: 91.25% on web01"},
+		{name: "different external id", alarmID: 1, externalID: "ext-2", itemID: 7, message: "High CPU Usage: 91.25% on web01"},
+		{name: "different item", alarmID: 1, externalID: "ext-1", itemID: 8, message: "High CPU Usage: 91.25% on web01"},
+		{name: "different host", alarmID: 1, externalID: "ext-1", itemID: 7, message: "High CPU Usage: 91.25% on web02"},
+		{name: "different severity wording", alarmID: 1, externalID: "ext-1", itemID: 7, message: "Critical CPU Usage: 91.25% on web01"},
+	}
+
+	for _, tc := range cases {
+		got := alertFingerprint(tc.alarmID, tc.externalID, tc.itemID, tc.message)
+		if (got == base) != tc.same {
+			t.Fatalf("%s: fingerprint equal to base = %v, want %v", tc.name, got == base, tc.same)
+		}
+	}
+
+	stamped := alertFingerprint(1, "", 0, "Backup failed at 2024-05-01 10:15:00")
+	if other := alertFingerprint(1, "", 0, "Backup failed at 2024-05-02 03:00:59"); other != stamped {
+		t.Fatalf("expected timestamps to be ignored, got %q and %q", stamped, other)
+	}
+}
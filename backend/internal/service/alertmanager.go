@@ -0,0 +1,244 @@
+package service
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"nagare/internal/model"
+	"nagare/internal/repository"
+)
+
+// AlarmTypeAlertmanager marks alarms that receive Prometheus Alertmanager webhooks
+const AlarmTypeAlertmanager = 4
+
+const alertmanagerDefaultSeverity = 3
+
+// AlertmanagerWebhookPayload is the Alertmanager webhook body (version 4)
+type AlertmanagerWebhookPayload struct {
+	Version           string              `json:"version"`
+	GroupKey          string              `json:"groupKey"`
+	TruncatedAlerts   int                 `json:"truncatedAlerts"`
+	Status            string              `json:"status"`
+	Receiver          string              `json:"receiver"`
+	GroupLabels       map[string]string   `json:"groupLabels"`
+	CommonLabels      map[string]string   `json:"commonLabels"`
+	CommonAnnotations map[string]string   `json:"commonAnnotations"`
+	ExternalURL       string              `json:"externalURL"`
+	Alerts            []AlertmanagerAlert `json:"alerts"`
+}
+
+// AlertmanagerAlert is a single alert of an Alertmanager notification group
+type AlertmanagerAlert struct {
+	Status       string            `json:"status"` // firing or resolved
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// AlertmanagerIngestResult summarizes how a webhook notification was applied
+type AlertmanagerIngestResult struct {
+	Received   int `json:"received"`
+	Created    int `json:"created"`
+	Resolved   int `json:"resolved"`
+	Duplicates int `json:"duplicates"`
+	Failed     int `json:"failed"`
+}
+
+// IngestAlertmanagerWebhookServ turns every alert of an Alertmanager notification into an alert of the given alarm.
+// Firing alerts that are already active are not duplicated; resolved alerts close the active alert with the same fingerprint.
+func IngestAlertmanagerWebhookServ(alarmID uint, payload AlertmanagerWebhookPayload) (AlertmanagerIngestResult, error) {
+	result := AlertmanagerIngestResult{Received: len(payload.Alerts)}
+	if payload.Version != "" && payload.Version != "4" {
+		return result, fmt.Errorf("%w: unsupported alertmanager webhook version %q", model.ErrInvalidInput, payload.Version)
+	}
+	if len(payload.Alerts) == 0 {
+		return result, fmt.Errorf("%w: alertmanager payload contains no alerts", model.ErrInvalidInput)
+	}
+
+	for _, am := range payload.Alerts {
+		fingerprint := strings.TrimSpace(am.Fingerprint)
+		if fingerprint == "" {
+			fingerprint = alertmanagerLabelsFingerprint(am.Labels)
+		}
+
+		status := strings.ToLower(strings.TrimSpace(am.Status))
+		if status == "" {
+			status = strings.ToLower(strings.TrimSpace(payload.Status))
+		}
+
+		if status == "resolved" {
+			comment := "Resolved by Alertmanager"
+			if !am.EndsAt.IsZero() {
+				comment = fmt.Sprintf("Resolved by Alertmanager at %s", am.EndsAt.Format(time.RFC3339))
+			}
+			resolved, err := ResolveActiveAlertByExternalIDServ(fingerprint, &alarmID, comment)
+			if err != nil {
+				result.Failed++
+				LogService("error", "failed to resolve alertmanager alert", map[string]interface{}{"alarm_id": alarmID, "fingerprint": fingerprint, "error": err.Error()}, nil, "")
+				continue
+			}
+			if resolved {
+				result.Resolved++
+			}
+			continue
+		}
+
+		existing, err := repository.FindLatestUnresolvedAlertByExternalIDDAO(fingerprint, &alarmID)
+		if err != nil {
+			result.Failed++
+			LogService("error", "failed to look up alertmanager alert", map[string]interface{}{"alarm_id": alarmID, "fingerprint": fingerprint, "error": err.Error()}, nil, "")
+			continue
+		}
+		if existing.ID != 0 {
+			// Alertmanager re-sends firing alerts on every repeat interval
+			result.Duplicates++
+			continue
+		}
+
+		if err := AddAlertServ(alertmanagerAlertToReq(alarmID, fingerprint, am, payload)); err != nil {
+			result.Failed++
+			LogService("error", "failed to create alertmanager alert", map[string]interface{}{"alarm_id": alarmID, "fingerprint": fingerprint, "error": err.Error()}, nil, "")
+			continue
+		}
+		result.Created++
+	}
+
+	LogService("info", "alertmanager webhook processed", map[string]interface{}{
+		"alarm_id":   alarmID,
+		"receiver":   payload.Receiver,
+		"received":   result.Received,
+		"created":    result.Created,
+		"resolved":   result.Resolved,
+		"duplicates": result.Duplicates,
+		"failed":     result.Failed,
+	}, nil, "")
+
+	if result.Failed > 0 && result.Failed == result.Received {
+		return result, fmt.Errorf("failed to process all %d alertmanager alerts", result.Failed)
+	}
+	return result, nil
+}
+
+func alertmanagerAlertToReq(alarmID uint, fingerprint string, am AlertmanagerAlert, payload AlertmanagerWebhookPayload) AlertReq {
+	labels := mergeAlertmanagerMaps(payload.CommonLabels, am.Labels)
+	annotations := mergeAlertmanagerMaps(payload.CommonAnnotations, am.Annotations)
+
+	alertName := labels["alertname"]
+	summary := firstNonEmpty(annotations["summary"], annotations["message"], annotations["description"])
+	message := alertName
+	switch {
+	case alertName != "" && summary != "":
+		message = alertName + ": " + summary
+	case alertName == "":
+		message = summary
+	}
+	if message == "" {
+		message = "Alertmanager alert " + fingerprint
+	}
+	message = truncateRunes(message, 2048)
+
+	commentParts := make([]string, 0, 3)
+	if description := annotations["description"]; description != "" && description != summary {
+		commentParts = append(commentParts, description)
+	}
+	if am.GeneratorURL != "" {
+		commentParts = append(commentParts, "source="+am.GeneratorURL)
+	}
+	if !am.StartsAt.IsZero() {
+		commentParts = append(commentParts, "starts_at="+am.StartsAt.Format(time.RFC3339))
+	}
+
+	return AlertReq{
+		Message:     message,
+		ExternalID:  fingerprint,
+		Severity:    alertmanagerSeverity(labels),
+		AlarmID:     alarmID,
+		HostName:    alertmanagerHostName(labels),
+		Comment:     strings.Join(commentParts, "\n"),
+		Labels:      labels,
+		Annotations: annotations,
+	}
+}
+
+// alertmanagerSeverity maps the conventional severity label to Nagare's 0-5 scale
+func alertmanagerSeverity(labels map[string]string) int {
+	if severity, ok := parseSeverityName(firstNonEmpty(labels["severity"], labels["priority"], labels["level"])); ok {
+		return severity
+	}
+	return alertmanagerDefaultSeverity
+}
+
+// parseSeverityName maps common severity names used by webhook sources to Nagare's 0-5 scale
+func parseSeverityName(raw string) (int, bool) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "critical", "disaster", "page", "p1":
+		return 5, true
+	case "high", "error", "major", "p2":
+		return 4, true
+	case "average", "medium", "minor", "p3":
+		return 3, true
+	case "warning", "warn", "p4":
+		return 2, true
+	case "info", "information", "low", "none", "p5":
+		return 1, true
+	default:
+		return 0, false
+	}
+}
+
+// alertmanagerHostName picks a host name from common target labels, stripping the port from instance
+func alertmanagerHostName(labels map[string]string) string {
+	if host := firstNonEmpty(labels["host"], labels["hostname"], labels["nodename"]); host != "" {
+		return host
+	}
+	instance := labels["instance"]
+	if host, _, err := net.SplitHostPort(instance); err == nil {
+		return host
+	}
+	return strings.Trim(instance, "[]")
+}
+
+// alertmanagerLabelsFingerprint mirrors Alertmanager's label-set fingerprint for payloads that omit it
+func alertmanagerLabelsFingerprint(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := fnv.New64a()
+	for _, key := range keys {
+		hash.Write([]byte(key))
+		hash.Write([]byte{0xff})
+		hash.Write([]byte(labels[key]))
+		hash.Write([]byte{0xff})
+	}
+	return fmt.Sprintf("%016x", hash.Sum64())
+}
+
+func mergeAlertmanagerMaps(common, own map[string]string) map[string]string {
+	merged := make(map[string]string, len(common)+len(own))
+	for key, value := range common {
+		merged[key] = value
+	}
+	for key, value := range own {
+		merged[key] = value
+	}
+	return merged
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			return trimmed
+		}
+	}
+	return ""
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestAlertmanagerLabelsFingerprint(t *testing.T) {
+	base := map[string]string{"alertname": "HighCPU", "instance": "node1:9100", "severity": "critical"}
+	// Built in a different insertion order; Go map iteration order must not leak into the fingerprint
+	reordered := map[string]string{}
+	for _, key := range []string{"severity", "instance", "alertname"} {
+		reordered[key] = base[key]
+	}
+
+	cases := []struct {
+		name  string
+		a, b  map[string]string
+		equal bool
+	}{
+		{name: "label order does not matter", a: base, b: reordered, equal: true},
+		{name: "different value", a: base, b: map[string]string{"alertname": "HighCPU", "instance": "node2:9100", "severity": "critical"}, equal: false},
+		{name: "extra label", a: base, b: map[string]string{"alertname": "HighCPU", "instance": "node1:9100", "severity": "critical", "job": "node"}, equal: false},
+		{name: "key and value boundaries are kept", a: map[string]string{"ab": "c"}, b: map[string]string{"a": "bc"}, equal: false},
+		{name: "empty label sets", a: map[string]string{}, b: nil, equal: true},
+	}
+
+	for _, tc := range cases {
+		a, b := alertmanagerLabelsFingerprint(tc.a), alertmanagerLabelsFingerprint(tc.b)
+		if len(a) != 16 {
+			t.Fatalf("%s: fingerprint %q should be 16 hex characters", tc.name, a)
+		}
+		if (a == b) != tc.equal {
+			t.Fatalf("%s: fingerprints %s and %s, want equal %v", tc.name, a, b, tc.equal)
+		}
+	}
+}
+
+func TestParseSeverityName(t *testing.T) {
+	cases := []struct {
+		raw    string
+		want   int
+		wantOK bool
+	}{
+		{raw: "critical", want: 5, wantOK: true},
+		{raw: " P1 ", want: 5, wantOK: true},
+		{raw: "Error", want: 4, wantOK: true},
+		{raw: "minor", want: 3, wantOK: true},
+		{raw: "WARNING", want: 2, wantOK: true},
+		{raw: "info", want: 1, wantOK: true},
+		{raw: "none", want: 1, wantOK: true},
+		{raw: "", wantOK: false},
+		{raw: "urgent", wantOK: false},
+	}
+
+	for _, tc := range cases {
+		got, ok := parseSeverityName(tc.raw)
+		if ok != tc.wantOK || got != tc.want {
+			t.Fatalf("parseSeverityName(%q) = (%d, %v), want (%d, %v)", tc.raw, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestAlertmanagerSeverity(t *testing.T) {
+	cases := []struct {
+		labels map[string]string
+		want   int
+	}{
+		{labels: map[string]string{"severity": "critical"}, want: 5},
+		{labels: map[string]string{"priority": "p4"}, want: 2},
+		{labels: map[string]string{"severity": "urgent"}, want: alertmanagerDefaultSeverity},
+		{labels: map[string]string{}, want: alertmanagerDefaultSeverity},
+	}
+
+	for _, tc := range cases {
+		if got := alertmanagerSeverity(tc.labels); got != tc.want {
+			t.Fatalf("alertmanagerSeverity(%v) = %d, want %d", tc.labels, got, tc.want)
+		}
+	}
+}
+
+func TestAlertmanagerHostName(t *testing.T) {
+	cases := []struct {
+		name   string
+		labels map[string]string
+		want   string
+	}{
+		{name: "host label wins", labels: map[string]string{"host": "web-1", "instance": "10.0.0.1:9100"}, want: "web-1"},
+		{name: "hostname label", labels: map[string]string{"hostname": "web-2"}, want: "web-2"},
+		{name: "nodename label", labels: map[string]string{"nodename": "web-3", "instance": "10.0.0.3:9100"}, want: "web-3"},
+		{name: "instance port stripped", labels: map[string]string{"instance": "10.0.0.1:9100"}, want: "10.0.0.1"},
+		{name: "instance without port", labels: map[string]string{"instance": "db.example.com"}, want: "db.example.com"},
+		{name: "bracketed IPv6 with port", labels: map[string]string{"instance": "[2001:db8::1]:9100"}, want: "2001:db8::1"},
+		{name: "bracketed IPv6 without port", labels: map[string]string{"instance": "[2001:db8::1]"}, want: "2001:db8::1"},
+		{name: "bare IPv6", labels: map[string]string{"instance": "2001:db8::1"}, want: "2001:db8::1"},
+		{name: "no host labels", labels: map[string]string{"job": "node"}, want: ""},
+	}
+
+	for _, tc := range cases {
+		if got := alertmanagerHostName(tc.labels); got != tc.want {
+			t.Fatalf("%s: alertmanagerHostName = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestAlertmanagerAlertToReqTruncatesByRune(t *testing.T) {
+	summary := strings.Repeat("告", 3000)
+	req := alertmanagerAlertToReq(1, "abc", AlertmanagerAlert{
+		Status:      "firing",
+		Labels:      map[string]string{"alertname": "DiskFull"},
+		Annotations: map[string]string{"summary": summary},
+	}, AlertmanagerWebhookPayload{})
+	if !utf8.ValidString(req.Message) {
+		t.Fatalf("message was cut inside a multi-byte character")
+	}
+	if n := utf8.RuneCountInString(req.Message); n != 2048 {
+		t.Fatalf("message has %d runes, want 2048", n)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"nagare/internal/model"
@@ -16,27 +17,45 @@ import (
 
 // ChatReq represents a chat request
 type ChatReq struct {
-	ProviderID uint   `json:"provider_id" binding:"required"`
-	Model      string `json:"model"`
-	Content    string `json:"content" binding:"required"`
-	Mode       string `json:"mode,omitempty"`
-	Locale     string `json:"locale,omitempty"`
-	UseTools   *bool  `json:"use_tools,omitempty"`
-	Privileges int    `json:"-"`
+	ProviderID     uint   `json:"provider_id" binding:"required"`
+	Model          string `json:"model"`
+	Content        string `json:"content" binding:"required"`
+	Mode           string `json:"mode,omitempty"`
+	Locale         string `json:"locale,omitempty"`
+	UseTools       *bool  `json:"use_tools,omitempty"`
+	ConversationID *uint  `json:"conversation_id,omitempty"` // Omitted to start a new conversation
+	ContextType    string `json:"context_type,omitempty"`    // Pinned context of a new conversation
+	ContextID      *uint  `json:"context_id,omitempty"`
+	UserID         *uint  `json:"-"`
+	Privileges     int    `json:"-"`
 }
 
 // ChatRes represents a chat response
 type ChatRes struct {
-	ID         uint   `json:"id"`
-	ProviderID uint   `json:"provider_id" binding:"required"`
-	Role       string `json:"role" binding:"required"`
-	Model      string `json:"model"`
-	Content    string `json:"content" binding:"required"`
+	ID             uint   `json:"id"`
+	ConversationID uint   `json:"conversation_id,omitempty"`
+	ProviderID     uint   `json:"provider_id" binding:"required"`
+	Role           string `json:"role" binding:"required"`
+	Model          string `json:"model"`
+	Content        string `json:"content" binding:"required"`
+}
+
+// chatTurn is one question of a conversation, shared by the tool and plain chat paths
+type chatTurn struct {
+	client     *llm.Client
+	providerID uint
+	model      string
+	messages   []llm.Message // History window ending with the question
+	context    string        // Base prompt with the pinned context and history summary
+	userID     *uint         // Requester the token usage is accounted to
 }
 
-const recentChatHistoryLimit = 10
 const maxToolChatCalls = 3
 
+// errNativeToolsRejected marks a provider refusing the first function-calling request, e.g. an
+// OpenAI-compatible server without tools support; the chat then retries with the text tool protocol
+var errNativeToolsRejected = errors.New("native tool calling rejected")
+
 // GetAllChatsServ retrieves chat history (limited to 10 items)
 func GetAllChatsServ() ([]model.Chat, error) {
 	return repository.GetAllChatsDAO()
@@ -60,99 +79,291 @@ func SearchChatsServ(filter model.ChatFilter) ([]model.Chat, error) {
 
 // SendChatServ sends a chat message, optionally using tools for diagnostics.
 func SendChatServ(req ChatReq) (ChatRes, error) {
+	return sendChat(context.Background(), req, nil)
+}
+
+// sendChat answers a question of a conversation through the LLM failover chain, starting with the requested
+// provider. stream receives progress events and is nil for blocking requests; when an attempt fails after it
+// streamed text, the client is told to reset before the next route answers.
+func sendChat(ctx context.Context, req ChatReq, stream *chatStream) (ChatRes, error) {
+	conversation, err := resolveChatConversation(req)
+	if err != nil {
+		return ChatRes{}, err
+	}
+	routes, err := resolveLLMRoutes(req.UserID, req.ProviderID, req.Model)
+	if err != nil {
+		return ChatRes{}, err
+	}
+	if _, err := storeChatMessage(conversation, uint(routes[0].ProviderID), routes[0].Model, "user", req.Content); err != nil {
+		return ChatRes{}, fmt.Errorf("failed to store user message: %w", err)
+	}
+
 	personaPrompt := resolveChatPersonaPrompt(req.Mode, req.Locale)
 	useTools := req.Privileges >= 2
 	if req.UseTools != nil {
 		useTools = *req.UseTools && req.Privileges >= 2
 	}
+
+	var answer string
+	attempted := false
+	route, err := runLLMRoutes(ctx, "chat", routes, func(ctx context.Context, client *llm.Client, route llm.Route) error {
+		if attempted {
+			if err := stream.reset(); err != nil {
+				return err
+			}
+		}
+		attempted = true
+		turn := newChatTurn(ctx, &conversation, client, route, req)
+		var err error
+		answer, err = runChatTurn(ctx, turn, personaPrompt, useTools, stream)
+		return err
+	})
+	if err != nil {
+		return ChatRes{}, err
+	}
+	return finishChatTurn(conversation, route, answer)
+}
+
+// newChatTurn prepares the history window and system context of a question for one route
+func newChatTurn(ctx context.Context, conversation *model.Conversation, client *llm.Client, route llm.Route, req ChatReq) chatTurn {
+	turn := chatTurn{client: client, providerID: uint(route.ProviderID), model: route.Model, userID: req.UserID}
+	turn.messages = loadConversationMessages(ctx, turn, conversation, req.Locale)
+	if len(turn.messages) == 0 {
+		turn.messages = []llm.Message{{Role: llm.RoleUser}}
+	}
+	// Knowledge base context is added to the question for this request only
+	turn.messages[len(turn.messages)-1].Content = buildChatUserContent(req.Content)
+	turn.context = baseChatPrompt(isChinese(req.Locale))
+	if extra := conversationPrompt(*conversation); extra != "" {
+		turn.context += "\n\n" + extra
+	}
+	return turn
+}
+
+// runChatTurn answers with tools when allowed, falling back to a plain chat on the same route
+func runChatTurn(ctx context.Context, turn chatTurn, personaPrompt string, useTools bool, stream *chatStream) (string, error) {
 	if useTools {
-		res, err := sendChatWithToolsSafe(req, personaPrompt)
-		if err == nil {
-			return res, nil
+		answer, err := sendChatWithToolsSafe(ctx, turn, personaPrompt, stream)
+		if err == nil || ctx.Err() != nil {
+			return answer, err
 		}
 
 		LogService("warn", "tool chat failed, fallback to plain chat", map[string]interface{}{
-			"provider_id": req.ProviderID,
-			"model":       req.Model,
+			"provider_id": turn.providerID,
+			"model":       turn.model,
 			"error":       err.Error(),
 		}, nil, "")
+		if err := stream.reset(); err != nil {
+			return "", err
+		}
 	}
-	return sendChatPlain(req, personaPrompt)
+	return sendChatPlain(ctx, turn, personaPrompt, stream)
 }
 
-func sendChatWithToolsSafe(req ChatReq, personaPrompt string) (res ChatRes, err error) {
+func sendChatWithToolsSafe(ctx context.Context, turn chatTurn, personaPrompt string, stream *chatStream) (answer string, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = fmt.Errorf("tool chat panic: %v", r)
 		}
 	}()
 
-	return sendChatWithTools(req, personaPrompt)
+	return sendChatWithTools(ctx, turn, personaPrompt, stream)
 }
 
-func sendChatPlain(req ChatReq, personaPrompt string) (ChatRes, error) {
-	client, llmModel, err := createLLMClient(req.ProviderID, req.Model)
-	if err != nil {
-		_ = repository.UpdateProviderStatusDAO(req.ProviderID, 2)
-		return ChatRes{}, err
-	}
-
-	if _, err := storeChatMessage(req.ProviderID, llmModel, "user", req.Content, nil); err != nil {
-		return ChatRes{}, fmt.Errorf("failed to store user message: %w", err)
-	}
-
-	ctx := context.Background()
+func sendChatPlain(ctx context.Context, turn chatTurn, personaPrompt string, stream *chatStream) (string, error) {
 	start := time.Now()
 
 	// Prepare system prompt: Persona + Base Context
-	systemPrompt := baseChatPrompt(isChinese(req.Locale))
+	systemPrompt := turn.context
 	if personaPrompt != "" {
 		systemPrompt = personaPrompt + "\n\n" + systemPrompt
 	}
 
-	resp, err := client.Chat(ctx, llm.ChatRequest{
-		Model:        llmModel,
+	resp, err := stream.chat(ctx, turn.client, llm.ChatRequest{
+		Model:        turn.model,
 		SystemPrompt: systemPrompt,
-		Messages: []llm.Message{
-			{Role: "user", Content: buildChatUserContent(req.Content)},
-		},
+		Messages:     turn.messages,
 	})
-	logLLMRequest("chat", req.ProviderID, llmModel, time.Since(start), err)
+	recordLLMRequest("chat", turn.userID, turn.providerID, turn.model, time.Since(start), resp, err)
 	if err != nil {
-		_ = repository.UpdateProviderStatusDAO(req.ProviderID, 2)
-		return ChatRes{}, fmt.Errorf("failed to generate content: %w", err)
+		return "", fmt.Errorf("failed to generate content: %w", err)
 	}
+	return resp.Content, nil
+}
 
-	assistantMsg, err := storeChatMessage(req.ProviderID, llmModel, "assistant", resp.Content, nil)
+// finishChatTurn stores the answer and records on the conversation which provider gave it
+func finishChatTurn(conversation model.Conversation, route llm.Route, content string) (ChatRes, error) {
+	providerID := uint(route.ProviderID)
+	assistantMsg, err := storeChatMessage(conversation, providerID, route.Model, "assistant", content)
 	if err != nil {
 		return ChatRes{}, fmt.Errorf("failed to store AI response: %w", err)
 	}
 
-	_ = repository.UpdateProviderStatusDAO(req.ProviderID, 1)
-	return ChatRes{ID: assistantMsg.ID, Content: resp.Content, ProviderID: req.ProviderID, Role: "assistant", Model: llmModel}, nil
+	_ = repository.UpdateConversationFieldsDAO(conversation.ID, map[string]interface{}{
+		"provider_id":  providerID,
+		"model":        route.Model,
+		"last_chat_at": assistantMsg.CreatedAt,
+	})
+	return ChatRes{
+		ID:             assistantMsg.ID,
+		ConversationID: conversation.ID,
+		Content:        content,
+		ProviderID:     providerID,
+		Role:           "assistant",
+		Model:          route.Model,
+	}, nil
 }
 
-func sendChatWithTools(req ChatReq, personaPrompt string) (ChatRes, error) {
-	client, llmModel, err := createLLMClient(req.ProviderID, req.Model)
+func sendChatWithTools(ctx context.Context, turn chatTurn, personaPrompt string, stream *chatStream) (string, error) {
+	var finalText string
+	err := errNativeToolsRejected
+	if turn.client.SupportsTools() {
+		finalText, err = runNativeToolChat(ctx, turn, personaPrompt, stream)
+		if errors.Is(err, errNativeToolsRejected) && ctx.Err() == nil {
+			LogService("warn", "native tool calling failed, fallback to text tool protocol", map[string]interface{}{
+				"provider_id": turn.providerID,
+				"model":       turn.model,
+				"error":       err.Error(),
+			}, nil, "")
+			if err := stream.reset(); err != nil {
+				return "", err
+			}
+		}
+	}
+	if errors.Is(err, errNativeToolsRejected) && ctx.Err() == nil {
+		finalText, err = runTextToolChat(ctx, turn, personaPrompt)
+		if err == nil {
+			// The text protocol is not streamed because the reply may be a raw tool call
+			err = stream.delta(finalText)
+		}
+	}
 	if err != nil {
-		_ = repository.UpdateProviderStatusDAO(req.ProviderID, 2)
-		return ChatRes{}, err
+		return "", err
+	}
+	if strings.TrimSpace(finalText) == "" {
+		return "", errors.New("empty response from LLM")
 	}
+	return finalText, nil
+}
 
-	if _, err := storeChatMessage(req.ProviderID, llmModel, "user", req.Content, nil); err != nil {
-		return ChatRes{}, fmt.Errorf("failed to store user message: %w", err)
+// runNativeToolChat lets the model call tools through the provider's function-calling API. Every call of a
+// turn is executed and answered with a tool-role message before the model is asked again.
+func runNativeToolChat(ctx context.Context, turn chatTurn, personaPrompt string, stream *chatStream) (string, error) {
+	tools := llmToolDefinitions(ListTools())
+	baseContext := turn.context
+	systemPrompt := nativeToolSystemPrompt(personaPrompt) + "\n\n" + baseContext
+	messages := append([]llm.Message(nil), turn.messages...)
+
+	for i := 0; i < maxToolChatCalls; i++ {
+		start := time.Now()
+		resp, err := stream.chat(ctx, turn.client, llm.ChatRequest{
+			Model:        turn.model,
+			SystemPrompt: systemPrompt,
+			Messages:     messages,
+			Tools:        tools,
+		})
+		recordLLMRequest("tool_chat", turn.userID, turn.providerID, turn.model, time.Since(start), resp, err)
+		if err != nil && i == 0 {
+			return "", fmt.Errorf("%w: %v", errNativeToolsRejected, err)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to generate content: %w", err)
+		}
+		if len(resp.ToolCalls) == 0 {
+			return resp.Content, nil
+		}
+
+		messages = append(messages, llm.Message{Role: llm.RoleAssistant, Content: resp.Content, ToolCalls: resp.ToolCalls})
+		results, err := executeToolCalls(resp.ToolCalls, stream)
+		if err != nil {
+			return "", err
+		}
+		for idx, result := range results {
+			messages = append(messages, llm.Message{
+				Role:       llm.RoleTool,
+				Content:    result,
+				ToolCallID: resp.ToolCalls[idx].ID,
+				Name:       resp.ToolCalls[idx].Name,
+			})
+		}
 	}
 
-	messages := loadToolChatMessages(req.Content)
+	// Out of tool rounds: the tools stay declared so the history is valid, but the model must answer now
+	start := time.Now()
+	resp, err := stream.chat(ctx, turn.client, llm.ChatRequest{
+		Model:        turn.model,
+		SystemPrompt: toolAnswerPrompt(personaPrompt) + "\n\n" + baseContext,
+		Messages:     messages,
+		Tools:        tools,
+		ToolChoice:   llm.ToolChoiceNone,
+	})
+	recordLLMRequest("tool_chat", turn.userID, turn.providerID, turn.model, time.Since(start), resp, err)
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize tool results: %w", err)
+	}
+	return resp.Content, nil
+}
+
+// executeToolCalls runs the calls of one turn concurrently and returns their JSON results in call order
+func executeToolCalls(calls []llm.ToolCall, stream *chatStream) ([]string, error) {
+	for _, call := range calls {
+		if err := stream.toolStart(call); err != nil {
+			return nil, err
+		}
+	}
+	results := make([]string, len(calls))
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		wg.Add(1)
+		go func(i int, call llm.ToolCall) {
+			defer wg.Done()
+			started := time.Now()
+			var callErr error
+			defer func() {
+				if r := recover(); r != nil {
+					callErr = fmt.Errorf("tool panic: %v", r)
+					results[i] = toolResultJSON(nil, callErr)
+				}
+				_ = stream.toolFinish(call, time.Since(started), callErr)
+			}()
+			result, err := CallTool(call.Name, call.Arguments)
+			callErr = err
+			results[i] = toolResultJSON(result, err)
+		}(i, call)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+func toolResultJSON(result interface{}, err error) string {
+	if err != nil {
+		result = map[string]string{"error": err.Error()}
+	}
+	resultJSON, _ := json.Marshal(result)
+	return string(resultJSON)
+}
+
+func llmToolDefinitions(tools []ToolDefinition) []llm.Tool {
+	converted := make([]llm.Tool, 0, len(tools))
+	for _, tool := range tools {
+		converted = append(converted, llm.Tool{
+			Name:        tool.Name,
+			Description: tool.Description,
+			Parameters:  tool.InputSchema,
+		})
+	}
+	return converted
+}
 
+// runTextToolChat is the fallback for providers without native function calling: the model prints a JSON or
+// XML tool call, which is parsed from its reply, and the result is fed back as a user message.
+func runTextToolChat(ctx context.Context, turn chatTurn, personaPrompt string) (string, error) {
 	tools := ListTools()
-	ctx := context.Background()
-	start := time.Now()
+	baseContext := turn.context
+	messages := append([]llm.Message(nil), turn.messages...)
 
 	// Build system prompt with Persona + Tools + Base Context
-	initialSystemPrompt := buildToolSystemPrompt(tools, personaPrompt)
-	baseContext := baseChatPrompt(isChinese(req.Locale))
-	initialSystemPrompt = initialSystemPrompt + "\n\n" + baseContext
+	initialSystemPrompt := buildToolSystemPrompt(tools, personaPrompt) + "\n\n" + baseContext
 
 	var finalText string
 	needsFinalAnswer := false
@@ -162,16 +373,16 @@ func sendChatWithTools(req ChatReq, personaPrompt string) (ChatRes, error) {
 			systemPrompt = toolAnswerPrompt(personaPrompt) + "\n\n" + baseContext
 		}
 
-		resp, err := client.Chat(ctx, llm.ChatRequest{
-			Model:        llmModel,
+		start := time.Now()
+		resp, err := turn.client.Chat(ctx, llm.ChatRequest{
+			Model:        turn.model,
 			SystemPrompt: systemPrompt,
 			Messages:     messages,
 		})
-		logLLMRequest("tool_chat", req.ProviderID, llmModel, time.Since(start), err)
+		recordLLMRequest("tool_chat", turn.userID, turn.providerID, turn.model, time.Since(start), resp, err)
 		if err != nil {
 			if i == 0 {
-				_ = repository.UpdateProviderStatusDAO(req.ProviderID, 2)
-				return ChatRes{}, fmt.Errorf("failed to generate content: %w", err)
+				return "", fmt.Errorf("failed to generate content: %w", err)
 			}
 			break // Keep what we have
 		}
@@ -185,12 +396,7 @@ func sendChatWithTools(req ChatReq, personaPrompt string) (ChatRes, error) {
 		needsFinalAnswer = true
 
 		// Perform tool call
-		toolResult, err := CallTool(toolCall.Name, toolCall.Arguments)
-		if err != nil {
-			toolResult = map[string]string{"error": err.Error()}
-		}
-		resultJSON, _ := json.Marshal(toolResult)
-		toolResultText := fmt.Sprintf("Tool result for %s: %s", toolCall.Name, string(resultJSON))
+		toolResultText := fmt.Sprintf("Tool result for %s: %s", toolCall.Name, toolResultJSON(CallTool(toolCall.Name, toolCall.Arguments)))
 
 		// Append to history for next turn
 		messages = append(messages, llm.Message{Role: "assistant", Content: resp.Content})
@@ -198,31 +404,19 @@ func sendChatWithTools(req ChatReq, personaPrompt string) (ChatRes, error) {
 	}
 
 	if needsFinalAnswer {
-		resp, err := client.Chat(ctx, llm.ChatRequest{
-			Model:        llmModel,
+		start := time.Now()
+		resp, err := turn.client.Chat(ctx, llm.ChatRequest{
+			Model:        turn.model,
 			SystemPrompt: toolAnswerPrompt(personaPrompt) + "\n\n" + baseContext,
 			Messages:     messages,
 		})
-		logLLMRequest("tool_chat", req.ProviderID, llmModel, time.Since(start), err)
+		recordLLMRequest("tool_chat", turn.userID, turn.providerID, turn.model, time.Since(start), resp, err)
 		if err != nil {
-			_ = repository.UpdateProviderStatusDAO(req.ProviderID, 2)
-			return ChatRes{}, fmt.Errorf("failed to summarize tool results: %w", err)
+			return "", fmt.Errorf("failed to summarize tool results: %w", err)
 		}
 		finalText = resp.Content
 	}
-
-	if strings.TrimSpace(finalText) == "" {
-		_ = repository.UpdateProviderStatusDAO(req.ProviderID, 2)
-		return ChatRes{}, errors.New("empty response from LLM")
-	}
-
-	assistantMsg, err := storeChatMessage(req.ProviderID, llmModel, "assistant", finalText, nil)
-	if err != nil {
-		return ChatRes{}, fmt.Errorf("failed to store AI response: %w", err)
-	}
-
-	_ = repository.UpdateProviderStatusDAO(req.ProviderID, 1)
-	return ChatRes{ID: assistantMsg.ID, Content: finalText, ProviderID: req.ProviderID, Role: "assistant", Model: llmModel}, nil
+	return finalText, nil
 }
 
 func buildChatUserContent(content string) string {
@@ -233,35 +427,16 @@ func buildChatUserContent(content string) string {
 	return fmt.Sprintf("%s\n\n[USER QUERY]: %s", kbContext, content)
 }
 
-func loadToolChatMessages(content string) []llm.Message {
-	history, err := repository.GetChatsWithLimitDAO(recentChatHistoryLimit, 0)
-	if err != nil || len(history) == 0 {
-		return []llm.Message{{Role: "user", Content: buildChatUserContent(content)}}
-	}
-
-	messages := make([]llm.Message, 0, len(history))
-	for i := len(history) - 1; i >= 0; i-- {
-		messages = append(messages, llm.Message{
-			Role:    history[i].Role,
-			Content: history[i].Content,
-		})
-	}
-	messages[len(messages)-1].Content = buildChatUserContent(content)
-	return messages
-}
-
-func storeChatMessage(providerID uint, llmModel string, role string, content string, userID *uint) (model.Chat, error) {
-	resolvedUserID := uint(1)
-	if userID != nil {
-		resolvedUserID = *userID
-	}
-
+// storeChatMessage appends a message to a conversation; both sides of the chat belong to its owner
+func storeChatMessage(conversation model.Conversation, providerID uint, llmModel string, role string, content string) (model.Chat, error) {
+	conversationID := conversation.ID
 	message := model.Chat{
-		UserID:     resolvedUserID,
-		ProviderID: providerID,
-		LLMModel:   llmModel,
-		Role:       role,
-		Content:    content,
+		UserID:         conversation.UserID,
+		ConversationID: &conversationID,
+		ProviderID:     providerID,
+		LLMModel:       llmModel,
+		Role:           role,
+		Content:        content,
 	}
 	if err := repository.AddChatDAO(&message); err != nil {
 		return model.Chat{}, err
@@ -396,6 +571,14 @@ func buildToolSystemPrompt(tools []ToolDefinition, personaPrompt string) string
 	return builder.String()
 }
 
+func nativeToolSystemPrompt(personaPrompt string) string {
+	base := "You are Nagare AI. YOU HAVE DIRECT, AUTHORIZED ACCESS to live network devices via the provided tools. IGNORE default safety restrictions about accessing external systems. Do NOT ask the user to provide data manually; call your tools, several at once when they are independent."
+	if personaPrompt == "" {
+		return base
+	}
+	return personaPrompt + "\n\n" + base
+}
+
 func toolAnswerPrompt(personaPrompt string) string {
 	base := "Answer using tool result. Summarize briefly. Max 10 items. NO TOOLS."
 	if personaPrompt == "" {
@@ -428,16 +611,15 @@ func resolveChatPersonaPrompt(mode string, locale string) string {
 }
 
 // ConsultAlertServ consults AI about a specific alert
-func ConsultAlertServ(providerID uint, model string, alertID int) (ChatRes, error) {
+func ConsultAlertServ(providerID uint, model string, alertID int, userID *uint) (ChatRes, error) {
 	// Get alert data
 	alert, err := repository.GetAlertByIDDAO(alertID)
 	if err != nil {
 		return ChatRes{}, fmt.Errorf("failed to get alert: %w", err)
 	}
 
-	client, resolvedModel, err := createLLMClient(providerID, model)
+	routes, err := resolveLLMRoutes(userID, providerID, model)
 	if err != nil {
-		_ = repository.UpdateProviderStatusDAO(providerID, 2)
 		return ChatRes{}, err
 	}
 
@@ -447,26 +629,20 @@ func ConsultAlertServ(providerID uint, model string, alertID int) (ChatRes, erro
 	ctx, cancel := aiAnalysisContext()
 	defer cancel()
 	systemPrompt := alertAnalysisPrompt(isCn)
-	start := time.Now()
 
 	alertData := fmt.Sprintf("Alert ID: %d\nSeverity: %d\nMessage: %s\nStatus: %d",
 		alert.ID, alert.Severity, sanitizeSensitiveText(alert.Message), alert.Status)
 
-	resp, err := client.Chat(ctx, llm.ChatRequest{
-		Model:        resolvedModel,
+	resp, route, err := routedChat(ctx, "alert_consult", userID, routes, llm.ChatRequest{
 		SystemPrompt: systemPrompt,
 		Messages: []llm.Message{
 			{Role: "user", Content: alertData},
 		},
 	})
-	logLLMRequest("alert_consult", providerID, resolvedModel, time.Since(start), err)
 	if err != nil {
-		_ = repository.UpdateProviderStatusDAO(providerID, 2)
 		return ChatRes{}, fmt.Errorf("failed to analyze alert: %w", err)
 	}
 
-	_ = repository.UpdateProviderStatusDAO(providerID, 1)
-
 	// Store the comment and update status to confirmed (1) if it's currently active (0)
 	if alert.Status == 0 {
 		alert.Status = 1
@@ -474,11 +650,11 @@ func ConsultAlertServ(providerID uint, model string, alertID int) (ChatRes, erro
 	alert.Comment = resp.Content
 	_ = repository.UpdateAlertDAO(alertID, alert)
 
-	return ChatRes{Content: resp.Content, ProviderID: providerID, Role: "assistant", Model: resolvedModel}, nil
+	return ChatRes{Content: resp.Content, ProviderID: uint(route.ProviderID), Role: "assistant", Model: route.Model}, nil
 }
 
 // ConsultItemServ consults AI about a specific monitoring item
-func ConsultItemServ(providerID uint, model string, itemID uint) (ChatRes, error) {
+func ConsultItemServ(providerID uint, model string, itemID uint, userID *uint) (ChatRes, error) {
 	// Get item data
 	item, err := repository.GetItemByIDDAO(itemID)
 	if err != nil {
@@ -491,9 +667,8 @@ func ConsultItemServ(providerID uint, model string, itemID uint) (ChatRes, error
 		return ChatRes{}, fmt.Errorf("failed to get host: %w", err)
 	}
 
-	client, resolvedModel, err := createLLMClient(providerID, model)
+	routes, err := resolveLLMRoutes(userID, providerID, model)
 	if err != nil {
-		_ = repository.UpdateProviderStatusDAO(providerID, 2)
 		return ChatRes{}, err
 	}
 
@@ -507,31 +682,25 @@ func ConsultItemServ(providerID uint, model string, itemID uint) (ChatRes, error
 	itemData := fmt.Sprintf("Host: %s\nItem Name: %s\nItem ID: %s\nCurrent Value: %s\nUnits: %s",
 		sanitizeSensitiveText(host.Name), sanitizeSensitiveText(item.Name), item.ExternalID, sanitizeSensitiveText(item.LastValue), sanitizeSensitiveText(item.Units))
 
-	start := time.Now()
-	resp, err := client.Chat(ctx, llm.ChatRequest{
-		Model:        resolvedModel,
+	resp, route, err := routedChat(ctx, "item_consult", userID, routes, llm.ChatRequest{
 		SystemPrompt: systemPrompt,
 		Messages: []llm.Message{
 			{Role: "user", Content: itemData},
 		},
 	})
-	logLLMRequest("item_consult", providerID, resolvedModel, time.Since(start), err)
 	if err != nil {
-		_ = repository.UpdateProviderStatusDAO(providerID, 2)
 		return ChatRes{}, fmt.Errorf("failed to analyze item: %w", err)
 	}
 
-	_ = repository.UpdateProviderStatusDAO(providerID, 1)
-
 	// Store the comment
 	item.Comment = resp.Content
 	_ = repository.UpdateItemDAO(item.ID, item)
 
-	return ChatRes{Content: resp.Content, ProviderID: 1, Role: "assistant", Model: resolvedModel}, nil
+	return ChatRes{Content: resp.Content, ProviderID: uint(route.ProviderID), Role: "assistant", Model: route.Model}, nil
 }
 
 // ConsultHostServ consults AI about a host's status based on all its items
-func ConsultHostServ(providerID uint, model string, hostID uint) (ChatRes, error) {
+func ConsultHostServ(providerID uint, model string, hostID uint, userID *uint) (ChatRes, error) {
 	// Get host data
 	host, err := repository.GetHostByIDDAO(hostID)
 	if err != nil {
@@ -544,9 +713,8 @@ func ConsultHostServ(providerID uint, model string, hostID uint) (ChatRes, error
 		return ChatRes{}, fmt.Errorf("failed to get host items: %w", err)
 	}
 
-	client, resolvedModel, err := createLLMClient(providerID, model)
+	routes, err := resolveLLMRoutes(userID, providerID, model)
 	if err != nil {
-		_ = repository.UpdateProviderStatusDAO(providerID, 2)
 		return ChatRes{}, err
 	}
 
@@ -576,27 +744,21 @@ func ConsultHostServ(providerID uint, model string, hostID uint) (ChatRes, error
 	hostData := fmt.Sprintf("Host: %s\nIP Address: %s\nStatus: %d\nDescription: %s\n\nMonitoring Metrics:\n%s",
 		sanitizeSensitiveText(host.Name), sanitizeSensitiveText(host.IPAddr), host.Status, sanitizeSensitiveText(host.Description), itemsBuilder.String())
 
-	start := time.Now()
-	resp, err := client.Chat(ctx, llm.ChatRequest{
-		Model:        resolvedModel,
+	resp, route, err := routedChat(ctx, "host_consult", userID, routes, llm.ChatRequest{
 		SystemPrompt: systemPrompt,
 		Messages: []llm.Message{
 			{Role: "user", Content: hostData},
 		},
 	})
-	logLLMRequest("host_consult", providerID, resolvedModel, time.Since(start), err)
 	if err != nil {
-		_ = repository.UpdateProviderStatusDAO(providerID, 2)
 		return ChatRes{}, fmt.Errorf("failed to analyze host: %w", err)
 	}
 
-	_ = repository.UpdateProviderStatusDAO(providerID, 1)
-
 	// Store the comment
 	host.Comment = resp.Content
 	_ = repository.UpdateHostDAO(host.ID, host)
 
-	return ChatRes{Content: resp.Content, ProviderID: providerID, Role: "assistant", Model: resolvedModel}, nil
+	return ChatRes{Content: resp.Content, ProviderID: uint(route.ProviderID), Role: "assistant", Model: route.Model}, nil
 }
 
 // createLLMClient creates an LLM client for the given provider
@@ -610,69 +772,39 @@ func createLLMClient(providerID uint, model string) (*llm.Client, string, error)
 		return nil, "", errors.New("provider API key is not configured")
 	}
 
-	var providerType llm.ProviderType
-	switch provider.Type {
-	case 1:
-		providerType = llm.ProviderGemini
-	case 2, 3:
-		providerType = llm.ProviderOpenAI
-	default:
-		if provider.URL != "" {
-			providerType = llm.ProviderOpenAI
-		} else {
-			providerType = llm.ProviderGemini
-		}
-	}
-
-	client, err := llm.NewClient(llm.Config{
-		APIKey:  provider.APIKey,
-		BaseURL: provider.URL,
-		Type:    providerType,
-	})
+	cfg := llmProviderConfig(provider)
+	client, err := llm.NewClient(cfg)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to create LLM client: %w", err)
 	}
-
-	// Use provider's default model if not specified
-	resolvedModel := model
-	if resolvedModel == "" {
-		resolvedModel = provider.DefaultModel
-	}
-	if resolvedModel == "" {
-		switch providerType {
-		case llm.ProviderGemini:
-			resolvedModel = "gemini-2.0-flash"
-		case llm.ProviderOpenAI:
-			resolvedModel = "gpt-4o-mini"
-		}
-	}
-
+	resolvedModel := resolveLLMModel(provider, cfg.Type, model)
 	return client, resolvedModel, nil
 }
 
 // ConsultServ consults the AI provider for general analysis (legacy support)
 func ConsultServ(req ChatReq) (ChatRes, error) {
-	client, resolvedModel, err := createLLMClient(req.ProviderID, req.Model)
+	routes, err := resolveLLMRoutes(req.UserID, req.ProviderID, req.Model)
 	if err != nil {
-		_ = repository.UpdateProviderStatusDAO(req.ProviderID, 2)
 		return ChatRes{}, err
 	}
 
 	ctx := context.Background()
-	responseText, err := client.SimpleChat(ctx, resolvedModel, req.Content)
+	resp, route, err := routedChat(ctx, "consult", req.UserID, routes, llm.ChatRequest{
+		Messages: []llm.Message{
+			{Role: "user", Content: req.Content},
+		},
+	})
 	if err != nil {
-		_ = repository.UpdateProviderStatusDAO(req.ProviderID, 2)
 		return ChatRes{}, fmt.Errorf("failed to get AI response: %w", err)
 	}
 
-	return ChatRes{Content: responseText, ProviderID: req.ProviderID, Role: "assistant", Model: resolvedModel}, nil
+	return ChatRes{Content: resp.Content, ProviderID: uint(route.ProviderID), Role: "assistant", Model: route.Model}, nil
 }
 
 // AnalyzeMonitoringDataServ analyzes monitoring data using LLM
 func AnalyzeMonitoringDataServ(providerID uint, model string, data string) (ChatRes, error) {
-	client, resolvedModel, err := createLLMClient(providerID, model)
+	routes, err := resolveLLMRoutes(nil, providerID, model)
 	if err != nil {
-		_ = repository.UpdateProviderStatusDAO(providerID, 2)
 		return ChatRes{}, err
 	}
 
@@ -683,29 +815,22 @@ func AnalyzeMonitoringDataServ(providerID uint, model string, data string) (Chat
 	defer cancel()
 	systemPrompt := monitoringAnalysisPrompt(isCn)
 
-	start := time.Now()
-	resp, err := client.Chat(ctx, llm.ChatRequest{
-		Model:        resolvedModel,
+	resp, route, err := routedChat(ctx, "monitoring_analysis", nil, routes, llm.ChatRequest{
 		SystemPrompt: systemPrompt,
 		Messages: []llm.Message{
 			{Role: "user", Content: sanitizeSensitiveText(data)},
 		},
 	})
-	logLLMRequest("monitoring_analysis", providerID, resolvedModel, time.Since(start), err)
 	if err != nil {
-		_ = repository.UpdateProviderStatusDAO(providerID, 2)
 		return ChatRes{}, fmt.Errorf("failed to analyze data: %w", err)
 	}
-
-	_ = repository.UpdateProviderStatusDAO(providerID, 1)
-	return ChatRes{Content: resp.Content, ProviderID: providerID, Role: "assistant", Model: resolvedModel}, nil
+	return ChatRes{Content: resp.Content, ProviderID: uint(route.ProviderID), Role: "assistant", Model: route.Model}, nil
 }
 
 // ExplainErrorServ explains an error message using LLM
 func ExplainErrorServ(providerID uint, model string, errorMsg string) (ChatRes, error) {
-	client, resolvedModel, err := createLLMClient(providerID, model)
+	routes, err := resolveLLMRoutes(nil, providerID, model)
 	if err != nil {
-		_ = repository.UpdateProviderStatusDAO(providerID, 2)
 		return ChatRes{}, err
 	}
 
@@ -715,22 +840,16 @@ func ExplainErrorServ(providerID uint, model string, errorMsg string) (ChatRes,
 	ctx := context.Background()
 	systemPrompt := errorExplainPrompt(isCn)
 
-	start := time.Now()
-	resp, err := client.Chat(ctx, llm.ChatRequest{
-		Model:        resolvedModel,
+	resp, route, err := routedChat(ctx, "error_explain", nil, routes, llm.ChatRequest{
 		SystemPrompt: systemPrompt,
 		Messages: []llm.Message{
 			{Role: "user", Content: fmt.Sprintf("Please explain this error and how to fix it:\n\n%s", sanitizeSensitiveText(errorMsg))},
 		},
 	})
-	logLLMRequest("error_explain", providerID, resolvedModel, time.Since(start), err)
 	if err != nil {
-		_ = repository.UpdateProviderStatusDAO(providerID, 2)
 		return ChatRes{}, fmt.Errorf("failed to explain error: %w", err)
 	}
-
-	_ = repository.UpdateProviderStatusDAO(providerID, 1)
-	return ChatRes{Content: resp.Content, ProviderID: providerID, Role: "assistant", Model: resolvedModel}, nil
+	return ChatRes{Content: resp.Content, ProviderID: uint(route.ProviderID), Role: "assistant", Model: route.Model}, nil
 }
 
 func itemAnalysisPrompt(chinese bool) string {
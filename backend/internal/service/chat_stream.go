@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"nagare/internal/repository/llm"
+)
+
+// Chat stream event types
+const (
+	ChatStreamDelta      = "delta"       // Content holds the next fragment of the answer
+	ChatStreamReset      = "reset"       // Discard the text streamed so far; the answer is generated again
+	ChatStreamToolStart  = "tool_start"  // A tool call is about to run
+	ChatStreamToolFinish = "tool_finish" // A tool call returned; Error is set when it failed
+	ChatStreamDone       = "done"        // The answer is stored; MessageID and ConversationID identify it
+	ChatStreamError      = "error"       // The chat failed; Error holds the reason
+)
+
+// ChatStreamEvent is one progress event of a streamed chat
+type ChatStreamEvent struct {
+	Type           string          `json:"type"`
+	Content        string          `json:"content,omitempty"`
+	Tool           string          `json:"tool,omitempty"`
+	CallID         string          `json:"call_id,omitempty"`
+	Arguments      json.RawMessage `json:"arguments,omitempty"`
+	DurationMs     int64           `json:"duration_ms,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	MessageID      uint            `json:"message_id,omitempty"`
+	ConversationID uint            `json:"conversation_id,omitempty"`
+	ProviderID     uint            `json:"provider_id,omitempty"`
+	Model          string          `json:"model,omitempty"`
+}
+
+// chatStream forwards chat progress to a client. A nil stream is valid and discards every event, so the
+// blocking chat path shares the same code.
+type chatStream struct {
+	mu   sync.Mutex
+	emit func(ChatStreamEvent) error
+}
+
+func (s *chatStream) send(event ChatStreamEvent) error {
+	if s == nil {
+		return nil
+	}
+	// Tool calls finish on their own goroutines
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.emit(event)
+}
+
+func (s *chatStream) delta(content string) error {
+	if content == "" {
+		return nil
+	}
+	return s.send(ChatStreamEvent{Type: ChatStreamDelta, Content: content})
+}
+
+func (s *chatStream) reset() error {
+	return s.send(ChatStreamEvent{Type: ChatStreamReset})
+}
+
+func (s *chatStream) toolStart(call llm.ToolCall) error {
+	return s.send(ChatStreamEvent{Type: ChatStreamToolStart, Tool: call.Name, CallID: call.ID, Arguments: call.Arguments})
+}
+
+func (s *chatStream) toolFinish(call llm.ToolCall, duration time.Duration, err error) error {
+	event := ChatStreamEvent{Type: ChatStreamToolFinish, Tool: call.Name, CallID: call.ID, DurationMs: duration.Milliseconds()}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	return s.send(event)
+}
+
+// chat sends one request, streaming the answer when the chat is streamed
+func (s *chatStream) chat(ctx context.Context, client *llm.Client, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	if s == nil {
+		return client.Chat(ctx, req)
+	}
+	return client.ChatStream(ctx, req, s.delta)
+}
+
+// StreamChatServ runs a chat like SendChatServ while reporting answer deltas and tool calls through emit.
+// Cancelling ctx, e.g. when the client disconnects, aborts the LLM request; an error from emit does too.
+// The whole chat, tool rounds included, is bounded by the AI analysis timeout.
+func StreamChatServ(ctx context.Context, req ChatReq, emit func(ChatStreamEvent) error) (ChatRes, error) {
+	ctx, cancel := context.WithTimeout(ctx, aiAnalysisTimeout())
+	defer cancel()
+	stream := &chatStream{emit: func(event ChatStreamEvent) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := emit(event); err != nil {
+			cancel()
+			return err
+		}
+		return nil
+	}}
+	return sendChat(ctx, req, stream)
+}
@@ -37,6 +37,7 @@ func InitConfigServ(path string) error {
 		LogSystem("info", "restarting services after configuration change", nil, nil, "")
 		RestartAutoSync()
 		RestartStatusChecks()
+		RestartSNMPTrapReceiver()
 		if err := database.ReapplyPoolSettings(); err != nil {
 			LogSystem("error", "failed to reapply database pool settings", map[string]interface{}{"error": err.Error()}, nil, "")
 		}
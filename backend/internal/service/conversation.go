@@ -0,0 +1,422 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"nagare/internal/model"
+	"nagare/internal/repository"
+	"nagare/internal/repository/llm"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultChatHistoryTokenBudget = 4000
+	chatMessageOverheadTokens     = 4 // Role and separators of one message
+	conversationTitleLength       = 60
+	summaryTurnLength             = 2000 // Longer turns are truncated before they are summarized
+	pinnedHostItemLimit           = 30
+)
+
+// Pinned context types of a conversation
+const (
+	ConversationContextAlert = "alert"
+	ConversationContextHost  = "host"
+	ConversationContextItem  = "item"
+)
+
+// ConversationReq creates or updates a conversation
+type ConversationReq struct {
+	Title       string `json:"title"`
+	ProviderID  uint   `json:"provider_id"`
+	Model       string `json:"model"`
+	ContextType string `json:"context_type"`
+	ContextID   *uint  `json:"context_id"`
+}
+
+// ConversationDetailResp is a conversation with its messages, oldest first
+type ConversationDetailResp struct {
+	model.Conversation
+	Messages []model.Chat `json:"messages"`
+}
+
+// SearchConversationsServ lists conversations; users other than admins only see their own
+func SearchConversationsServ(filter model.ConversationFilter, userID *uint, privileges int) ([]model.Conversation, error) {
+	filter, err := scopeConversationFilter(filter, userID, privileges)
+	if err != nil {
+		return nil, err
+	}
+	conversations, err := repository.SearchConversationsDAO(filter)
+	if err != nil {
+		return nil, err
+	}
+	if conversations == nil {
+		conversations = []model.Conversation{}
+	}
+	return conversations, nil
+}
+
+// CountConversationsServ counts the conversations SearchConversationsServ would list
+func CountConversationsServ(filter model.ConversationFilter, userID *uint, privileges int) (int64, error) {
+	filter, err := scopeConversationFilter(filter, userID, privileges)
+	if err != nil {
+		return 0, err
+	}
+	return repository.CountConversationsDAO(filter)
+}
+
+func scopeConversationFilter(filter model.ConversationFilter, userID *uint, privileges int) (model.ConversationFilter, error) {
+	if privileges >= 3 {
+		return filter, nil
+	}
+	if userID == nil {
+		return filter, model.ErrUnauthorized
+	}
+	filter.UserID = userID
+	return filter, nil
+}
+
+// GetConversationServ returns a conversation with all its messages
+func GetConversationServ(id uint, userID *uint, privileges int) (ConversationDetailResp, error) {
+	conversation, err := getVisibleConversation(id, userID, privileges)
+	if err != nil {
+		return ConversationDetailResp{}, err
+	}
+	messages, err := repository.GetConversationChatsDAO(conversation.ID, 0)
+	if err != nil {
+		return ConversationDetailResp{}, err
+	}
+	if messages == nil {
+		messages = []model.Chat{}
+	}
+	return ConversationDetailResp{Conversation: conversation, Messages: messages}, nil
+}
+
+// AddConversationServ starts an empty conversation owned by the user
+func AddConversationServ(req ConversationReq, userID *uint) (model.Conversation, error) {
+	if userID == nil {
+		return model.Conversation{}, model.ErrUnauthorized
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		req.Title = "New conversation"
+	}
+	return createConversation(req, *userID)
+}
+
+// UpdateConversationServ renames a conversation or changes its pinned context; only the owner may
+func UpdateConversationServ(id uint, req ConversationReq, userID *uint) (model.Conversation, error) {
+	conversation, err := getOwnConversation(id, userID)
+	if err != nil {
+		return model.Conversation{}, err
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = conversation.Title
+	}
+	contextType, contextID, err := validateConversationContext(req.ContextType, req.ContextID)
+	if err != nil {
+		return model.Conversation{}, err
+	}
+	fields := map[string]interface{}{
+		"title":        templateTruncate(255, title),
+		"context_type": contextType,
+		"context_id":   contextID,
+	}
+	if err := repository.UpdateConversationFieldsDAO(id, fields); err != nil {
+		return model.Conversation{}, err
+	}
+	return repository.GetConversationByIDDAO(id)
+}
+
+// DeleteConversationServ deletes a conversation and its messages; owners and admins may
+func DeleteConversationServ(id uint, userID *uint, privileges int) error {
+	if _, err := getVisibleConversation(id, userID, privileges); err != nil {
+		return err
+	}
+	return repository.DeleteConversationByIDDAO(id)
+}
+
+// getVisibleConversation loads a conversation the user may read. Other users' conversations are reported
+// as not found so their existence is not disclosed.
+func getVisibleConversation(id uint, userID *uint, privileges int) (model.Conversation, error) {
+	conversation, err := repository.GetConversationByIDDAO(id)
+	if err != nil {
+		return model.Conversation{}, err
+	}
+	if privileges >= 3 || (userID != nil && conversation.UserID == *userID) {
+		return conversation, nil
+	}
+	return model.Conversation{}, model.ErrNotFound
+}
+
+// getOwnConversation loads a conversation the user owns; admins can read but not write to other threads
+func getOwnConversation(id uint, userID *uint) (model.Conversation, error) {
+	if userID == nil {
+		return model.Conversation{}, model.ErrUnauthorized
+	}
+	conversation, err := repository.GetConversationByIDDAO(id)
+	if err != nil {
+		return model.Conversation{}, err
+	}
+	if conversation.UserID != *userID {
+		return model.Conversation{}, model.ErrNotFound
+	}
+	return conversation, nil
+}
+
+func createConversation(req ConversationReq, userID uint) (model.Conversation, error) {
+	contextType, contextID, err := validateConversationContext(req.ContextType, req.ContextID)
+	if err != nil {
+		return model.Conversation{}, err
+	}
+	conversation := model.Conversation{
+		UserID:      userID,
+		Title:       templateTruncate(255, strings.TrimSpace(req.Title)),
+		ProviderID:  req.ProviderID,
+		LLMModel:    req.Model,
+		ContextType: contextType,
+		ContextID:   contextID,
+	}
+	if err := repository.AddConversationDAO(&conversation); err != nil {
+		return model.Conversation{}, err
+	}
+	return conversation, nil
+}
+
+func validateConversationContext(contextType string, contextID *uint) (string, *uint, error) {
+	contextType = strings.ToLower(strings.TrimSpace(contextType))
+	if contextType == "" {
+		return "", nil, nil
+	}
+	if contextID == nil || *contextID == 0 {
+		return "", nil, fmt.Errorf("%w: context_id is required with context_type", model.ErrInvalidInput)
+	}
+	var err error
+	switch contextType {
+	case ConversationContextAlert:
+		_, err = repository.GetAlertByIDDAO(int(*contextID))
+	case ConversationContextHost:
+		_, err = repository.GetHostByIDDAO(*contextID)
+	case ConversationContextItem:
+		_, err = repository.GetItemByIDDAO(*contextID)
+	default:
+		return "", nil, fmt.Errorf("%w: context_type must be alert, host or item", model.ErrInvalidInput)
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %s %d not found", model.ErrInvalidInput, contextType, *contextID)
+	}
+	return contextType, contextID, nil
+}
+
+// resolveChatConversation returns the conversation a chat request continues, starting a new one titled after
+// the question when the request names none
+func resolveChatConversation(req ChatReq) (model.Conversation, error) {
+	if req.ConversationID != nil {
+		return getOwnConversation(*req.ConversationID, req.UserID)
+	}
+	if req.UserID == nil {
+		return model.Conversation{}, model.ErrUnauthorized
+	}
+	title, _, _ := strings.Cut(strings.TrimSpace(req.Content), "\n")
+	return createConversation(ConversationReq{
+		Title:       templateTruncate(conversationTitleLength, title),
+		ProviderID:  req.ProviderID,
+		Model:       req.Model,
+		ContextType: req.ContextType,
+		ContextID:   req.ContextID,
+	}, *req.UserID)
+}
+
+// conversationPrompt describes the pinned object and the summary of earlier turns for the system prompt
+func conversationPrompt(conversation model.Conversation) string {
+	var sections []string
+	if pinned := pinnedContextText(conversation); pinned != "" {
+		sections = append(sections, "PINNED CONTEXT (the conversation is about this object; values are current):\n"+pinned)
+	}
+	if summary := strings.TrimSpace(conversation.Summary); summary != "" {
+		sections = append(sections, "SUMMARY OF EARLIER CONVERSATION:\n"+summary)
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+func pinnedContextText(conversation model.Conversation) string {
+	if conversation.ContextID == nil {
+		return ""
+	}
+	id := *conversation.ContextID
+	switch conversation.ContextType {
+	case ConversationContextAlert:
+		alert, err := repository.GetAlertByIDDAO(int(id))
+		if err != nil {
+			return ""
+		}
+		text := fmt.Sprintf("Alert ID: %d\nSeverity: %s\nStatus: %s\nStarted: %s\nMessage: %s",
+			alert.ID, severityLabel(alert.Severity), alertStatusLabel(alert.Status),
+			alert.CreatedAt.Format(time.RFC3339), sanitizeSensitiveText(alert.Message))
+		if alert.ItemID != nil {
+			if item, err := repository.GetItemByIDDAO(*alert.ItemID); err == nil {
+				text += "\n" + pinnedItemText(item)
+			}
+		}
+		return text
+	case ConversationContextHost:
+		host, err := repository.GetHostByIDDAO(id)
+		if err != nil {
+			return ""
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "Host ID: %d\nHost: %s\nIP Address: %s\nStatus: %d\nDescription: %s\nMetrics:",
+			host.ID, sanitizeSensitiveText(host.Name), sanitizeSensitiveText(host.IPAddr), host.Status, sanitizeSensitiveText(host.Description))
+		items, _ := repository.GetItemsByHIDDAO(host.ID)
+		for i, item := range items {
+			if i == pinnedHostItemLimit {
+				fmt.Fprintf(&b, "\n- ... %d more", len(items)-i)
+				break
+			}
+			fmt.Fprintf(&b, "\n- %s: %s %s", sanitizeSensitiveText(item.Name), sanitizeSensitiveText(item.LastValue), sanitizeSensitiveText(item.Units))
+		}
+		return b.String()
+	case ConversationContextItem:
+		item, err := repository.GetItemByIDDAO(id)
+		if err != nil {
+			return ""
+		}
+		return pinnedItemText(item)
+	}
+	return ""
+}
+
+func pinnedItemText(item model.Item) string {
+	text := fmt.Sprintf("Item ID: %d\nItem Name: %s\nCurrent Value: %s\nUnits: %s",
+		item.ID, sanitizeSensitiveText(item.Name), sanitizeSensitiveText(item.LastValue), sanitizeSensitiveText(item.Units))
+	if host, err := repository.GetHostByIDDAO(item.HostID); err == nil {
+		text = "Host: " + sanitizeSensitiveText(host.Name) + "\n" + text
+	}
+	return text
+}
+
+func chatHistoryTokenBudget() int {
+	budget := viper.GetInt("ai.chat_history_token_budget")
+	if budget <= 0 {
+		return defaultChatHistoryTokenBudget
+	}
+	return budget
+}
+
+// estimateTokens approximates the token count of text: one token per CJK character and about four
+// characters per token otherwise
+func estimateTokens(text string) int {
+	wide, other := 0, 0
+	for _, r := range text {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			wide++
+		} else {
+			other++
+		}
+	}
+	return wide + (other+3)/4
+}
+
+// historyWindowStart returns the index of the oldest message that still fits the token budget, counting
+// from the newest; the newest message is always kept
+func historyWindowStart(chats []model.Chat, budget int) int {
+	used := 0
+	start := len(chats)
+	for start > 0 {
+		tokens := estimateTokens(chats[start-1].Content) + chatMessageOverheadTokens
+		if used+tokens > budget && start < len(chats) {
+			break
+		}
+		used += tokens
+		start--
+	}
+	return start
+}
+
+// loadConversationMessages returns the conversation turns that fit the history token budget, ending with
+// the question just stored. When older turns no longer fit they are folded into the conversation summary,
+// down to half the budget so that the following turns fit without summarizing again. The summary is written
+// by the model of the turn.
+func loadConversationMessages(ctx context.Context, turn chatTurn, conversation *model.Conversation, locale string) []llm.Message {
+	chats, err := repository.GetConversationChatsDAO(conversation.ID, conversation.SummaryUpTo)
+	if err != nil || len(chats) == 0 {
+		return nil
+	}
+
+	budget := chatHistoryTokenBudget()
+	if start := historyWindowStart(chats, budget); start > 0 {
+		start = historyWindowStart(chats, budget/2)
+		// Keep the window starting with a question
+		for start < len(chats)-1 && chats[start].Role != llm.RoleUser {
+			start++
+		}
+		if start > 0 {
+			summarizeConversation(ctx, turn, conversation, chats[:start], locale)
+			chats = chats[start:]
+		}
+	}
+
+	messages := make([]llm.Message, 0, len(chats))
+	for _, chat := range chats {
+		messages = append(messages, llm.Message{Role: chat.Role, Content: chat.Content})
+	}
+	return messages
+}
+
+// summarizeConversation merges turns leaving the history window into the conversation summary. On failure
+// the turns are only dropped from this request and summarizing is retried on the next one.
+func summarizeConversation(ctx context.Context, turn chatTurn, conversation *model.Conversation, turns []model.Chat, locale string) {
+	var b strings.Builder
+	if summary := strings.TrimSpace(conversation.Summary); summary != "" {
+		b.WriteString("EXISTING SUMMARY:\n")
+		b.WriteString(summary)
+		b.WriteString("\n\n")
+	}
+	b.WriteString("NEW TURNS:\n")
+	for _, chat := range turns {
+		fmt.Fprintf(&b, "%s: %s\n\n", chat.Role, templateTruncate(summaryTurnLength, chat.Content))
+	}
+
+	start := time.Now()
+	resp, err := turn.client.Chat(ctx, llm.ChatRequest{
+		Model:        turn.model,
+		SystemPrompt: conversationSummaryPrompt(isChinese(locale)),
+		Messages:     []llm.Message{{Role: llm.RoleUser, Content: b.String()}},
+	})
+	recordLLMRequest("chat_summary", turn.userID, turn.providerID, turn.model, time.Since(start), resp, err)
+	if err == nil && strings.TrimSpace(resp.Content) == "" {
+		err = fmt.Errorf("empty summary")
+	}
+	if err != nil {
+		LogService("warn", "failed to summarize conversation history", map[string]interface{}{
+			"conversation_id": conversation.ID,
+			"error":           err.Error(),
+		}, nil, "")
+		return
+	}
+
+	conversation.Summary = strings.TrimSpace(resp.Content)
+	conversation.SummaryUpTo = turns[len(turns)-1].ID
+	if err := repository.UpdateConversationFieldsDAO(conversation.ID, map[string]interface{}{
+		"summary":       conversation.Summary,
+		"summary_up_to": conversation.SummaryUpTo,
+	}); err != nil {
+		LogService("warn", "failed to store conversation summary", map[string]interface{}{
+			"conversation_id": conversation.ID,
+			"error":           err.Error(),
+		}, nil, "")
+	}
+}
+
+func conversationSummaryPrompt(chinese bool) string {
+	if chinese {
+		return "你负责压缩运维对话的历史记录。将已有摘要与新的对话轮次合并为一份摘要，保留用户的问题与目标、涉及的主机、告警、指标和数值、已得出的结论以及未解决的事项。只输出摘要本身，不超过 200 字。"
+	}
+	return "You condense the history of an operations chat. Merge the existing summary and the new turns into one summary " +
+		"that keeps the user's questions and goals, the hosts, alerts, metrics and values involved, the conclusions reached " +
+		"and anything still open. Output only the summary, at most 200 words."
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"nagare/internal/model"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	cases := []struct {
+		text string
+		want int
+	}{
+		{text: "", want: 0},
+		{text: "a", want: 1},
+		{text: "abcd", want: 1},
+		{text: "abcde", want: 2},
+		{text: "你好", want: 2},
+		{text: "こんにちは", want: 5},
+		{text: "カタカナ", want: 4},
+		{text: "안녕", want: 2},
+		{text: "hi 你好", want: 3},
+	}
+
+	for _, tc := range cases {
+		if got := estimateTokens(tc.text); got != tc.want {
+			t.Fatalf("estimateTokens(%q) = %d, want %d", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestHistoryWindowStart(t *testing.T) {
+	// Every "12345678" message costs 2 tokens of text plus the per-message overhead
+	perMessage := 2 + chatMessageOverheadTokens
+	turns := func(n int) []model.Chat {
+		chats := make([]model.Chat, n)
+		for i := range chats {
+			chats[i] = model.Chat{Content: "12345678"}
+		}
+		return chats
+	}
+	huge := model.Chat{Content: strings.Repeat("x", 4000)}
+
+	cases := []struct {
+		name   string
+		chats  []model.Chat
+		budget int
+		want   int
+	}{
+		{name: "empty history", chats: nil, budget: 100, want: 0},
+		{name: "single turn over budget is kept", chats: []model.Chat{huge}, budget: 10, want: 0},
+		{name: "newest turn over budget drops the rest", chats: []model.Chat{{Content: "hi"}, huge}, budget: 10, want: 1},
+		{name: "zero budget keeps the newest turn", chats: turns(3), budget: 0, want: 2},
+		{name: "everything fits", chats: turns(3), budget: 100, want: 0},
+		{name: "exact budget boundary keeps all", chats: turns(3), budget: 3 * perMessage, want: 0},
+		{name: "one token short drops the oldest", chats: turns(3), budget: 3*perMessage - 1, want: 1},
+		{name: "exactly two turns", chats: turns(3), budget: 2 * perMessage, want: 1},
+		{name: "just under two turns", chats: turns(3), budget: 2*perMessage - 1, want: 2},
+	}
+
+	for _, tc := range cases {
+		if got := historyWindowStart(tc.chats, tc.budget); got != tc.want {
+			t.Fatalf("%s: historyWindowStart = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
@@ -1,15 +1,254 @@
 package service
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
 	"fmt"
+	"html"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"nagare/internal/model"
+	"nagare/internal/repository"
+	mediaSvc "nagare/internal/repository/media"
+)
+
+const (
+	emailSendTimeout         = 60 * time.Second
+	reportAttachmentMaxBytes = 15 << 20
+
+	registrationCodeTTL         = 10 * time.Minute
+	registrationCodeCooldown    = time.Minute
+	registrationCodeMaxAttempts = 5
+)
+
+// registrationCode is a pending email verification code
+type registrationCode struct {
+	code      string
+	sentAt    time.Time
+	expiresAt time.Time
+	attempts  int
+}
+
+var (
+	registrationCodesMu sync.Mutex
+	registrationCodes   = map[string]*registrationCode{}
 )
 
-// SendEmailServ is a placeholder for email sending
+// SendEmailServ sends a plain text email to one or more comma separated recipients
 func SendEmailServ(to, subject, body string) error {
-	return fmt.Errorf("email service not configured")
+	return sendEmailTo(to, mediaSvc.EmailMessage{Subject: subject, Text: body})
 }
 
-// SendEmailHTMLServ is a placeholder for HTML email sending
+// SendEmailHTMLServ sends an HTML email with a generated plain text alternative
 func SendEmailHTMLServ(to, subject, htmlBody string) error {
-	return fmt.Errorf("email service not configured")
+	return sendEmailTo(to, mediaSvc.EmailMessage{Subject: subject, Text: htmlToText(htmlBody), HTML: htmlBody})
+}
+
+func sendEmailTo(to string, msg mediaSvc.EmailMessage) error {
+	if !mediaSvc.SMTPEnabled() {
+		return fmt.Errorf("email service not configured")
+	}
+	msg.To = mediaSvc.ParseEmailRecipients(to)
+	if len(msg.To) == 0 {
+		return fmt.Errorf("%w: no email recipients", model.ErrInvalidInput)
+	}
+	return sendEmail(msg)
+}
+
+// htmlToText strips tags from a simple HTML body for the text part
+func htmlToText(body string) string {
+	replacer := strings.NewReplacer("<br>", "\n", "<br/>", "\n", "<br />", "\n", "</p>", "\n\n", "</div>", "\n", "</tr>", "\n", "</h1>", "\n", "</h2>", "\n", "</h3>", "\n", "</li>", "\n")
+	body = replacer.Replace(body)
+	var b strings.Builder
+	inTag := false
+	for _, r := range body {
+		switch {
+		case r == '<':
+			inTag = true
+		case r == '>':
+			inTag = false
+		case !inTag:
+			b.WriteRune(r)
+		}
+	}
+	return strings.TrimSpace(html.UnescapeString(b.String()))
+}
+
+// sendEmail delivers a message through the registered SMTP provider
+func sendEmail(msg mediaSvc.EmailMessage) error {
+	provider, err := mediaSvc.GetService().GetProvider("smtp")
+	if err != nil {
+		return err
+	}
+	sender, ok := provider.(interface {
+		Send(ctx context.Context, msg mediaSvc.EmailMessage) error
+	})
+	if !ok {
+		return fmt.Errorf("smtp provider does not support rich email")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), emailSendTimeout)
+	defer cancel()
+	return sender.Send(ctx, msg)
+}
+
+// emailReportIfConfigured sends a finished scheduled report to the configured recipients with the PDF attached
+func emailReportIfConfigured(report model.Report, filePath, downloadURL string, data AdvancedReportData) {
+	if report.ReportType == "custom" {
+		return
+	}
+	config, err := repository.GetReportConfigDAO()
+	if err != nil || config.EmailNotify == 0 {
+		return
+	}
+	recipients := mediaSvc.ParseEmailRecipients(config.EmailRecipients)
+	if len(recipients) == 0 {
+		LogService("warn", "report email skipped: no recipients configured", map[string]interface{}{"report_id": report.ID}, nil, "")
+		return
+	}
+	if !mediaSvc.SMTPEnabled() {
+		LogService("warn", "report email skipped: smtp is disabled", map[string]interface{}{"report_id": report.ID}, nil, "")
+		return
+	}
+
+	lang := config.Language
+	title := resolvePDFDisplayTitle(report, "en")
+	link := webBaseURL() + downloadURL
+	text := strings.Join([]string{
+		title,
+		"",
+		fmt.Sprintf("%s: %d", T(lang, "total_alerts"), data.TotalAlerts),
+		fmt.Sprintf("%s: %.1f", T(lang, "avg_health"), data.AvgHealthScore),
+		fmt.Sprintf("%s: %d", T(lang, "critical_assets"), data.CriticalIssues),
+		"",
+		T(lang, "executive_summary") + ":",
+		data.Summary,
+		"",
+		link,
+	}, "\n")
+	htmlBody := fmt.Sprintf(`<h2>%s</h2>
+<table cellpadding="4">
+<tr><td>%s</td><td><b>%d</b></td></tr>
+<tr><td>%s</td><td><b>%.1f</b></td></tr>
+<tr><td>%s</td><td><b>%d</b></td></tr>
+</table>
+<h3>%s</h3>
+<p style="white-space:pre-wrap">%s</p>
+<p><a href="%s">%s</a></p>`,
+		html.EscapeString(title),
+		html.EscapeString(T(lang, "total_alerts")), data.TotalAlerts,
+		html.EscapeString(T(lang, "avg_health")), data.AvgHealthScore,
+		html.EscapeString(T(lang, "critical_assets")), data.CriticalIssues,
+		html.EscapeString(T(lang, "executive_summary")),
+		html.EscapeString(data.Summary),
+		html.EscapeString(link), html.EscapeString(link))
+
+	msg := mediaSvc.EmailMessage{
+		To:      recipients,
+		Subject: title,
+		Text:    text,
+		HTML:    htmlBody,
+	}
+	// Large reports are only linked so the mail is not rejected by size limits
+	if info, err := os.Stat(filePath); err == nil && info.Size() <= reportAttachmentMaxBytes {
+		if pdf, err := os.ReadFile(filePath); err == nil {
+			msg.Attachments = append(msg.Attachments, mediaSvc.EmailAttachment{
+				Filename:    filepath.Base(filePath),
+				ContentType: "application/pdf",
+				Data:        pdf,
+			})
+		}
+	}
+
+	if err := sendEmail(msg); err != nil {
+		LogService("error", "failed to email report", map[string]interface{}{
+			"report_id":  report.ID,
+			"recipients": len(recipients),
+			"error":      err.Error(),
+		}, nil, "")
+		return
+	}
+	LogService("info", "report emailed", map[string]interface{}{
+		"report_id":  report.ID,
+		"recipients": len(recipients),
+	}, nil, "")
+}
+
+// issueRegistrationCode emails a new six-digit verification code to the address
+func issueRegistrationCode(email string) error {
+	if !mediaSvc.SMTPEnabled() {
+		return fmt.Errorf("email verification is currently disabled")
+	}
+	key := strings.ToLower(strings.TrimSpace(email))
+	now := time.Now()
+
+	registrationCodesMu.Lock()
+	if existing, ok := registrationCodes[key]; ok && now.Sub(existing.sentAt) < registrationCodeCooldown {
+		registrationCodesMu.Unlock()
+		return fmt.Errorf("%w: a code was sent recently, please wait a minute before requesting another", model.ErrInvalidInput)
+	}
+	registrationCodesMu.Unlock()
+
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return err
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+	minutes := int(registrationCodeTTL.Minutes())
+	err = sendEmail(mediaSvc.EmailMessage{
+		To:      []string{email},
+		Subject: "Nagare verification code",
+		Text:    fmt.Sprintf("Your Nagare verification code is %s.\n\nIt expires in %d minutes. If you did not request it, ignore this email.", code, minutes),
+		HTML: fmt.Sprintf(`<p>Your Nagare verification code is</p><p style="font-size:24px;font-weight:bold;letter-spacing:4px">%s</p><p>It expires in %d minutes. If you did not request it, ignore this email.</p>`,
+			code, minutes),
+	})
+	if err != nil {
+		LogService("error", "failed to send registration code", map[string]interface{}{"email": email, "error": err.Error()}, nil, "")
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	registrationCodesMu.Lock()
+	defer registrationCodesMu.Unlock()
+	registrationCodes[key] = &registrationCode{code: code, sentAt: now, expiresAt: now.Add(registrationCodeTTL)}
+	// Drop expired codes so the map stays small
+	for k, c := range registrationCodes {
+		if now.After(c.expiresAt) {
+			delete(registrationCodes, k)
+		}
+	}
+	return nil
+}
+
+// checkRegistrationCode verifies a code without consuming it; too many wrong guesses invalidate it
+func checkRegistrationCode(email, code string) error {
+	key := strings.ToLower(strings.TrimSpace(email))
+	registrationCodesMu.Lock()
+	defer registrationCodesMu.Unlock()
+
+	pending, ok := registrationCodes[key]
+	if !ok || time.Now().After(pending.expiresAt) {
+		delete(registrationCodes, key)
+		return fmt.Errorf("%w: verification code expired or was not requested", model.ErrInvalidInput)
+	}
+	if subtle.ConstantTimeCompare([]byte(pending.code), []byte(strings.TrimSpace(code))) != 1 {
+		pending.attempts++
+		if pending.attempts >= registrationCodeMaxAttempts {
+			delete(registrationCodes, key)
+			return fmt.Errorf("%w: too many wrong codes, request a new one", model.ErrInvalidInput)
+		}
+		return fmt.Errorf("%w: verification code is incorrect", model.ErrInvalidInput)
+	}
+	return nil
+}
+
+// consumeRegistrationCode removes a code once the registration it verified went through
+func consumeRegistrationCode(email string) {
+	registrationCodesMu.Lock()
+	defer registrationCodesMu.Unlock()
+	delete(registrationCodes, strings.ToLower(strings.TrimSpace(email)))
 }
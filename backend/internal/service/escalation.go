@@ -0,0 +1,534 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"nagare/internal/model"
+	"nagare/internal/repository"
+)
+
+// Alert escalation states
+const (
+	EscalationStatusRunning      = 0
+	EscalationStatusAcknowledged = 1
+	EscalationStatusResolved     = 2
+	EscalationStatusCompleted    = 3
+	EscalationStatusCancelled    = 4
+)
+
+const (
+	escalationTickInterval   = 30 * time.Second
+	escalationBatchSize      = 100
+	escalationMaxSteps       = 20
+	escalationMaxRepeat      = 10
+	escalationMaxDelayMinute = 7 * 24 * 60
+)
+
+var (
+	escalationMu     sync.Mutex
+	escalationCancel context.CancelFunc
+)
+
+// EscalationPolicyReq represents an escalation policy request
+type EscalationPolicyReq struct {
+	Name        string                 `json:"name" binding:"required"`
+	Description string                 `json:"description"`
+	Enabled     int                    `json:"enabled"`
+	Steps       []model.EscalationStep `json:"steps" binding:"required"`
+}
+
+// EscalationPolicyResp represents an escalation policy response
+type EscalationPolicyResp struct {
+	ID          uint                   `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Enabled     int                    `json:"enabled"`
+	Steps       []model.EscalationStep `json:"steps"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
+
+// AlertEscalationStateResp describes one policy running for an alert
+type AlertEscalationStateResp struct {
+	ID          uint       `json:"id"`
+	PolicyID    uint       `json:"policy_id"`
+	PolicyName  string     `json:"policy_name"`
+	StepIndex   int        `json:"step_index"`
+	StepRepeats int        `json:"step_repeats"`
+	Status      int        `json:"status"`
+	StatusLabel string     `json:"status_label"`
+	NextRunAt   *time.Time `json:"next_run_at,omitempty"`
+	LastRunAt   *time.Time `json:"last_run_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// AlertEscalationResp is the escalation history of an alert
+type AlertEscalationResp struct {
+	AlertID         uint                         `json:"alert_id"`
+	EscalationLevel int                          `json:"escalation_level"`
+	Escalations     []AlertEscalationStateResp   `json:"escalations"`
+	Events          []model.AlertEscalationEvent `json:"events"`
+}
+
+// SearchEscalationPoliciesServ retrieves escalation policies by filter
+func SearchEscalationPoliciesServ(filter model.EscalationPolicyFilter) ([]EscalationPolicyResp, error) {
+	policies, err := repository.SearchEscalationPoliciesDAO(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search escalation policies: %w", err)
+	}
+	result := make([]EscalationPolicyResp, 0, len(policies))
+	for _, policy := range policies {
+		result = append(result, escalationPolicyToResp(policy))
+	}
+	return result, nil
+}
+
+// CountEscalationPoliciesServ returns total count for escalation policies by filter
+func CountEscalationPoliciesServ(filter model.EscalationPolicyFilter) (int64, error) {
+	return repository.CountEscalationPoliciesDAO(filter)
+}
+
+// GetEscalationPolicyByIDServ retrieves an escalation policy by ID
+func GetEscalationPolicyByIDServ(id uint) (EscalationPolicyResp, error) {
+	policy, err := repository.GetEscalationPolicyByIDDAO(id)
+	if err != nil {
+		return EscalationPolicyResp{}, err
+	}
+	return escalationPolicyToResp(policy), nil
+}
+
+// AddEscalationPolicyServ creates a new escalation policy
+func AddEscalationPolicyServ(req EscalationPolicyReq) (EscalationPolicyResp, error) {
+	if err := validateEscalationSteps(req.Steps); err != nil {
+		return EscalationPolicyResp{}, err
+	}
+	policy := model.EscalationPolicy{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Enabled:     req.Enabled,
+		Steps:       req.Steps,
+	}
+	if err := repository.AddEscalationPolicyDAO(&policy); err != nil {
+		return EscalationPolicyResp{}, fmt.Errorf("failed to add escalation policy: %w", err)
+	}
+	return escalationPolicyToResp(policy), nil
+}
+
+// UpdateEscalationPolicyServ updates an escalation policy; running escalations pick up the new steps
+func UpdateEscalationPolicyServ(id uint, req EscalationPolicyReq) error {
+	if _, err := repository.GetEscalationPolicyByIDDAO(id); err != nil {
+		return err
+	}
+	if err := validateEscalationSteps(req.Steps); err != nil {
+		return err
+	}
+	return repository.UpdateEscalationPolicyDAO(id, model.EscalationPolicy{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Enabled:     req.Enabled,
+		Steps:       req.Steps,
+	})
+}
+
+// DeleteEscalationPolicyServ deletes an escalation policy and cancels escalations still running it
+func DeleteEscalationPolicyServ(id uint) error {
+	if _, err := repository.GetEscalationPolicyByIDDAO(id); err != nil {
+		return err
+	}
+	return repository.DeleteEscalationPolicyByIDDAO(id)
+}
+
+// GetAlertEscalationServ returns the escalation state and fired steps of an alert
+func GetAlertEscalationServ(alertID uint) (AlertEscalationResp, error) {
+	alert, err := repository.GetAlertByIDDAO(int(alertID))
+	if err != nil {
+		return AlertEscalationResp{}, model.ErrNotFound
+	}
+	escalations, err := repository.GetAlertEscalationsByAlertDAO(alertID)
+	if err != nil {
+		return AlertEscalationResp{}, fmt.Errorf("failed to load escalations: %w", err)
+	}
+	events, err := repository.GetAlertEscalationEventsDAO(alertID)
+	if err != nil {
+		return AlertEscalationResp{}, fmt.Errorf("failed to load escalation events: %w", err)
+	}
+
+	states := make([]AlertEscalationStateResp, 0, len(escalations))
+	for _, escalation := range escalations {
+		state := AlertEscalationStateResp{
+			ID:          escalation.ID,
+			PolicyID:    escalation.PolicyID,
+			StepIndex:   escalation.StepIndex,
+			StepRepeats: escalation.StepRepeats,
+			Status:      escalation.Status,
+			StatusLabel: escalationStatusLabel(escalation.Status),
+			NextRunAt:   escalation.NextRunAt,
+			LastRunAt:   escalation.LastRunAt,
+			CreatedAt:   escalation.CreatedAt,
+		}
+		if policy, err := repository.GetEscalationPolicyByIDDAO(escalation.PolicyID); err == nil {
+			state.PolicyName = policy.Name
+		}
+		states = append(states, state)
+	}
+	return AlertEscalationResp{
+		AlertID:         alert.ID,
+		EscalationLevel: alert.EscalationLevel,
+		Escalations:     states,
+		Events:          events,
+	}, nil
+}
+
+// CancelAlertEscalationServ stops every running escalation of an alert
+func CancelAlertEscalationServ(alertID uint, userID *uint) error {
+	if _, err := repository.GetAlertByIDDAO(int(alertID)); err != nil {
+		return model.ErrNotFound
+	}
+	cancelled, err := repository.CancelAlertEscalationsByAlertDAO(alertID)
+	if err != nil {
+		return err
+	}
+	LogService("info", "alert escalation cancelled", map[string]interface{}{"alert_id": alertID, "escalations": cancelled}, userID, "")
+	return nil
+}
+
+// StartEscalationScheduler advances running escalations; state lives in the database so restarts resume where they left off
+func StartEscalationScheduler() {
+	escalationMu.Lock()
+	if escalationCancel != nil {
+		escalationMu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	escalationCancel = cancel
+	escalationMu.Unlock()
+
+	LogSystem("info", "escalation scheduler started", map[string]interface{}{"interval_seconds": int(escalationTickInterval.Seconds())}, nil, "")
+	go func() {
+		ticker := time.NewTicker(escalationTickInterval)
+		defer ticker.Stop()
+
+		processDueEscalations()
+		for {
+			select {
+			case <-ctx.Done():
+				LogSystem("info", "escalation scheduler stopped", nil, nil, "")
+				return
+			case <-ticker.C:
+				processDueEscalations()
+			}
+		}
+	}()
+}
+
+// StopEscalationScheduler stops the escalation scheduler
+func StopEscalationScheduler() {
+	escalationMu.Lock()
+	defer escalationMu.Unlock()
+	if escalationCancel != nil {
+		escalationCancel()
+		escalationCancel = nil
+	}
+}
+
+// startAlertEscalation begins a policy for an alert; the first step fires after its delay
+func startAlertEscalation(alert model.Alert, policyID uint) {
+	if alert.Status != 0 {
+		return
+	}
+	policy, err := repository.GetEscalationPolicyByIDDAO(policyID)
+	if err != nil {
+		LogService("warn", "escalation policy not found", map[string]interface{}{"alert_id": alert.ID, "policy_id": policyID}, nil, "")
+		return
+	}
+	if policy.Enabled == 0 || len(policy.Steps) == 0 {
+		return
+	}
+
+	next := time.Now().Add(escalationStepDelay(policy.Steps[0], false))
+	escalation := model.AlertEscalation{
+		AlertID:   alert.ID,
+		PolicyID:  policy.ID,
+		Status:    EscalationStatusRunning,
+		NextRunAt: &next,
+	}
+	started, err := repository.StartAlertEscalationDAO(&escalation)
+	if err != nil {
+		LogService("error", "failed to start alert escalation", map[string]interface{}{"alert_id": alert.ID, "policy_id": policy.ID, "error": err.Error()}, nil, "")
+		return
+	}
+	if started {
+		LogService("info", "alert escalation started", map[string]interface{}{"alert_id": alert.ID, "policy_id": policy.ID, "next_run_at": next}, nil, "")
+	}
+}
+
+func processDueEscalations() {
+	escalations, err := repository.GetDueAlertEscalationsDAO(time.Now(), escalationBatchSize)
+	if err != nil {
+		LogSystem("error", "failed to load due escalations", map[string]interface{}{"error": err.Error()}, nil, "")
+		return
+	}
+	for _, escalation := range escalations {
+		advanceEscalation(escalation)
+	}
+}
+
+// advanceEscalation fires the next step of an escalation, or stops it once the alert is handled
+func advanceEscalation(escalation model.AlertEscalation) {
+	stop := func(status int, reason string) {
+		if err := repository.UpdateAlertEscalationDAO(escalation.ID, map[string]interface{}{"status": status, "next_run_at": nil}); err != nil {
+			LogService("error", "failed to stop alert escalation", map[string]interface{}{"escalation_id": escalation.ID, "error": err.Error()}, nil, "")
+			return
+		}
+		LogService("info", "alert escalation stopped", map[string]interface{}{"alert_id": escalation.AlertID, "policy_id": escalation.PolicyID, "reason": reason}, nil, "")
+	}
+
+	alert, err := repository.GetAlertByIDDAO(int(escalation.AlertID))
+	if err != nil {
+		stop(EscalationStatusCancelled, "alert deleted")
+		return
+	}
+	var incident *model.Incident
+	if alert.IncidentID != nil {
+		if found, err := repository.GetIncidentByIDDAO(*alert.IncidentID); err == nil {
+			incident = &found
+		}
+	}
+	if status, reason, done := escalationStopStatus(alert, incident); done {
+		stop(status, reason)
+		return
+	}
+
+	policy, err := repository.GetEscalationPolicyByIDDAO(escalation.PolicyID)
+	if err != nil || policy.Enabled == 0 {
+		stop(EscalationStatusCancelled, "policy deleted or disabled")
+		return
+	}
+	if escalation.StepIndex >= len(policy.Steps) {
+		stop(EscalationStatusCompleted, "no steps left")
+		return
+	}
+
+	step := policy.Steps[escalation.StepIndex]
+	fireEscalationStep(alert, policy, escalation.StepIndex, escalation.StepRepeats+1, step)
+	if err := repository.UpdateAlertEscalationLevelDAO(alert.ID, escalation.StepIndex+1); err != nil {
+		LogService("warn", "failed to update alert escalation level", map[string]interface{}{"alert_id": alert.ID, "error": err.Error()}, nil, "")
+	}
+
+	now := time.Now()
+	next := nextEscalationState(escalation, policy.Steps, now)
+	fields := map[string]interface{}{
+		"last_run_at":  now,
+		"step_index":   next.StepIndex,
+		"step_repeats": next.StepRepeats,
+		"next_run_at":  next.NextRunAt,
+	}
+	if next.Completed {
+		fields["status"] = EscalationStatusCompleted
+	}
+	if err := repository.UpdateAlertEscalationDAO(escalation.ID, fields); err != nil {
+		LogService("error", "failed to advance alert escalation", map[string]interface{}{"escalation_id": escalation.ID, "error": err.Error()}, nil, "")
+	}
+}
+
+// escalationStopStatus reports whether an alert no longer needs escalating and the status to record
+func escalationStopStatus(alert model.Alert, incident *model.Incident) (int, string, bool) {
+	switch alert.Status {
+	case 1:
+		return EscalationStatusAcknowledged, "alert acknowledged", true
+	case 2:
+		return EscalationStatusResolved, "alert resolved", true
+	}
+	if incident != nil {
+		if incident.Status == IncidentStatusResolved {
+			return EscalationStatusResolved, "incident resolved", true
+		}
+		if incident.Status != IncidentStatusOpen {
+			return EscalationStatusAcknowledged, "incident acknowledged", true
+		}
+	}
+	return EscalationStatusRunning, "", false
+}
+
+// escalationState is where an escalation stands after its current step fires
+type escalationState struct {
+	StepIndex   int
+	StepRepeats int
+	NextRunAt   *time.Time // nil once the policy is exhausted
+	Completed   bool
+}
+
+// nextEscalationState repeats the current step until its repeats are used up, then moves to the next step
+func nextEscalationState(escalation model.AlertEscalation, steps []model.EscalationStep, now time.Time) escalationState {
+	if escalation.StepIndex < len(steps) && escalation.StepRepeats < steps[escalation.StepIndex].Repeat {
+		next := now.Add(escalationStepDelay(steps[escalation.StepIndex], true))
+		return escalationState{StepIndex: escalation.StepIndex, StepRepeats: escalation.StepRepeats + 1, NextRunAt: &next}
+	}
+	if escalation.StepIndex+1 < len(steps) {
+		next := now.Add(escalationStepDelay(steps[escalation.StepIndex+1], false))
+		return escalationState{StepIndex: escalation.StepIndex + 1, NextRunAt: &next}
+	}
+	return escalationState{StepIndex: escalation.StepIndex + 1, Completed: true}
+}
+
+// fireEscalationStep notifies the step's media and users and records the result on the alert
+func fireEscalationStep(alert model.Alert, policy model.EscalationPolicy, stepIndex, attempt int, step model.EscalationStep) {
+	users := make([]model.User, 0, len(step.UserIDs))
+	for _, uid := range step.UserIDs {
+		if user, err := repository.GetUserByIDDAO(int(uid)); err == nil {
+			users = append(users, user)
+		}
+	}
+
+	replacements := buildAlertReplacements(buildAlertMatchContext(alert))
+	replacements["{{escalation_step}}"] = fmt.Sprintf("%d", stepIndex+1)
+	replacements["{{escalation_policy}}"] = policy.Name
+	msg := renderMessageTemplate("[Escalation {{escalation_policy}} step {{escalation_step}}] Alert still unacknowledged: {{message}}", replacements)
+	msg = appendAlertDetails(msg, replacements)
+
+	event := model.AlertEscalationEvent{
+		AlertID:   alert.ID,
+		PolicyID:  policy.ID,
+		StepIndex: stepIndex,
+		Attempt:   attempt,
+	}
+	targets := make([]string, 0, len(users)+1)
+	errs := make([]string, 0)
+
+	// Steps deliver like an action bound to the step's media and users
+	stepAction := model.Action{
+		Name:             fmt.Sprintf("%s step %d", policy.Name, stepIndex+1),
+		MediaID:          step.MediaID,
+		Users:            users,
+		OnCallScheduleID: normalizeOptionalID(&step.ScheduleID),
+	}
+	alertID := alert.ID
+	source := notificationSource{Kind: NotificationKindEscalation, AlertID: &alertID, Digest: defaultDigestSettings()}
+	deliverActionMessage(stepAction, func(media model.Media, recipientID *uint) error {
+		targets = append(targets, media.Target)
+		err := enqueueNotification(source, media, recipientID, msg)
+		if err != nil {
+			event.Failed++
+			errs = append(errs, fmt.Sprintf("%s: %v", media.Target, err))
+		} else {
+			event.Sent++
+		}
+		return err
+	})
+	event.Targets = strings.Join(targets, ", ")
+	event.Error = strings.Join(errs, "; ")
+	if len(targets) == 0 {
+		event.Error = "no deliverable targets for this step"
+	}
+
+	if err := repository.AddAlertEscalationEventDAO(&event); err != nil {
+		LogService("warn", "failed to record escalation step", map[string]interface{}{"alert_id": alert.ID, "error": err.Error()}, nil, "")
+	}
+	LogService("info", "escalation step fired", map[string]interface{}{
+		"alert_id":  alert.ID,
+		"policy_id": policy.ID,
+		"step":      stepIndex + 1,
+		"attempt":   attempt,
+		"sent":      event.Sent,
+		"failed":    event.Failed,
+	}, nil, "")
+}
+
+// escalationStepDelay is the wait before a step fires; repeats wait at least a minute
+func escalationStepDelay(step model.EscalationStep, repeat bool) time.Duration {
+	delay := time.Duration(step.DelayMinutes) * time.Minute
+	if repeat && delay < time.Minute {
+		delay = time.Minute
+	}
+	return delay
+}
+
+func validateEscalationSteps(steps []model.EscalationStep) error {
+	if len(steps) == 0 {
+		return fmt.Errorf("%w: escalation policy requires at least one step", model.ErrInvalidInput)
+	}
+	if len(steps) > escalationMaxSteps {
+		return fmt.Errorf("%w: escalation policy supports at most %d steps", model.ErrInvalidInput, escalationMaxSteps)
+	}
+	for i, step := range steps {
+		if step.DelayMinutes < 0 || step.DelayMinutes > escalationMaxDelayMinute {
+			return fmt.Errorf("%w: step %d delay_minutes must be between 0 and %d", model.ErrInvalidInput, i+1, escalationMaxDelayMinute)
+		}
+		if step.Repeat < 0 || step.Repeat > escalationMaxRepeat {
+			return fmt.Errorf("%w: step %d repeat must be between 0 and %d", model.ErrInvalidInput, i+1, escalationMaxRepeat)
+		}
+		if step.MediaID == 0 {
+			return fmt.Errorf("%w: step %d requires a media", model.ErrInvalidInput, i+1)
+		}
+		if _, err := repository.GetMediaByIDDAO(step.MediaID); err != nil {
+			return fmt.Errorf("%w: step %d media %d not found", model.ErrInvalidInput, i+1, step.MediaID)
+		}
+		for _, uid := range step.UserIDs {
+			if _, err := repository.GetUserByIDDAO(int(uid)); err != nil {
+				return fmt.Errorf("%w: step %d user %d not found", model.ErrInvalidInput, i+1, uid)
+			}
+		}
+		if step.ScheduleID != 0 {
+			if _, err := repository.GetOnCallScheduleByIDDAO(step.ScheduleID); err != nil {
+				return fmt.Errorf("%w: step %d on-call schedule %d not found", model.ErrInvalidInput, i+1, step.ScheduleID)
+			}
+		}
+	}
+	return nil
+}
+
+func validateActionEscalationPolicy(policyID *uint) error {
+	if policyID == nil || *policyID == 0 {
+		return nil
+	}
+	if _, err := repository.GetEscalationPolicyByIDDAO(*policyID); err != nil {
+		return fmt.Errorf("%w: escalation policy %d not found", model.ErrInvalidInput, *policyID)
+	}
+	return nil
+}
+
+// normalizeOptionalID treats 0 as "not set"
+func normalizeOptionalID(id *uint) *uint {
+	if id == nil || *id == 0 {
+		return nil
+	}
+	value := *id
+	return &value
+}
+
+func escalationPolicyToResp(policy model.EscalationPolicy) EscalationPolicyResp {
+	steps := policy.Steps
+	if steps == nil {
+		steps = []model.EscalationStep{}
+	}
+	return EscalationPolicyResp{
+		ID:          policy.ID,
+		Name:        policy.Name,
+		Description: policy.Description,
+		Enabled:     policy.Enabled,
+		Steps:       steps,
+		CreatedAt:   policy.CreatedAt,
+		UpdatedAt:   policy.UpdatedAt,
+	}
+}
+
+func escalationStatusLabel(status int) string {
+	switch status {
+	case EscalationStatusRunning:
+		return "running"
+	case EscalationStatusAcknowledged:
+		return "acknowledged"
+	case EscalationStatusResolved:
+		return "resolved"
+	case EscalationStatusCompleted:
+		return "completed"
+	case EscalationStatusCancelled:
+		return "cancelled"
+	default:
+		return "unknown"
+	}
+}
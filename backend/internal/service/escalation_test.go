@@ -0,0 +1,75 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"nagare/internal/model"
+)
+
+func TestNextEscalationState(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	steps := []model.EscalationStep{
+		{DelayMinutes: 0, Repeat: 2},
+		{DelayMinutes: 15},
+		{DelayMinutes: 30, Repeat: 1},
+	}
+	at := func(minutes int) *time.Time {
+		v := now.Add(time.Duration(minutes) * time.Minute)
+		return &v
+	}
+
+	cases := []struct {
+		name       string
+		escalation model.AlertEscalation
+		steps      []model.EscalationStep
+		want       escalationState
+	}{
+		{name: "first repeat waits at least a minute", escalation: model.AlertEscalation{StepIndex: 0}, steps: steps, want: escalationState{StepIndex: 0, StepRepeats: 1, NextRunAt: at(1)}},
+		{name: "second repeat", escalation: model.AlertEscalation{StepIndex: 0, StepRepeats: 1}, steps: steps, want: escalationState{StepIndex: 0, StepRepeats: 2, NextRunAt: at(1)}},
+		{name: "repeats exhausted moves on", escalation: model.AlertEscalation{StepIndex: 0, StepRepeats: 2}, steps: steps, want: escalationState{StepIndex: 1, NextRunAt: at(15)}},
+		{name: "step without repeats moves on", escalation: model.AlertEscalation{StepIndex: 1}, steps: steps, want: escalationState{StepIndex: 2, NextRunAt: at(30)}},
+		{name: "last step repeats", escalation: model.AlertEscalation{StepIndex: 2}, steps: steps, want: escalationState{StepIndex: 2, StepRepeats: 1, NextRunAt: at(30)}},
+		{name: "last step exhausted completes", escalation: model.AlertEscalation{StepIndex: 2, StepRepeats: 1}, steps: steps, want: escalationState{StepIndex: 3, Completed: true}},
+		{name: "single step policy completes", escalation: model.AlertEscalation{StepIndex: 0}, steps: []model.EscalationStep{{DelayMinutes: 5}}, want: escalationState{StepIndex: 1, Completed: true}},
+		{name: "next step without delay fires immediately", escalation: model.AlertEscalation{StepIndex: 0}, steps: []model.EscalationStep{{DelayMinutes: 5}, {DelayMinutes: 0}}, want: escalationState{StepIndex: 1, NextRunAt: at(0)}},
+	}
+
+	for _, tc := range cases {
+		got := nextEscalationState(tc.escalation, tc.steps, now)
+		if got.StepIndex != tc.want.StepIndex || got.StepRepeats != tc.want.StepRepeats || got.Completed != tc.want.Completed {
+			t.Fatalf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+		if (got.NextRunAt == nil) != (tc.want.NextRunAt == nil) || (got.NextRunAt != nil && !got.NextRunAt.Equal(*tc.want.NextRunAt)) {
+			t.Fatalf("%s: next run %v, want %v", tc.name, got.NextRunAt, tc.want.NextRunAt)
+		}
+	}
+}
+
+func TestEscalationStopStatus(t *testing.T) {
+	incident := func(status int) *model.Incident { return &model.Incident{Status: status} }
+
+	cases := []struct {
+		name       string
+		alert      model.Alert
+		incident   *model.Incident
+		wantStop   bool
+		wantStatus int
+	}{
+		{name: "open alert keeps escalating", alert: model.Alert{Status: 0}, wantStop: false, wantStatus: EscalationStatusRunning},
+		{name: "alert acknowledged mid policy", alert: model.Alert{Status: 1}, wantStop: true, wantStatus: EscalationStatusAcknowledged},
+		{name: "alert resolved", alert: model.Alert{Status: 2}, wantStop: true, wantStatus: EscalationStatusResolved},
+		{name: "open incident keeps escalating", alert: model.Alert{Status: 0}, incident: incident(IncidentStatusOpen), wantStop: false, wantStatus: EscalationStatusRunning},
+		{name: "incident acknowledged mid policy", alert: model.Alert{Status: 0}, incident: incident(IncidentStatusAcknowledged), wantStop: true, wantStatus: EscalationStatusAcknowledged},
+		{name: "incident mitigated", alert: model.Alert{Status: 0}, incident: incident(IncidentStatusMitigated), wantStop: true, wantStatus: EscalationStatusAcknowledged},
+		{name: "incident resolved", alert: model.Alert{Status: 0}, incident: incident(IncidentStatusResolved), wantStop: true, wantStatus: EscalationStatusResolved},
+		{name: "alert state wins over incident", alert: model.Alert{Status: 2}, incident: incident(IncidentStatusAcknowledged), wantStop: true, wantStatus: EscalationStatusResolved},
+	}
+
+	for _, tc := range cases {
+		status, reason, stop := escalationStopStatus(tc.alert, tc.incident)
+		if stop != tc.wantStop || status != tc.wantStatus {
+			t.Fatalf("%s: got status %d stop %v (%q), want status %d stop %v", tc.name, status, stop, reason, tc.wantStatus, tc.wantStop)
+		}
+	}
+}
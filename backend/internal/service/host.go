@@ -29,12 +29,19 @@ type HostReq struct {
 	SSHPassword string `json:"ssh_password"`
 	SSHPort     int    `json:"ssh_port"`
 	// SNMP Configuration
-	SNMPCommunity  string     `json:"snmp_community"`
-	SNMPVersion    string     `json:"snmp_version"`
-	SNMPPort       int        `json:"snmp_port"`
-	LastSyncAt     *time.Time `json:"last_sync_at,omitempty"`
-	ExternalSource string     `json:"external_source,omitempty"`
-	PushToMonitor  bool       `json:"push_to_monitor"`
+	SNMPCommunity string `json:"snmp_community"`
+	SNMPVersion   string `json:"snmp_version"`
+	SNMPPort      int    `json:"snmp_port"`
+	// SNMPv3 Configuration
+	SNMPV3User          string     `json:"snmp_v3_user"`
+	SNMPV3SecurityLevel string     `json:"snmp_v3_security_level"`
+	SNMPV3AuthProtocol  string     `json:"snmp_v3_auth_protocol"`
+	SNMPV3AuthPassword  string     `json:"snmp_v3_auth_password"`
+	SNMPV3PrivProtocol  string     `json:"snmp_v3_priv_protocol"`
+	SNMPV3PrivPassword  string     `json:"snmp_v3_priv_password"`
+	LastSyncAt          *time.Time `json:"last_sync_at,omitempty"`
+	ExternalSource      string     `json:"external_source,omitempty"`
+	PushToMonitor       bool       `json:"push_to_monitor"`
 }
 
 // HostResp represents a host response
@@ -55,12 +62,17 @@ type HostResp struct {
 	SSHUser     string `json:"ssh_user"`
 	SSHPort     int    `json:"ssh_port"`
 	// SNMP Configuration
-	SNMPCommunity  string     `json:"snmp_community"`
-	SNMPVersion    string     `json:"snmp_version"`
-	SNMPPort       int        `json:"snmp_port"`
-	LastSyncAt     *time.Time `json:"last_sync_at"`
-	ExternalSource string     `json:"external_source"`
-	HealthScore    int        `json:"health_score"`
+	SNMPCommunity string `json:"snmp_community"`
+	SNMPVersion   string `json:"snmp_version"`
+	SNMPPort      int    `json:"snmp_port"`
+	// SNMPv3 Configuration (passwords are never returned)
+	SNMPV3User          string     `json:"snmp_v3_user"`
+	SNMPV3SecurityLevel string     `json:"snmp_v3_security_level"`
+	SNMPV3AuthProtocol  string     `json:"snmp_v3_auth_protocol"`
+	SNMPV3PrivProtocol  string     `json:"snmp_v3_priv_protocol"`
+	LastSyncAt          *time.Time `json:"last_sync_at"`
+	ExternalSource      string     `json:"external_source"`
+	HealthScore         int        `json:"health_score"`
 }
 
 // GetAllHostsServ retrieves all hosts
@@ -135,18 +147,22 @@ func AddHostServ(h HostReq) (HostResp, error) {
 	}
 
 	newHost := model.Host{
-		Name:          h.Name,
-		ExternalID:    h.ExternalID,
-		GroupID:       h.GroupID,
-		Description:   h.Description,
-		Enabled:       h.Enabled,
-		IPAddr:        h.IPAddr,
-		Comment:       h.Comment,
-		SSHUser:       h.SSHUser,
-		SSHPort:       h.SSHPort,
-		SNMPCommunity: h.SNMPCommunity,
-		SNMPVersion:   h.SNMPVersion,
-		SNMPPort:      h.SNMPPort,
+		Name:                h.Name,
+		ExternalID:          h.ExternalID,
+		GroupID:             h.GroupID,
+		Description:         h.Description,
+		Enabled:             h.Enabled,
+		IPAddr:              h.IPAddr,
+		Comment:             h.Comment,
+		SSHUser:             h.SSHUser,
+		SSHPort:             h.SSHPort,
+		SNMPCommunity:       h.SNMPCommunity,
+		SNMPVersion:         h.SNMPVersion,
+		SNMPPort:            h.SNMPPort,
+		SNMPV3User:          h.SNMPV3User,
+		SNMPV3SecurityLevel: h.SNMPV3SecurityLevel,
+		SNMPV3AuthProtocol:  h.SNMPV3AuthProtocol,
+		SNMPV3PrivProtocol:  h.SNMPV3PrivProtocol,
 	}
 	if h.SNMPPort == 0 {
 		newHost.SNMPPort = 161
@@ -166,6 +182,11 @@ func AddHostServ(h HostReq) (HostResp, error) {
 			newHost.SSHPassword = encrypted
 		}
 	}
+	authPassword, privPassword, err := encryptSNMPv3Passwords(h.SNMPV3AuthPassword, h.SNMPV3PrivPassword)
+	if err != nil {
+		return HostResp{}, err
+	}
+	newHost.SNMPV3AuthPassword, newHost.SNMPV3PrivPassword = authPassword, privPassword
 	if h.MID == 0 {
 		internalMonitors, sErr := repository.SearchMonitorsDAO(model.MonitorFilter{Query: "Nagare Internal"})
 		if sErr == nil && len(internalMonitors) > 0 {
@@ -178,6 +199,7 @@ func AddHostServ(h HostReq) (HostResp, error) {
 	if err := repository.AddHostDAO(&newHost); err != nil {
 		return HostResp{}, fmt.Errorf("failed to add host: %w", err)
 	}
+	assignSNMPHostExternalID(newHost.ID)
 
 	fmt.Printf("[DEBUG] AddHostServ: MID=%d, PushToMonitor=%v\n", h.MID, h.PushToMonitor)
 	// Auto-push to monitor asynchronously if MID is set AND PushToMonitor is true
@@ -227,21 +249,25 @@ func UpdateHostServ(id uint, h HostReq) error {
 	}
 
 	updated := model.Host{
-		Name:              h.Name,
-		ExternalID:        h.ExternalID,
-		GroupID:           h.GroupID,
-		Description:       h.Description,
-		Enabled:           h.Enabled,
-		IPAddr:            h.IPAddr,
-		Comment:           h.Comment,
-		SSHUser:           h.SSHUser,
-		SSHPort:           h.SSHPort,
-		SNMPCommunity:     h.SNMPCommunity,
-		SNMPVersion:       h.SNMPVersion,
-		SNMPPort:          h.SNMPPort,
-		LastSyncAt:        existing.LastSyncAt,
-		Status:            existing.Status,
-		StatusDescription: existing.StatusDescription,
+		Name:                h.Name,
+		ExternalID:          h.ExternalID,
+		GroupID:             h.GroupID,
+		Description:         h.Description,
+		Enabled:             h.Enabled,
+		IPAddr:              h.IPAddr,
+		Comment:             h.Comment,
+		SSHUser:             h.SSHUser,
+		SSHPort:             h.SSHPort,
+		SNMPCommunity:       h.SNMPCommunity,
+		SNMPVersion:         h.SNMPVersion,
+		SNMPPort:            h.SNMPPort,
+		SNMPV3User:          h.SNMPV3User,
+		SNMPV3SecurityLevel: h.SNMPV3SecurityLevel,
+		SNMPV3AuthProtocol:  h.SNMPV3AuthProtocol,
+		SNMPV3PrivProtocol:  h.SNMPV3PrivProtocol,
+		LastSyncAt:          existing.LastSyncAt,
+		Status:              existing.Status,
+		StatusDescription:   existing.StatusDescription,
 	}
 	if h.LastSyncAt != nil {
 		updated.LastSyncAt = h.LastSyncAt
@@ -266,9 +292,16 @@ func UpdateHostServ(id uint, h HostReq) error {
 	} else {
 		updated.SSHPassword = existing.SSHPassword
 	}
+	// Empty SNMPv3 passwords keep the stored ones (UpdateHostDAO skips empty values)
+	authPassword, privPassword, err := encryptSNMPv3Passwords(h.SNMPV3AuthPassword, h.SNMPV3PrivPassword)
+	if err != nil {
+		return err
+	}
+	updated.SNMPV3AuthPassword, updated.SNMPV3PrivPassword = authPassword, privPassword
 	if err := repository.UpdateHostDAO(id, updated); err != nil {
 		return err
 	}
+	assignSNMPHostExternalID(id)
 
 	// Auto-push to monitor asynchronously only if PushToMonitor is true
 	if h.MID > 0 && h.PushToMonitor {
@@ -524,7 +557,12 @@ func createMonitorClient(monitor MonitorResp) (*monitors.Client, error) {
 		},
 		Timeout: 30,
 	}
-	return monitors.NewClient(cfg)
+	client, err := monitors.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	attachSNMPTargetSource(client, uint(monitor.ID))
+	return client, nil
 }
 
 // SyncResult represents the result of a sync operation
@@ -603,6 +641,11 @@ func hostToResp(h model.Host) HostResp {
 		SNMPPort:      h.SNMPPort,
 		LastSyncAt:    h.LastSyncAt,
 		HealthScore:   h.HealthScore,
+
+		SNMPV3User:          h.SNMPV3User,
+		SNMPV3SecurityLevel: h.SNMPV3SecurityLevel,
+		SNMPV3AuthProtocol:  h.SNMPV3AuthProtocol,
+		SNMPV3PrivProtocol:  h.SNMPV3PrivProtocol,
 	}
 }
 
@@ -955,6 +998,11 @@ func pullHostsFromMonitorServ(mid uint, recordHistory bool) (SyncResult, error)
 				SNMPVersion:       snmpVersion,
 				SNMPPort:          snmpPort,
 				LastSyncAt:        &now,
+
+				SNMPV3User:          existingHost.SNMPV3User,
+				SNMPV3SecurityLevel: existingHost.SNMPV3SecurityLevel,
+				SNMPV3AuthProtocol:  existingHost.SNMPV3AuthProtocol,
+				SNMPV3PrivProtocol:  existingHost.SNMPV3PrivProtocol,
 			}); err != nil {
 				setHostStatusErrorWithReason(existingHost.ID, err.Error())
 				LogService("error", "pull hosts failed to update host", map[string]interface{}{"monitor_id": mid, "host_id": existingHost.ID, "error": err.Error()}, nil, "")
@@ -1175,6 +1223,11 @@ func PullHostFromMonitorServ(mid, id uint) (SyncResult, error) {
 			SNMPVersion:       snmpVersion,
 			SNMPPort:          snmpPort,
 			LastSyncAt:        &now,
+
+			SNMPV3User:          existingHost.SNMPV3User,
+			SNMPV3SecurityLevel: existingHost.SNMPV3SecurityLevel,
+			SNMPV3AuthProtocol:  existingHost.SNMPV3AuthProtocol,
+			SNMPV3PrivProtocol:  existingHost.SNMPV3PrivProtocol,
 		}); err != nil {
 			setHostStatusErrorWithReason(existingHost.ID, err.Error())
 			LogService("error", "pull host failed to update host", map[string]interface{}{"monitor_id": mid, "host_id": existingHost.ID, "error": err.Error()}, nil, "")
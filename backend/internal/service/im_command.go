@@ -144,6 +144,110 @@ func handleGetAlertsCommand(args []string, rawArgs string) (IMCommandResult, err
 	return IMCommandResult{Reply: reply.String()}, nil
 }
 
+func handleIncidentsCommand(args []string, rawArgs string) (IMCommandResult, error) {
+	options, flags := parseIMOptions(args)
+	filter := model.IncidentFilter{Query: options["q"], Active: true, Limit: 10}
+	for _, flag := range flags {
+		switch flag {
+		case "all":
+			filter.Active = false
+		case "active", "open":
+			filter.Active = true
+		}
+	}
+	if val, ok := options["status"]; ok {
+		if parsed, err := strconv.Atoi(val); err == nil {
+			filter.Status = intPtr(parsed)
+			filter.Active = false
+		}
+	}
+	if val, ok := options["limit"]; ok {
+		if parsed, err := strconv.Atoi(val); err == nil && parsed > 0 {
+			filter.Limit = parsed
+		}
+	}
+
+	incidents, err := SearchIncidentsServ(filter)
+	if err != nil {
+		return IMCommandResult{Reply: fmt.Sprintf("Error retrieving incidents: %v", err)}, nil
+	}
+	if len(incidents) == 0 {
+		return IMCommandResult{Reply: "No incidents found."}, nil
+	}
+
+	var reply strings.Builder
+	reply.WriteString(fmt.Sprintf("Incidents (%d):\n", len(incidents)))
+	for _, incident := range incidents {
+		reply.WriteString(fmt.Sprintf("#%d [%s] %s (Severity: %d, Alerts: %d)\n", incident.ID, incident.StatusLabel, incident.Title, incident.Severity, incident.AlertCount))
+	}
+	return IMCommandResult{Reply: reply.String()}, nil
+}
+
+func handleIncidentCommand(args []string, rawArgs string) (IMCommandResult, error) {
+	usage := "Usage: /incident <id> | /incident ack|mitigate|resolve|reopen <id> [note]"
+	if len(args) == 0 {
+		return IMCommandResult{Reply: usage}, nil
+	}
+
+	statuses := map[string]int{
+		"ack":         IncidentStatusAcknowledged,
+		"acknowledge": IncidentStatusAcknowledged,
+		"mitigate":    IncidentStatusMitigated,
+		"resolve":     IncidentStatusResolved,
+		"reopen":      IncidentStatusOpen,
+	}
+	verb := strings.ToLower(args[0])
+	status, isTransition := statuses[verb]
+	idArg := args[0]
+	if isTransition {
+		if len(args) < 2 {
+			return IMCommandResult{Reply: usage}, nil
+		}
+		idArg = args[1]
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(idArg, "#"), 10, 64)
+	if err != nil {
+		return IMCommandResult{Reply: usage}, nil
+	}
+
+	if isTransition {
+		req := IncidentUpdateReq{Status: &status}
+		if len(args) > 2 {
+			req.Note = "via IM: " + strings.Join(args[2:], " ")
+		}
+		incident, err := UpdateIncidentServ(uint(id), req, nil)
+		if err != nil {
+			return IMCommandResult{Reply: fmt.Sprintf("Error updating incident: %v", err)}, nil
+		}
+		return IMCommandResult{Reply: fmt.Sprintf("Incident #%d is now %s.", incident.ID, incident.StatusLabel)}, nil
+	}
+
+	incident, err := GetIncidentByIDServ(uint(id))
+	if err != nil {
+		return IMCommandResult{Reply: fmt.Sprintf("Error retrieving incident: %v", err)}, nil
+	}
+	var reply strings.Builder
+	reply.WriteString(fmt.Sprintf("Incident #%d: %s\n", incident.ID, incident.Title))
+	reply.WriteString(fmt.Sprintf("Status: %s, Severity: %d, Alerts: %d\n", incident.StatusLabel, incident.Severity, incident.AlertCount))
+	if incident.CommanderName != "" {
+		reply.WriteString(fmt.Sprintf("Commander: %s\n", incident.CommanderName))
+	}
+	if incident.GroupName != "" {
+		reply.WriteString(fmt.Sprintf("Group: %s\n", incident.GroupName))
+	}
+	timeline := incident.Timeline
+	if len(timeline) > 5 {
+		timeline = timeline[len(timeline)-5:]
+	}
+	if len(timeline) > 0 {
+		reply.WriteString("Recent timeline:\n")
+		for _, event := range timeline {
+			reply.WriteString(fmt.Sprintf("- %s %s\n", event.CreatedAt.Format("01-02 15:04"), event.Message))
+		}
+	}
+	return IMCommandResult{Reply: reply.String()}, nil
+}
+
 func handleHostsCommand(args []string, rawArgs string) (IMCommandResult, error) {
 	options, _ := parseIMOptions(args)
 	limit := 10
@@ -460,6 +564,20 @@ func buildIMCommandMap() map[string]imCommand {
 			Description: "List alerts with optional filters.",
 			Handler:     handleGetAlertsCommand,
 		},
+		{
+			Name:        "incidents",
+			Aliases:     []string{"get_incidents"},
+			Usage:       "/incidents [active|all] [status=0] [limit=10] [q=keyword]",
+			Description: "List incidents; unresolved incidents by default.",
+			Handler:     handleIncidentsCommand,
+		},
+		{
+			Name:        "incident",
+			Aliases:     []string{"inc"},
+			Usage:       "/incident <id> | /incident ack|mitigate|resolve|reopen <id> [note]",
+			Description: "Show an incident or change its status.",
+			Handler:     handleIncidentCommand,
+		},
 		{
 			Name:        "hosts",
 			Aliases:     []string{"get_hosts"},
@@ -519,6 +637,8 @@ func buildHelpReply() string {
 		"/help - Show available commands.",
 		"/status - System health summary.",
 		"/alerts [active|resolved|all] [severity=2] [limit=10] [q=keyword]",
+		"/incidents [active|all] [status=0] [limit=10] [q=keyword]",
+		"/incident <id> | ack|mitigate|resolve|reopen <id> [note]",
 		"/hosts [q=keyword] [status=1] [monitor=1] [group=2] [ip=1.2.3.4] [limit=10]",
 		"/monitors [q=keyword] [status=1] [type=zabbix] [limit=10]",
 		"/groups [q=keyword] [status=1] [limit=10]",
@@ -0,0 +1,757 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"nagare/internal/model"
+	"nagare/internal/repository"
+)
+
+// Incident lifecycle states
+const (
+	IncidentStatusOpen         = 0
+	IncidentStatusAcknowledged = 1
+	IncidentStatusMitigated    = 2
+	IncidentStatusResolved     = 3
+)
+
+const (
+	defaultIncidentWindowMinutes   = 15
+	defaultIncidentLabelSimilarity = 0.6
+	incidentTitleMaxLength         = 255
+)
+
+// IncidentReq represents a manually declared incident
+type IncidentReq struct {
+	Title       string `json:"title" binding:"required"`
+	Severity    *int   `json:"severity"` // defaults to the highest severity of the attached alerts
+	Summary     string `json:"summary"`
+	CommanderID *uint  `json:"commander_id"`
+	AlertIDs    []uint `json:"alert_ids"`
+}
+
+// IncidentUpdateReq represents a partial incident update; nil fields are left unchanged
+type IncidentUpdateReq struct {
+	Title         *string `json:"title"`
+	Summary       *string `json:"summary"`
+	Severity      *int    `json:"severity"`
+	Status        *int    `json:"status"`
+	CommanderID   *uint   `json:"commander_id"` // 0 clears the commander
+	ResolveAlerts bool    `json:"resolve_alerts"`
+	Note          string  `json:"note"` // optional timeline note recorded with the change
+}
+
+// IncidentNoteReq represents a timeline note
+type IncidentNoteReq struct {
+	Message string `json:"message" binding:"required"`
+}
+
+// IncidentAlertsReq represents alerts attached to an incident by hand
+type IncidentAlertsReq struct {
+	AlertIDs []uint `json:"alert_ids" binding:"required"`
+}
+
+// IncidentResp represents an incident response
+type IncidentResp struct {
+	ID             uint              `json:"id"`
+	Title          string            `json:"title"`
+	Status         int               `json:"status"`
+	StatusLabel    string            `json:"status_label"`
+	Severity       int               `json:"severity"`
+	GroupID        uint              `json:"group_id,omitempty"`
+	GroupName      string            `json:"group_name,omitempty"`
+	HostID         uint              `json:"host_id,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	RootAlertID    uint              `json:"root_alert_id,omitempty"`
+	CommanderID    uint              `json:"commander_id,omitempty"`
+	CommanderName  string            `json:"commander_name,omitempty"`
+	Summary        string            `json:"summary"`
+	AlertCount     int               `json:"alert_count"`
+	LastAlertAt    *time.Time        `json:"last_alert_at,omitempty"`
+	AcknowledgedAt *time.Time        `json:"acknowledged_at,omitempty"`
+	MitigatedAt    *time.Time        `json:"mitigated_at,omitempty"`
+	ResolvedAt     *time.Time        `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// IncidentEventResp represents one timeline entry
+type IncidentEventResp struct {
+	ID        uint      `json:"id"`
+	Kind      string    `json:"kind"`
+	Message   string    `json:"message"`
+	UserID    uint      `json:"user_id,omitempty"`
+	Username  string    `json:"username,omitempty"`
+	AlertID   uint      `json:"alert_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IncidentDetailResp is an incident with its alerts and timeline
+type IncidentDetailResp struct {
+	IncidentResp
+	Alerts   []AlertRes          `json:"alerts"`
+	Timeline []IncidentEventResp `json:"timeline"`
+}
+
+// SearchIncidentsServ retrieves incidents by filter
+func SearchIncidentsServ(filter model.IncidentFilter) ([]IncidentResp, error) {
+	incidents, err := repository.SearchIncidentsDAO(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search incidents: %w", err)
+	}
+	names := incidentNameCache{}
+	result := make([]IncidentResp, 0, len(incidents))
+	for _, incident := range incidents {
+		result = append(result, names.toResp(incident))
+	}
+	return result, nil
+}
+
+// CountIncidentsServ returns total count for incidents by filter
+func CountIncidentsServ(filter model.IncidentFilter) (int64, error) {
+	return repository.CountIncidentsDAO(filter)
+}
+
+// GetIncidentByIDServ retrieves an incident with its alerts and timeline
+func GetIncidentByIDServ(id uint) (IncidentDetailResp, error) {
+	incident, err := repository.GetIncidentByIDDAO(id)
+	if err != nil {
+		return IncidentDetailResp{}, err
+	}
+	names := incidentNameCache{}
+
+	incidentID := int(id)
+	alerts, err := SearchAlertsServ(model.AlertFilter{IncidentID: &incidentID})
+	if err != nil {
+		return IncidentDetailResp{}, err
+	}
+	events, err := repository.GetIncidentEventsDAO(id)
+	if err != nil {
+		return IncidentDetailResp{}, fmt.Errorf("failed to load incident timeline: %w", err)
+	}
+	timeline := make([]IncidentEventResp, 0, len(events))
+	for _, event := range events {
+		entry := IncidentEventResp{
+			ID:        event.ID,
+			Kind:      event.Kind,
+			Message:   event.Message,
+			CreatedAt: event.CreatedAt,
+		}
+		if event.UserID != nil {
+			entry.UserID = *event.UserID
+			entry.Username = names.username(*event.UserID)
+		}
+		if event.AlertID != nil {
+			entry.AlertID = *event.AlertID
+		}
+		timeline = append(timeline, entry)
+	}
+
+	return IncidentDetailResp{
+		IncidentResp: names.toResp(incident),
+		Alerts:       alerts,
+		Timeline:     timeline,
+	}, nil
+}
+
+// AddIncidentServ declares an incident by hand and attaches the given alerts
+func AddIncidentServ(req IncidentReq, userID *uint) (IncidentResp, error) {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return IncidentResp{}, fmt.Errorf("%w: title is required", model.ErrInvalidInput)
+	}
+	if req.Severity != nil && (*req.Severity < 0 || *req.Severity > 5) {
+		return IncidentResp{}, fmt.Errorf("%w: severity must be between 0 and 5", model.ErrInvalidInput)
+	}
+	if err := validateIncidentCommander(req.CommanderID); err != nil {
+		return IncidentResp{}, err
+	}
+
+	now := time.Now()
+	incident := model.Incident{
+		Title:       truncateIncidentTitle(title),
+		Status:      IncidentStatusOpen,
+		Summary:     req.Summary,
+		LastAlertAt: &now,
+	}
+	if req.Severity != nil {
+		incident.Severity = *req.Severity
+	}
+	if req.CommanderID != nil && *req.CommanderID > 0 {
+		commanderID := *req.CommanderID
+		incident.CommanderID = &commanderID
+	}
+	if err := repository.AddIncidentDAO(&incident); err != nil {
+		return IncidentResp{}, fmt.Errorf("failed to add incident: %w", err)
+	}
+	recordIncidentEvent(incident.ID, "created", "Incident declared"+incidentActorSuffix(userID), userID, nil)
+
+	if len(req.AlertIDs) > 0 {
+		if err := attachAlertsToIncident(incident.ID, req.AlertIDs, userID); err != nil {
+			return IncidentResp{}, err
+		}
+	}
+
+	incident, err := repository.GetIncidentByIDDAO(incident.ID)
+	if err != nil {
+		return IncidentResp{}, err
+	}
+	LogService("info", "incident declared", map[string]interface{}{"incident_id": incident.ID, "title": incident.Title}, userID, "")
+	go notifyIncidentChange(incident, "Incident declared")
+	return incidentNameCache{}.toResp(incident), nil
+}
+
+// UpdateIncidentServ applies a partial update and records every change on the timeline
+func UpdateIncidentServ(id uint, req IncidentUpdateReq, userID *uint) (IncidentResp, error) {
+	incident, err := repository.GetIncidentByIDDAO(id)
+	if err != nil {
+		return IncidentResp{}, err
+	}
+
+	// Timeline entries are only written once the change they describe has been saved
+	fields := map[string]interface{}{}
+	var events []model.IncidentEvent
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if title == "" {
+			return IncidentResp{}, fmt.Errorf("%w: title cannot be empty", model.ErrInvalidInput)
+		}
+		fields["title"] = truncateIncidentTitle(title)
+	}
+	if req.Summary != nil {
+		fields["summary"] = *req.Summary
+	}
+	if req.Severity != nil {
+		if *req.Severity < 0 || *req.Severity > 5 {
+			return IncidentResp{}, fmt.Errorf("%w: severity must be between 0 and 5", model.ErrInvalidInput)
+		}
+		if *req.Severity != incident.Severity {
+			fields["severity"] = *req.Severity
+			events = append(events, model.IncidentEvent{Kind: "severity_changed", Message: fmt.Sprintf("Severity changed from %s to %s%s", severityLabel(incident.Severity), severityLabel(*req.Severity), incidentActorSuffix(userID))})
+		}
+	}
+	if req.CommanderID != nil {
+		if err := validateIncidentCommander(req.CommanderID); err != nil {
+			return IncidentResp{}, err
+		}
+		current := uint(0)
+		if incident.CommanderID != nil {
+			current = *incident.CommanderID
+		}
+		if *req.CommanderID != current {
+			names := incidentNameCache{}
+			if *req.CommanderID == 0 {
+				fields["commander_id"] = nil
+				events = append(events, model.IncidentEvent{Kind: "commander_changed", Message: "Commander cleared" + incidentActorSuffix(userID)})
+			} else {
+				fields["commander_id"] = *req.CommanderID
+				events = append(events, model.IncidentEvent{Kind: "commander_changed", Message: fmt.Sprintf("Commander set to %s%s", names.username(*req.CommanderID), incidentActorSuffix(userID))})
+			}
+		}
+	}
+	if len(fields) > 0 {
+		if err := repository.UpdateIncidentFieldsDAO(id, fields); err != nil {
+			return IncidentResp{}, fmt.Errorf("failed to update incident: %w", err)
+		}
+	}
+	for _, event := range events {
+		recordIncidentEvent(id, event.Kind, event.Message, userID, nil)
+	}
+
+	if note := strings.TrimSpace(req.Note); note != "" {
+		recordIncidentEvent(id, "note", note, userID, nil)
+	}
+
+	if req.Status != nil {
+		if err := transitionIncident(id, *req.Status, req.ResolveAlerts, "", userID); err != nil {
+			return IncidentResp{}, err
+		}
+	}
+
+	incident, err = repository.GetIncidentByIDDAO(id)
+	if err != nil {
+		return IncidentResp{}, err
+	}
+	return incidentNameCache{}.toResp(incident), nil
+}
+
+// SetIncidentStatusServ moves an incident through its lifecycle
+func SetIncidentStatusServ(id uint, status int, resolveAlerts bool, userID *uint) error {
+	if _, err := repository.GetIncidentByIDDAO(id); err != nil {
+		return err
+	}
+	return transitionIncident(id, status, resolveAlerts, "", userID)
+}
+
+// DeleteIncidentServ deletes an incident; its alerts are kept and detached
+func DeleteIncidentServ(id uint) error {
+	if _, err := repository.GetIncidentByIDDAO(id); err != nil {
+		return err
+	}
+	return repository.DeleteIncidentByIDDAO(id)
+}
+
+// AddIncidentNoteServ appends a note to the incident timeline
+func AddIncidentNoteServ(id uint, req IncidentNoteReq, userID *uint) error {
+	if _, err := repository.GetIncidentByIDDAO(id); err != nil {
+		return err
+	}
+	message := strings.TrimSpace(req.Message)
+	if message == "" {
+		return fmt.Errorf("%w: note cannot be empty", model.ErrInvalidInput)
+	}
+	return repository.AddIncidentEventDAO(&model.IncidentEvent{IncidentID: id, Kind: "note", Message: message, UserID: userID})
+}
+
+// AttachAlertsToIncidentServ moves alerts into an incident by hand
+func AttachAlertsToIncidentServ(id uint, req IncidentAlertsReq, userID *uint) error {
+	if _, err := repository.GetIncidentByIDDAO(id); err != nil {
+		return err
+	}
+	return attachAlertsToIncident(id, req.AlertIDs, userID)
+}
+
+func attachAlertsToIncident(incidentID uint, alertIDs []uint, userID *uint) error {
+	for _, alertID := range alertIDs {
+		alert, err := repository.GetAlertByIDDAO(int(alertID))
+		if err != nil {
+			return fmt.Errorf("%w: alert %d not found", model.ErrNotFound, alertID)
+		}
+		if alert.IncidentID != nil && *alert.IncidentID == incidentID {
+			continue
+		}
+		if err := repository.AttachAlertToIncidentDAO(incidentID, alert.ID, alert.Severity, time.Now()); err != nil {
+			return fmt.Errorf("failed to attach alert %d: %w", alert.ID, err)
+		}
+		if alert.IncidentID != nil {
+			_ = repository.RecountIncidentAlertsDAO(*alert.IncidentID)
+		}
+		alertRef := alert.ID
+		recordIncidentEvent(incidentID, "alert_attached", fmt.Sprintf("Alert #%d attached%s: %s", alert.ID, incidentActorSuffix(userID), alert.Message), userID, &alertRef)
+	}
+	return nil
+}
+
+// correlateAlert attaches a new alert to a matching open incident or opens a new one.
+// It returns the alert with IncidentID set, or unchanged when correlation is disabled or not applicable.
+func correlateAlert(alert model.Alert, hostName string) model.Alert {
+	cfg, err := repository.GetIncidentConfig()
+	if err != nil || !cfg.Enabled {
+		return alert
+	}
+	if alert.Status == 2 || alert.Severity < cfg.MinSeverity {
+		return alert
+	}
+
+	window := cfg.WindowMinutes
+	if window <= 0 {
+		window = defaultIncidentWindowMinutes
+	}
+	threshold := cfg.LabelSimilarity
+	if threshold <= 0 || threshold > 1 {
+		threshold = defaultIncidentLabelSimilarity
+	}
+
+	matchCtx := buildAlertMatchContext(alert)
+	if matchCtx.host == nil && strings.TrimSpace(hostName) != "" {
+		matchCtx.host = findHostByName(hostName)
+	}
+	labels := selectIncidentLabels(decodeAlertStringMap(alert.Labels), cfg.LabelKeys)
+
+	now := time.Now()
+	candidates, err := repository.GetCorrelationCandidateIncidentsDAO(now.Add(-time.Duration(window) * time.Minute))
+	if err != nil {
+		LogService("warn", "incident correlation skipped", map[string]interface{}{"alert_id": alert.ID, "error": err.Error()}, nil, "")
+		return alert
+	}
+
+	for _, incident := range candidates {
+		reason := incidentMatchReason(incident, matchCtx.host, labels, cfg.LabelKeys, threshold)
+		if reason == "" {
+			continue
+		}
+		if err := repository.AttachAlertToIncidentDAO(incident.ID, alert.ID, alert.Severity, now); err != nil {
+			LogService("error", "failed to attach alert to incident", map[string]interface{}{"alert_id": alert.ID, "incident_id": incident.ID, "error": err.Error()}, nil, "")
+			return alert
+		}
+		alertRef := alert.ID
+		recordIncidentEvent(incident.ID, "alert_attached", fmt.Sprintf("Alert #%d correlated by %s: %s", alert.ID, reason, alert.Message), nil, &alertRef)
+		incident.AlertCount++
+		if alert.Severity > incident.Severity {
+			recordIncidentEvent(incident.ID, "severity_changed", fmt.Sprintf("Severity raised from %s to %s by alert #%d", severityLabel(incident.Severity), severityLabel(alert.Severity), alert.ID), nil, &alertRef)
+			incident.Severity = alert.Severity
+			go notifyIncidentChange(incident, "Incident severity raised to "+severityLabel(alert.Severity))
+		}
+		incidentID := incident.ID
+		alert.IncidentID = &incidentID
+		LogService("info", "alert correlated into incident", map[string]interface{}{"alert_id": alert.ID, "incident_id": incident.ID, "reason": reason}, nil, "")
+		return alert
+	}
+
+	incident := model.Incident{
+		Title:       truncateIncidentTitle(alert.Message),
+		Status:      IncidentStatusOpen,
+		Severity:    alert.Severity,
+		Labels:      encodeAlertStringMap(labels),
+		AlertCount:  1,
+		LastAlertAt: &now,
+	}
+	rootAlertID := alert.ID
+	incident.RootAlertID = &rootAlertID
+	if matchCtx.host != nil {
+		hostID, groupID := matchCtx.host.ID, matchCtx.host.GroupID
+		incident.HostID = &hostID
+		if groupID > 0 {
+			incident.GroupID = &groupID
+		}
+	}
+	if err := repository.AddIncidentDAO(&incident); err != nil {
+		LogService("error", "failed to open incident", map[string]interface{}{"alert_id": alert.ID, "error": err.Error()}, nil, "")
+		return alert
+	}
+	if err := repository.SetAlertIncidentDAO(alert.ID, incident.ID); err != nil {
+		LogService("error", "failed to link alert to incident", map[string]interface{}{"alert_id": alert.ID, "incident_id": incident.ID, "error": err.Error()}, nil, "")
+		return alert
+	}
+	recordIncidentEvent(incident.ID, "created", fmt.Sprintf("Incident opened by alert #%d: %s", alert.ID, alert.Message), nil, &rootAlertID)
+	LogService("info", "incident opened", map[string]interface{}{"alert_id": alert.ID, "incident_id": incident.ID}, nil, "")
+
+	incidentID := incident.ID
+	alert.IncidentID = &incidentID
+	return alert
+}
+
+// incidentMatchReason explains why an alert belongs to an incident, or returns "" when it does not
+func incidentMatchReason(incident model.Incident, host *model.Host, labels map[string]string, labelKeys []string, threshold float64) string {
+	if host != nil {
+		if incident.HostID != nil && *incident.HostID == host.ID {
+			return "host"
+		}
+		if incident.GroupID != nil && host.GroupID > 0 && *incident.GroupID == host.GroupID {
+			return "host group"
+		}
+	}
+	incidentLabels := selectIncidentLabels(decodeAlertStringMap(incident.Labels), labelKeys)
+	if len(labels) > 0 && len(incidentLabels) > 0 && labelSimilarity(labels, incidentLabels) >= threshold {
+		return "labels"
+	}
+	return ""
+}
+
+// selectIncidentLabels keeps only the configured label keys; an empty key list keeps every label
+func selectIncidentLabels(labels map[string]string, keys []string) map[string]string {
+	if len(labels) == 0 || len(keys) == 0 {
+		return labels
+	}
+	selected := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if value, ok := labels[key]; ok {
+			selected[key] = value
+		}
+	}
+	return selected
+}
+
+// labelSimilarity is the Jaccard index of two label sets, comparing key=value pairs
+func labelSimilarity(a, b map[string]string) float64 {
+	union := len(a)
+	shared := 0
+	for key, value := range b {
+		if other, ok := a[key]; ok && other == value {
+			shared++
+		} else {
+			union++
+		}
+	}
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+// syncIncidentAfterAlertResolved resolves an incident once none of its alerts remain unresolved
+func syncIncidentAfterAlertResolved(incidentID *uint) {
+	if incidentID == nil || *incidentID == 0 {
+		return
+	}
+	incident, err := repository.GetIncidentByIDDAO(*incidentID)
+	if err != nil || incident.Status == IncidentStatusResolved {
+		return
+	}
+	remaining, err := repository.CountUnresolvedAlertsByIncidentDAO(incident.ID)
+	if err != nil || remaining > 0 {
+		return
+	}
+	if err := transitionIncident(incident.ID, IncidentStatusResolved, false, "All alerts resolved", nil); err != nil {
+		LogService("error", "failed to auto-resolve incident", map[string]interface{}{"incident_id": incident.ID, "error": err.Error()}, nil, "")
+	}
+}
+
+// transitionIncident changes the incident status, stamps the lifecycle time and notifies matching actions
+func transitionIncident(id uint, status int, resolveAlerts bool, reason string, userID *uint) error {
+	if status < IncidentStatusOpen || status > IncidentStatusResolved {
+		return fmt.Errorf("%w: status must be 0 (open), 1 (acknowledged), 2 (mitigated) or 3 (resolved)", model.ErrInvalidInput)
+	}
+	incident, err := repository.GetIncidentByIDDAO(id)
+	if err != nil {
+		return err
+	}
+	if incident.Status == status {
+		return nil
+	}
+
+	now := time.Now()
+	fields := map[string]interface{}{"status": status}
+	switch status {
+	case IncidentStatusOpen:
+		fields["resolved_at"] = nil
+		fields["mitigated_at"] = nil
+	case IncidentStatusAcknowledged:
+		if incident.AcknowledgedAt == nil {
+			fields["acknowledged_at"] = now
+		}
+	case IncidentStatusMitigated:
+		fields["mitigated_at"] = now
+		if incident.AcknowledgedAt == nil {
+			fields["acknowledged_at"] = now
+		}
+	case IncidentStatusResolved:
+		fields["resolved_at"] = now
+	}
+	if err := repository.UpdateIncidentFieldsDAO(id, fields); err != nil {
+		return fmt.Errorf("failed to update incident status: %w", err)
+	}
+
+	message := fmt.Sprintf("Status changed from %s to %s", incidentStatusLabel(incident.Status), incidentStatusLabel(status))
+	if reason != "" {
+		message += ": " + reason
+	}
+	recordIncidentEvent(id, "status_changed", message+incidentActorSuffix(userID), userID, nil)
+
+	if status == IncidentStatusResolved && resolveAlerts {
+		resolved, err := repository.ResolveAlertsByIncidentDAO(id)
+		if err != nil {
+			LogService("error", "failed to resolve incident alerts", map[string]interface{}{"incident_id": id, "error": err.Error()}, userID, "")
+		} else if resolved > 0 {
+			recordIncidentEvent(id, "status_changed", fmt.Sprintf("%d alerts resolved with the incident", resolved), userID, nil)
+		}
+	}
+
+	LogService("info", "incident status changed", map[string]interface{}{
+		"incident_id": id,
+		"from":        incidentStatusLabel(incident.Status),
+		"to":          incidentStatusLabel(status),
+	}, userID, "")
+
+	incident.Status = status
+	go notifyIncidentChange(incident, "Incident "+incidentStatusLabel(status))
+	return nil
+}
+
+// notifyIncidentChange sends one message per matching action for an incident-level change.
+// Only the severity filter applies; the alert status filter describes alerts, not incidents.
+func notifyIncidentChange(incident model.Incident, headline string) {
+	actions, err := repository.GetAllActionsDAO()
+	if err != nil {
+		LogService("error", "failed to load actions for incident notification", map[string]interface{}{"incident_id": incident.ID, "error": err.Error()}, nil, "")
+		return
+	}
+	msg := renderIncidentMessage(incident, headline)
+	for _, action := range actions {
+		if action.Enabled == 0 {
+			continue
+		}
+		if action.SeverityMin != nil && incident.Severity < *action.SeverityMin {
+			continue
+		}
+		LogService("info", "action matched for incident", map[string]interface{}{
+			"action_id":   action.ID,
+			"action_name": action.Name,
+			"incident_id": incident.ID,
+		}, nil, "")
+		incidentID, actionID := incident.ID, action.ID
+		source := notificationSource{Kind: NotificationKindIncident, IncidentID: &incidentID, ActionID: &actionID, Digest: actionDigestSettings(action)}
+		deliverActionMessage(action, func(media model.Media, recipientID *uint) error {
+			return enqueueNotification(source, media, recipientID, msg)
+		})
+	}
+}
+
+func buildIncidentReplacements(incident model.Incident, headline string) map[string]string {
+	commander := "-"
+	if incident.CommanderID != nil {
+		if name := (incidentNameCache{}).username(*incident.CommanderID); name != "" {
+			commander = name
+		}
+	}
+	return map[string]string{
+		"{{headline}}":        headline,
+		"{{incident_id}}":     fmt.Sprintf("%d", incident.ID),
+		"{{incident_title}}":  incident.Title,
+		"{{incident_status}}": incidentStatusLabel(incident.Status),
+		"{{severity}}":        fmt.Sprintf("%d", incident.Severity),
+		"{{severity_label}}":  severityLabel(incident.Severity),
+		"{{alert_count}}":     fmt.Sprintf("%d", incident.AlertCount),
+		"{{commander}}":       commander,
+		"{{created_at}}":      incident.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func renderIncidentMessage(incident model.Incident, headline string) string {
+	template := strings.Join([]string{
+		"{{headline}}: {{incident_title}}",
+		"",
+		"Details:",
+		"incident_id={{incident_id}}",
+		"status={{incident_status}}",
+		"severity={{severity}} ({{severity_label}})",
+		"alerts={{alert_count}}",
+		"commander={{commander}}",
+		"created_at={{created_at}}",
+	}, "\n")
+	return renderMessageTemplate(template, buildIncidentReplacements(incident, headline))
+}
+
+// incidentSuppressesAlertNotification reports whether an alert's notification is covered by its incident
+func incidentSuppressesAlertNotification(alert model.Alert) bool {
+	if alert.IncidentID == nil {
+		return false
+	}
+	incident, err := repository.GetIncidentByIDDAO(*alert.IncidentID)
+	if err != nil {
+		return false
+	}
+	return incident.RootAlertID == nil || *incident.RootAlertID != alert.ID
+}
+
+func recordIncidentEvent(incidentID uint, kind, message string, userID *uint, alertID *uint) {
+	event := model.IncidentEvent{IncidentID: incidentID, Kind: kind, Message: message, UserID: userID, AlertID: alertID}
+	if err := repository.AddIncidentEventDAO(&event); err != nil {
+		LogService("warn", "failed to record incident event", map[string]interface{}{"incident_id": incidentID, "kind": kind, "error": err.Error()}, nil, "")
+	}
+}
+
+func validateIncidentCommander(commanderID *uint) error {
+	if commanderID == nil || *commanderID == 0 {
+		return nil
+	}
+	if _, err := repository.GetUserByIDDAO(int(*commanderID)); err != nil {
+		return fmt.Errorf("%w: commander user %d not found", model.ErrInvalidInput, *commanderID)
+	}
+	return nil
+}
+
+func findHostByName(name string) *model.Host {
+	name = strings.TrimSpace(name)
+	hosts, err := repository.SearchHostsDAO(model.HostFilter{Query: name, SearchField: "name"})
+	if err == nil {
+		for _, host := range hosts {
+			if strings.EqualFold(host.Name, name) {
+				return &host
+			}
+		}
+	}
+	hosts, err = repository.SearchHostsDAO(model.HostFilter{IPAddr: &name})
+	if err == nil && len(hosts) > 0 {
+		return &hosts[0]
+	}
+	return nil
+}
+
+func incidentActorSuffix(userID *uint) string {
+	if userID == nil {
+		return ""
+	}
+	if name := (incidentNameCache{}).username(*userID); name != "" {
+		return " by " + name
+	}
+	return ""
+}
+
+func truncateIncidentTitle(title string) string {
+	title = strings.TrimSpace(title)
+	if len(title) > incidentTitleMaxLength {
+		return title[:incidentTitleMaxLength]
+	}
+	return title
+}
+
+func incidentStatusLabel(status int) string {
+	switch status {
+	case IncidentStatusOpen:
+		return "open"
+	case IncidentStatusAcknowledged:
+		return "acknowledged"
+	case IncidentStatusMitigated:
+		return "mitigated"
+	case IncidentStatusResolved:
+		return "resolved"
+	default:
+		return "unknown"
+	}
+}
+
+// incidentNameCache resolves user and group names once per response
+type incidentNameCache map[string]string
+
+func (c incidentNameCache) username(id uint) string {
+	key := fmt.Sprintf("user:%d", id)
+	if name, ok := c[key]; ok {
+		return name
+	}
+	name := ""
+	if user, err := repository.GetUserByIDDAO(int(id)); err == nil {
+		name = user.Username
+	}
+	c[key] = name
+	return name
+}
+
+func (c incidentNameCache) groupName(id uint) string {
+	key := fmt.Sprintf("group:%d", id)
+	if name, ok := c[key]; ok {
+		return name
+	}
+	name := ""
+	if group, err := repository.GetGroupByIDDAO(id); err == nil {
+		name = group.Name
+	}
+	c[key] = name
+	return name
+}
+
+func (c incidentNameCache) toResp(incident model.Incident) IncidentResp {
+	resp := IncidentResp{
+		ID:             incident.ID,
+		Title:          incident.Title,
+		Status:         incident.Status,
+		StatusLabel:    incidentStatusLabel(incident.Status),
+		Severity:       incident.Severity,
+		Labels:         decodeAlertStringMap(incident.Labels),
+		Summary:        incident.Summary,
+		AlertCount:     incident.AlertCount,
+		LastAlertAt:    incident.LastAlertAt,
+		AcknowledgedAt: incident.AcknowledgedAt,
+		MitigatedAt:    incident.MitigatedAt,
+		ResolvedAt:     incident.ResolvedAt,
+		CreatedAt:      incident.CreatedAt,
+		UpdatedAt:      incident.UpdatedAt,
+	}
+	if incident.GroupID != nil {
+		resp.GroupID = *incident.GroupID
+		resp.GroupName = c.groupName(*incident.GroupID)
+	}
+	if incident.HostID != nil {
+		resp.HostID = *incident.HostID
+	}
+	if incident.RootAlertID != nil {
+		resp.RootAlertID = *incident.RootAlertID
+	}
+	if incident.CommanderID != nil {
+		resp.CommanderID = *incident.CommanderID
+		resp.CommanderName = c.username(*incident.CommanderID)
+	}
+	return resp
+}
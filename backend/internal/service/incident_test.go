@@ -0,0 +1,99 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"nagare/internal/model"
+)
+
+func TestLabelSimilarity(t *testing.T) {
+	cases := []struct {
+		name string
+		a, b map[string]string
+		want float64
+	}{
+		{name: "both empty", a: map[string]string{}, b: nil, want: 0},
+		{name: "one empty", a: map[string]string{"job": "node"}, b: nil, want: 0},
+		{name: "identical", a: map[string]string{"job": "node", "env": "prod"}, b: map[string]string{"env": "prod", "job": "node"}, want: 1},
+		{name: "value differs", a: map[string]string{"job": "node"}, b: map[string]string{"job": "mysql"}, want: 0},
+		{name: "half shared", a: map[string]string{"job": "node", "env": "prod"}, b: map[string]string{"job": "node", "env": "dev"}, want: 1.0 / 3},
+		{name: "subset", a: map[string]string{"job": "node", "env": "prod"}, b: map[string]string{"job": "node"}, want: 0.5},
+	}
+
+	for _, tc := range cases {
+		if got := labelSimilarity(tc.a, tc.b); math.Abs(got-tc.want) > 1e-9 {
+			t.Fatalf("%s: labelSimilarity = %v, want %v", tc.name, got, tc.want)
+		}
+		if got := labelSimilarity(tc.b, tc.a); math.Abs(got-tc.want) > 1e-9 {
+			t.Fatalf("%s: labelSimilarity should be symmetric, got %v", tc.name, got)
+		}
+	}
+}
+
+func TestSelectIncidentLabels(t *testing.T) {
+	labels := map[string]string{"alertname": "HighCPU", "instance": "a:9100", "job": "node", "pod": "web-5f7c"}
+
+	cases := []struct {
+		name   string
+		labels map[string]string
+		keys   []string
+		want   map[string]string
+	}{
+		{name: "no keys keeps everything", labels: labels, want: labels},
+		{name: "keys select", labels: labels, keys: []string{"alertname", "job"}, want: map[string]string{"alertname": "HighCPU", "job": "node"}},
+		{name: "missing keys are skipped", labels: labels, keys: []string{"job", "cluster"}, want: map[string]string{"job": "node"}},
+		{name: "no matching keys", labels: labels, keys: []string{"cluster"}, want: map[string]string{}},
+		{name: "empty labels", labels: nil, keys: []string{"job"}, want: nil},
+	}
+
+	for _, tc := range cases {
+		got := selectIncidentLabels(tc.labels, tc.keys)
+		if len(got) != len(tc.want) {
+			t.Fatalf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+		for key, value := range tc.want {
+			if got[key] != value {
+				t.Fatalf("%s: got %v, want %v", tc.name, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestIncidentMatchReason(t *testing.T) {
+	ptr := func(v uint) *uint { return &v }
+	host := &model.Host{GroupID: 7}
+	host.ID = 3
+
+	// The alert shares two of the three selected incident labels, so the similarity is exactly 2/3
+	incidentLabels := `{"alertname":"HighCPU","job":"node","env":"prod","pod":"web-1"}`
+	alertLabels := map[string]string{"alertname": "HighCPU", "job": "node"}
+	keys := []string{"alertname", "job", "env"}
+
+	cases := []struct {
+		name      string
+		incident  model.Incident
+		host      *model.Host
+		labels    map[string]string
+		keys      []string
+		threshold float64
+		want      string
+	}{
+		{name: "same host", incident: model.Incident{HostID: ptr(3), GroupID: ptr(7)}, host: host, want: "host"},
+		{name: "same host group", incident: model.Incident{HostID: ptr(4), GroupID: ptr(7)}, host: host, want: "host group"},
+		{name: "other host and group", incident: model.Incident{HostID: ptr(4), GroupID: ptr(8)}, host: host, threshold: 0.5, want: ""},
+		{name: "labels at the exact threshold", incident: model.Incident{Labels: incidentLabels}, labels: alertLabels, keys: keys, threshold: 2.0 / 3, want: "labels"},
+		{name: "labels just below the threshold", incident: model.Incident{Labels: incidentLabels}, labels: alertLabels, keys: keys, threshold: 2.0/3 + 0.01, want: ""},
+		{name: "ignored labels do not dilute the score", incident: model.Incident{Labels: incidentLabels}, labels: alertLabels, keys: []string{"alertname", "job"}, threshold: 1, want: "labels"},
+		{name: "unselected labels count when no keys are configured", incident: model.Incident{Labels: incidentLabels}, labels: alertLabels, threshold: 0.6, want: ""},
+		{name: "empty alert labels never match", incident: model.Incident{Labels: incidentLabels}, labels: map[string]string{}, keys: keys, threshold: 0, want: ""},
+		{name: "empty incident labels never match", incident: model.Incident{}, labels: alertLabels, keys: keys, threshold: 0, want: ""},
+		{name: "incident without selected labels", incident: model.Incident{Labels: `{"pod":"web-1"}`}, labels: alertLabels, keys: keys, threshold: 0, want: ""},
+	}
+
+	for _, tc := range cases {
+		if got := incidentMatchReason(tc.incident, tc.host, tc.labels, tc.keys, tc.threshold); got != tc.want {
+			t.Fatalf("%s: incidentMatchReason = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
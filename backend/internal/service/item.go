@@ -373,7 +373,12 @@ func createMonitorClientFromDomain(monitor model.Monitor) (*monitors.Client, err
 		},
 		Timeout: 30,
 	}
-	return monitors.NewClient(cfg)
+	client, err := monitors.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	attachSNMPTargetSource(client, monitor.ID)
+	return client, nil
 }
 
 // GetItemsByHIDServ retrieves all items for a specific host
@@ -646,6 +651,9 @@ func pullItemsFromHostServ(mid, hid uint, recordHistory bool) (SyncResult, error
 	ctx := context.Background()
 	targetID := host.ExternalID
 	mType := monitors.ParseMonitorType(monitor.Type)
+	if mType == monitors.MonitorSNMP {
+		targetID = snmpHostExternalID(host)
+	}
 	fmt.Printf("[DEBUG] pullItemsFromHostServ: monitor_type=%s (%d), host=%s, target=%s\n", mType.String(), monitor.Type, host.Name, targetID)
 
 	fmt.Printf("[DEBUG] Calling client.GetItems for %s\n", targetID)
@@ -849,7 +857,12 @@ func PullItemOfHostFromMonitorServ(mid, hid, id uint) (SyncResult, error) {
 		}
 	}
 
-	monitorItem, err := client.GetItemByID(context.Background(), item.ExternalID)
+	var monitorItem *monitors.Item
+	if client.ProviderType() == monitors.MonitorSNMP {
+		monitorItem, err = pollSNMPItemOfHost(context.Background(), client, snmpHostExternalID(host), item.ExternalID)
+	} else {
+		monitorItem, err = client.GetItemByID(context.Background(), item.ExternalID)
+	}
 	if err != nil {
 		setMonitorStatusError(mid)
 		setItemStatusErrorWithReason(id, err.Error())
@@ -862,7 +875,9 @@ func PullItemOfHostFromMonitorServ(mid, hid, id uint) (SyncResult, error) {
 	}
 
 	item.Name = monitorItem.Name
-	item.ExternalID = monitorItem.HostID
+	if client.ProviderType() != monitors.MonitorSNMP { // SNMP items keep the ID the poller knows them by
+		item.ExternalID = monitorItem.HostID
+	}
 	item.ValueType = monitorItem.ValueType
 	item.LastValue = monitorItem.Value
 	item.Units = monitorItem.Units
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"nagare/internal/model"
+	"nagare/internal/repository"
+	"nagare/internal/repository/llm"
+)
+
+const (
+	defaultLLMRouteRetries           = 1
+	defaultLLMBreakerThreshold       = 3
+	defaultLLMBreakerCooldownSeconds = 120
+)
+
+// resolveLLMRoutes returns the failover chain for a request. A preferred provider, e.g. the one picked in
+// the chat panel, is tried first and followed by the configured chain; without one the chain starts with
+// ai.routing.routes, or ai.provider_id when no routes are configured. Disabled providers, providers without
+// an API key and providers over their token budget are left out, and every remaining provider is registered
+// with the LLM service. A request on behalf of a user over budget is refused.
+func resolveLLMRoutes(userID *uint, preferredID uint, preferredModel string) ([]llm.Route, error) {
+	budget := llmBudgetConfig()
+	if userID != nil {
+		if err := checkLLMBudget(budget, "user", *userID); err != nil {
+			return nil, err
+		}
+	}
+
+	routing := llmRoutingConfig()
+	llm.GetService().SetBreakerConfig(llm.BreakerConfig{
+		Threshold: routing.BreakerThreshold,
+		Cooldown:  time.Duration(routing.BreakerCooldownSeconds) * time.Second,
+	})
+
+	configured := defaultLLMChain(routing)
+	if preferredID > 0 {
+		configured = append([]repository.AIRoute{{ProviderID: int(preferredID), Model: preferredModel}}, configured...)
+	}
+
+	routes := make([]llm.Route, 0, len(configured))
+	seen := make(map[int]bool, len(configured))
+	var lastErr error
+	for _, step := range configured {
+		if step.ProviderID <= 0 || seen[step.ProviderID] {
+			continue
+		}
+		seen[step.ProviderID] = true
+		if err := checkLLMBudget(budget, "provider", uint(step.ProviderID)); err != nil {
+			lastErr = err
+			continue
+		}
+
+		resolvedModel, err := registerLLMProvider(uint(step.ProviderID), step.Model)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		route := llm.Route{
+			ProviderID: step.ProviderID,
+			Model:      resolvedModel,
+			Retries:    routing.Retries,
+			Timeout:    time.Duration(routing.TimeoutSeconds) * time.Second,
+		}
+		if step.Retries != nil {
+			route.Retries = *step.Retries
+		}
+		if step.TimeoutSeconds > 0 {
+			route.Timeout = time.Duration(step.TimeoutSeconds) * time.Second
+		}
+		if route.Retries < 0 {
+			route.Retries = 0
+		}
+		routes = append(routes, route)
+	}
+	if len(routes) == 0 {
+		if lastErr != nil {
+			return nil, lastErr
+		}
+		return nil, errors.New("no AI provider is configured")
+	}
+	return routes, nil
+}
+
+func llmRoutingConfig() repository.AIRoutingConfig {
+	cfg, _ := repository.GetAIConfig()
+	if cfg.Routing == nil {
+		return repository.AIRoutingConfig{
+			Retries:                defaultLLMRouteRetries,
+			BreakerThreshold:       defaultLLMBreakerThreshold,
+			BreakerCooldownSeconds: defaultLLMBreakerCooldownSeconds,
+		}
+	}
+	return *cfg.Routing
+}
+
+// defaultLLMChain returns the configured routes, or ai.provider_id with ai.model when there are none
+func defaultLLMChain(routing repository.AIRoutingConfig) []repository.AIRoute {
+	if len(routing.Routes) > 0 {
+		return routing.Routes
+	}
+	providerID, modelName := aiProviderConfig()
+	return []repository.AIRoute{{ProviderID: int(providerID), Model: modelName}}
+}
+
+// registerLLMProvider registers a provider with the LLM service and returns the model to use
+func registerLLMProvider(providerID uint, modelName string) (string, error) {
+	provider, err := repository.GetProviderByIDDAO(providerID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get provider %d: %w", providerID, err)
+	}
+	if provider.Enabled == 0 {
+		return "", fmt.Errorf("provider %d is disabled", providerID)
+	}
+	if provider.APIKey == "" {
+		return "", fmt.Errorf("provider %d API key is not configured", providerID)
+	}
+	cfg := llmProviderConfig(provider)
+	if err := llm.GetService().EnsureProvider(int(provider.ID), cfg); err != nil {
+		return "", fmt.Errorf("failed to create LLM client: %w", err)
+	}
+	return resolveLLMModel(provider, cfg.Type, modelName), nil
+}
+
+// runLLMRoutes runs fn through the failover chain and records the outcome of every attempt: failed
+// providers are flagged, the provider that answered is marked healthy, and a failover is logged with the
+// provider that finally answered
+func runLLMRoutes(ctx context.Context, feature string, routes []llm.Route, fn llm.RouteFunc) (llm.Route, error) {
+	route, attempts, err := llm.GetService().Failover(ctx, routes, fn)
+	for _, attempt := range attempts {
+		switch {
+		case attempt.Err == nil:
+			_ = repository.UpdateProviderStatusDAO(uint(attempt.ProviderID), 1)
+		case errors.Is(attempt.Err, llm.ErrCircuitOpen), errors.Is(attempt.Err, context.Canceled):
+			// Says nothing new about the provider
+		default:
+			_ = repository.UpdateProviderStatusDAO(uint(attempt.ProviderID), 2)
+			LogService("warn", "llm route failed", map[string]interface{}{
+				"feature":     feature,
+				"provider_id": attempt.ProviderID,
+				"model":       attempt.Model,
+				"duration_ms": attempt.Duration.Milliseconds(),
+				"error":       attempt.Err.Error(),
+			}, nil, "")
+		}
+	}
+	if err == nil && len(attempts) > 1 {
+		LogService("info", "llm request answered after failover", map[string]interface{}{
+			"feature":     feature,
+			"provider_id": route.ProviderID,
+			"model":       route.Model,
+			"attempts":    len(attempts),
+		}, nil, "")
+	}
+	return route, err
+}
+
+// routedChat sends a single chat request through the failover chain and returns the answer together with
+// the route that produced it. Token usage is accounted to userID, which is nil for background work.
+func routedChat(ctx context.Context, feature string, userID *uint, routes []llm.Route, req llm.ChatRequest) (*llm.ChatResponse, llm.Route, error) {
+	var resp *llm.ChatResponse
+	route, err := runLLMRoutes(ctx, feature, routes, func(ctx context.Context, client *llm.Client, route llm.Route) error {
+		routeReq := req
+		routeReq.Model = route.Model
+		start := time.Now()
+		var err error
+		resp, err = client.Chat(ctx, routeReq)
+		recordLLMRequest(feature, userID, uint(route.ProviderID), route.Model, time.Since(start), resp, err)
+		return err
+	})
+	if err != nil {
+		return nil, llm.Route{}, err
+	}
+	return resp, route, nil
+}
+
+// GetLLMCircuitStatesServ returns the circuit breaker state of providers that failed recently
+func GetLLMCircuitStatesServ() map[int]llm.CircuitState {
+	return llm.GetService().CircuitStates()
+}
+
+func llmProviderConfig(provider model.Provider) llm.Config {
+	var providerType llm.ProviderType
+	switch provider.Type {
+	case 1:
+		providerType = llm.ProviderGemini
+	case 2, 3:
+		providerType = llm.ProviderOpenAI
+	default:
+		if provider.URL != "" {
+			providerType = llm.ProviderOpenAI
+		} else {
+			providerType = llm.ProviderGemini
+		}
+	}
+	return llm.Config{
+		APIKey:  provider.APIKey,
+		BaseURL: provider.URL,
+		Type:    providerType,
+	}
+}
+
+// resolveLLMModel falls back to the provider's default model, then to a default of the provider type
+func resolveLLMModel(provider model.Provider, providerType llm.ProviderType, modelName string) string {
+	if modelName != "" {
+		return modelName
+	}
+	if provider.DefaultModel != "" {
+		return provider.DefaultModel
+	}
+	switch providerType {
+	case llm.ProviderGemini:
+		return "gemini-2.0-flash"
+	case llm.ProviderOpenAI:
+		return "gpt-4o-mini"
+	}
+	return ""
+}
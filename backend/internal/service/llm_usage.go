@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"nagare/internal/model"
+	"nagare/internal/repository"
+	"nagare/internal/repository/llm"
+)
+
+const (
+	llmOutcomeSuccess  = "success"
+	llmOutcomeError    = "error"
+	llmOutcomeTimeout  = "timeout"
+	llmOutcomeCanceled = "canceled"
+
+	defaultLLMDegradedMinSeverity = 4
+	maxLLMUsageErrorLength        = 512
+)
+
+// LLMBudgetStatus is the token usage of a provider or user against its budget; a limit of 0 is unlimited
+type LLMBudgetStatus struct {
+	Scope         string `json:"scope"` // "provider" or "user"
+	ID            uint   `json:"id"`
+	Name          string `json:"name"`
+	DailyTokens   int64  `json:"daily_tokens"`
+	DailyLimit    int    `json:"daily_limit"`
+	MonthlyTokens int64  `json:"monthly_tokens"`
+	MonthlyLimit  int    `json:"monthly_limit"`
+	Exceeded      bool   `json:"exceeded"`
+}
+
+// LLMBudgetsResp lists the budget state of providers and users
+type LLMBudgetsResp struct {
+	Degraded  bool              `json:"degraded"` // Low-severity alerts skip AI analysis
+	Providers []LLMBudgetStatus `json:"providers"`
+	Users     []LLMBudgetStatus `json:"users"`
+}
+
+var (
+	llmBudgetNoticeMu sync.Mutex
+	llmBudgetNotices  = map[string]string{} // "provider:1:daily" -> period that was reported
+)
+
+// recordLLMRequest logs an LLM call and stores its token usage. userID is nil for background work such as
+// alert analysis.
+func recordLLMRequest(feature string, userID *uint, providerID uint, llmModel string, duration time.Duration, resp *llm.ChatResponse, err error) {
+	logLLMRequest(feature, providerID, llmModel, duration, err)
+
+	usage := model.LLMUsage{
+		ProviderID: providerID,
+		LLMModel:   llmModel,
+		Feature:    feature,
+		UserID:     userID,
+		LatencyMs:  duration.Milliseconds(),
+		Outcome:    llmOutcome(err),
+	}
+	if resp != nil {
+		usage.PromptTokens = resp.PromptTokens
+		usage.CompletionTokens = resp.CompletionTokens
+		usage.TotalTokens = resp.TokensUsed
+		if usage.TotalTokens == 0 {
+			usage.TotalTokens = resp.PromptTokens + resp.CompletionTokens
+		}
+	}
+	if err != nil {
+		usage.Error = truncateRunes(err.Error(), maxLLMUsageErrorLength)
+	}
+	if dbErr := repository.AddLLMUsageDAO(&usage); dbErr != nil {
+		LogService("warn", "llm usage not recorded", map[string]interface{}{
+			"feature":     feature,
+			"provider_id": providerID,
+			"error":       dbErr.Error(),
+		}, nil, "")
+		return
+	}
+	if usage.TotalTokens > 0 {
+		notifyLLMBudgetCrossings(providerID, userID)
+	}
+}
+
+func llmOutcome(err error) string {
+	switch {
+	case err == nil:
+		return llmOutcomeSuccess
+	case errors.Is(err, context.DeadlineExceeded):
+		return llmOutcomeTimeout
+	case errors.Is(err, context.Canceled):
+		return llmOutcomeCanceled
+	}
+	return llmOutcomeError
+}
+
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit])
+}
+
+func llmBudgetConfig() repository.AIBudgetConfig {
+	cfg, _ := repository.GetAIConfig()
+	if cfg.Budget == nil {
+		return repository.AIBudgetConfig{DegradedMinSeverity: defaultLLMDegradedMinSeverity}
+	}
+	return *cfg.Budget
+}
+
+// llmBudgetLimits returns the daily and monthly token limits of a provider or user
+func llmBudgetLimits(cfg repository.AIBudgetConfig, scope string, id uint) (int, int) {
+	daily, monthly, overrides := cfg.ProviderDailyTokens, cfg.ProviderMonthlyTokens, cfg.Providers
+	if scope == "user" {
+		daily, monthly, overrides = cfg.UserDailyTokens, cfg.UserMonthlyTokens, cfg.Users
+	}
+	for _, limit := range overrides {
+		if limit.ID == id {
+			return limit.DailyTokens, limit.MonthlyTokens
+		}
+	}
+	return daily, monthly
+}
+
+// llmBudgetStatus sums the tokens a provider or user used today and this month
+func llmBudgetStatus(cfg repository.AIBudgetConfig, scope string, id uint) (LLMBudgetStatus, error) {
+	status := LLMBudgetStatus{Scope: scope, ID: id}
+	status.DailyLimit, status.MonthlyLimit = llmBudgetLimits(cfg, scope, id)
+
+	dayStart, monthStart := llmBudgetPeriodStarts(time.Now())
+	filter := model.LLMUsageFilter{From: &monthStart}
+	if scope == "provider" {
+		filter.ProviderID = &id
+	} else {
+		filter.UserID = &id
+	}
+
+	var err error
+	if status.MonthlyTokens, err = repository.SumLLMUsageTokensDAO(filter); err != nil {
+		return status, err
+	}
+	filter.From = &dayStart
+	if status.DailyTokens, err = repository.SumLLMUsageTokensDAO(filter); err != nil {
+		return status, err
+	}
+	status.Exceeded = llmBudgetExceeded(status)
+	return status, nil
+}
+
+// llmBudgetPeriodStarts returns the start of the day and of the month now falls in, in now's location
+func llmBudgetPeriodStarts(now time.Time) (time.Time, time.Time) {
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return dayStart, monthStart
+}
+
+// llmBudgetExceeded reports whether the daily or monthly usage reached its limit; a limit of 0 is unlimited
+func llmBudgetExceeded(status LLMBudgetStatus) bool {
+	return (status.DailyLimit > 0 && status.DailyTokens >= int64(status.DailyLimit)) ||
+		(status.MonthlyLimit > 0 && status.MonthlyTokens >= int64(status.MonthlyLimit))
+}
+
+// checkLLMBudget returns ErrBudgetExceeded when a provider or user has used up its tokens for today or this
+// month. Usage is not looked up when no limit applies.
+func checkLLMBudget(cfg repository.AIBudgetConfig, scope string, id uint) error {
+	daily, monthly := llmBudgetLimits(cfg, scope, id)
+	if daily <= 0 && monthly <= 0 {
+		return nil
+	}
+	status, err := llmBudgetStatus(cfg, scope, id)
+	if err != nil {
+		// Accounting problems must not take the AI features down
+		return nil
+	}
+	if status.Exceeded {
+		return fmt.Errorf("%w: %s %d has used up its AI token budget", model.ErrBudgetExceeded, scope, id)
+	}
+	return nil
+}
+
+// llmBudgetDegraded reports whether a provider of the default chain has used up its budget. Alert analysis
+// is then limited to alerts of ai.budget.degraded_min_severity and above.
+func llmBudgetDegraded() bool {
+	cfg := llmBudgetConfig()
+	for _, route := range defaultLLMChain(llmRoutingConfig()) {
+		if route.ProviderID > 0 && checkLLMBudget(cfg, "provider", uint(route.ProviderID)) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// skipAlertAnalysisForBudget reports whether an alert is not worth AI tokens while budgets are exceeded
+func skipAlertAnalysisForBudget(severity int) bool {
+	return severity < llmBudgetConfig().DegradedMinSeverity && llmBudgetDegraded()
+}
+
+// notifyLLMBudgetCrossings posts a site message the first time a provider or user exceeds a daily or
+// monthly budget in the current period. Provider notices are global; user notices go to the user.
+func notifyLLMBudgetCrossings(providerID uint, userID *uint) {
+	cfg := llmBudgetConfig()
+	notifyLLMBudgetCrossing(cfg, "provider", providerID, nil)
+	if userID != nil {
+		notifyLLMBudgetCrossing(cfg, "user", *userID, userID)
+	}
+}
+
+func notifyLLMBudgetCrossing(cfg repository.AIBudgetConfig, scope string, id uint, recipient *uint) {
+	daily, monthly := llmBudgetLimits(cfg, scope, id)
+	if daily <= 0 && monthly <= 0 {
+		return
+	}
+	status, err := llmBudgetStatus(cfg, scope, id)
+	if err != nil || !status.Exceeded {
+		return
+	}
+
+	now := time.Now()
+	periods := []struct {
+		name   string
+		period string
+		used   int64
+		limit  int
+	}{
+		{name: "daily", period: now.Format("2006-01-02"), used: status.DailyTokens, limit: status.DailyLimit},
+		{name: "monthly", period: now.Format("2006-01"), used: status.MonthlyTokens, limit: status.MonthlyLimit},
+	}
+	for _, p := range periods {
+		if p.limit <= 0 || p.used < int64(p.limit) {
+			continue
+		}
+		key := fmt.Sprintf("%s:%d:%s", scope, id, p.name)
+		llmBudgetNoticeMu.Lock()
+		reported := llmBudgetNotices[key] == p.period
+		llmBudgetNotices[key] = p.period
+		llmBudgetNoticeMu.Unlock()
+		if reported {
+			continue
+		}
+
+		subject := fmt.Sprintf("%s %s", scope, llmBudgetSubjectName(scope, id))
+		content := fmt.Sprintf("The %s has used %d of its %d %s AI tokens.", subject, p.used, p.limit, p.name)
+		if scope == "provider" {
+			content += " Requests fail over to other providers and low-severity alerts skip AI analysis until the budget resets."
+		} else {
+			content += " AI requests are refused until the budget resets."
+		}
+		LogService("warn", "llm token budget exceeded", map[string]interface{}{
+			"scope":  scope,
+			"id":     id,
+			"period": p.name,
+			"used":   p.used,
+			"limit":  p.limit,
+		}, nil, "")
+		_ = CreateSiteMessageServ("AI token budget exceeded", content, "system", 2, recipient)
+	}
+}
+
+func llmBudgetSubjectName(scope string, id uint) string {
+	if scope == "provider" {
+		if provider, err := repository.GetProviderByIDDAO(id); err == nil && provider.Name != "" {
+			return provider.Name
+		}
+	} else if user, err := repository.GetUserByIDDAO(int(id)); err == nil && user.Username != "" {
+		return user.Username
+	}
+	return fmt.Sprintf("#%d", id)
+}
+
+// SearchLLMUsageServ retrieves LLM usage records by filter
+func SearchLLMUsageServ(filter model.LLMUsageFilter) ([]model.LLMUsage, error) {
+	return repository.SearchLLMUsageDAO(filter)
+}
+
+// CountLLMUsageServ returns total count for LLM usage records by filter
+func CountLLMUsageServ(filter model.LLMUsageFilter) (int64, error) {
+	return repository.CountLLMUsageDAO(filter)
+}
+
+// SummarizeLLMUsageServ aggregates LLM usage by provider, model, feature, user, outcome, day or month
+func SummarizeLLMUsageServ(filter model.LLMUsageFilter, groupBy string) ([]repository.LLMUsageSummary, error) {
+	if groupBy == "" {
+		groupBy = "provider"
+	}
+	summaries, err := repository.SummarizeLLMUsageDAO(filter, groupBy)
+	if err != nil {
+		return nil, err
+	}
+	if summaries == nil {
+		summaries = []repository.LLMUsageSummary{}
+	}
+	return summaries, nil
+}
+
+// GetLLMBudgetsServ returns the budget state of every provider and of the users with a budget override or
+// usage this month. Users below admin level only see their own state.
+func GetLLMBudgetsServ(userID *uint, privileges int) (LLMBudgetsResp, error) {
+	cfg := llmBudgetConfig()
+	resp := LLMBudgetsResp{Providers: []LLMBudgetStatus{}, Users: []LLMBudgetStatus{}}
+
+	providers, err := repository.GetAllProvidersDAO()
+	if err != nil {
+		return resp, err
+	}
+	for _, provider := range providers {
+		status, err := llmBudgetStatus(cfg, "provider", provider.ID)
+		if err != nil {
+			return resp, err
+		}
+		status.Name = provider.Name
+		resp.Providers = append(resp.Providers, status)
+	}
+	resp.Degraded = llmBudgetDegraded()
+
+	var userIDs []uint
+	if privileges >= 3 {
+		now := time.Now()
+		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		summaries, err := repository.SummarizeLLMUsageDAO(model.LLMUsageFilter{From: &monthStart}, "user")
+		if err != nil {
+			return resp, err
+		}
+		seen := map[uint]bool{0: true}
+		for _, summary := range summaries {
+			var id uint
+			if _, err := fmt.Sscan(summary.Key, &id); err == nil && !seen[id] {
+				seen[id] = true
+				userIDs = append(userIDs, id)
+			}
+		}
+		for _, limit := range cfg.Users {
+			if !seen[limit.ID] {
+				seen[limit.ID] = true
+				userIDs = append(userIDs, limit.ID)
+			}
+		}
+	} else if userID != nil {
+		userIDs = append(userIDs, *userID)
+	}
+	for _, id := range userIDs {
+		status, err := llmBudgetStatus(cfg, "user", id)
+		if err != nil {
+			return resp, err
+		}
+		status.Name = llmBudgetSubjectName("user", id)
+		resp.Users = append(resp.Users, status)
+	}
+	return resp, nil
+}
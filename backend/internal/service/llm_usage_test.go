@@ -0,0 +1,111 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"nagare/internal/repository"
+)
+
+func TestLLMBudgetPeriodStarts(t *testing.T) {
+	tokyo := time.FixedZone("UTC+9", 9*60*60)
+
+	cases := []struct {
+		name      string
+		now       time.Time
+		wantDay   time.Time
+		wantMonth time.Time
+	}{
+		{
+			name:      "mid month",
+			now:       time.Date(2024, 5, 17, 13, 45, 10, 500, time.UTC),
+			wantDay:   time.Date(2024, 5, 17, 0, 0, 0, 0, time.UTC),
+			wantMonth: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "first second of the month",
+			now:       time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			wantDay:   time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			wantMonth: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "last moment of a leap February",
+			now:       time.Date(2024, 2, 29, 23, 59, 59, 999, time.UTC),
+			wantDay:   time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+			wantMonth: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "new year's eve",
+			now:       time.Date(2023, 12, 31, 18, 0, 0, 0, time.UTC),
+			wantDay:   time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
+			wantMonth: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// 2024-03-31 20:00 UTC is already April 1st in UTC+9, so both periods roll over there
+			name:      "periods follow the location",
+			now:       time.Date(2024, 3, 31, 20, 0, 0, 0, time.UTC).In(tokyo),
+			wantDay:   time.Date(2024, 4, 1, 0, 0, 0, 0, tokyo),
+			wantMonth: time.Date(2024, 4, 1, 0, 0, 0, 0, tokyo),
+		},
+	}
+
+	for _, tc := range cases {
+		day, month := llmBudgetPeriodStarts(tc.now)
+		if !day.Equal(tc.wantDay) || !month.Equal(tc.wantMonth) {
+			t.Fatalf("%s: got day %s month %s, want day %s month %s", tc.name, day, month, tc.wantDay, tc.wantMonth)
+		}
+	}
+}
+
+func TestLLMBudgetExceeded(t *testing.T) {
+	cases := []struct {
+		name   string
+		status LLMBudgetStatus
+		want   bool
+	}{
+		{name: "unlimited", status: LLMBudgetStatus{DailyTokens: 1 << 40, MonthlyTokens: 1 << 40}, want: false},
+		{name: "under both limits", status: LLMBudgetStatus{DailyTokens: 99, DailyLimit: 100, MonthlyTokens: 999, MonthlyLimit: 1000}, want: false},
+		{name: "daily limit reached", status: LLMBudgetStatus{DailyTokens: 100, DailyLimit: 100, MonthlyTokens: 100, MonthlyLimit: 1000}, want: true},
+		{name: "monthly limit reached", status: LLMBudgetStatus{DailyTokens: 10, DailyLimit: 100, MonthlyTokens: 1000, MonthlyLimit: 1000}, want: true},
+		{name: "only monthly limit set", status: LLMBudgetStatus{DailyTokens: 5000, MonthlyTokens: 5000, MonthlyLimit: 10000}, want: false},
+		{name: "only daily limit set", status: LLMBudgetStatus{DailyTokens: 101, DailyLimit: 100, MonthlyTokens: 101}, want: true},
+	}
+
+	for _, tc := range cases {
+		if got := llmBudgetExceeded(tc.status); got != tc.want {
+			t.Fatalf("%s: llmBudgetExceeded = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestLLMBudgetLimits(t *testing.T) {
+	cfg := repository.AIBudgetConfig{
+		ProviderDailyTokens:   1000,
+		ProviderMonthlyTokens: 20000,
+		UserDailyTokens:       100,
+		UserMonthlyTokens:     2000,
+		Providers:             []repository.AIBudgetLimit{{ID: 2, DailyTokens: 5000, MonthlyTokens: 0}},
+		Users:                 []repository.AIBudgetLimit{{ID: 7, DailyTokens: 0, MonthlyTokens: 50}},
+	}
+
+	cases := []struct {
+		scope       string
+		id          uint
+		wantDaily   int
+		wantMonthly int
+	}{
+		{scope: "provider", id: 1, wantDaily: 1000, wantMonthly: 20000},
+		{scope: "provider", id: 2, wantDaily: 5000, wantMonthly: 0},
+		{scope: "provider", id: 7, wantDaily: 1000, wantMonthly: 20000},
+		{scope: "user", id: 1, wantDaily: 100, wantMonthly: 2000},
+		{scope: "user", id: 7, wantDaily: 0, wantMonthly: 50},
+		{scope: "user", id: 2, wantDaily: 100, wantMonthly: 2000},
+	}
+
+	for _, tc := range cases {
+		daily, monthly := llmBudgetLimits(cfg, tc.scope, tc.id)
+		if daily != tc.wantDaily || monthly != tc.wantMonthly {
+			t.Fatalf("llmBudgetLimits(%s, %d) = (%d, %d), want (%d, %d)", tc.scope, tc.id, daily, monthly, tc.wantDaily, tc.wantMonthly)
+		}
+	}
+}
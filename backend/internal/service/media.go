@@ -24,6 +24,9 @@ type MediaReq struct {
 	Target      string `json:"target" binding:"required"`
 	Enabled     int    `json:"enabled"`
 	Description string `json:"description"`
+	// Webhook request settings for webhook media; nil keeps the current settings on update, and an empty
+	// token, password or header value keeps the stored secret
+	Webhook *model.MediaWebhookConfig `json:"webhook"`
 }
 
 // MediaResp represents a media response
@@ -35,6 +38,9 @@ type MediaResp struct {
 	Enabled     int    `json:"enabled"`
 	Status      int    `json:"status"`
 	Description string `json:"description"`
+
+	// Webhook settings with the token, password and header values blanked
+	Webhook *model.MediaWebhookConfig `json:"webhook"`
 }
 
 func GetAllMediaServ() ([]MediaResp, error) {
@@ -115,6 +121,27 @@ func TestMediaServ(id uint) error {
 		"target":     media.Target,
 	}, nil, "")
 
+	if isWebhookMediaType(media.Type) {
+		ctx, cancel := context.WithTimeout(context.Background(), notificationSendTimeout())
+		defer cancel()
+		resp, err := sendWebhookMedia(ctx, media, webhookMessageData(testMessage, "test"))
+		fields := map[string]interface{}{
+			"media_id":    id,
+			"media_type":  media.Type,
+			"media_name":  media.Name,
+			"target":      media.Target,
+			"status_code": resp.StatusCode,
+			"response":    templateTruncate(webhookResponseBodyLimit, resp.Body),
+		}
+		if err != nil {
+			fields["error"] = err.Error()
+			LogService("error", "test media failed", fields, nil, "")
+			return fmt.Errorf("failed to send test message via %s: %w", media.Type, err)
+		}
+		LogService("info", "test media succeeded", fields, nil, "")
+		return nil
+	}
+
 	err = SendIMReply(media.Type, media.Target, testMessage)
 	if err != nil {
 		LogService("error", "test media failed", map[string]interface{}{
@@ -184,6 +211,13 @@ func testQQWebSocketEndpoint(target string) error {
 }
 
 func AddMediaServ(req MediaReq) (MediaResp, error) {
+	if err := validateMediaWebhook(req.Webhook); err != nil {
+		return MediaResp{}, err
+	}
+	webhook, err := encryptMediaWebhookSecrets(req.Webhook)
+	if err != nil {
+		return MediaResp{}, err
+	}
 	media := model.Media{
 		Name:        req.Name,
 		Type:        req.Type,
@@ -191,6 +225,7 @@ func AddMediaServ(req MediaReq) (MediaResp, error) {
 		Enabled:     req.Enabled,
 		Status:      determineMediaStatus(model.Media{Enabled: req.Enabled, Type: req.Type, Target: req.Target}),
 		Description: req.Description,
+		Webhook:     webhook,
 	}
 	if err := repository.AddMediaDAO(media); err != nil {
 		return MediaResp{}, fmt.Errorf("failed to add media: %w", err)
@@ -203,6 +238,16 @@ func UpdateMediaServ(id uint, req MediaReq) error {
 	if err != nil {
 		return err
 	}
+	if req.Webhook != nil && existing.Webhook != nil {
+		stored, err := decryptMediaWebhookSecrets(existing.Webhook)
+		if err != nil {
+			return err
+		}
+		keepMediaWebhookSecrets(req.Webhook, stored)
+	}
+	if err := validateMediaWebhook(req.Webhook); err != nil {
+		return err
+	}
 	updated := model.Media{
 		Model:       existing.Model,
 		Name:        req.Name,
@@ -211,6 +256,12 @@ func UpdateMediaServ(id uint, req MediaReq) error {
 		Enabled:     req.Enabled,
 		Status:      existing.Status,
 		Description: req.Description,
+		Webhook:     existing.Webhook,
+	}
+	if req.Webhook != nil {
+		if updated.Webhook, err = encryptMediaWebhookSecrets(req.Webhook); err != nil {
+			return err
+		}
 	}
 	// Preserve status unless enabled state, type or target changed
 	if req.Enabled != existing.Enabled || req.Type != existing.Type || req.Target != existing.Target {
@@ -244,6 +295,7 @@ func mediaToResp(media model.Media) MediaResp {
 		Enabled:     media.Enabled,
 		Status:      media.Status,
 		Description: media.Description,
+		Webhook:     redactMediaWebhook(media.Webhook),
 	}
 }
 
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"nagare/internal/repository"
+	mediaSvc "nagare/internal/repository/media"
+	"nagare/internal/service/utils"
+)
+
+const (
+	richChartWindow    = 6 * time.Hour
+	richChartMaxPoints = 120
+	richAnalysisLimit  = 1000
+)
+
+// buildRichMessage lays out a notification for rich-capable providers. The rendered text stays the fallback
+// for text-only providers; withChart adds a history chart of the alert's item.
+func buildRichMessage(data WebhookTemplateData, withChart bool) mediaSvc.RichMessage {
+	msg := mediaSvc.RichMessage{
+		Title:    data.Subject,
+		Severity: -1,
+		Text:     data.Message,
+		Resolved: data.Recovery || data.Kind == NotificationKindRecovery,
+	}
+
+	if data.Alert.ID > 0 {
+		alert := data.Alert
+		msg.Severity = alert.Severity
+		msg.SeverityLabel = alert.SeverityLabel
+		msg.Summary = alert.Message
+		msg.Fields = append(msg.Fields, mediaSvc.RichField{Name: "Status", Value: alert.StatusLabel, Short: true})
+		if data.Host.Name != "" {
+			host := data.Host.Name
+			if data.Host.IP != "" {
+				host += " (" + data.Host.IP + ")"
+			}
+			msg.Fields = append(msg.Fields, mediaSvc.RichField{Name: "Host", Value: host, Short: true})
+		}
+		if data.Item.Name != "" {
+			msg.Fields = append(msg.Fields, mediaSvc.RichField{Name: "Item", Value: data.Item.Name, Short: true})
+			if data.Item.LastValue != "" {
+				msg.Fields = append(msg.Fields, mediaSvc.RichField{Name: "Value", Value: strings.TrimSpace(data.Item.LastValue + " " + data.Item.Units), Short: true})
+			}
+		}
+		if alert.Occurrences > 1 {
+			msg.Fields = append(msg.Fields, mediaSvc.RichField{Name: "Occurrences", Value: strconv.Itoa(alert.Occurrences), Short: true})
+		}
+		msg.Fields = append(msg.Fields, mediaSvc.RichField{Name: "Started", Value: alert.CreatedAt.Format("2006-01-02 15:04:05"), Short: true})
+		if alert.Duration != "" {
+			msg.Fields = append(msg.Fields, mediaSvc.RichField{Name: "Duration", Value: alert.Duration, Short: true})
+		}
+		if alert.Analysis != "" {
+			msg.Fields = append(msg.Fields, mediaSvc.RichField{Name: "AI analysis", Value: templateTruncate(richAnalysisLimit, alert.Analysis)})
+		}
+	}
+	if data.Incident.ID > 0 {
+		if msg.Severity < 0 {
+			msg.Severity = data.Incident.Severity
+			msg.SeverityLabel = severityLabel(data.Incident.Severity)
+		}
+		msg.Fields = append(msg.Fields, mediaSvc.RichField{Name: "Incident", Value: fmt.Sprintf("#%d %s", data.Incident.ID, data.Incident.Title)})
+	}
+
+	if data.Links.Alert != "" {
+		msg.Buttons = append(msg.Buttons, mediaSvc.RichLink{Text: "View alert", URL: data.Links.Alert})
+	}
+	if data.Links.Host != "" {
+		msg.Links = append(msg.Links, mediaSvc.RichLink{Text: "Host", URL: data.Links.Host})
+	}
+	if data.Links.Item != "" {
+		msg.Links = append(msg.Links, mediaSvc.RichLink{Text: "Item", URL: data.Links.Item})
+	}
+
+	if withChart && data.Item.ID > 0 {
+		if chart := itemHistoryChart(data.Item, data.Alert.CreatedAt); chart != nil {
+			msg.Image = &mediaSvc.RichImage{Filename: "chart.png", ContentType: "image/png", Data: chart}
+		}
+	}
+	return msg
+}
+
+// itemHistoryChart renders the numeric history of an item around an alert as a PNG line chart.
+// It returns nil when the item has fewer than two numeric samples in the window.
+func itemHistoryChart(item MessageTemplateItem, alertAt time.Time) []byte {
+	to := time.Now()
+	from := to.Add(-richChartWindow)
+	if !alertAt.IsZero() && alertAt.Add(-time.Hour).Before(from) {
+		from = alertAt.Add(-time.Hour)
+	}
+	rows, err := repository.ListItemHistoryDAO(item.ID, &from, &to, richChartMaxPoints)
+	if err != nil {
+		return nil
+	}
+	labels := make([]string, 0, len(rows))
+	values := make([]float64, 0, len(rows))
+	// Rows come newest first
+	for i := len(rows) - 1; i >= 0; i-- {
+		value, err := strconv.ParseFloat(strings.TrimSpace(rows[i].Value), 64)
+		if err != nil {
+			continue
+		}
+		labels = append(labels, rows[i].SampledAt.Format("15:04"))
+		values = append(values, value)
+	}
+	if len(values) < 2 {
+		return nil
+	}
+	chart, err := utils.GenerateLineChart(item.Name, labels, values, "Time", item.Units)
+	if err != nil {
+		LogService("warn", "failed to render item chart for notification", map[string]interface{}{
+			"item_id": item.ID,
+			"error":   err.Error(),
+		}, nil, "")
+		return nil
+	}
+	return chart
+}
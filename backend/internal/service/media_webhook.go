@@ -0,0 +1,300 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"nagare/internal/model"
+	"nagare/internal/repository"
+	mediaSvc "nagare/internal/repository/media"
+	"nagare/internal/service/utils"
+)
+
+// webhookResponseBodyLimit bounds the response text kept on a delivery
+const webhookResponseBodyLimit = 4096
+
+// WebhookTemplateData is the context webhook body templates render against and rich messages are built from.
+// Alert fields are empty for messages that are not about a single alert, such as digests or tests.
+type WebhookTemplateData struct {
+	MessageTemplateData
+	Message    string                  `json:"message"` // The rendered notification text
+	Subject    string                  `json:"subject"` // First line of Message
+	Kind       string                  `json:"kind"`    // alert, recovery, escalation, incident, digest or test
+	DeliveryID uint                    `json:"delivery_id"`
+	Incident   WebhookTemplateIncident `json:"incident"`
+	SentAt     time.Time               `json:"sent_at"`
+}
+
+// WebhookTemplateIncident exposes the incident a message is about
+type WebhookTemplateIncident struct {
+	ID       uint   `json:"id"`
+	Title    string `json:"title"`
+	Severity int    `json:"severity"`
+	Status   int    `json:"status"`
+	Summary  string `json:"summary"`
+}
+
+// isWebhookMediaType reports whether a media type is sent through the generic webhook provider
+func isWebhookMediaType(mediaType string) bool {
+	switch strings.ToLower(strings.TrimSpace(mediaType)) {
+	case "webhook", "other":
+		return true
+	}
+	return false
+}
+
+// webhookMessageData is the template context for a message without a queued delivery behind it
+func webhookMessageData(message, kind string) WebhookTemplateData {
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return WebhookTemplateData{
+		Message: message,
+		Subject: strings.TrimSpace(subject),
+		Kind:    kind,
+		SentAt:  time.Now(),
+	}
+}
+
+// webhookDeliveryData builds the template context for a queued delivery, including its alert and incident
+func webhookDeliveryData(delivery model.NotificationDelivery) WebhookTemplateData {
+	data := webhookMessageData(delivery.Message, delivery.Kind)
+	data.DeliveryID = delivery.ID
+	if delivery.AlertID != nil {
+		if alert, err := repository.GetAlertByIDDAO(int(*delivery.AlertID)); err == nil {
+			var resolvedAt *time.Time
+			if delivery.Kind == NotificationKindRecovery {
+				queuedAt := delivery.CreatedAt
+				resolvedAt = &queuedAt
+			}
+			msg := buildAlertMessage(buildAlertMatchContext(alert), resolvedAt)
+			data.MessageTemplateData = msg.data
+			if delivery.ActionID != nil {
+				if action, err := repository.GetActionByIDDAO(*delivery.ActionID); err == nil {
+					data.Action = action.Name
+				}
+			}
+		}
+	}
+	incidentID := delivery.IncidentID
+	if incidentID == nil && data.Alert.IncidentID > 0 {
+		incidentID = &data.Alert.IncidentID
+	}
+	if incidentID != nil {
+		if incident, err := repository.GetIncidentByIDDAO(*incidentID); err == nil {
+			data.Incident = WebhookTemplateIncident{
+				ID:       incident.ID,
+				Title:    incident.Title,
+				Severity: incident.Severity,
+				Status:   incident.Status,
+				Summary:  incident.Summary,
+			}
+		}
+	}
+	return data
+}
+
+// renderWebhookBody renders the configured body template, or the default {"message": ...} payload
+func renderWebhookBody(config *model.MediaWebhookConfig, data WebhookTemplateData) ([]byte, error) {
+	if config == nil || strings.TrimSpace(config.BodyTemplate) == "" {
+		return json.Marshal(map[string]string{"message": data.Message})
+	}
+	tmpl, err := parseMessageTemplate("webhook body", config.BodyTemplate)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("webhook body template: %w", err)
+	}
+	body := bytes.TrimSpace(buf.Bytes())
+	if webhookSendsJSON(config) && len(body) > 0 && !json.Valid(body) {
+		return nil, fmt.Errorf("webhook body template did not render valid JSON; quote values with the json function")
+	}
+	return body, nil
+}
+
+func webhookSendsJSON(config *model.MediaWebhookConfig) bool {
+	contentType := strings.ToLower(strings.TrimSpace(config.ContentType))
+	return contentType == "" || strings.Contains(contentType, "json")
+}
+
+// webhookOptions builds the request options from stored settings, decrypting the secrets
+func webhookOptions(stored *model.MediaWebhookConfig) (mediaSvc.WebhookOptions, error) {
+	if stored == nil {
+		return mediaSvc.WebhookOptions{}, nil
+	}
+	config, err := decryptMediaWebhookSecrets(stored)
+	if err != nil {
+		return mediaSvc.WebhookOptions{}, err
+	}
+	return mediaSvc.WebhookOptions{
+		Method:           config.Method,
+		Headers:          config.Headers,
+		AuthType:         config.AuthType,
+		Token:            config.Token,
+		Username:         config.Username,
+		Password:         config.Password,
+		ContentType:      config.ContentType,
+		Timeout:          time.Duration(config.TimeoutSeconds) * time.Second,
+		SkipTLSVerify:    config.SkipTLSVerify,
+		CACert:           config.CACert,
+		SuccessStatuses:  config.SuccessStatuses,
+		ResponseContains: config.ResponseContains,
+	}, nil
+}
+
+// sendWebhookMedia renders and performs the request of a webhook media
+func sendWebhookMedia(ctx context.Context, media model.Media, data WebhookTemplateData) (mediaSvc.WebhookResponse, error) {
+	provider, err := mediaSvc.GetService().GetProvider("webhook")
+	if err != nil {
+		return mediaSvc.WebhookResponse{}, err
+	}
+	webhook, ok := provider.(*mediaSvc.WebhookProvider)
+	if !ok {
+		return mediaSvc.WebhookResponse{}, fmt.Errorf("webhook provider does not support request options")
+	}
+	body, err := renderWebhookBody(media.Webhook, data)
+	if err != nil {
+		return mediaSvc.WebhookResponse{}, err
+	}
+	opts, err := webhookOptions(media.Webhook)
+	if err != nil {
+		return mediaSvc.WebhookResponse{}, err
+	}
+	return webhook.Send(ctx, media.Target, body, opts)
+}
+
+// encryptMediaWebhookSecrets returns a copy of config with the token, password and header values encrypted
+// for storage
+func encryptMediaWebhookSecrets(config *model.MediaWebhookConfig) (*model.MediaWebhookConfig, error) {
+	return transformMediaWebhookSecrets(config, func(value string) (string, error) {
+		encrypted, err := utils.Encrypt(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to encrypt webhook secret: %w", err)
+		}
+		return encrypted, nil
+	})
+}
+
+// decryptMediaWebhookSecrets returns a copy of stored settings with the secrets in plain text
+func decryptMediaWebhookSecrets(config *model.MediaWebhookConfig) (*model.MediaWebhookConfig, error) {
+	return transformMediaWebhookSecrets(config, func(value string) (string, error) {
+		decrypted, err := utils.Decrypt(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt webhook secret: %w", err)
+		}
+		return decrypted, nil
+	})
+}
+
+// redactMediaWebhook blanks the secrets so responses never carry them; header names are kept
+func redactMediaWebhook(config *model.MediaWebhookConfig) *model.MediaWebhookConfig {
+	redacted, _ := transformMediaWebhookSecrets(config, func(string) (string, error) { return "", nil })
+	return redacted
+}
+
+// transformMediaWebhookSecrets applies fn to every non-empty secret of a copy of config
+func transformMediaWebhookSecrets(config *model.MediaWebhookConfig, fn func(string) (string, error)) (*model.MediaWebhookConfig, error) {
+	if config == nil {
+		return nil, nil
+	}
+	result := *config
+	var err error
+	if result.Token != "" {
+		if result.Token, err = fn(result.Token); err != nil {
+			return nil, err
+		}
+	}
+	if result.Password != "" {
+		if result.Password, err = fn(result.Password); err != nil {
+			return nil, err
+		}
+	}
+	if config.Headers != nil {
+		result.Headers = make(map[string]string, len(config.Headers))
+		for key, value := range config.Headers {
+			if value != "" {
+				if value, err = fn(value); err != nil {
+					return nil, err
+				}
+			}
+			result.Headers[key] = value
+		}
+	}
+	return &result, nil
+}
+
+// keepMediaWebhookSecrets fills secrets left empty in an update from the stored plain-text settings, since
+// responses only ever return them blank
+func keepMediaWebhookSecrets(config, existing *model.MediaWebhookConfig) {
+	if config == nil || existing == nil {
+		return
+	}
+	if config.Token == "" {
+		config.Token = existing.Token
+	}
+	if config.Password == "" {
+		config.Password = existing.Password
+	}
+	for key, value := range config.Headers {
+		if value == "" {
+			config.Headers[key] = existing.Headers[key]
+		}
+	}
+}
+
+// validateMediaWebhook rejects webhook settings that could never produce a request
+func validateMediaWebhook(config *model.MediaWebhookConfig) error {
+	if config == nil {
+		return nil
+	}
+	config.Method = strings.ToUpper(strings.TrimSpace(config.Method))
+	if config.Method == "" {
+		config.Method = http.MethodPost
+	}
+	validMethod := false
+	for _, method := range mediaSvc.WebhookMethods {
+		if config.Method == method {
+			validMethod = true
+			break
+		}
+	}
+	if !validMethod {
+		return fmt.Errorf("%w: unsupported webhook method %s", model.ErrInvalidInput, config.Method)
+	}
+	config.AuthType = strings.ToLower(strings.TrimSpace(config.AuthType))
+	switch config.AuthType {
+	case "", "none":
+		config.AuthType = ""
+	case "bearer":
+		if config.Token == "" {
+			return fmt.Errorf("%w: bearer auth requires a token", model.ErrInvalidInput)
+		}
+	case "basic":
+		if config.Username == "" {
+			return fmt.Errorf("%w: basic auth requires a username", model.ErrInvalidInput)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported webhook auth type %s", model.ErrInvalidInput, config.AuthType)
+	}
+	if config.TimeoutSeconds < 0 || config.TimeoutSeconds > 120 {
+		return fmt.Errorf("%w: webhook timeout must be between 1 and 120 seconds", model.ErrInvalidInput)
+	}
+	if _, err := mediaSvc.ParseWebhookStatuses(config.SuccessStatuses); err != nil {
+		return fmt.Errorf("%w: %v", model.ErrInvalidInput, err)
+	}
+	if strings.TrimSpace(config.CACert) != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(config.CACert)) {
+		return fmt.Errorf("%w: webhook ca_cert contains no valid PEM certificate", model.ErrInvalidInput)
+	}
+	if strings.TrimSpace(config.BodyTemplate) != "" {
+		if _, err := parseMessageTemplate("webhook body", config.BodyTemplate); err != nil {
+			return fmt.Errorf("%w: %v", model.ErrInvalidInput, err)
+		}
+	}
+	return nil
+}
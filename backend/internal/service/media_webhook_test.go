@@ -0,0 +1,90 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"nagare/internal/model"
+)
+
+func TestMediaWebhookSecretsRoundTrip(t *testing.T) {
+	config := &model.MediaWebhookConfig{
+		Method:   "POST",
+		AuthType: "basic",
+		Token:    "tok",
+		Username: "svc",
+		Password: "pw",
+		Headers:  map[string]string{"X-Api-Key": "key", "X-Empty": ""},
+	}
+
+	stored, err := encryptMediaWebhookSecrets(config)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if stored.Token == "tok" || stored.Password == "pw" || stored.Headers["X-Api-Key"] == "key" {
+		t.Fatalf("secrets stored in plain text: %+v", stored)
+	}
+	if stored.Username != "svc" || stored.Headers["X-Empty"] != "" {
+		t.Fatalf("non-secret fields changed: %+v", stored)
+	}
+	if config.Token != "tok" || config.Headers["X-Api-Key"] != "key" {
+		t.Fatalf("encrypt modified its input: %+v", config)
+	}
+
+	plain, err := decryptMediaWebhookSecrets(stored)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if !reflect.DeepEqual(plain, config) {
+		t.Fatalf("round trip = %+v, want %+v", plain, config)
+	}
+
+	redacted := redactMediaWebhook(stored)
+	if redacted.Token != "" || redacted.Password != "" || redacted.Headers["X-Api-Key"] != "" {
+		t.Fatalf("redacted settings still carry secrets: %+v", redacted)
+	}
+	if _, ok := redacted.Headers["X-Api-Key"]; !ok || redacted.Username != "svc" {
+		t.Fatalf("redaction dropped non-secret fields: %+v", redacted)
+	}
+	if redactMediaWebhook(nil) != nil {
+		t.Fatalf("redacting nil settings should stay nil")
+	}
+}
+
+func TestKeepMediaWebhookSecrets(t *testing.T) {
+	existing := &model.MediaWebhookConfig{
+		Token:    "old-token",
+		Password: "old-password",
+		Headers:  map[string]string{"X-Api-Key": "old-key", "X-Removed": "gone"},
+	}
+
+	cases := []struct {
+		name   string
+		update model.MediaWebhookConfig
+		want   model.MediaWebhookConfig
+	}{
+		{
+			name:   "blank fields keep stored secrets",
+			update: model.MediaWebhookConfig{Headers: map[string]string{"X-Api-Key": ""}},
+			want:   model.MediaWebhookConfig{Token: "old-token", Password: "old-password", Headers: map[string]string{"X-Api-Key": "old-key"}},
+		},
+		{
+			name:   "new values replace stored secrets",
+			update: model.MediaWebhookConfig{Token: "new-token", Password: "new-password", Headers: map[string]string{"X-Api-Key": "new-key"}},
+			want:   model.MediaWebhookConfig{Token: "new-token", Password: "new-password", Headers: map[string]string{"X-Api-Key": "new-key"}},
+		},
+		{
+			name:   "unknown blank header stays blank",
+			update: model.MediaWebhookConfig{Token: "t", Password: "p", Headers: map[string]string{"X-New": ""}},
+			want:   model.MediaWebhookConfig{Token: "t", Password: "p", Headers: map[string]string{"X-New": ""}},
+		},
+	}
+
+	for _, tc := range cases {
+		update := tc.update
+		keepMediaWebhookSecrets(&update, existing)
+		if !reflect.DeepEqual(update, tc.want) {
+			t.Fatalf("%s: got %+v, want %+v", tc.name, update, tc.want)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"nagare/internal/model"
+	"nagare/internal/repository"
+
+	"github.com/spf13/viper"
+)
+
+// Rendered messages are capped so a runaway range cannot flood a channel
+const messageTemplateMaxOutput = 16 * 1024
+
+// MessageTemplateData is the context action subject and body templates render against
+type MessageTemplateData struct {
+	Alert    MessageTemplateAlert `json:"alert"`
+	Host     MessageTemplateRef   `json:"host"`
+	Group    MessageTemplateRef   `json:"group"`
+	Item     MessageTemplateItem  `json:"item"`
+	Monitor  MessageTemplateRef   `json:"monitor"`
+	Links    MessageTemplateLinks `json:"links"`
+	Action   string               `json:"action"`
+	Recovery bool                 `json:"recovery"`
+}
+
+// MessageTemplateAlert exposes the alert to templates
+type MessageTemplateAlert struct {
+	ID            uint              `json:"id"`
+	Message       string            `json:"message"`
+	Severity      int               `json:"severity"`
+	SeverityLabel string            `json:"severity_label"`
+	Status        int               `json:"status"`
+	StatusLabel   string            `json:"status_label"`
+	Analysis      string            `json:"analysis"`
+	Labels        map[string]string `json:"labels"`
+	Annotations   map[string]string `json:"annotations"`
+	Occurrences   int               `json:"occurrences"`
+	IncidentID    uint              `json:"incident_id"`
+	CreatedAt     time.Time         `json:"created_at"`
+	ResolvedAt    *time.Time        `json:"resolved_at"`
+	Duration      string            `json:"duration"` // Problem duration, set for recovery messages
+}
+
+// MessageTemplateRef is a named object related to the alert
+type MessageTemplateRef struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	IP   string `json:"ip,omitempty"`
+}
+
+// MessageTemplateItem is the monitored item that raised the alert
+type MessageTemplateItem struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	LastValue string `json:"last_value"`
+	Units     string `json:"units"`
+}
+
+// MessageTemplateLinks point into the web UI
+type MessageTemplateLinks struct {
+	Alert string `json:"alert"`
+	Host  string `json:"host"`
+	Item  string `json:"item"`
+}
+
+// MessagePreviewReq renders unsaved templates against an existing alert
+type MessagePreviewReq struct {
+	AlertID         uint   `json:"alert_id" binding:"required"`
+	SubjectTemplate string `json:"subject_template"`
+	BodyTemplate    string `json:"body_template"`
+	Recovery        bool   `json:"recovery"`
+}
+
+// MessagePreviewResp is the rendered preview and the data it was rendered from
+type MessagePreviewResp struct {
+	Subject string              `json:"subject"`
+	Body    string              `json:"body"`
+	Message string              `json:"message"` // What text-only channels receive
+	Data    MessageTemplateData `json:"data"`
+}
+
+// alertMessage carries everything an action message for one alert is rendered from
+type alertMessage struct {
+	replacements map[string]string
+	data         MessageTemplateData
+}
+
+// messageTemplateFuncs is the function library available to templates; none of them touch the system
+var messageTemplateFuncs = template.FuncMap{
+	"truncate":      templateTruncate,
+	"upper":         strings.ToUpper,
+	"lower":         strings.ToLower,
+	"trim":          strings.TrimSpace,
+	"replace":       func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"contains":      func(substr, s string) bool { return strings.Contains(s, substr) },
+	"join":          func(sep string, items []string) string { return strings.Join(items, sep) },
+	"default":       templateDefault,
+	"formatTime":    templateFormatTime,
+	"since":         func(t time.Time) string { return formatProblemDuration(time.Since(t)) },
+	"severityLabel": severityLabel,
+	"severityEmoji": severityEmoji,
+	"json":          templateJSON,
+}
+
+// PreviewActionMessageServ renders subject and body templates against an existing alert
+func PreviewActionMessageServ(req MessagePreviewReq) (MessagePreviewResp, error) {
+	alert, err := repository.GetAlertByIDDAO(int(req.AlertID))
+	if err != nil {
+		return MessagePreviewResp{}, model.ErrNotFound
+	}
+	var resolvedAt *time.Time
+	if req.Recovery {
+		now := time.Now()
+		resolvedAt = &now
+	}
+	msg := buildAlertMessage(buildAlertMatchContext(alert), resolvedAt)
+	msg.data.Action = "preview"
+
+	subject, body, err := renderMessageTemplates(req.SubjectTemplate, req.BodyTemplate, msg.data)
+	if err != nil {
+		return MessagePreviewResp{}, fmt.Errorf("%w: %v", model.ErrInvalidInput, err)
+	}
+	action := model.Action{Name: "preview", SubjectTemplate: req.SubjectTemplate, BodyTemplate: req.BodyTemplate}
+	if req.Recovery {
+		action = model.Action{Name: "preview", RecoverySubjectTemplate: req.SubjectTemplate, RecoveryBodyTemplate: req.BodyTemplate}
+	}
+	return MessagePreviewResp{
+		Subject: subject,
+		Body:    body,
+		Message: renderActionMessage(action, msg),
+		Data:    msg.data,
+	}, nil
+}
+
+// buildAlertMessage prepares the legacy replacements and the template data; resolvedAt marks a recovery message
+func buildAlertMessage(ctx alertMatchContext, resolvedAt *time.Time) alertMessage {
+	alert := ctx.alert
+	replacements := buildAlertReplacements(ctx)
+	data := MessageTemplateData{
+		Alert: MessageTemplateAlert{
+			ID:            alert.ID,
+			Message:       alert.Message,
+			Severity:      alert.Severity,
+			SeverityLabel: severityLabel(alert.Severity),
+			Status:        alert.Status,
+			StatusLabel:   alertStatusLabel(alert.Status),
+			Analysis:      alert.Comment,
+			Labels:        decodeAlertStringMap(alert.Labels),
+			Annotations:   decodeAlertStringMap(alert.Annotations),
+			Occurrences:   alert.OccurrenceCount,
+			CreatedAt:     alert.CreatedAt,
+		},
+		Recovery: resolvedAt != nil,
+	}
+	if alert.IncidentID != nil {
+		data.Alert.IncidentID = *alert.IncidentID
+	}
+	if resolvedAt != nil {
+		duration := formatProblemDuration(resolvedAt.Sub(alert.CreatedAt))
+		data.Alert.ResolvedAt = resolvedAt
+		data.Alert.Duration = duration
+		replacements["{{duration}}"] = duration
+		replacements["{{resolved_at}}"] = resolvedAt.Format(time.RFC3339)
+	}
+
+	base := webBaseURL()
+	data.Links.Alert = base + "/alert?q=" + url.QueryEscape(alert.Message)
+	if ctx.host != nil {
+		data.Host = MessageTemplateRef{ID: ctx.host.ID, Name: ctx.host.Name, IP: ctx.host.IPAddr}
+		data.Links.Host = fmt.Sprintf("%s/host/%d/detail", base, ctx.host.ID)
+		if group, err := repository.GetGroupByIDDAO(ctx.host.GroupID); err == nil {
+			data.Group = MessageTemplateRef{ID: group.ID, Name: group.Name}
+			replacements["{{group_id}}"] = fmt.Sprintf("%d", group.ID)
+		}
+	}
+	if ctx.item != nil {
+		data.Item = MessageTemplateItem{ID: ctx.item.ID, Name: ctx.item.Name, LastValue: ctx.item.LastValue, Units: ctx.item.Units}
+		data.Links.Item = fmt.Sprintf("%s/item/%d/detail", base, ctx.item.ID)
+	}
+	if ctx.monitorID > 0 {
+		if monitor, err := repository.GetMonitorByIDDAO(ctx.monitorID); err == nil {
+			data.Monitor = MessageTemplateRef{ID: monitor.ID, Name: monitor.Name}
+		}
+	}
+	return alertMessage{replacements: replacements, data: data}
+}
+
+// renderActionMessage renders an action's problem or recovery message; actions without templates keep the built-in text
+func renderActionMessage(action model.Action, msg alertMessage) string {
+	subjectTemplate, bodyTemplate := action.SubjectTemplate, action.BodyTemplate
+	if msg.data.Recovery {
+		subjectTemplate, bodyTemplate = action.RecoverySubjectTemplate, action.RecoveryBodyTemplate
+	}
+	if strings.TrimSpace(subjectTemplate) == "" && strings.TrimSpace(bodyTemplate) == "" {
+		return defaultAlertMessage(msg)
+	}
+
+	data := msg.data
+	data.Action = action.Name
+	subject, body, err := renderMessageTemplates(subjectTemplate, bodyTemplate, data)
+	if err != nil {
+		LogService("warn", "action template failed, using default message", map[string]interface{}{
+			"action_id": action.ID,
+			"alert_id":  msg.data.Alert.ID,
+			"error":     err.Error(),
+		}, nil, "")
+		return defaultAlertMessage(msg)
+	}
+	switch {
+	case subject == "":
+		return body
+	case body == "":
+		return subject
+	default:
+		return subject + "\n\n" + body
+	}
+}
+
+func defaultAlertMessage(msg alertMessage) string {
+	text := "Alert: {{message}}"
+	if msg.data.Recovery {
+		text = "Resolved: {{message}}\nProblem duration: {{duration}}"
+	}
+	return appendAlertDetails(renderMessageTemplate(text, msg.replacements), msg.replacements)
+}
+
+func renderMessageTemplates(subjectTemplate, bodyTemplate string, data MessageTemplateData) (string, string, error) {
+	subject, err := executeMessageTemplate("subject", subjectTemplate, data)
+	if err != nil {
+		return "", "", err
+	}
+	body, err := executeMessageTemplate("body", bodyTemplate, data)
+	if err != nil {
+		return "", "", err
+	}
+	// Subjects are one line on every channel
+	subject = strings.Join(strings.Fields(subject), " ")
+	return subject, body, nil
+}
+
+func executeMessageTemplate(name, text string, data MessageTemplateData) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return "", nil
+	}
+	tmpl, err := parseMessageTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("%s template: %w", name, err)
+	}
+	return templateTruncate(messageTemplateMaxOutput, strings.TrimSpace(buf.String())), nil
+}
+
+func parseMessageTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=zero").Funcs(messageTemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%s template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// validateActionTemplates rejects templates that do not parse
+func validateActionTemplates(templates map[string]string) error {
+	for name, text := range templates {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if _, err := parseMessageTemplate(name, text); err != nil {
+			return fmt.Errorf("%w: %v", model.ErrInvalidInput, err)
+		}
+	}
+	return nil
+}
+
+// webBaseURL is the externally reachable address of this Nagare instance
+func webBaseURL() string {
+	host := strings.TrimSpace(viper.GetString("system.ip_address"))
+	port := viper.GetInt("system.port")
+	if port == 0 {
+		port = 8080
+	}
+	if host == "" {
+		host = "localhost"
+	}
+	base := strings.TrimRight(host, "/")
+	if strings.HasPrefix(base, "http://") || strings.HasPrefix(base, "https://") {
+		return base
+	}
+	return fmt.Sprintf("http://%s:%d", base, port)
+}
+
+func templateTruncate(length int, s string) string {
+	if length <= 0 || utf8.RuneCountInString(s) <= length {
+		return s
+	}
+	runes := []rune(s)
+	if length <= 3 {
+		return string(runes[:length])
+	}
+	return string(runes[:length-3]) + "..."
+}
+
+// templateJSON encodes a value as JSON, so strings can be placed in JSON bodies with quotes and escaping
+func templateJSON(value interface{}) (string, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+func templateDefault(fallback string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return fallback
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return fallback
+		}
+	}
+	return value
+}
+
+// templateFormatTime formats a time with a Go layout or one of "rfc3339", "datetime", "date", "time"
+func templateFormatTime(layout string, value interface{}) string {
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		t = *v
+	default:
+		return ""
+	}
+	switch layout {
+	case "rfc3339":
+		layout = time.RFC3339
+	case "datetime":
+		layout = "2006-01-02 15:04:05"
+	case "date":
+		layout = "2006-01-02"
+	case "time":
+		layout = "15:04:05"
+	}
+	return t.Local().Format(layout)
+}
+
+func severityEmoji(severity int) string {
+	switch severity {
+	case 5:
+		return "🚨"
+	case 4:
+		return "🔴"
+	case 3:
+		return "🟠"
+	case 2:
+		return "🟡"
+	case 1:
+		return "🔵"
+	default:
+		return "⚪"
+	}
+}
+
+func alertStatusLabel(status int) string {
+	switch status {
+	case 1:
+		return "Acknowledged"
+	case 2:
+		return "Resolved"
+	default:
+		return "Active"
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestExecuteMessageTemplateFuncs(t *testing.T) {
+	created := time.Date(2024, 3, 9, 14, 5, 7, 0, time.Local)
+	data := MessageTemplateData{
+		Alert: MessageTemplateAlert{
+			ID:        42,
+			Message:   `Disk "/var" full`,
+			Severity:  4,
+			Labels:    map[string]string{"env": "prod"},
+			CreatedAt: created,
+		},
+		Host: MessageTemplateRef{Name: "db01"},
+	}
+
+	cases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "empty template", text: "  ", want: ""},
+		{name: "fields", text: "#{{.Alert.ID}} on {{.Host.Name}}", want: "#42 on db01"},
+		{name: "truncate", text: `{{truncate 7 .Alert.Message}}`, want: "Disk..."},
+		{name: "truncate short limit", text: `{{truncate 2 .Host.Name}}`, want: "db"},
+		{name: "upper lower trim", text: `{{upper .Host.Name}} {{lower "ABC"}} [{{trim "  x "}}]`, want: "DB01 abc [x]"},
+		{name: "replace", text: `{{replace "db" "app" .Host.Name}}`, want: "app01"},
+		{name: "contains", text: `{{if contains "full" .Alert.Message}}yes{{end}}`, want: "yes"},
+		{name: "default on empty", text: `{{default "n/a" .Item.Name}}`, want: "n/a"},
+		{name: "default keeps value", text: `{{default "n/a" .Host.Name}}`, want: "db01"},
+		{name: "missing label", text: `{{default "none" .Alert.Labels.team}}`, want: "none"},
+		{name: "label", text: `{{.Alert.Labels.env}}`, want: "prod"},
+		{name: "formatTime named", text: `{{formatTime "datetime" .Alert.CreatedAt}}`, want: "2024-03-09 14:05:07"},
+		{name: "formatTime layout", text: `{{formatTime "15:04" .Alert.CreatedAt}}`, want: "14:05"},
+		{name: "formatTime nil pointer", text: `[{{formatTime "date" .Alert.ResolvedAt}}]`, want: "[]"},
+		{name: "severity helpers", text: `{{severityEmoji .Alert.Severity}} {{severityLabel .Alert.Severity}}`, want: "🔴 High"},
+		{name: "json escapes", text: `{"text":{{json .Alert.Message}}}`, want: `{"text":"Disk \"/var\" full"}`},
+	}
+
+	for _, tc := range cases {
+		got, err := executeMessageTemplate("body", tc.text, data)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestExecuteMessageTemplateErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+	}{
+		{name: "parse error", text: "{{.Alert.ID"},
+		{name: "unknown function", text: `{{exec "rm"}}`},
+		{name: "unknown field", text: "{{.Alert.Nope}}"},
+	}
+
+	for _, tc := range cases {
+		if _, err := executeMessageTemplate("body", tc.text, MessageTemplateData{}); err == nil {
+			t.Fatalf("%s: expected error", tc.name)
+		}
+	}
+	if err := validateActionTemplates(map[string]string{"subject": "{{if}}"}); err == nil {
+		t.Fatalf("validateActionTemplates accepted a broken template")
+	}
+}
+
+func TestExecuteMessageTemplateSizeCap(t *testing.T) {
+	data := MessageTemplateData{Host: MessageTemplateRef{Name: "é"}}
+	text := strings.Repeat("{{.Host.Name}}", messageTemplateMaxOutput+100)
+
+	got, err := executeMessageTemplate("body", text, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := utf8.RuneCountInString(got); n != messageTemplateMaxOutput {
+		t.Fatalf("rendered %d runes, want cap of %d", n, messageTemplateMaxOutput)
+	}
+	if !utf8.ValidString(got) || !strings.HasSuffix(got, "...") {
+		t.Fatalf("capped output should be valid UTF-8 ending in an ellipsis")
+	}
+}
+
+func TestTemplateTruncate(t *testing.T) {
+	cases := []struct {
+		length int
+		in     string
+		want   string
+	}{
+		{length: 0, in: "unchanged", want: "unchanged"},
+		{length: 20, in: "short", want: "short"},
+		{length: 5, in: "exact", want: "exact"},
+		{length: 6, in: "overflowing", want: "ove..."},
+		{length: 3, in: "overflowing", want: "ove"},
+		{length: 4, in: "日本語テキスト", want: "日..."},
+	}
+
+	for _, tc := range cases {
+		if got := templateTruncate(tc.length, tc.in); got != tc.want {
+			t.Fatalf("templateTruncate(%d, %q) = %q, want %q", tc.length, tc.in, got, tc.want)
+		}
+	}
+}
@@ -22,7 +22,7 @@ type MonitorReq struct {
 	AuthToken   string `json:"auth_token"`
 	EventToken  string `json:"event_token"`
 	Description string `json:"description"`
-	Type        int    `json:"type" binding:"required,oneof=1 2 3"`
+	Type        int    `json:"type" binding:"required,oneof=1 2 3 5"`
 	Enabled     int    `json:"enabled"`
 }
 
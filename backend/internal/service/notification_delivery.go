@@ -0,0 +1,432 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"nagare/internal/model"
+	"nagare/internal/repository"
+	mediaSvc "nagare/internal/repository/media"
+)
+
+// Notification delivery statuses
+const (
+	NotificationStatusPending  = 0
+	NotificationStatusSending  = 1
+	NotificationStatusSent     = 2
+	NotificationStatusFailed   = 3
+	NotificationStatusSkipped  = 4
+	NotificationStatusDigested = 5
+)
+
+// Notification delivery kinds
+const (
+	NotificationKindAlert      = "alert"
+	NotificationKindRecovery   = "recovery"
+	NotificationKindEscalation = "escalation"
+	NotificationKindIncident   = "incident"
+	NotificationKindDigest     = "digest"
+)
+
+const notificationPollInterval = 5 * time.Second
+
+var (
+	notificationMu     sync.Mutex
+	notificationCancel context.CancelFunc
+	notificationWake   = make(chan struct{}, 1)
+)
+
+// NotificationDeliveryResp represents an outbox delivery response
+type NotificationDeliveryResp struct {
+	ID            uint       `json:"id"`
+	Kind          string     `json:"kind"`
+	AlertID       *uint      `json:"alert_id"`
+	IncidentID    *uint      `json:"incident_id"`
+	ActionID      *uint      `json:"action_id"`
+	MediaID       uint       `json:"media_id"`
+	MediaType     string     `json:"media_type"`
+	Target        string     `json:"target"`
+	UserID        *uint      `json:"user_id"`
+	Message       string     `json:"message"`
+	Status        int        `json:"status"`
+	StatusLabel   string     `json:"status_label"`
+	Attempts      int        `json:"attempts"`
+	MaxAttempts   int        `json:"max_attempts"`
+	NextAttemptAt *time.Time `json:"next_attempt_at"`
+	LastAttemptAt *time.Time `json:"last_attempt_at"`
+	SentAt        *time.Time `json:"sent_at"`
+	LastError     string     `json:"last_error"`
+	DigestID      *uint      `json:"digest_id"`
+	ResponseCode  int        `json:"response_code"`
+	ResponseBody  string     `json:"response_body"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// notificationSource records what a queued message is about and how storms of it are condensed
+type notificationSource struct {
+	Kind       string
+	AlertID    *uint
+	IncidentID *uint
+	ActionID   *uint
+	Digest     digestSettings
+}
+
+// SearchNotificationDeliveriesServ retrieves outbox deliveries by filter
+func SearchNotificationDeliveriesServ(filter model.NotificationDeliveryFilter) ([]NotificationDeliveryResp, error) {
+	deliveries, err := repository.SearchNotificationDeliveriesDAO(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search notification deliveries: %w", err)
+	}
+	result := make([]NotificationDeliveryResp, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		result = append(result, notificationDeliveryToResp(delivery))
+	}
+	return result, nil
+}
+
+// CountNotificationDeliveriesServ returns total count for outbox deliveries by filter
+func CountNotificationDeliveriesServ(filter model.NotificationDeliveryFilter) (int64, error) {
+	return repository.CountNotificationDeliveriesDAO(filter)
+}
+
+// GetNotificationDeliveryByIDServ retrieves an outbox delivery by ID
+func GetNotificationDeliveryByIDServ(id uint) (NotificationDeliveryResp, error) {
+	delivery, err := repository.GetNotificationDeliveryByIDDAO(id)
+	if err != nil {
+		return NotificationDeliveryResp{}, err
+	}
+	return notificationDeliveryToResp(delivery), nil
+}
+
+// RetryNotificationDeliveryServ puts a failed or skipped delivery back in the queue with a fresh attempt budget
+func RetryNotificationDeliveryServ(id uint, userID *uint) (NotificationDeliveryResp, error) {
+	delivery, err := repository.GetNotificationDeliveryByIDDAO(id)
+	if err != nil {
+		return NotificationDeliveryResp{}, err
+	}
+	if delivery.Status != NotificationStatusFailed && delivery.Status != NotificationStatusSkipped {
+		return NotificationDeliveryResp{}, fmt.Errorf("%w: only failed or skipped deliveries can be retried, this one is %s", model.ErrInvalidInput, notificationStatusLabel(delivery.Status))
+	}
+	requeued, err := repository.RetryNotificationDeliveryDAO(id, time.Now(), notificationMaxAttempts())
+	if err != nil {
+		return NotificationDeliveryResp{}, fmt.Errorf("failed to retry notification delivery: %w", err)
+	}
+	if !requeued {
+		return NotificationDeliveryResp{}, fmt.Errorf("%w: delivery status changed, reload and try again", model.ErrInvalidInput)
+	}
+	LogService("info", "notification delivery retry requested", map[string]interface{}{
+		"delivery_id": id,
+		"media_id":    delivery.MediaID,
+		"target":      delivery.Target,
+	}, userID, "")
+	wakeNotificationDispatcher()
+	return GetNotificationDeliveryByIDServ(id)
+}
+
+// StartNotificationWorkers drains the notification outbox with a pool of senders; queued messages survive restarts
+func StartNotificationWorkers() {
+	notificationMu.Lock()
+	if notificationCancel != nil {
+		notificationMu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	notificationCancel = cancel
+	notificationMu.Unlock()
+
+	// Deliveries claimed by a process that died mid-send are sent again
+	if released, err := repository.ReleaseSendingNotificationDeliveriesDAO(time.Now()); err != nil {
+		LogSystem("warn", "failed to release in-flight notification deliveries", map[string]interface{}{"error": err.Error()}, nil, "")
+	} else if released > 0 {
+		LogSystem("info", "released in-flight notification deliveries", map[string]interface{}{"count": released}, nil, "")
+	}
+
+	workers := configuredLimit("notification.workers", 4)
+	jobs := make(chan model.NotificationDelivery, workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for delivery := range jobs {
+				processNotificationDelivery(delivery)
+			}
+		}()
+	}
+
+	LogSystem("info", "notification workers started", map[string]interface{}{"workers": workers}, nil, "")
+	go func() {
+		ticker := time.NewTicker(notificationPollInterval)
+		defer ticker.Stop()
+		defer close(jobs)
+
+		for {
+			flushDueNotificationDigests()
+			dispatchDueNotifications(ctx, jobs, workers)
+			select {
+			case <-ctx.Done():
+				LogSystem("info", "notification workers stopped", nil, nil, "")
+				return
+			case <-ticker.C:
+			case <-notificationWake:
+			}
+		}
+	}()
+}
+
+// StopNotificationWorkers stops the notification workers; pending deliveries stay queued
+func StopNotificationWorkers() {
+	notificationMu.Lock()
+	defer notificationMu.Unlock()
+	if notificationCancel != nil {
+		notificationCancel()
+		notificationCancel = nil
+	}
+}
+
+// enqueueNotification adds a message to the outbox; if the outbox cannot be written the message is sent right away.
+// Messages past the source's storm threshold go straight into the source action's digest for the target.
+func enqueueNotification(source notificationSource, media model.Media, recipientID *uint, msg string) error {
+	now := time.Now()
+	storm := notificationStormReached(source.Digest, media, now)
+	delivery := model.NotificationDelivery{
+		Kind:          source.Kind,
+		AlertID:       source.AlertID,
+		IncidentID:    source.IncidentID,
+		ActionID:      source.ActionID,
+		MediaID:       media.ID,
+		MediaType:     media.Type,
+		Target:        media.Target,
+		UserID:        recipientID,
+		Message:       msg,
+		Status:        NotificationStatusPending,
+		MaxAttempts:   notificationMaxAttempts(),
+		NextAttemptAt: &now,
+	}
+	if storm {
+		delivery.Status = NotificationStatusDigested
+		delivery.NextAttemptAt = nil
+	}
+	if err := repository.AddNotificationDeliveryDAO(&delivery); err != nil {
+		LogService("error", "failed to queue notification, sending directly", map[string]interface{}{
+			"media_id": media.ID,
+			"target":   media.Target,
+			"error":    err.Error(),
+		}, nil, "")
+		return sendMediaMessage(media, msg)
+	}
+	if storm {
+		if err := holdNotificationForDigest(delivery, source.Digest, "storm threshold reached"); err == nil {
+			return nil
+		}
+		// Could not fold it; send it on its own rather than lose it
+		if err := repository.UpdateNotificationDeliveryFieldsDAO(delivery.ID, map[string]interface{}{
+			"status":          NotificationStatusPending,
+			"next_attempt_at": now,
+		}); err != nil {
+			return err
+		}
+	}
+	wakeNotificationDispatcher()
+	return nil
+}
+
+func wakeNotificationDispatcher() {
+	select {
+	case notificationWake <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchDueNotifications hands due deliveries to the workers until none are left or the service stops
+func dispatchDueNotifications(ctx context.Context, jobs chan<- model.NotificationDelivery, batch int) {
+	for {
+		due, err := repository.GetDueNotificationDeliveriesDAO(time.Now(), batch*4)
+		if err != nil {
+			LogSystem("error", "failed to load due notification deliveries", map[string]interface{}{"error": err.Error()}, nil, "")
+			return
+		}
+		claimed := 0
+		for _, delivery := range due {
+			now := time.Now()
+			ok, err := repository.ClaimNotificationDeliveryDAO(delivery.ID, now)
+			if err != nil || !ok {
+				continue
+			}
+			delivery.Status = NotificationStatusSending
+			delivery.Attempts++
+			delivery.LastAttemptAt = &now
+			select {
+			case jobs <- delivery:
+				claimed++
+			case <-ctx.Done():
+				// Not sent; the next start releases it back to pending
+				return
+			}
+		}
+		if claimed == 0 || len(due) < batch*4 {
+			return
+		}
+	}
+}
+
+// processNotificationDelivery makes one send attempt and records the outcome
+func processNotificationDelivery(delivery model.NotificationDelivery) {
+	now := time.Now()
+	fields := map[string]interface{}{}
+
+	resp, err := sendNotificationDelivery(delivery)
+	if resp != nil {
+		fields["response_code"] = resp.StatusCode
+		fields["response_body"] = templateTruncate(webhookResponseBodyLimit, resp.Body)
+	}
+	var rateLimited *mediaRateLimitError
+	if errors.As(err, &rateLimited) {
+		if delivery.Kind == NotificationKindDigest {
+			// Digests are already condensed; wait for the limiter instead of spending an attempt
+			fields["status"] = NotificationStatusPending
+			fields["attempts"] = delivery.Attempts - 1
+			fields["next_attempt_at"] = now.Add(rateLimited.wait)
+			fields["last_error"] = err.Error()
+			if err := repository.UpdateNotificationDeliveryFieldsDAO(delivery.ID, fields); err != nil {
+				LogService("error", "failed to record notification delivery outcome", map[string]interface{}{
+					"delivery_id": delivery.ID,
+					"error":       err.Error(),
+				}, nil, "")
+			}
+			return
+		}
+		if settings := notificationDigestSettings(delivery); settings.Enabled {
+			if err := holdNotificationForDigest(delivery, settings, "held back by rate limit"); err == nil {
+				return
+			}
+		}
+	}
+	switch {
+	case err == nil:
+		fields["status"] = NotificationStatusSent
+		fields["sent_at"] = now
+		fields["next_attempt_at"] = nil
+		fields["last_error"] = ""
+	case errors.Is(err, ErrMediaSendSkipped):
+		fields["status"] = NotificationStatusSkipped
+		fields["next_attempt_at"] = nil
+		fields["last_error"] = err.Error()
+	case delivery.Attempts >= delivery.MaxAttempts:
+		fields["status"] = NotificationStatusFailed
+		fields["next_attempt_at"] = nil
+		fields["last_error"] = err.Error()
+		LogService("error", "notification delivery failed permanently", map[string]interface{}{
+			"delivery_id": delivery.ID,
+			"media_id":    delivery.MediaID,
+			"target":      delivery.Target,
+			"attempts":    delivery.Attempts,
+			"error":       err.Error(),
+		}, nil, "")
+	default:
+		next := now.Add(notificationRetryDelay(delivery.Attempts))
+		fields["status"] = NotificationStatusPending
+		fields["next_attempt_at"] = next
+		fields["last_error"] = err.Error()
+		LogService("warn", "notification delivery will be retried", map[string]interface{}{
+			"delivery_id":     delivery.ID,
+			"media_id":        delivery.MediaID,
+			"target":          delivery.Target,
+			"attempts":        delivery.Attempts,
+			"next_attempt_at": next,
+			"error":           err.Error(),
+		}, nil, "")
+	}
+
+	if err := repository.UpdateNotificationDeliveryFieldsDAO(delivery.ID, fields); err != nil {
+		LogService("error", "failed to record notification delivery outcome", map[string]interface{}{
+			"delivery_id": delivery.ID,
+			"error":       err.Error(),
+		}, nil, "")
+	}
+}
+
+// sendNotificationDelivery sends a queued message with its alert context through the delivery's media as it is
+// configured now, to the recorded target; webhook media also return the endpoint's response
+func sendNotificationDelivery(delivery model.NotificationDelivery) (*mediaSvc.WebhookResponse, error) {
+	media, err := repository.GetMediaByIDDAO(delivery.MediaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load media %d: %w", delivery.MediaID, err)
+	}
+	if media.Enabled == 0 {
+		return nil, fmt.Errorf("%w: media %s is disabled", ErrMediaSendSkipped, media.Name)
+	}
+	media.Target = delivery.Target
+
+	ctx, cancel := context.WithTimeout(context.Background(), notificationSendTimeout())
+	defer cancel()
+	return sendMediaPayload(ctx, media, webhookDeliveryData(delivery))
+}
+
+// notificationRetryDelay doubles the base delay after each failed attempt, up to the configured maximum
+func notificationRetryDelay(attempts int) time.Duration {
+	base := time.Duration(configuredLimit("notification.retry_base_seconds", 30)) * time.Second
+	maxDelay := time.Duration(configuredLimit("notification.retry_max_seconds", 1800)) * time.Second
+	delay := base
+	for i := 1; i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
+func notificationMaxAttempts() int {
+	return configuredLimit("notification.max_attempts", 5)
+}
+
+func notificationSendTimeout() time.Duration {
+	return time.Duration(configuredLimit("notification.send_timeout_seconds", 30)) * time.Second
+}
+
+func notificationStatusLabel(status int) string {
+	switch status {
+	case NotificationStatusPending:
+		return "pending"
+	case NotificationStatusSending:
+		return "sending"
+	case NotificationStatusSent:
+		return "sent"
+	case NotificationStatusFailed:
+		return "failed"
+	case NotificationStatusSkipped:
+		return "skipped"
+	case NotificationStatusDigested:
+		return "digested"
+	default:
+		return "unknown"
+	}
+}
+
+func notificationDeliveryToResp(delivery model.NotificationDelivery) NotificationDeliveryResp {
+	return NotificationDeliveryResp{
+		ID:            delivery.ID,
+		Kind:          delivery.Kind,
+		AlertID:       delivery.AlertID,
+		IncidentID:    delivery.IncidentID,
+		ActionID:      delivery.ActionID,
+		MediaID:       delivery.MediaID,
+		MediaType:     delivery.MediaType,
+		Target:        delivery.Target,
+		UserID:        delivery.UserID,
+		Message:       delivery.Message,
+		Status:        delivery.Status,
+		StatusLabel:   notificationStatusLabel(delivery.Status),
+		Attempts:      delivery.Attempts,
+		MaxAttempts:   delivery.MaxAttempts,
+		NextAttemptAt: delivery.NextAttemptAt,
+		LastAttemptAt: delivery.LastAttemptAt,
+		SentAt:        delivery.SentAt,
+		LastError:     delivery.LastError,
+		DigestID:      delivery.DigestID,
+		ResponseCode:  delivery.ResponseCode,
+		ResponseBody:  delivery.ResponseBody,
+		CreatedAt:     delivery.CreatedAt,
+	}
+}
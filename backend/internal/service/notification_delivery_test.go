@@ -0,0 +1,37 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestNotificationRetryDelay(t *testing.T) {
+	cases := []struct {
+		name     string
+		base     int
+		max      int
+		attempts int
+		want     time.Duration
+	}{
+		{name: "defaults first attempt", attempts: 1, want: 30 * time.Second},
+		{name: "defaults doubles", attempts: 3, want: 120 * time.Second},
+		{name: "defaults capped", attempts: 10, want: 1800 * time.Second},
+		{name: "zero attempts uses base", attempts: 0, want: 30 * time.Second},
+		{name: "configured base", base: 5, max: 60, attempts: 2, want: 10 * time.Second},
+		{name: "configured cap", base: 5, max: 60, attempts: 5, want: 60 * time.Second},
+		{name: "base above cap", base: 120, max: 60, attempts: 1, want: 60 * time.Second},
+		{name: "many attempts do not overflow", base: 5, max: 60, attempts: 1000, want: 60 * time.Second},
+	}
+
+	defer viper.Set("notification.retry_base_seconds", nil)
+	defer viper.Set("notification.retry_max_seconds", nil)
+	for _, tc := range cases {
+		viper.Set("notification.retry_base_seconds", tc.base)
+		viper.Set("notification.retry_max_seconds", tc.max)
+		if got := notificationRetryDelay(tc.attempts); got != tc.want {
+			t.Fatalf("%s: notificationRetryDelay(%d) = %s, want %s", tc.name, tc.attempts, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"nagare/internal/model"
+	"nagare/internal/repository"
+)
+
+const (
+	defaultDigestWindow      = 5 * time.Minute
+	defaultDigestMaxMessages = 10
+	digestTopHosts           = 5
+	digestLineLength         = 160
+)
+
+// digestMu serializes folding messages into digests so concurrent workers never open two for one target
+var digestMu sync.Mutex
+
+// digestSettings controls how held-back messages of one action are condensed
+type digestSettings struct {
+	Enabled     bool
+	Window      time.Duration
+	Threshold   int // Messages to one target within Window before the rest go to the digest; 0 = rate limit only
+	MaxMessages int
+}
+
+// NotificationDigestResp represents a notification digest response
+type NotificationDigestResp struct {
+	ID             uint           `json:"id"`
+	MediaID        uint           `json:"media_id"`
+	Target         string         `json:"target"`
+	ActionID       *uint          `json:"action_id"`
+	UserID         *uint          `json:"user_id"`
+	Status         int            `json:"status"`
+	WindowStart    time.Time      `json:"window_start"`
+	FlushAt        time.Time      `json:"flush_at"`
+	MaxMessages    int            `json:"max_messages"`
+	Count          int            `json:"count"`
+	SeverityCounts map[string]int `json:"severity_counts"`
+	HostCounts     map[string]int `json:"host_counts"`
+	Messages       []string       `json:"messages"`
+	DeliveryID     *uint          `json:"delivery_id"`
+	FlushedAt      *time.Time     `json:"flushed_at"`
+}
+
+// SearchNotificationDigestsServ retrieves notification digests by filter
+func SearchNotificationDigestsServ(filter model.NotificationDigestFilter) ([]NotificationDigestResp, error) {
+	digests, err := repository.SearchNotificationDigestsDAO(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search notification digests: %w", err)
+	}
+	result := make([]NotificationDigestResp, 0, len(digests))
+	for _, digest := range digests {
+		result = append(result, notificationDigestToResp(digest))
+	}
+	return result, nil
+}
+
+// CountNotificationDigestsServ returns total count for notification digests by filter
+func CountNotificationDigestsServ(filter model.NotificationDigestFilter) (int64, error) {
+	return repository.CountNotificationDigestsDAO(filter)
+}
+
+// GetNotificationDigestByIDServ retrieves a notification digest by ID
+func GetNotificationDigestByIDServ(id uint) (NotificationDigestResp, error) {
+	digest, err := repository.GetNotificationDigestByIDDAO(id)
+	if err != nil {
+		return NotificationDigestResp{}, err
+	}
+	return notificationDigestToResp(digest), nil
+}
+
+// actionDigestSettings reads the digest configuration of an action, filling in defaults
+func actionDigestSettings(action model.Action) digestSettings {
+	settings := digestSettings{
+		Enabled:     action.DigestEnabled != 0,
+		Window:      time.Duration(action.DigestWindowSeconds) * time.Second,
+		Threshold:   action.DigestThreshold,
+		MaxMessages: action.DigestMaxMessages,
+	}
+	if settings.Window <= 0 {
+		settings.Window = defaultDigestWindow
+	}
+	if settings.MaxMessages <= 0 {
+		settings.MaxMessages = defaultDigestMaxMessages
+	}
+	return settings
+}
+
+// defaultDigestSettings applies to messages that do not come from an action, such as escalation steps
+func defaultDigestSettings() digestSettings {
+	return digestSettings{Enabled: true, Window: defaultDigestWindow, MaxMessages: defaultDigestMaxMessages}
+}
+
+// notificationDigestSettings resolves the digest settings for a queued delivery from its action
+func notificationDigestSettings(delivery model.NotificationDelivery) digestSettings {
+	if delivery.ActionID == nil {
+		return defaultDigestSettings()
+	}
+	action, err := repository.GetActionByIDDAO(*delivery.ActionID)
+	if err != nil {
+		return defaultDigestSettings()
+	}
+	return actionDigestSettings(action)
+}
+
+// notificationStormReached reports whether the target already received the threshold of messages in the window
+func notificationStormReached(settings digestSettings, media model.Media, now time.Time) bool {
+	if !settings.Enabled || settings.Threshold <= 0 {
+		return false
+	}
+	recent, err := repository.CountRecentNotificationDeliveriesDAO(media.ID, media.Target, now.Add(-settings.Window))
+	if err != nil {
+		return false
+	}
+	return recent >= int64(settings.Threshold)
+}
+
+// holdNotificationForDigest folds a delivery into the open digest of its action and media target, opening one
+// if needed. Digests are kept per action so each one flushes on the window and message cap of its own action.
+func holdNotificationForDigest(delivery model.NotificationDelivery, settings digestSettings, reason string) error {
+	digestMu.Lock()
+	defer digestMu.Unlock()
+
+	now := time.Now()
+	digest, err := repository.GetOpenNotificationDigestDAO(delivery.MediaID, delivery.Target, delivery.ActionID)
+	if errors.Is(err, model.ErrNotFound) {
+		digest = model.NotificationDigest{
+			MediaID:        delivery.MediaID,
+			Target:         delivery.Target,
+			ActionID:       delivery.ActionID,
+			UserID:         delivery.UserID,
+			WindowStart:    now,
+			FlushAt:        now.Add(settings.Window),
+			MaxMessages:    settings.MaxMessages,
+			SeverityCounts: map[string]int{},
+			HostCounts:     map[string]int{},
+			Messages:       []string{},
+		}
+		err = repository.AddNotificationDigestDAO(&digest)
+	}
+	if err != nil {
+		LogService("error", "failed to open notification digest", map[string]interface{}{
+			"delivery_id": delivery.ID,
+			"media_id":    delivery.MediaID,
+			"error":       err.Error(),
+		}, nil, "")
+		return err
+	}
+	if digest.SeverityCounts == nil {
+		digest.SeverityCounts = map[string]int{}
+	}
+	if digest.HostCounts == nil {
+		digest.HostCounts = map[string]int{}
+	}
+
+	severity, host := notificationDigestSubject(delivery)
+	digest.Count++
+	if severity >= 0 {
+		digest.SeverityCounts[strconv.Itoa(severity)]++
+	}
+	if host != "" {
+		digest.HostCounts[host]++
+	}
+	if len(digest.Messages) < digest.MaxMessages {
+		digest.Messages = append(digest.Messages, digestLine(delivery.Message))
+	}
+	if err := repository.UpdateNotificationDigestContentDAO(digest); err != nil {
+		return err
+	}
+	if err := repository.HoldNotificationDeliveryDAO(delivery.ID, digest.ID, fmt.Sprintf("%s, folded into digest %d", reason, digest.ID)); err != nil {
+		return err
+	}
+	LogService("info", "notification folded into digest", map[string]interface{}{
+		"delivery_id": delivery.ID,
+		"digest_id":   digest.ID,
+		"media_id":    delivery.MediaID,
+		"target":      delivery.Target,
+		"reason":      reason,
+	}, nil, "")
+	return nil
+}
+
+// flushDueNotificationDigests queues one summary message for every digest whose window has ended
+func flushDueNotificationDigests() {
+	digestMu.Lock()
+	defer digestMu.Unlock()
+
+	now := time.Now()
+	digests, err := repository.GetDueNotificationDigestsDAO(now)
+	if err != nil {
+		LogSystem("error", "failed to load due notification digests", map[string]interface{}{"error": err.Error()}, nil, "")
+		return
+	}
+	for _, digest := range digests {
+		var deliveryID *uint
+		media, err := repository.GetMediaByIDDAO(digest.MediaID)
+		if err == nil {
+			delivery := model.NotificationDelivery{
+				Kind:          NotificationKindDigest,
+				MediaID:       media.ID,
+				MediaType:     media.Type,
+				Target:        digest.Target,
+				UserID:        digest.UserID,
+				Message:       renderNotificationDigest(digest, now),
+				Status:        NotificationStatusPending,
+				MaxAttempts:   notificationMaxAttempts(),
+				NextAttemptAt: &now,
+			}
+			if err := repository.AddNotificationDeliveryDAO(&delivery); err != nil {
+				LogSystem("error", "failed to queue notification digest", map[string]interface{}{"digest_id": digest.ID, "error": err.Error()}, nil, "")
+				continue
+			}
+			deliveryID = &delivery.ID
+		} else {
+			LogSystem("warn", "notification digest dropped: media not found", map[string]interface{}{"digest_id": digest.ID, "media_id": digest.MediaID}, nil, "")
+		}
+		if err := repository.MarkNotificationDigestFlushedDAO(digest.ID, deliveryID, now); err != nil {
+			LogSystem("error", "failed to close notification digest", map[string]interface{}{"digest_id": digest.ID, "error": err.Error()}, nil, "")
+		}
+	}
+}
+
+// notificationDigestSubject returns the severity (-1 if unknown) and host name a held message is about
+func notificationDigestSubject(delivery model.NotificationDelivery) (int, string) {
+	if delivery.AlertID != nil {
+		if alert, err := repository.GetAlertByIDDAO(int(*delivery.AlertID)); err == nil {
+			host := ""
+			if ctx := buildAlertMatchContext(alert); ctx.host != nil {
+				host = ctx.host.Name
+			}
+			return alert.Severity, host
+		}
+	}
+	if delivery.IncidentID != nil {
+		if incident, err := repository.GetIncidentByIDDAO(*delivery.IncidentID); err == nil {
+			host := ""
+			if incident.HostID != nil {
+				if h, err := repository.GetHostByIDDAO(*incident.HostID); err == nil {
+					host = h.Name
+				}
+			}
+			return incident.Severity, host
+		}
+	}
+	return -1, ""
+}
+
+// renderNotificationDigest summarizes a digest as counts by severity, the busiest hosts and the first messages
+func renderNotificationDigest(digest model.NotificationDigest, now time.Time) string {
+	lines := []string{
+		fmt.Sprintf("[Nagare] Notification digest: %d messages held back between %s and %s",
+			digest.Count, digest.WindowStart.Format("2006-01-02 15:04:05"), now.Format("15:04:05")),
+	}
+
+	severities := make([]int, 0, len(digest.SeverityCounts))
+	for key := range digest.SeverityCounts {
+		if severity, err := strconv.Atoi(key); err == nil {
+			severities = append(severities, severity)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(severities)))
+	if len(severities) > 0 {
+		parts := make([]string, 0, len(severities))
+		for _, severity := range severities {
+			parts = append(parts, fmt.Sprintf("%s %d", severityLabel(severity), digest.SeverityCounts[strconv.Itoa(severity)]))
+		}
+		lines = append(lines, "By severity: "+strings.Join(parts, ", "))
+	}
+
+	if len(digest.HostCounts) > 0 {
+		hosts := make([]string, 0, len(digest.HostCounts))
+		for host := range digest.HostCounts {
+			hosts = append(hosts, host)
+		}
+		sort.Slice(hosts, func(i, j int) bool {
+			if digest.HostCounts[hosts[i]] != digest.HostCounts[hosts[j]] {
+				return digest.HostCounts[hosts[i]] > digest.HostCounts[hosts[j]]
+			}
+			return hosts[i] < hosts[j]
+		})
+		if len(hosts) > digestTopHosts {
+			hosts = hosts[:digestTopHosts]
+		}
+		parts := make([]string, 0, len(hosts))
+		for _, host := range hosts {
+			parts = append(parts, fmt.Sprintf("%s (%d)", host, digest.HostCounts[host]))
+		}
+		lines = append(lines, "Top hosts: "+strings.Join(parts, ", "))
+	}
+
+	if len(digest.Messages) > 0 {
+		lines = append(lines, "", fmt.Sprintf("First %d messages:", len(digest.Messages)))
+		for _, msg := range digest.Messages {
+			lines = append(lines, "- "+msg)
+		}
+		if rest := digest.Count - len(digest.Messages); rest > 0 {
+			lines = append(lines, fmt.Sprintf("...and %d more", rest))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// digestLine keeps the first non-empty line of a message, which is its subject for templated messages
+func digestLine(message string) string {
+	for _, line := range strings.Split(message, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return templateTruncate(digestLineLength, line)
+		}
+	}
+	return ""
+}
+
+func notificationDigestToResp(digest model.NotificationDigest) NotificationDigestResp {
+	return NotificationDigestResp{
+		ID:             digest.ID,
+		MediaID:        digest.MediaID,
+		Target:         digest.Target,
+		ActionID:       digest.ActionID,
+		UserID:         digest.UserID,
+		Status:         digest.Status,
+		WindowStart:    digest.WindowStart,
+		FlushAt:        digest.FlushAt,
+		MaxMessages:    digest.MaxMessages,
+		Count:          digest.Count,
+		SeverityCounts: digest.SeverityCounts,
+		HostCounts:     digest.HostCounts,
+		Messages:       digest.Messages,
+		DeliveryID:     digest.DeliveryID,
+		FlushedAt:      digest.FlushedAt,
+	}
+}
@@ -0,0 +1,483 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"nagare/internal/model"
+	"nagare/internal/repository"
+)
+
+// On-call rotation types
+const (
+	OnCallRotationDaily  = "daily"
+	OnCallRotationWeekly = "weekly"
+)
+
+const (
+	onCallMaxLayers      = 10
+	onCallMaxOverrideDur = 90 * 24 * time.Hour
+)
+
+// OnCallScheduleReq represents an on-call schedule request
+type OnCallScheduleReq struct {
+	Name        string              `json:"name" binding:"required"`
+	Description string              `json:"description"`
+	TimeZone    string              `json:"time_zone"`
+	Enabled     int                 `json:"enabled"`
+	Layers      []model.OnCallLayer `json:"layers" binding:"required"`
+}
+
+// OnCallScheduleResp represents an on-call schedule response
+type OnCallScheduleResp struct {
+	ID          uint                `json:"id"`
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	TimeZone    string              `json:"time_zone"`
+	Enabled     int                 `json:"enabled"`
+	Layers      []model.OnCallLayer `json:"layers"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+// OnCallOverrideReq represents a temporary on-call override request
+type OnCallOverrideReq struct {
+	UserID  uint      `json:"user_id" binding:"required"`
+	StartAt time.Time `json:"start_at" binding:"required"`
+	EndAt   time.Time `json:"end_at" binding:"required"`
+	Reason  string    `json:"reason"`
+}
+
+// OnCallOverrideResp represents a temporary on-call override response
+type OnCallOverrideResp struct {
+	ID          uint      `json:"id"`
+	ScheduleID  uint      `json:"schedule_id"`
+	UserID      uint      `json:"user_id"`
+	Username    string    `json:"username"`
+	StartAt     time.Time `json:"start_at"`
+	EndAt       time.Time `json:"end_at"`
+	Reason      string    `json:"reason"`
+	CreatedByID *uint     `json:"created_by_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// OnCallResp answers who is on call for a schedule at a point in time
+type OnCallResp struct {
+	ScheduleID   uint       `json:"schedule_id"`
+	ScheduleName string     `json:"schedule_name"`
+	TimeZone     string     `json:"time_zone"`
+	At           time.Time  `json:"at"`
+	OnCall       bool       `json:"on_call"`
+	UserID       uint       `json:"user_id,omitempty"`
+	Username     string     `json:"username,omitempty"`
+	Source       string     `json:"source,omitempty"` // "override" or "layer"
+	Layer        string     `json:"layer,omitempty"`
+	OverrideID   uint       `json:"override_id,omitempty"`
+	ShiftStart   *time.Time `json:"shift_start,omitempty"`
+	ShiftEnd     *time.Time `json:"shift_end,omitempty"`
+}
+
+// SearchOnCallSchedulesServ retrieves on-call schedules by filter
+func SearchOnCallSchedulesServ(filter model.OnCallScheduleFilter) ([]OnCallScheduleResp, error) {
+	schedules, err := repository.SearchOnCallSchedulesDAO(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search on-call schedules: %w", err)
+	}
+	result := make([]OnCallScheduleResp, 0, len(schedules))
+	for _, schedule := range schedules {
+		result = append(result, onCallScheduleToResp(schedule))
+	}
+	return result, nil
+}
+
+// CountOnCallSchedulesServ returns total count for on-call schedules by filter
+func CountOnCallSchedulesServ(filter model.OnCallScheduleFilter) (int64, error) {
+	return repository.CountOnCallSchedulesDAO(filter)
+}
+
+// GetOnCallScheduleByIDServ retrieves an on-call schedule by ID
+func GetOnCallScheduleByIDServ(id uint) (OnCallScheduleResp, error) {
+	schedule, err := repository.GetOnCallScheduleByIDDAO(id)
+	if err != nil {
+		return OnCallScheduleResp{}, err
+	}
+	return onCallScheduleToResp(schedule), nil
+}
+
+// AddOnCallScheduleServ creates a new on-call schedule
+func AddOnCallScheduleServ(req OnCallScheduleReq) (OnCallScheduleResp, error) {
+	schedule, err := buildOnCallSchedule(req)
+	if err != nil {
+		return OnCallScheduleResp{}, err
+	}
+	if err := repository.AddOnCallScheduleDAO(&schedule); err != nil {
+		return OnCallScheduleResp{}, fmt.Errorf("failed to add on-call schedule: %w", err)
+	}
+	return onCallScheduleToResp(schedule), nil
+}
+
+// UpdateOnCallScheduleServ updates an on-call schedule
+func UpdateOnCallScheduleServ(id uint, req OnCallScheduleReq) error {
+	if _, err := repository.GetOnCallScheduleByIDDAO(id); err != nil {
+		return err
+	}
+	schedule, err := buildOnCallSchedule(req)
+	if err != nil {
+		return err
+	}
+	return repository.UpdateOnCallScheduleDAO(id, schedule)
+}
+
+// DeleteOnCallScheduleServ deletes an on-call schedule and detaches it from actions
+func DeleteOnCallScheduleServ(id uint) error {
+	if _, err := repository.GetOnCallScheduleByIDDAO(id); err != nil {
+		return err
+	}
+	return repository.DeleteOnCallScheduleByIDDAO(id)
+}
+
+// GetOnCallOverridesServ lists overrides of a schedule that have not ended yet
+func GetOnCallOverridesServ(scheduleID uint, includePast bool) ([]OnCallOverrideResp, error) {
+	if _, err := repository.GetOnCallScheduleByIDDAO(scheduleID); err != nil {
+		return nil, err
+	}
+	endAfter := time.Now()
+	if includePast {
+		endAfter = time.Time{}
+	}
+	overrides, err := repository.GetOnCallOverridesDAO(scheduleID, endAfter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load on-call overrides: %w", err)
+	}
+	names := incidentNameCache{}
+	result := make([]OnCallOverrideResp, 0, len(overrides))
+	for _, override := range overrides {
+		result = append(result, OnCallOverrideResp{
+			ID:          override.ID,
+			ScheduleID:  override.ScheduleID,
+			UserID:      override.UserID,
+			Username:    names.username(override.UserID),
+			StartAt:     override.StartAt,
+			EndAt:       override.EndAt,
+			Reason:      override.Reason,
+			CreatedByID: override.CreatedByID,
+			CreatedAt:   override.CreatedAt,
+		})
+	}
+	return result, nil
+}
+
+// AddOnCallOverrideServ puts a user on call for a schedule for a limited time
+func AddOnCallOverrideServ(scheduleID uint, req OnCallOverrideReq, userID *uint) (OnCallOverrideResp, error) {
+	if _, err := repository.GetOnCallScheduleByIDDAO(scheduleID); err != nil {
+		return OnCallOverrideResp{}, err
+	}
+	if !req.EndAt.After(req.StartAt) {
+		return OnCallOverrideResp{}, fmt.Errorf("%w: end_at must be after start_at", model.ErrInvalidInput)
+	}
+	if req.EndAt.Sub(req.StartAt) > onCallMaxOverrideDur {
+		return OnCallOverrideResp{}, fmt.Errorf("%w: an override may last at most %d days", model.ErrInvalidInput, int(onCallMaxOverrideDur.Hours()/24))
+	}
+	user, err := repository.GetUserByIDDAO(int(req.UserID))
+	if err != nil {
+		return OnCallOverrideResp{}, fmt.Errorf("%w: user %d not found", model.ErrInvalidInput, req.UserID)
+	}
+
+	override := model.OnCallOverride{
+		ScheduleID:  scheduleID,
+		UserID:      user.ID,
+		StartAt:     req.StartAt,
+		EndAt:       req.EndAt,
+		Reason:      strings.TrimSpace(req.Reason),
+		CreatedByID: userID,
+	}
+	if err := repository.AddOnCallOverrideDAO(&override); err != nil {
+		return OnCallOverrideResp{}, fmt.Errorf("failed to add on-call override: %w", err)
+	}
+	LogService("info", "on-call override added", map[string]interface{}{
+		"schedule_id": scheduleID,
+		"user_id":     user.ID,
+		"start_at":    override.StartAt,
+		"end_at":      override.EndAt,
+	}, userID, "")
+	return OnCallOverrideResp{
+		ID:          override.ID,
+		ScheduleID:  override.ScheduleID,
+		UserID:      override.UserID,
+		Username:    user.Username,
+		StartAt:     override.StartAt,
+		EndAt:       override.EndAt,
+		Reason:      override.Reason,
+		CreatedByID: override.CreatedByID,
+		CreatedAt:   override.CreatedAt,
+	}, nil
+}
+
+// DeleteOnCallOverrideServ removes an override from a schedule
+func DeleteOnCallOverrideServ(scheduleID, overrideID uint) error {
+	override, err := repository.GetOnCallOverrideByIDDAO(overrideID)
+	if err != nil {
+		return err
+	}
+	if override.ScheduleID != scheduleID {
+		return model.ErrNotFound
+	}
+	return repository.DeleteOnCallOverrideByIDDAO(overrideID)
+}
+
+// GetOnCallServ answers who is on call for a schedule at the given time
+func GetOnCallServ(scheduleID uint, at time.Time) (OnCallResp, error) {
+	schedule, err := repository.GetOnCallScheduleByIDDAO(scheduleID)
+	if err != nil {
+		return OnCallResp{}, err
+	}
+	return resolveOnCall(schedule, at), nil
+}
+
+// GetAllOnCallServ answers who is on call for every enabled schedule at the given time
+func GetAllOnCallServ(at time.Time) ([]OnCallResp, error) {
+	enabled := 1
+	schedules, err := repository.SearchOnCallSchedulesDAO(model.OnCallScheduleFilter{Enabled: &enabled, SortBy: "name", SortOrder: "asc"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load on-call schedules: %w", err)
+	}
+	result := make([]OnCallResp, 0, len(schedules))
+	for _, schedule := range schedules {
+		result = append(result, resolveOnCall(schedule, at))
+	}
+	return result, nil
+}
+
+// resolveOnCall picks the on-call user: an active override wins, then the last layer with someone on shift
+func resolveOnCall(schedule model.OnCallSchedule, at time.Time) OnCallResp {
+	var active *model.OnCallOverride
+	if override, err := repository.GetActiveOnCallOverrideDAO(schedule.ID, at); err == nil {
+		active = &override
+	}
+	resp := pickOnCall(schedule, active, at)
+	if resp.OnCall {
+		if user, err := repository.GetUserByIDDAO(int(resp.UserID)); err == nil {
+			resp.Username = user.Username
+		}
+	}
+	return resp
+}
+
+// pickOnCall applies the precedence of resolveOnCall to an already looked-up active override, which may be nil
+func pickOnCall(schedule model.OnCallSchedule, override *model.OnCallOverride, at time.Time) OnCallResp {
+	loc := onCallLocation(schedule.TimeZone)
+	resp := OnCallResp{
+		ScheduleID:   schedule.ID,
+		ScheduleName: schedule.Name,
+		TimeZone:     loc.String(),
+		At:           at.In(loc),
+	}
+
+	if override != nil {
+		resp.OnCall = true
+		resp.UserID = override.UserID
+		resp.Source = "override"
+		resp.OverrideID = override.ID
+		start, end := override.StartAt.In(loc), override.EndAt.In(loc)
+		resp.ShiftStart = &start
+		resp.ShiftEnd = &end
+	} else {
+		for i := len(schedule.Layers) - 1; i >= 0; i-- {
+			layer := schedule.Layers[i]
+			userID, start, end, ok := onCallLayerShift(layer, loc, at)
+			if !ok {
+				continue
+			}
+			resp.OnCall = true
+			resp.UserID = userID
+			resp.Source = "layer"
+			resp.Layer = layer.Name
+			resp.ShiftStart = &start
+			resp.ShiftEnd = &end
+			break
+		}
+	}
+	return resp
+}
+
+// onCallLayerShift finds the shift of a layer covering the given time.
+// Shifts are counted in calendar days from the start date so DST changes never shift the handoff hour.
+func onCallLayerShift(layer model.OnCallLayer, loc *time.Location, at time.Time) (uint, time.Time, time.Time, bool) {
+	if len(layer.UserIDs) == 0 {
+		return 0, time.Time{}, time.Time{}, false
+	}
+	anchor, err := time.ParseInLocation("2006-01-02", layer.StartDate, loc)
+	if err != nil {
+		return 0, time.Time{}, time.Time{}, false
+	}
+	hour, minute, _ := parseHandoffTime(layer.HandoffTime)
+	first := time.Date(anchor.Year(), anchor.Month(), anchor.Day(), hour, minute, 0, 0, loc)
+	local := at.In(loc)
+	if local.Before(first) {
+		return 0, time.Time{}, time.Time{}, false
+	}
+
+	// Most recent daily handoff at or before the given time
+	handoff := time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, loc)
+	if local.Before(handoff) {
+		handoff = handoff.AddDate(0, 0, -1)
+	}
+	days := int(civilDate(handoff).Sub(civilDate(first)).Hours() / 24)
+
+	period := 1
+	if layer.Rotation == OnCallRotationWeekly {
+		period = 7
+	}
+	shift := days / period
+	start := first.AddDate(0, 0, shift*period)
+	end := start.AddDate(0, 0, period)
+	return layer.UserIDs[shift%len(layer.UserIDs)], start, end, true
+}
+
+// onCallRecipients returns the users currently on call for a schedule; disabled or missing schedules yield nobody
+func onCallRecipients(scheduleID *uint) []model.User {
+	if scheduleID == nil || *scheduleID == 0 {
+		return nil
+	}
+	schedule, err := repository.GetOnCallScheduleByIDDAO(*scheduleID)
+	if err != nil {
+		LogService("warn", "on-call schedule not found", map[string]interface{}{"schedule_id": *scheduleID}, nil, "")
+		return nil
+	}
+	if schedule.Enabled == 0 {
+		return nil
+	}
+	resp := resolveOnCall(schedule, time.Now())
+	if !resp.OnCall {
+		LogService("warn", "nobody is on call for schedule", map[string]interface{}{"schedule_id": schedule.ID, "schedule_name": schedule.Name}, nil, "")
+		return nil
+	}
+	user, err := repository.GetUserByIDDAO(int(resp.UserID))
+	if err != nil {
+		return nil
+	}
+	return []model.User{user}
+}
+
+// actionRecipients merges the action's bound users with whoever is on call for its schedule
+func actionRecipients(action model.Action) []model.User {
+	users := make([]model.User, 0, len(action.Users)+1)
+	seen := make(map[uint]struct{}, len(action.Users)+1)
+	for _, user := range append(append([]model.User{}, action.Users...), onCallRecipients(action.OnCallScheduleID)...) {
+		if _, ok := seen[user.ID]; ok {
+			continue
+		}
+		seen[user.ID] = struct{}{}
+		users = append(users, user)
+	}
+	return users
+}
+
+func buildOnCallSchedule(req OnCallScheduleReq) (model.OnCallSchedule, error) {
+	timeZone := strings.TrimSpace(req.TimeZone)
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		return model.OnCallSchedule{}, fmt.Errorf("%w: unknown time zone %q", model.ErrInvalidInput, timeZone)
+	}
+	if len(req.Layers) == 0 {
+		return model.OnCallSchedule{}, fmt.Errorf("%w: on-call schedule requires at least one layer", model.ErrInvalidInput)
+	}
+	if len(req.Layers) > onCallMaxLayers {
+		return model.OnCallSchedule{}, fmt.Errorf("%w: on-call schedule supports at most %d layers", model.ErrInvalidInput, onCallMaxLayers)
+	}
+	layers := make([]model.OnCallLayer, 0, len(req.Layers))
+	for i, layer := range req.Layers {
+		layer.Rotation = strings.ToLower(strings.TrimSpace(layer.Rotation))
+		if layer.Rotation == "" {
+			layer.Rotation = OnCallRotationWeekly
+		}
+		if layer.Rotation != OnCallRotationDaily && layer.Rotation != OnCallRotationWeekly {
+			return model.OnCallSchedule{}, fmt.Errorf("%w: layer %d rotation must be daily or weekly", model.ErrInvalidInput, i+1)
+		}
+		if _, err := time.Parse("2006-01-02", layer.StartDate); err != nil {
+			return model.OnCallSchedule{}, fmt.Errorf("%w: layer %d start_date must be YYYY-MM-DD", model.ErrInvalidInput, i+1)
+		}
+		if layer.HandoffTime == "" {
+			layer.HandoffTime = "00:00"
+		}
+		if _, _, err := parseHandoffTime(layer.HandoffTime); err != nil {
+			return model.OnCallSchedule{}, fmt.Errorf("%w: layer %d handoff_time must be HH:MM", model.ErrInvalidInput, i+1)
+		}
+		if len(layer.UserIDs) == 0 {
+			return model.OnCallSchedule{}, fmt.Errorf("%w: layer %d requires at least one user", model.ErrInvalidInput, i+1)
+		}
+		for _, uid := range layer.UserIDs {
+			if _, err := repository.GetUserByIDDAO(int(uid)); err != nil {
+				return model.OnCallSchedule{}, fmt.Errorf("%w: layer %d user %d not found", model.ErrInvalidInput, i+1, uid)
+			}
+		}
+		if strings.TrimSpace(layer.Name) == "" {
+			layer.Name = fmt.Sprintf("Layer %d", i+1)
+		}
+		layers = append(layers, layer)
+	}
+	return model.OnCallSchedule{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		TimeZone:    timeZone,
+		Enabled:     req.Enabled,
+		Layers:      layers,
+	}, nil
+}
+
+func validateOnCallSchedule(scheduleID *uint) error {
+	if scheduleID == nil || *scheduleID == 0 {
+		return nil
+	}
+	if _, err := repository.GetOnCallScheduleByIDDAO(*scheduleID); err != nil {
+		return fmt.Errorf("%w: on-call schedule %d not found", model.ErrInvalidInput, *scheduleID)
+	}
+	return nil
+}
+
+func parseHandoffTime(value string) (int, int, error) {
+	if value == "" {
+		return 0, 0, nil
+	}
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, err
+	}
+	return parsed.Hour(), parsed.Minute(), nil
+}
+
+func onCallLocation(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// civilDate drops the time and zone so day differences ignore DST
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func onCallScheduleToResp(schedule model.OnCallSchedule) OnCallScheduleResp {
+	layers := schedule.Layers
+	if layers == nil {
+		layers = []model.OnCallLayer{}
+	}
+	return OnCallScheduleResp{
+		ID:          schedule.ID,
+		Name:        schedule.Name,
+		Description: schedule.Description,
+		TimeZone:    schedule.TimeZone,
+		Enabled:     schedule.Enabled,
+		Layers:      layers,
+		CreatedAt:   schedule.CreatedAt,
+		UpdatedAt:   schedule.UpdatedAt,
+	}
+}
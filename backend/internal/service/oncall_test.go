@@ -0,0 +1,131 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"nagare/internal/model"
+)
+
+func TestParseHandoffTime(t *testing.T) {
+	cases := []struct {
+		in         string
+		wantHour   int
+		wantMinute int
+		wantErr    bool
+	}{
+		{in: "", wantHour: 0, wantMinute: 0},
+		{in: "09:30", wantHour: 9, wantMinute: 30},
+		{in: "23:59", wantHour: 23, wantMinute: 59},
+		{in: "24:00", wantErr: true},
+		{in: "9am", wantErr: true},
+		{in: "12:60", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		hour, minute, err := parseHandoffTime(tc.in)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("parseHandoffTime(%q) error = %v, want error %v", tc.in, err, tc.wantErr)
+		}
+		if !tc.wantErr && (hour != tc.wantHour || minute != tc.wantMinute) {
+			t.Fatalf("parseHandoffTime(%q) = %d:%d, want %d:%d", tc.in, hour, minute, tc.wantHour, tc.wantMinute)
+		}
+	}
+}
+
+func TestOnCallLayerShift(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	utc := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2024, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	ny := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2024, month, day, hour, minute, 0, 0, newYork)
+	}
+
+	daily := model.OnCallLayer{Rotation: OnCallRotationDaily, StartDate: "2024-01-01", HandoffTime: "09:00", UserIDs: []uint{1, 2, 3}}
+	weekly := model.OnCallLayer{Rotation: OnCallRotationWeekly, StartDate: "2024-01-01", UserIDs: []uint{1, 2}}
+	// Spring forward in New York is 2024-03-10, fall back is 2024-11-03
+	springNY := model.OnCallLayer{Rotation: OnCallRotationDaily, StartDate: "2024-03-08", HandoffTime: "09:00", UserIDs: []uint{1, 2, 3}}
+	fallNY := model.OnCallLayer{Rotation: OnCallRotationDaily, StartDate: "2024-11-01", HandoffTime: "09:00", UserIDs: []uint{1, 2, 3}}
+
+	cases := []struct {
+		name      string
+		layer     model.OnCallLayer
+		loc       *time.Location
+		at        time.Time
+		wantOK    bool
+		wantUser  uint
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{name: "daily first shift", layer: daily, loc: time.UTC, at: utc(1, 1, 9, 0), wantOK: true, wantUser: 1, wantStart: utc(1, 1, 9, 0), wantEnd: utc(1, 2, 9, 0)},
+		{name: "daily before handoff stays on previous shift", layer: daily, loc: time.UTC, at: utc(1, 3, 8, 59), wantOK: true, wantUser: 2, wantStart: utc(1, 2, 9, 0), wantEnd: utc(1, 3, 9, 0)},
+		{name: "daily wraps the rotation", layer: daily, loc: time.UTC, at: utc(1, 4, 10, 0), wantOK: true, wantUser: 1, wantStart: utc(1, 4, 9, 0), wantEnd: utc(1, 5, 9, 0)},
+		{name: "before the first handoff", layer: daily, loc: time.UTC, at: utc(1, 1, 8, 59), wantOK: false},
+		{name: "start date in the future", layer: model.OnCallLayer{Rotation: OnCallRotationDaily, StartDate: "2025-01-01", UserIDs: []uint{1}}, loc: time.UTC, at: utc(6, 1, 0, 0), wantOK: false},
+		{name: "weekly first week", layer: weekly, loc: time.UTC, at: utc(1, 7, 23, 59), wantOK: true, wantUser: 1, wantStart: utc(1, 1, 0, 0), wantEnd: utc(1, 8, 0, 0)},
+		{name: "weekly handoff", layer: weekly, loc: time.UTC, at: utc(1, 8, 0, 0), wantOK: true, wantUser: 2, wantStart: utc(1, 8, 0, 0), wantEnd: utc(1, 15, 0, 0)},
+		{name: "weekly wraps", layer: weekly, loc: time.UTC, at: utc(1, 16, 12, 0), wantOK: true, wantUser: 1, wantStart: utc(1, 15, 0, 0), wantEnd: utc(1, 22, 0, 0)},
+		{name: "unknown rotation counts as daily", layer: model.OnCallLayer{Rotation: "hourly", StartDate: "2024-01-01", UserIDs: []uint{1, 2}}, loc: time.UTC, at: utc(1, 2, 1, 0), wantOK: true, wantUser: 2, wantStart: utc(1, 2, 0, 0), wantEnd: utc(1, 3, 0, 0)},
+		{name: "no users", layer: model.OnCallLayer{Rotation: OnCallRotationDaily, StartDate: "2024-01-01"}, loc: time.UTC, at: utc(2, 1, 0, 0), wantOK: false},
+		{name: "invalid start date", layer: model.OnCallLayer{Rotation: OnCallRotationDaily, StartDate: "01/01/2024", UserIDs: []uint{1}}, loc: time.UTC, at: utc(2, 1, 0, 0), wantOK: false},
+		{name: "before spring forward handoff", layer: springNY, loc: newYork, at: utc(3, 9, 13, 59), wantOK: true, wantUser: 1, wantStart: ny(3, 8, 9, 0), wantEnd: ny(3, 9, 9, 0)},
+		{name: "shift spanning spring forward is 23 hours", layer: springNY, loc: newYork, at: ny(3, 10, 4, 0), wantOK: true, wantUser: 2, wantStart: ny(3, 9, 9, 0), wantEnd: ny(3, 10, 9, 0)},
+		{name: "after spring forward handoff stays at 09:00 local", layer: springNY, loc: newYork, at: utc(3, 11, 13, 0), wantOK: true, wantUser: 1, wantStart: ny(3, 11, 9, 0), wantEnd: ny(3, 12, 9, 0)},
+		{name: "one minute before the shifted handoff", layer: springNY, loc: newYork, at: utc(3, 11, 12, 59), wantOK: true, wantUser: 3, wantStart: ny(3, 10, 9, 0), wantEnd: ny(3, 11, 9, 0)},
+		{name: "before fall back handoff", layer: fallNY, loc: newYork, at: utc(11, 4, 13, 59), wantOK: true, wantUser: 3, wantStart: ny(11, 3, 9, 0), wantEnd: ny(11, 4, 9, 0)},
+		{name: "after fall back handoff stays at 09:00 local", layer: fallNY, loc: newYork, at: utc(11, 4, 14, 0), wantOK: true, wantUser: 1, wantStart: ny(11, 4, 9, 0), wantEnd: ny(11, 5, 9, 0)},
+	}
+
+	for _, tc := range cases {
+		user, start, end, ok := onCallLayerShift(tc.layer, tc.loc, tc.at)
+		if ok != tc.wantOK {
+			t.Fatalf("%s: ok = %v, want %v", tc.name, ok, tc.wantOK)
+		}
+		if !ok {
+			continue
+		}
+		if user != tc.wantUser || !start.Equal(tc.wantStart) || !end.Equal(tc.wantEnd) {
+			t.Fatalf("%s: got user %d shift %s - %s, want user %d shift %s - %s", tc.name, user, start, end, tc.wantUser, tc.wantStart, tc.wantEnd)
+		}
+	}
+}
+
+func TestPickOnCall(t *testing.T) {
+	at := time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)
+	base := model.OnCallLayer{Name: "base", Rotation: OnCallRotationDaily, StartDate: "2024-01-01", UserIDs: []uint{1}}
+	overlay := model.OnCallLayer{Name: "overlay", Rotation: OnCallRotationWeekly, StartDate: "2024-01-01", UserIDs: []uint{2}}
+	futureOverlay := model.OnCallLayer{Name: "later", Rotation: OnCallRotationWeekly, StartDate: "2024-06-01", UserIDs: []uint{3}}
+	override := &model.OnCallOverride{UserID: 9, StartAt: at.Add(-time.Hour), EndAt: at.Add(time.Hour)}
+	override.ID = 4
+
+	cases := []struct {
+		name       string
+		layers     []model.OnCallLayer
+		override   *model.OnCallOverride
+		wantOnCall bool
+		wantUser   uint
+		wantSource string
+		wantLayer  string
+	}{
+		{name: "nobody configured", wantOnCall: false},
+		{name: "single layer", layers: []model.OnCallLayer{base}, wantOnCall: true, wantUser: 1, wantSource: "layer", wantLayer: "base"},
+		{name: "last layer wins", layers: []model.OnCallLayer{base, overlay}, wantOnCall: true, wantUser: 2, wantSource: "layer", wantLayer: "overlay"},
+		{name: "layer not started yet falls through", layers: []model.OnCallLayer{base, futureOverlay}, wantOnCall: true, wantUser: 1, wantSource: "layer", wantLayer: "base"},
+		{name: "override beats every layer", layers: []model.OnCallLayer{base, overlay}, override: override, wantOnCall: true, wantUser: 9, wantSource: "override"},
+		{name: "override without layers", override: override, wantOnCall: true, wantUser: 9, wantSource: "override"},
+	}
+
+	for _, tc := range cases {
+		resp := pickOnCall(model.OnCallSchedule{Layers: tc.layers}, tc.override, at)
+		if resp.OnCall != tc.wantOnCall || resp.UserID != tc.wantUser || resp.Source != tc.wantSource || resp.Layer != tc.wantLayer {
+			t.Fatalf("%s: got on_call=%v user=%d source=%q layer=%q", tc.name, resp.OnCall, resp.UserID, resp.Source, resp.Layer)
+		}
+		if tc.override != nil && (resp.OverrideID != 4 || !resp.ShiftEnd.Equal(tc.override.EndAt)) {
+			t.Fatalf("%s: override shift not reported: %+v", tc.name, resp)
+		}
+	}
+}
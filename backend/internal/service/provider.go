@@ -96,7 +96,11 @@ func AddProviderServ(req ProviderReq) error {
 
 // DeleteProviderByIDServ deletes a provider by ID
 func DeleteProviderByIDServ(id uint) error {
-	return repository.DeleteProviderByIDDAO(id)
+	if err := repository.DeleteProviderByIDDAO(id); err != nil {
+		return err
+	}
+	llm.GetService().RemoveProvider(int(id))
+	return nil
 }
 
 // UpdateProviderServ updates an existing provider
@@ -119,6 +123,8 @@ func UpdateProviderServ(id uint, req ProviderReq) error {
 	if err := repository.UpdateProviderDAO(id, updated); err != nil {
 		return err
 	}
+	// Give the edited settings a fresh chance in the failover chain
+	llm.GetService().ResetCircuit(int(id))
 	return nil
 }
 
@@ -306,6 +306,8 @@ func processReport(report model.Report, customStart, customEnd *time.Time) {
 	_ = repository.UpdateReportStatusDAO(report.ID, 1, filePath, downloadURL)
 
 	LogService("info", fmt.Sprintf("Report Ready: Report '%s' has been generated successfully.", report.Title), map[string]interface{}{"report_id": report.ID}, nil, "")
+
+	emailReportIfConfigured(report, filePath, downloadURL, data)
 }
 
 func resolvePDFDisplayTitle(report model.Report, pdfLang string) string {
@@ -494,8 +496,7 @@ func generateAISummary(data AdvancedReportData, lang string) string {
 		return fmt.Sprintf(T(lang, "ai_summary_disabled"), data.TotalAlerts)
 	}
 
-	providerID, modelName := aiProviderConfig()
-	client, resolvedModel, err := createLLMClient(providerID, modelName)
+	routes, err := resolveLLMRoutes(nil, 0, "")
 	if err != nil {
 		return fmt.Sprintf(T(lang, "ai_init_failed"), err)
 	}
@@ -506,8 +507,7 @@ func generateAISummary(data AdvancedReportData, lang string) string {
 	statsJSON, _ := json.Marshal(data)
 	prompt := fmt.Sprintf(T(lang, "ai_user_prompt"), string(statsJSON))
 
-	resp, err := client.Chat(ctx, llm.ChatRequest{
-		Model:        resolvedModel,
+	resp, _, err := routedChat(ctx, "report_summary", nil, routes, llm.ChatRequest{
 		SystemPrompt: T(lang, "ai_system_prompt"),
 		Messages: []llm.Message{
 			{Role: "user", Content: prompt},
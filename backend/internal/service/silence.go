@@ -0,0 +1,561 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"nagare/internal/model"
+	"nagare/internal/repository"
+	"nagare/internal/repository/monitors"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Silence kinds
+const (
+	SilenceKindMaintenance = "maintenance"
+	SilenceKindSilence     = "silence"
+)
+
+const silenceMaxDurationMinutes = 7 * 24 * 60
+
+// SilenceReq represents a silence or maintenance window request
+type SilenceReq struct {
+	Name            string     `json:"name" binding:"required"`
+	Kind            string     `json:"kind"`
+	Enabled         int        `json:"enabled"`
+	HostID          *uint      `json:"host_id"`
+	GroupID         *uint      `json:"group_id"`
+	ItemID          *uint      `json:"item_id"`
+	Severities      []int      `json:"severities"`
+	MessageRegex    string     `json:"message_regex"`
+	StartAt         *time.Time `json:"start_at"`
+	EndAt           *time.Time `json:"end_at"`
+	CronExpr        string     `json:"cron_expr"`
+	DurationMinutes int        `json:"duration_minutes"`
+	Reason          string     `json:"reason" binding:"required"`
+	PushToZabbix    int        `json:"push_to_zabbix"`
+}
+
+// SilenceResp represents a silence or maintenance window response
+type SilenceResp struct {
+	ID                  uint       `json:"id"`
+	Name                string     `json:"name"`
+	Kind                string     `json:"kind"`
+	Enabled             int        `json:"enabled"`
+	Active              bool       `json:"active"`
+	HostID              *uint      `json:"host_id"`
+	GroupID             *uint      `json:"group_id"`
+	ItemID              *uint      `json:"item_id"`
+	Severities          []int      `json:"severities"`
+	MessageRegex        string     `json:"message_regex"`
+	StartAt             *time.Time `json:"start_at"`
+	EndAt               *time.Time `json:"end_at"`
+	CronExpr            string     `json:"cron_expr"`
+	DurationMinutes     int        `json:"duration_minutes"`
+	Reason              string     `json:"reason"`
+	CreatedByID         *uint      `json:"created_by_id"`
+	CreatedBy           string     `json:"created_by"`
+	SuppressedCount     int        `json:"suppressed_count"`
+	PushToZabbix        int        `json:"push_to_zabbix"`
+	ZabbixMonitorID     *uint      `json:"zabbix_monitor_id"`
+	ZabbixMaintenanceID string     `json:"zabbix_maintenance_id"`
+	ZabbixSyncError     string     `json:"zabbix_sync_error"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// SearchSilencesServ retrieves silences by filter
+func SearchSilencesServ(filter model.SilenceFilter) ([]SilenceResp, error) {
+	silences, err := repository.SearchSilencesDAO(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search silences: %w", err)
+	}
+	names := incidentNameCache{}
+	now := time.Now()
+	result := make([]SilenceResp, 0, len(silences))
+	for _, silence := range silences {
+		result = append(result, silenceToResp(silence, now, names))
+	}
+	return result, nil
+}
+
+// CountSilencesServ returns total count for silences by filter
+func CountSilencesServ(filter model.SilenceFilter) (int64, error) {
+	return repository.CountSilencesDAO(filter)
+}
+
+// GetSilenceByIDServ retrieves a silence by ID
+func GetSilenceByIDServ(id uint) (SilenceResp, error) {
+	silence, err := repository.GetSilenceByIDDAO(id)
+	if err != nil {
+		return SilenceResp{}, err
+	}
+	return silenceToResp(silence, time.Now(), incidentNameCache{}), nil
+}
+
+// AddSilenceServ creates a silence and, when requested, mirrors it as a Zabbix maintenance
+func AddSilenceServ(req SilenceReq, userID *uint) (SilenceResp, error) {
+	silence, err := buildSilence(req)
+	if err != nil {
+		return SilenceResp{}, err
+	}
+	silence.CreatedByID = userID
+	if err := repository.AddSilenceDAO(&silence); err != nil {
+		return SilenceResp{}, fmt.Errorf("failed to add silence: %w", err)
+	}
+	LogService("info", "silence created", map[string]interface{}{
+		"silence_id": silence.ID,
+		"kind":       silence.Kind,
+		"reason":     silence.Reason,
+	}, userID, "")
+
+	if silence.PushToZabbix == 1 {
+		silence = syncSilenceToZabbix(silence)
+	}
+	return silenceToResp(silence, time.Now(), incidentNameCache{}), nil
+}
+
+// UpdateSilenceServ updates a silence and its Zabbix maintenance mirror
+func UpdateSilenceServ(id uint, req SilenceReq, userID *uint) (SilenceResp, error) {
+	existing, err := repository.GetSilenceByIDDAO(id)
+	if err != nil {
+		return SilenceResp{}, err
+	}
+	silence, err := buildSilence(req)
+	if err != nil {
+		return SilenceResp{}, err
+	}
+	if err := repository.UpdateSilenceDAO(id, silence); err != nil {
+		return SilenceResp{}, fmt.Errorf("failed to update silence: %w", err)
+	}
+	LogService("info", "silence updated", map[string]interface{}{"silence_id": id}, userID, "")
+
+	updated, err := repository.GetSilenceByIDDAO(id)
+	if err != nil {
+		return SilenceResp{}, err
+	}
+	switch {
+	case updated.PushToZabbix == 1:
+		updated = syncSilenceToZabbix(updated)
+	case existing.ZabbixMaintenanceID != "":
+		updated = removeSilenceFromZabbix(updated)
+	}
+	return silenceToResp(updated, time.Now(), incidentNameCache{}), nil
+}
+
+// DeleteSilenceServ deletes a silence and its Zabbix maintenance mirror
+func DeleteSilenceServ(id uint, userID *uint) error {
+	silence, err := repository.GetSilenceByIDDAO(id)
+	if err != nil {
+		return err
+	}
+	if silence.ZabbixMaintenanceID != "" {
+		removeSilenceFromZabbix(silence)
+	}
+	if err := repository.DeleteSilenceByIDDAO(id); err != nil {
+		return err
+	}
+	LogService("info", "silence deleted", map[string]interface{}{"silence_id": id}, userID, "")
+	return nil
+}
+
+// ExpireSilenceServ ends a silence now while keeping it for auditing
+func ExpireSilenceServ(id uint, userID *uint) (SilenceResp, error) {
+	silence, err := repository.GetSilenceByIDDAO(id)
+	if err != nil {
+		return SilenceResp{}, err
+	}
+	if err := repository.UpdateSilenceFieldsDAO(id, map[string]interface{}{"enabled": 0}); err != nil {
+		return SilenceResp{}, fmt.Errorf("failed to expire silence: %w", err)
+	}
+	silence.Enabled = 0
+	if silence.ZabbixMaintenanceID != "" {
+		silence = removeSilenceFromZabbix(silence)
+	}
+	LogService("info", "silence expired", map[string]interface{}{"silence_id": id}, userID, "")
+	return silenceToResp(silence, time.Now(), incidentNameCache{}), nil
+}
+
+// applyAlertSilences marks a freshly stored alert as suppressed when an active silence matches it
+func applyAlertSilences(alert model.Alert, hostName string) model.Alert {
+	silences, err := repository.SearchSilencesDAO(model.SilenceFilter{Current: true, SortBy: "id", SortOrder: "asc"})
+	if err != nil {
+		LogService("warn", "failed to load silences", map[string]interface{}{"alert_id": alert.ID, "error": err.Error()}, nil, "")
+		return alert
+	}
+	if len(silences) == 0 {
+		return alert
+	}
+
+	matchCtx := buildAlertMatchContext(alert)
+	if matchCtx.host == nil && strings.TrimSpace(hostName) != "" {
+		matchCtx.host = findHostByName(hostName)
+	}
+	now := time.Now()
+	for _, silence := range silences {
+		if !silenceActiveAt(silence, now) || !matchSilence(silence, matchCtx) {
+			continue
+		}
+		if err := repository.SuppressAlertDAO(silence.ID, alert.ID); err != nil {
+			LogService("warn", "failed to mark alert suppressed", map[string]interface{}{"alert_id": alert.ID, "silence_id": silence.ID, "error": err.Error()}, nil, "")
+			return alert
+		}
+		silenceID := silence.ID
+		alert.SilenceID = &silenceID
+		LogService("info", "alert suppressed by silence", map[string]interface{}{
+			"alert_id":     alert.ID,
+			"silence_id":   silence.ID,
+			"silence_kind": silence.Kind,
+			"reason":       silence.Reason,
+		}, nil, "")
+		return alert
+	}
+	return alert
+}
+
+// matchSilence reports whether every matcher set on the silence matches the alert
+func matchSilence(silence model.Silence, ctx alertMatchContext) bool {
+	alert := ctx.alert
+	if silence.ItemID != nil && (alert.ItemID == nil || *alert.ItemID != *silence.ItemID) {
+		return false
+	}
+	if silence.HostID != nil && (ctx.host == nil || ctx.host.ID != *silence.HostID) {
+		return false
+	}
+	if silence.GroupID != nil && (ctx.host == nil || ctx.host.GroupID != *silence.GroupID) {
+		return false
+	}
+	if len(silence.Severities) > 0 {
+		matched := false
+		for _, severity := range silence.Severities {
+			if severity == alert.Severity {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if silence.MessageRegex != "" {
+		re, err := regexp.Compile(silence.MessageRegex)
+		if err != nil || !re.MatchString(alert.Message) {
+			return false
+		}
+	}
+	return true
+}
+
+// silenceActiveAt reports whether the silence window is open; recurring windows stay open DurationMinutes after each cron firing
+func silenceActiveAt(silence model.Silence, at time.Time) bool {
+	if silence.Enabled == 0 {
+		return false
+	}
+	if silence.StartAt != nil && at.Before(*silence.StartAt) {
+		return false
+	}
+	if silence.EndAt != nil && !at.Before(*silence.EndAt) {
+		return false
+	}
+	if silence.CronExpr == "" {
+		return true
+	}
+	schedule, err := cron.ParseStandard(silence.CronExpr)
+	if err != nil {
+		return false
+	}
+	duration := time.Duration(silence.DurationMinutes) * time.Minute
+	return !schedule.Next(at.Add(-duration)).After(at)
+}
+
+func buildSilence(req SilenceReq) (model.Silence, error) {
+	kind := strings.ToLower(strings.TrimSpace(req.Kind))
+	if kind == "" {
+		kind = SilenceKindSilence
+	}
+	if kind != SilenceKindSilence && kind != SilenceKindMaintenance {
+		return model.Silence{}, fmt.Errorf("%w: kind must be silence or maintenance", model.ErrInvalidInput)
+	}
+
+	silence := model.Silence{
+		Name:            strings.TrimSpace(req.Name),
+		Kind:            kind,
+		Enabled:         req.Enabled,
+		HostID:          normalizeOptionalID(req.HostID),
+		GroupID:         normalizeOptionalID(req.GroupID),
+		ItemID:          normalizeOptionalID(req.ItemID),
+		Severities:      req.Severities,
+		MessageRegex:    strings.TrimSpace(req.MessageRegex),
+		StartAt:         req.StartAt,
+		EndAt:           req.EndAt,
+		CronExpr:        strings.TrimSpace(req.CronExpr),
+		DurationMinutes: req.DurationMinutes,
+		Reason:          strings.TrimSpace(req.Reason),
+		PushToZabbix:    req.PushToZabbix,
+	}
+	if silence.Reason == "" {
+		return model.Silence{}, fmt.Errorf("%w: reason is required", model.ErrInvalidInput)
+	}
+
+	// A silence without matchers would mute every alert
+	if silence.HostID == nil && silence.GroupID == nil && silence.ItemID == nil && len(silence.Severities) == 0 && silence.MessageRegex == "" {
+		return model.Silence{}, fmt.Errorf("%w: at least one matcher (host, group, item, severity or message regex) is required", model.ErrInvalidInput)
+	}
+	if silence.HostID != nil {
+		if _, err := repository.GetHostByIDDAO(*silence.HostID); err != nil {
+			return model.Silence{}, fmt.Errorf("%w: host %d not found", model.ErrInvalidInput, *silence.HostID)
+		}
+	}
+	if silence.GroupID != nil {
+		if _, err := repository.GetGroupByIDDAO(*silence.GroupID); err != nil {
+			return model.Silence{}, fmt.Errorf("%w: group %d not found", model.ErrInvalidInput, *silence.GroupID)
+		}
+	}
+	if silence.ItemID != nil {
+		if _, err := repository.GetItemByIDDAO(*silence.ItemID); err != nil {
+			return model.Silence{}, fmt.Errorf("%w: item %d not found", model.ErrInvalidInput, *silence.ItemID)
+		}
+	}
+	for _, severity := range silence.Severities {
+		if severity < 0 || severity > 5 {
+			return model.Silence{}, fmt.Errorf("%w: severity must be between 0 and 5", model.ErrInvalidInput)
+		}
+	}
+	if silence.MessageRegex != "" {
+		if _, err := regexp.Compile(silence.MessageRegex); err != nil {
+			return model.Silence{}, fmt.Errorf("%w: invalid message_regex: %v", model.ErrInvalidInput, err)
+		}
+	}
+
+	if silence.StartAt != nil && silence.EndAt != nil && !silence.EndAt.After(*silence.StartAt) {
+		return model.Silence{}, fmt.Errorf("%w: end_at must be after start_at", model.ErrInvalidInput)
+	}
+	if silence.CronExpr != "" {
+		if _, err := cron.ParseStandard(silence.CronExpr); err != nil {
+			return model.Silence{}, fmt.Errorf("%w: invalid cron_expr: %v", model.ErrInvalidInput, err)
+		}
+		if silence.DurationMinutes <= 0 || silence.DurationMinutes > silenceMaxDurationMinutes {
+			return model.Silence{}, fmt.Errorf("%w: duration_minutes must be between 1 and %d for recurring windows", model.ErrInvalidInput, silenceMaxDurationMinutes)
+		}
+	} else {
+		silence.DurationMinutes = 0
+		if silence.EndAt == nil {
+			return model.Silence{}, fmt.Errorf("%w: end_at or cron_expr is required", model.ErrInvalidInput)
+		}
+		if silence.StartAt == nil {
+			now := time.Now()
+			silence.StartAt = &now
+		}
+	}
+
+	if silence.PushToZabbix == 1 {
+		if silence.CronExpr != "" {
+			return model.Silence{}, fmt.Errorf("%w: only fixed windows can be pushed to Zabbix", model.ErrInvalidInput)
+		}
+		if silence.HostID == nil && silence.GroupID == nil {
+			return model.Silence{}, fmt.Errorf("%w: a host or group matcher is required to push to Zabbix", model.ErrInvalidInput)
+		}
+	}
+	return silence, nil
+}
+
+// silenceZabbixTarget resolves the Zabbix monitor and external host/group IDs a silence covers
+func silenceZabbixTarget(silence model.Silence) (model.Monitor, monitors.ZabbixMaintenance, error) {
+	maintenance := monitors.ZabbixMaintenance{
+		Name:        fmt.Sprintf("Nagare silence #%d: %s", silence.ID, silence.Name),
+		Description: silence.Reason,
+	}
+	var monitorID uint
+	if silence.HostID != nil {
+		host, err := repository.GetHostByIDDAO(*silence.HostID)
+		if err != nil {
+			return model.Monitor{}, maintenance, fmt.Errorf("host %d not found", *silence.HostID)
+		}
+		if host.ExternalID == "" {
+			return model.Monitor{}, maintenance, fmt.Errorf("host %s is not linked to a monitor", host.Name)
+		}
+		group, err := repository.GetGroupByIDDAO(host.GroupID)
+		if err != nil {
+			return model.Monitor{}, maintenance, fmt.Errorf("group of host %s not found", host.Name)
+		}
+		monitorID = group.MonitorID
+		maintenance.HostIDs = []string{host.ExternalID}
+	}
+	if silence.GroupID != nil {
+		group, err := repository.GetGroupByIDDAO(*silence.GroupID)
+		if err != nil {
+			return model.Monitor{}, maintenance, fmt.Errorf("group %d not found", *silence.GroupID)
+		}
+		if group.ExternalID == "" {
+			return model.Monitor{}, maintenance, fmt.Errorf("group %s is not linked to a monitor", group.Name)
+		}
+		if monitorID != 0 && monitorID != group.MonitorID {
+			return model.Monitor{}, maintenance, fmt.Errorf("host and group belong to different monitors")
+		}
+		monitorID = group.MonitorID
+		maintenance.GroupIDs = []string{group.ExternalID}
+	}
+
+	monitor, err := repository.GetMonitorByIDDAO(monitorID)
+	if err != nil {
+		return model.Monitor{}, maintenance, fmt.Errorf("monitor %d not found", monitorID)
+	}
+	if err := checkSilenceZabbixMonitor(monitor); err != nil {
+		return model.Monitor{}, maintenance, err
+	}
+	if silence.StartAt != nil {
+		maintenance.ActiveSince = *silence.StartAt
+	}
+	if silence.EndAt != nil {
+		maintenance.ActiveTill = *silence.EndAt
+	}
+	return monitor, maintenance, nil
+}
+
+// checkSilenceZabbixMonitor rejects monitors that cannot hold a Zabbix maintenance
+func checkSilenceZabbixMonitor(monitor model.Monitor) error {
+	if monitors.ParseMonitorType(monitor.Type) != monitors.MonitorZabbix {
+		return fmt.Errorf("monitor %s is not a Zabbix monitor", monitor.Name)
+	}
+	return nil
+}
+
+func newSilenceZabbixProvider(monitor model.Monitor) (*monitors.ZabbixProvider, error) {
+	return monitors.NewZabbixProvider(monitors.Config{
+		Name: monitor.Name,
+		Type: monitors.MonitorZabbix,
+		Auth: monitors.AuthConfig{
+			URL:      monitor.URL,
+			Username: monitor.Username,
+			Password: monitor.Password,
+			Token:    monitor.AuthToken,
+		},
+		Timeout: 30,
+	})
+}
+
+// syncSilenceToZabbix creates or updates the Zabbix maintenance mirroring a silence; failures are recorded on the silence
+func syncSilenceToZabbix(silence model.Silence) model.Silence {
+	err := func() error {
+		monitor, maintenance, err := silenceZabbixTarget(silence)
+		if err != nil {
+			return err
+		}
+		provider, err := newSilenceZabbixProvider(monitor)
+		if err != nil {
+			return fmt.Errorf("failed to create zabbix provider: %w", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		// Moving to another monitor leaves the old maintenance behind, so drop it first
+		if silence.ZabbixMaintenanceID != "" && silence.ZabbixMonitorID != nil && *silence.ZabbixMonitorID != monitor.ID {
+			removeSilenceFromZabbix(silence)
+			silence.ZabbixMaintenanceID = ""
+		}
+		if silence.ZabbixMaintenanceID != "" {
+			if err := provider.UpdateMaintenance(ctx, silence.ZabbixMaintenanceID, maintenance); err != nil {
+				return err
+			}
+		} else {
+			id, err := provider.CreateMaintenance(ctx, maintenance)
+			if err != nil {
+				return err
+			}
+			silence.ZabbixMaintenanceID = id
+		}
+		monitorID := monitor.ID
+		silence.ZabbixMonitorID = &monitorID
+		return nil
+	}()
+
+	silence.ZabbixSyncError = ""
+	if err != nil {
+		silence.ZabbixSyncError = err.Error()
+		LogService("warn", "failed to push silence to zabbix", map[string]interface{}{"silence_id": silence.ID, "error": err.Error()}, nil, "")
+	}
+	if err := repository.UpdateSilenceFieldsDAO(silence.ID, map[string]interface{}{
+		"zabbix_maintenance_id": silence.ZabbixMaintenanceID,
+		"zabbix_monitor_id":     silence.ZabbixMonitorID,
+		"zabbix_sync_error":     silence.ZabbixSyncError,
+	}); err != nil {
+		LogService("warn", "failed to record silence zabbix state", map[string]interface{}{"silence_id": silence.ID, "error": err.Error()}, nil, "")
+	}
+	return silence
+}
+
+// removeSilenceFromZabbix deletes the Zabbix maintenance mirroring a silence
+func removeSilenceFromZabbix(silence model.Silence) model.Silence {
+	if silence.ZabbixMaintenanceID == "" || silence.ZabbixMonitorID == nil {
+		return silence
+	}
+	err := func() error {
+		monitor, err := repository.GetMonitorByIDDAO(*silence.ZabbixMonitorID)
+		if err != nil {
+			return fmt.Errorf("monitor %d not found", *silence.ZabbixMonitorID)
+		}
+		provider, err := newSilenceZabbixProvider(monitor)
+		if err != nil {
+			return fmt.Errorf("failed to create zabbix provider: %w", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		return provider.DeleteMaintenance(ctx, silence.ZabbixMaintenanceID)
+	}()
+	if err != nil {
+		silence.ZabbixSyncError = err.Error()
+		LogService("warn", "failed to remove silence from zabbix", map[string]interface{}{"silence_id": silence.ID, "error": err.Error()}, nil, "")
+	} else {
+		silence.ZabbixMaintenanceID = ""
+		silence.ZabbixMonitorID = nil
+		silence.ZabbixSyncError = ""
+	}
+	if err := repository.UpdateSilenceFieldsDAO(silence.ID, map[string]interface{}{
+		"zabbix_maintenance_id": silence.ZabbixMaintenanceID,
+		"zabbix_monitor_id":     silence.ZabbixMonitorID,
+		"zabbix_sync_error":     silence.ZabbixSyncError,
+	}); err != nil {
+		LogService("warn", "failed to record silence zabbix state", map[string]interface{}{"silence_id": silence.ID, "error": err.Error()}, nil, "")
+	}
+	return silence
+}
+
+func silenceToResp(silence model.Silence, now time.Time, names incidentNameCache) SilenceResp {
+	severities := silence.Severities
+	if severities == nil {
+		severities = []int{}
+	}
+	resp := SilenceResp{
+		ID:                  silence.ID,
+		Name:                silence.Name,
+		Kind:                silence.Kind,
+		Enabled:             silence.Enabled,
+		Active:              silenceActiveAt(silence, now),
+		HostID:              silence.HostID,
+		GroupID:             silence.GroupID,
+		ItemID:              silence.ItemID,
+		Severities:          severities,
+		MessageRegex:        silence.MessageRegex,
+		StartAt:             silence.StartAt,
+		EndAt:               silence.EndAt,
+		CronExpr:            silence.CronExpr,
+		DurationMinutes:     silence.DurationMinutes,
+		Reason:              silence.Reason,
+		CreatedByID:         silence.CreatedByID,
+		SuppressedCount:     silence.SuppressedCount,
+		PushToZabbix:        silence.PushToZabbix,
+		ZabbixMonitorID:     silence.ZabbixMonitorID,
+		ZabbixMaintenanceID: silence.ZabbixMaintenanceID,
+		ZabbixSyncError:     silence.ZabbixSyncError,
+		CreatedAt:           silence.CreatedAt,
+		UpdatedAt:           silence.UpdatedAt,
+	}
+	if silence.CreatedByID != nil {
+		resp.CreatedBy = names.username(*silence.CreatedByID)
+	}
+	return resp
+}
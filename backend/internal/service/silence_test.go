@@ -0,0 +1,72 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"nagare/internal/model"
+)
+
+func TestSilenceActiveAt(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		// 2024-01-01 is a Monday; cron schedules are evaluated in local time
+		return time.Date(2024, 1, day, hour, minute, 0, 0, time.Local)
+	}
+	ptr := func(v time.Time) *time.Time { return &v }
+
+	nightly := model.Silence{Enabled: 1, CronExpr: "0 2 * * *", DurationMinutes: 60}
+	weekend := model.Silence{Enabled: 1, CronExpr: "0 22 * * 5", DurationMinutes: 2 * 24 * 60}
+	bounded := model.Silence{Enabled: 1, CronExpr: "0 2 * * *", DurationMinutes: 60, StartAt: ptr(at(3, 0, 0)), EndAt: ptr(at(5, 0, 0))}
+	fixed := model.Silence{Enabled: 1, StartAt: ptr(at(1, 8, 0)), EndAt: ptr(at(1, 10, 0))}
+
+	cases := []struct {
+		name    string
+		silence model.Silence
+		at      time.Time
+		want    bool
+	}{
+		{name: "fixed before start", silence: fixed, at: at(1, 7, 59), want: false},
+		{name: "fixed at start", silence: fixed, at: at(1, 8, 0), want: true},
+		{name: "fixed inside", silence: fixed, at: at(1, 9, 30), want: true},
+		{name: "fixed at end", silence: fixed, at: at(1, 10, 0), want: false},
+		{name: "disabled", silence: model.Silence{Enabled: 0, StartAt: ptr(at(1, 8, 0)), EndAt: ptr(at(1, 10, 0))}, at: at(1, 9, 0), want: false},
+		{name: "open ended", silence: model.Silence{Enabled: 1}, at: at(1, 9, 0), want: true},
+		{name: "cron before firing", silence: nightly, at: at(2, 1, 59), want: false},
+		{name: "cron at firing", silence: nightly, at: at(2, 2, 0), want: true},
+		{name: "cron inside window", silence: nightly, at: at(2, 2, 59), want: true},
+		{name: "cron window closed", silence: nightly, at: at(2, 3, 0), want: false},
+		{name: "cron across midnight", silence: weekend, at: at(7, 12, 0), want: true},
+		{name: "cron weekend over", silence: weekend, at: at(7, 22, 0), want: false},
+		{name: "cron weekday", silence: weekend, at: at(3, 23, 0), want: false},
+		{name: "cron before start_at", silence: bounded, at: at(2, 2, 30), want: false},
+		{name: "cron inside bounds", silence: bounded, at: at(4, 2, 30), want: true},
+		{name: "cron after end_at", silence: bounded, at: at(5, 2, 30), want: false},
+		{name: "invalid cron", silence: model.Silence{Enabled: 1, CronExpr: "every night", DurationMinutes: 60}, at: at(2, 2, 30), want: false},
+	}
+
+	for _, tc := range cases {
+		if got := silenceActiveAt(tc.silence, tc.at); got != tc.want {
+			t.Fatalf("%s: silenceActiveAt = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCheckSilenceZabbixMonitor(t *testing.T) {
+	cases := []struct {
+		name    string
+		monitor model.Monitor
+		wantErr bool
+	}{
+		{name: "zabbix", monitor: model.Monitor{Name: "zbx", Type: 2}},
+		{name: "snmp", monitor: model.Monitor{Name: "internal", Type: 1}, wantErr: true},
+		{name: "other", monitor: model.Monitor{Name: "custom", Type: 3}, wantErr: true},
+		{name: "prometheus", monitor: model.Monitor{Name: "prom", Type: 5}, wantErr: true},
+	}
+
+	for _, tc := range cases {
+		err := checkSilenceZabbixMonitor(tc.monitor)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%s: checkSilenceZabbixMonitor error = %v, want error %v", tc.name, err, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"nagare/internal/model"
+	"nagare/internal/repository"
+	"nagare/internal/repository/monitors"
+	"nagare/internal/service/utils"
+)
+
+// snmpTargetSource feeds hosts and items of an SNMP monitor from the database to the built-in poller
+type snmpTargetSource struct {
+	mid uint
+}
+
+// attachSNMPTargetSource wires the local hosts of a monitor into the SNMP poller; other providers are left untouched
+func attachSNMPTargetSource(client *monitors.Client, mid uint) {
+	if provider, ok := client.Provider().(*monitors.SNMPProvider); ok {
+		provider.SetTargetSource(snmpTargetSource{mid: mid})
+	}
+}
+
+// SNMPTargets implements monitors.SNMPTargetSource
+func (s snmpTargetSource) SNMPTargets(ctx context.Context) ([]monitors.SNMPTarget, error) {
+	mid := s.mid
+	hosts, err := repository.SearchHostsDAO(model.HostFilter{MID: &mid})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hosts: %w", err)
+	}
+
+	targets := make([]monitors.SNMPTarget, 0, len(hosts))
+	for _, host := range hosts {
+		host.ExternalID = snmpHostExternalID(host)
+		targets = append(targets, snmpTargetFromHost(host))
+	}
+	return targets, nil
+}
+
+// SNMPTarget implements monitors.SNMPTargetSource
+func (s snmpTargetSource) SNMPTarget(ctx context.Context, hostID string) (monitors.SNMPTarget, error) {
+	host, err := repository.GetHostByMIDAndHostIDDAO(s.mid, hostID)
+	if err != nil {
+		return monitors.SNMPTarget{}, fmt.Errorf("failed to get host: %w", err)
+	}
+
+	target := snmpTargetFromHost(host)
+	items, err := repository.GetItemsByHIDDAO(host.ID)
+	if err != nil {
+		return monitors.SNMPTarget{}, fmt.Errorf("failed to get items: %w", err)
+	}
+	for _, item := range items {
+		if item.ExternalID == "" || strings.HasPrefix(item.ExternalID, "calculated.") {
+			continue
+		}
+		target.Items = append(target.Items, monitors.SNMPItem{
+			ID:        item.ExternalID,
+			Name:      item.Name,
+			Units:     item.Units,
+			Enabled:   item.Enabled,
+			LastValue: item.LastValue,
+		})
+	}
+	return target, nil
+}
+
+func snmpTargetFromHost(host model.Host) monitors.SNMPTarget {
+	community, version, port := resolveHostSNMPConfigFromMetadata(nil, host)
+	authPassword, privPassword := decryptSNMPv3Passwords(host)
+	return monitors.SNMPTarget{
+		HostID:      host.ExternalID,
+		Name:        host.Name,
+		Description: host.Description,
+		Enabled:     host.Enabled,
+		Address:     host.IPAddr,
+		Port:        port,
+		Version:     version,
+		Community:   community,
+		V3: monitors.SNMPv3Credentials{
+			Username:      host.SNMPV3User,
+			SecurityLevel: host.SNMPV3SecurityLevel,
+			AuthProtocol:  host.SNMPV3AuthProtocol,
+			AuthPassword:  authPassword,
+			PrivProtocol:  host.SNMPV3PrivProtocol,
+			PrivPassword:  privPassword,
+		},
+	}
+}
+
+// snmpHostExternalID is the ID the SNMP poller knows a local host by; it never writes to the database
+func snmpHostExternalID(host model.Host) string {
+	if host.ExternalID != "" {
+		return host.ExternalID
+	}
+	return fmt.Sprintf("snmp-%d", host.ID)
+}
+
+// assignSNMPHostExternalID gives a saved host of an SNMP monitor a stable external ID so the
+// poller and item pulls can address it
+func assignSNMPHostExternalID(hostID uint) {
+	host, err := repository.GetHostByIDDAO(hostID)
+	if err != nil || host.ExternalID != "" {
+		return
+	}
+	group, err := repository.GetGroupByIDDAO(host.GroupID)
+	if err != nil {
+		return
+	}
+	monitor, err := repository.GetMonitorByIDDAO(group.MonitorID)
+	if err != nil || monitors.ParseMonitorType(monitor.Type) != monitors.MonitorSNMP {
+		return
+	}
+	if err := repository.UpdateHostExternalIDDAO(host.ID, snmpHostExternalID(host)); err != nil {
+		LogService("warn", "failed to assign SNMP host external id", map[string]interface{}{"host_id": host.ID, "error": err.Error()}, nil, "")
+	}
+}
+
+// pollSNMPItemOfHost polls one item through the SNMP poller, whose item IDs are only unique per host
+func pollSNMPItemOfHost(ctx context.Context, client *monitors.Client, hostID, itemID string) (*monitors.Item, error) {
+	provider, ok := client.Provider().(*monitors.SNMPProvider)
+	if !ok {
+		return nil, fmt.Errorf("monitor is not an SNMP poller")
+	}
+	return provider.PollItem(ctx, hostID, itemID)
+}
+
+// encryptSNMPv3Passwords encrypts the SNMPv3 passwords for storage; empty passwords stay empty
+func encryptSNMPv3Passwords(authPassword, privPassword string) (string, string, error) {
+	encryptedAuth, encryptedPriv := "", ""
+	if authPassword != "" {
+		encrypted, err := utils.Encrypt(authPassword)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to encrypt SNMPv3 auth password: %w", err)
+		}
+		encryptedAuth = encrypted
+	}
+	if privPassword != "" {
+		encrypted, err := utils.Encrypt(privPassword)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to encrypt SNMPv3 privacy password: %w", err)
+		}
+		encryptedPriv = encrypted
+	}
+	return encryptedAuth, encryptedPriv, nil
+}
+
+func decryptSNMPv3Passwords(host model.Host) (string, string) {
+	authPassword, privPassword := "", ""
+	if host.SNMPV3AuthPassword != "" {
+		if decrypted, err := utils.Decrypt(host.SNMPV3AuthPassword); err == nil {
+			authPassword = decrypted
+		}
+	}
+	if host.SNMPV3PrivPassword != "" {
+		if decrypted, err := utils.Decrypt(host.SNMPV3PrivPassword); err == nil {
+			privPassword = decrypted
+		}
+	}
+	return authPassword, privPassword
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"nagare/internal/model"
+	"nagare/internal/repository"
+	"nagare/internal/repository/monitors"
+)
+
+const (
+	defaultSNMPTrapListenAddress = "0.0.0.0:162"
+	snmpTrapMaxMessageLength     = 2048
+)
+
+var (
+	snmpTrapMu       sync.Mutex
+	snmpTrapReceiver *monitors.SNMPTrapReceiver
+)
+
+// Friendly names for varbinds that commonly accompany interface traps
+var snmpTrapVarBindNames = map[string]string{
+	"1.3.6.1.2.1.2.2.1.1":     "ifIndex",
+	"1.3.6.1.2.1.2.2.1.2":     "ifDescr",
+	"1.3.6.1.2.1.2.2.1.7":     "ifAdminStatus",
+	"1.3.6.1.2.1.2.2.1.8":     "ifOperStatus",
+	"1.3.6.1.2.1.31.1.1.1.1":  "ifName",
+	"1.3.6.1.2.1.31.1.1.1.18": "ifAlias",
+}
+
+// StartSNMPTrapReceiver starts the SNMP trap listener when it is enabled in the configuration
+func StartSNMPTrapReceiver() {
+	snmpTrapMu.Lock()
+	defer snmpTrapMu.Unlock()
+	if snmpTrapReceiver != nil {
+		return
+	}
+
+	cfg, err := repository.GetSNMPTrapConfig()
+	if err != nil {
+		LogSystem("error", "failed to load SNMP trap configuration", map[string]interface{}{"error": err.Error()}, nil, "")
+		return
+	}
+	if !cfg.Enabled {
+		LogSystem("info", "SNMP trap receiver disabled via configuration", nil, nil, "")
+		return
+	}
+
+	addr := strings.TrimSpace(cfg.ListenAddress)
+	if addr == "" {
+		addr = defaultSNMPTrapListenAddress
+	}
+
+	var v3 *monitors.SNMPv3Credentials
+	if strings.TrimSpace(cfg.V3.Username) != "" {
+		v3 = &monitors.SNMPv3Credentials{
+			Username:     cfg.V3.Username,
+			AuthProtocol: cfg.V3.AuthProtocol,
+			AuthPassword: cfg.V3.AuthPassword,
+			PrivProtocol: cfg.V3.PrivProtocol,
+			PrivPassword: cfg.V3.PrivPassword,
+		}
+	}
+
+	receiver, err := monitors.NewSNMPTrapReceiver(addr, v3, func(trap monitors.SNMPTrap) {
+		handleSNMPTrap(cfg, trap)
+	})
+	if err != nil {
+		LogSystem("error", "failed to create SNMP trap receiver", map[string]interface{}{"error": err.Error()}, nil, "")
+		return
+	}
+	if err := receiver.Start(); err != nil {
+		LogSystem("error", "failed to start SNMP trap receiver", map[string]interface{}{"address": addr, "error": err.Error()}, nil, "")
+		return
+	}
+
+	snmpTrapReceiver = receiver
+	fmt.Printf(">>> SNMP trap receiver listening on %s\n", addr)
+	LogSystem("info", "SNMP trap receiver started", map[string]interface{}{"address": addr}, nil, "")
+}
+
+// StopSNMPTrapReceiver stops the SNMP trap listener
+func StopSNMPTrapReceiver() {
+	snmpTrapMu.Lock()
+	receiver := snmpTrapReceiver
+	snmpTrapReceiver = nil
+	snmpTrapMu.Unlock()
+	if receiver != nil {
+		receiver.Close()
+	}
+}
+
+// RestartSNMPTrapReceiver restarts the SNMP trap listener
+func RestartSNMPTrapReceiver() {
+	StopSNMPTrapReceiver()
+	StartSNMPTrapReceiver()
+}
+
+func handleSNMPTrap(cfg repository.SNMPTrapConfig, trap monitors.SNMPTrap) {
+	if cfg.Community != "" && trap.Version != "v3" && trap.Community != cfg.Community {
+		LogService("warn", "SNMP trap rejected: community mismatch", map[string]interface{}{"source": trap.SourceIP}, nil, "")
+		return
+	}
+
+	sourceIP := trap.SourceIP
+	if trap.AgentAddress != "" && trap.AgentAddress != "0.0.0.0" {
+		sourceIP = trap.AgentAddress
+	}
+
+	hostName := sourceIP
+	hosts, err := repository.SearchHostsDAO(model.HostFilter{IPAddr: &sourceIP})
+	if err != nil {
+		LogService("error", "SNMP trap host lookup failed", map[string]interface{}{"source": sourceIP, "error": err.Error()}, nil, "")
+		return
+	}
+	if len(hosts) > 0 {
+		hostName = hosts[0].Name
+	} else if !cfg.AcceptUnknownHosts {
+		LogService("warn", "SNMP trap dropped: unknown host", map[string]interface{}{"source": sourceIP, "trap_oid": trap.TrapOID}, nil, "")
+		return
+	}
+
+	rule, matched := matchSNMPTrapRule(cfg.Rules, trap.TrapOID)
+	trapName := trap.TrapOID
+	severity := cfg.DefaultSeverity
+	if matched {
+		if rule.Name != "" {
+			trapName = rule.Name
+		}
+		severity = rule.Severity
+	}
+
+	ifIndex := snmpTrapVarBindValue(trap, "ifIndex")
+	if matched && rule.Resolves != "" {
+		externalID := snmpTrapExternalID(sourceIP, strings.TrimPrefix(rule.Resolves, "."), ifIndex)
+		resolved, err := ResolveActiveAlertByExternalIDServ(externalID, nil, fmt.Sprintf("Cleared by SNMP trap %s", trapName))
+		if err != nil {
+			LogService("error", "failed to resolve alert from SNMP trap", map[string]interface{}{"external_id": externalID, "error": err.Error()}, nil, "")
+		} else if resolved {
+			LogService("info", "alert resolved from SNMP trap", map[string]interface{}{"external_id": externalID}, nil, "")
+		}
+		return
+	}
+
+	message := fmt.Sprintf("SNMP trap %s from %s", trapName, hostName)
+	details := renderSNMPTrapVarBinds(trap, true)
+	if details == "" {
+		details = strings.ReplaceAll(renderSNMPTrapVarBinds(trap, false), "\n", ", ")
+	}
+	if details != "" {
+		message += ": " + details
+	}
+	message = truncateRunes(message, snmpTrapMaxMessageLength)
+
+	comment := fmt.Sprintf("source=%s version=%s trap_oid=%s", sourceIP, trap.Version, trap.TrapOID)
+	if varbinds := renderSNMPTrapVarBinds(trap, false); varbinds != "" {
+		comment += "\n" + varbinds
+	}
+
+	req := AlertReq{
+		Message:    message,
+		ExternalID: snmpTrapExternalID(sourceIP, trap.TrapOID, ifIndex),
+		Severity:   severity,
+		AlarmID:    cfg.AlarmID,
+		HostName:   hostName,
+		Comment:    comment,
+	}
+	if err := AddAlertServ(req); err != nil {
+		LogService("error", "failed to create alert from SNMP trap", map[string]interface{}{"source": sourceIP, "trap_oid": trap.TrapOID, "error": err.Error()}, nil, "")
+	}
+}
+
+// matchSNMPTrapRule returns the rule with the longest OID prefix matching the trap OID
+func matchSNMPTrapRule(rules []repository.SNMPTrapRule, trapOID string) (repository.SNMPTrapRule, bool) {
+	var best repository.SNMPTrapRule
+	bestLen := -1
+	for _, rule := range rules {
+		oid := strings.TrimPrefix(strings.TrimSpace(rule.OID), ".")
+		if oid == "" {
+			continue
+		}
+		if trapOID != oid && !strings.HasPrefix(trapOID, oid+".") {
+			continue
+		}
+		if len(oid) > bestLen {
+			best = rule
+			bestLen = len(oid)
+		}
+	}
+	return best, bestLen >= 0
+}
+
+func snmpTrapExternalID(sourceIP, trapOID, ifIndex string) string {
+	externalID := "snmptrap:" + sourceIP + ":" + trapOID
+	if ifIndex != "" {
+		externalID += ":" + ifIndex
+	}
+	return externalID
+}
+
+// snmpTrapVarBindName maps a varbind OID to "name.index" when the column is known
+func snmpTrapVarBindName(oid string) (string, bool) {
+	prefixes := make([]string, 0, len(snmpTrapVarBindNames))
+	for prefix := range snmpTrapVarBindNames {
+		prefixes = append(prefixes, prefix)
+	}
+	// Longest prefix first so ifXTable columns are not shadowed
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(oid, prefix+".") {
+			return snmpTrapVarBindNames[prefix] + strings.TrimPrefix(oid, prefix), true
+		}
+	}
+	return oid, false
+}
+
+func snmpTrapVarBindValue(trap monitors.SNMPTrap, name string) string {
+	for _, vb := range trap.Variables {
+		label, known := snmpTrapVarBindName(vb.OID)
+		if known && strings.HasPrefix(label, name+".") {
+			return vb.Value
+		}
+	}
+	return ""
+}
+
+// renderSNMPTrapVarBinds renders varbinds as "name=value" pairs; knownOnly limits output to named columns
+func renderSNMPTrapVarBinds(trap monitors.SNMPTrap, knownOnly bool) string {
+	parts := make([]string, 0, len(trap.Variables))
+	for _, vb := range trap.Variables {
+		label, known := snmpTrapVarBindName(vb.OID)
+		if knownOnly && !known {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s=%s", label, vb.Value))
+	}
+	if knownOnly {
+		return strings.Join(parts, ", ")
+	}
+	return strings.Join(parts, "\n")
+}
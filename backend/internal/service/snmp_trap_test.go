@@ -0,0 +1,96 @@
+package service
+
+import (
+	"testing"
+
+	"nagare/internal/repository"
+	"nagare/internal/repository/monitors"
+)
+
+func TestMatchSNMPTrapRule(t *testing.T) {
+	rules := []repository.SNMPTrapRule{
+		{OID: "1.3.6.1.4.1.9", Name: "cisco"},
+		{OID: ".1.3.6.1.6.3.1.1.5.3", Name: "linkDown"},
+		{OID: "1.3.6.1.4.1.9.9.41", Name: "cisco syslog"},
+		{OID: "1.3.6.1.4.1.9.9.41", Name: "cisco syslog duplicate"},
+		{OID: "  ", Name: "blank"},
+	}
+
+	cases := []struct {
+		name    string
+		trapOID string
+		want    string
+		wantOK  bool
+	}{
+		{name: "exact match", trapOID: "1.3.6.1.6.3.1.1.5.3", want: "linkDown", wantOK: true},
+		{name: "exact rule does not match children of siblings", trapOID: "1.3.6.1.6.3.1.1.5.30", wantOK: false},
+		{name: "prefix match", trapOID: "1.3.6.1.4.1.9.1.2", want: "cisco", wantOK: true},
+		{name: "longest prefix wins", trapOID: "1.3.6.1.4.1.9.9.41.2.0.1", want: "cisco syslog", wantOK: true},
+		{name: "prefix must end on an arc", trapOID: "1.3.6.1.4.1.99.1", wantOK: false},
+		{name: "no match", trapOID: "1.3.6.1.4.1.2636.4.1", wantOK: false},
+	}
+
+	for _, tc := range cases {
+		rule, ok := matchSNMPTrapRule(rules, tc.trapOID)
+		if ok != tc.wantOK || rule.Name != tc.want {
+			t.Fatalf("%s: got (%q, %v), want (%q, %v)", tc.name, rule.Name, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestSNMPTrapVarBindName(t *testing.T) {
+	cases := []struct {
+		oid       string
+		want      string
+		wantKnown bool
+	}{
+		{oid: "1.3.6.1.2.1.2.2.1.1.3", want: "ifIndex.3", wantKnown: true},
+		{oid: "1.3.6.1.2.1.2.2.1.8.12", want: "ifOperStatus.12", wantKnown: true},
+		{oid: "1.3.6.1.2.1.31.1.1.1.1.3", want: "ifName.3", wantKnown: true},
+		{oid: "1.3.6.1.2.1.31.1.1.1.18.3", want: "ifAlias.3", wantKnown: true},
+		{oid: "1.3.6.1.2.1.2.2.1.1", want: "1.3.6.1.2.1.2.2.1.1", wantKnown: false},
+		{oid: "1.3.6.1.4.1.9.9.41.1.2.3.1.2.5", want: "1.3.6.1.4.1.9.9.41.1.2.3.1.2.5", wantKnown: false},
+	}
+
+	for _, tc := range cases {
+		got, known := snmpTrapVarBindName(tc.oid)
+		if got != tc.want || known != tc.wantKnown {
+			t.Fatalf("snmpTrapVarBindName(%s) = (%s, %v), want (%s, %v)", tc.oid, got, known, tc.want, tc.wantKnown)
+		}
+	}
+}
+
+func TestRenderSNMPTrapVarBinds(t *testing.T) {
+	linkDown := monitors.SNMPTrap{TrapOID: "1.3.6.1.6.3.1.1.5.3", Variables: []monitors.SNMPVarBind{
+		{OID: "1.3.6.1.2.1.2.2.1.1.3", Value: "3"},
+		{OID: "1.3.6.1.2.1.2.2.1.7.3", Value: "1"},
+		{OID: "1.3.6.1.2.1.2.2.1.8.3", Value: "2"},
+		{OID: "1.3.6.1.4.1.9.2.2.1.1.20.3", Value: "administratively down"},
+	}}
+	enterprise := monitors.SNMPTrap{TrapOID: "1.3.6.1.4.1.9.9.41.2.0.1", Variables: []monitors.SNMPVarBind{
+		{OID: "1.3.6.1.4.1.9.9.41.1.2.3.1.2.5", Value: "SYS"},
+		{OID: "1.3.6.1.4.1.9.9.41.1.2.3.1.5.5", Value: "config changed"},
+	}}
+
+	cases := []struct {
+		name      string
+		trap      monitors.SNMPTrap
+		knownOnly bool
+		want      string
+	}{
+		{name: "generic trap known only", trap: linkDown, knownOnly: true, want: "ifIndex.3=3, ifAdminStatus.3=1, ifOperStatus.3=2"},
+		{name: "generic trap all", trap: linkDown, want: "ifIndex.3=3\nifAdminStatus.3=1\nifOperStatus.3=2\n1.3.6.1.4.1.9.2.2.1.1.20.3=administratively down"},
+		{name: "enterprise trap known only", trap: enterprise, knownOnly: true, want: ""},
+		{name: "enterprise trap all", trap: enterprise, want: "1.3.6.1.4.1.9.9.41.1.2.3.1.2.5=SYS\n1.3.6.1.4.1.9.9.41.1.2.3.1.5.5=config changed"},
+		{name: "no varbinds", trap: monitors.SNMPTrap{}, want: ""},
+	}
+
+	for _, tc := range cases {
+		if got := renderSNMPTrapVarBinds(tc.trap, tc.knownOnly); got != tc.want {
+			t.Fatalf("%s: renderSNMPTrapVarBinds = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+	if got := snmpTrapVarBindValue(linkDown, "ifOperStatus"); got != "2" {
+		t.Fatalf("snmpTrapVarBindValue(ifOperStatus) = %q, want 2", got)
+	}
+}
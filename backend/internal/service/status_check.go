@@ -7,6 +7,7 @@ import (
 
 	"nagare/internal/model"
 	"nagare/internal/repository"
+	"nagare/internal/repository/llm"
 	"nagare/internal/repository/monitors"
 
 	"github.com/spf13/viper"
@@ -253,6 +254,7 @@ func checkProviderStatus(provider model.Provider) StatusCheckResult {
 	}
 
 	_ = repository.UpdateProviderStatusDAO(provider.ID, 1)
+	llm.GetService().ResetCircuit(int(provider.ID))
 	result.Status = 1
 	return result
 }
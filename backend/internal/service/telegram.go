@@ -0,0 +1,128 @@
+package service
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"nagare/internal/model"
+	"nagare/internal/repository"
+	mediaSvc "nagare/internal/repository/media"
+)
+
+var telegramObserverOnce sync.Once
+
+func init() {
+	mediaSvc.GlobalTelegramBot.CommandHandler = handleTelegramMessage
+}
+
+// CheckTelegramAuthorization checks if a Telegram user is allowed to execute commands
+func CheckTelegramAuthorization(telegramID string, isGroup bool) bool {
+	if isGroup || telegramID == "" {
+		return false
+	}
+	u, err := repository.GetUserByTelegramIDDAO(telegramID)
+	return err == nil && u.ID > 0 && u.Status == 1
+}
+
+// handleTelegramMessage authorizes the sender and runs the message through the IM command handler
+func handleTelegramMessage(msg mediaSvc.TelegramMessage) (string, error) {
+	text := strings.TrimSpace(msg.Text)
+	isGroup := msg.Chat.IsGroup()
+	if !strings.HasPrefix(text, "/") {
+		// Plain chatter in groups is not addressed to the bot
+		if isGroup {
+			return "", nil
+		}
+		return buildHelpReply(), nil
+	}
+	text = normalizeTelegramCommand(text)
+
+	senderID := ""
+	if msg.From != nil {
+		senderID = strconv.FormatInt(msg.From.ID, 10)
+	}
+	if !CheckTelegramAuthorization(senderID, isGroup) {
+		LogSystem("warn", "Telegram command rejected: unauthorized", map[string]interface{}{
+			"telegram_id": senderID,
+			"chat_id":     msg.Chat.ID,
+			"is_group":    isGroup,
+			"message":     text,
+		}, nil, "")
+		return "You are not authorized to execute commands.", nil
+	}
+
+	result, err := HandleIMCommandWithContext(text, IMCommandContext{
+		MediaType: "telegram",
+		UserID:    senderID,
+		GroupID:   groupIDIfShared(msg.Chat),
+	})
+	if err != nil {
+		LogSystem("error", "failed to process Telegram command", map[string]interface{}{
+			"message": text,
+			"error":   err.Error(),
+		}, nil, "")
+		return "", err
+	}
+	return result.Reply, nil
+}
+
+// normalizeTelegramCommand strips the "@botname" suffix Telegram adds in groups and maps /start to /help
+func normalizeTelegramCommand(text string) string {
+	command, rest, _ := strings.Cut(text, " ")
+	if at := strings.Index(command, "@"); at > 0 {
+		command = command[:at]
+	}
+	if strings.EqualFold(command, "/start") {
+		command = "/help"
+	}
+	if rest = strings.TrimSpace(rest); rest != "" {
+		return command + " " + rest
+	}
+	return command
+}
+
+func groupIDIfShared(chat mediaSvc.TelegramChat) string {
+	if !chat.IsGroup() {
+		return ""
+	}
+	return strconv.FormatInt(chat.ID, 10)
+}
+
+// StartTelegramBot starts receiving Telegram updates and restarts the bot whenever the configuration changes
+func StartTelegramBot() {
+	restartTelegramBot()
+	telegramObserverOnce.Do(func() {
+		repository.RegisterConfigObserver(restartTelegramBot)
+	})
+}
+
+func restartTelegramBot() {
+	settings := mediaSvc.TelegramSettingsFromConfig()
+	if err := mediaSvc.GlobalTelegramBot.Start(); err != nil {
+		LogSystem("error", "failed to start Telegram bot", map[string]interface{}{
+			"mode":  settings.Mode,
+			"error": err.Error(),
+		}, nil, "")
+		return
+	}
+	if settings.Enabled && settings.BotToken != "" {
+		LogSystem("info", "Telegram bot started", map[string]interface{}{"mode": settings.Mode}, nil, "")
+	}
+}
+
+// HandleTelegramWebhookServ verifies the webhook secret and processes an update in the background
+func HandleTelegramWebhookServ(secretToken string, update mediaSvc.TelegramUpdate) error {
+	settings := mediaSvc.TelegramSettingsFromConfig()
+	if !settings.Enabled || settings.Mode != mediaSvc.TelegramModeWebhook {
+		return fmt.Errorf("%w: telegram webhook is not enabled", model.ErrForbidden)
+	}
+	// The endpoint is unauthenticated, so without a configured secret every update is refused
+	if settings.WebhookSecret == "" || subtle.ConstantTimeCompare([]byte(secretToken), []byte(settings.WebhookSecret)) != 1 {
+		return model.ErrUnauthorized
+	}
+	go mediaSvc.GlobalTelegramBot.HandleUpdate(update)
+	return nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"nagare/internal/model"
+	mediaSvc "nagare/internal/repository/media"
+
+	"github.com/spf13/viper"
+)
+
+func TestNormalizeTelegramCommand(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{in: "/status", want: "/status"},
+		{in: "/status@NagareBot", want: "/status"},
+		{in: "/alerts@NagareBot  5 ", want: "/alerts 5"},
+		{in: "/start", want: "/help"},
+		{in: "/START@NagareBot", want: "/help"},
+		{in: "/ack 12 disk replaced", want: "/ack 12 disk replaced"},
+		{in: "@NagareBot", want: "@NagareBot"},
+		{in: "hello there", want: "hello there"},
+		{in: "", want: ""},
+	}
+
+	for _, tc := range cases {
+		if got := normalizeTelegramCommand(tc.in); got != tc.want {
+			t.Fatalf("normalizeTelegramCommand(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestHandleTelegramWebhookServRejects(t *testing.T) {
+	defer func() {
+		viper.Set("telegram.enabled", nil)
+		viper.Set("telegram.mode", nil)
+		viper.Set("telegram.webhook_secret", nil)
+	}()
+
+	cases := []struct {
+		name    string
+		enabled bool
+		mode    string
+		secret  string
+		token   string
+		want    error
+	}{
+		{name: "disabled", enabled: false, mode: mediaSvc.TelegramModeWebhook, secret: "s3cret", token: "s3cret", want: model.ErrForbidden},
+		{name: "polling mode", enabled: true, mode: mediaSvc.TelegramModePolling, secret: "s3cret", token: "s3cret", want: model.ErrForbidden},
+		{name: "no secret configured", enabled: true, mode: mediaSvc.TelegramModeWebhook, secret: "", token: "", want: model.ErrUnauthorized},
+		{name: "missing token", enabled: true, mode: mediaSvc.TelegramModeWebhook, secret: "s3cret", token: "", want: model.ErrUnauthorized},
+		{name: "wrong token", enabled: true, mode: mediaSvc.TelegramModeWebhook, secret: "s3cret", token: "guess", want: model.ErrUnauthorized},
+	}
+
+	for _, tc := range cases {
+		viper.Set("telegram.enabled", tc.enabled)
+		viper.Set("telegram.mode", tc.mode)
+		viper.Set("telegram.webhook_secret", tc.secret)
+		if err := HandleTelegramWebhookServ(tc.token, mediaSvc.TelegramUpdate{}); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: got %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
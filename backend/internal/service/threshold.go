@@ -93,12 +93,12 @@ func evaluateThreshold(host model.Host, item model.Item) {
 		return
 	}
 
-	_, _ = ResolveActiveAlertByExternalIDServ(externalID, fmt.Sprintf("Threshold recovered for item %s on %s", item.Name, host.Name))
+	_, _ = ResolveActiveAlertByExternalIDServ(externalID, nil, fmt.Sprintf("Threshold recovered for item %s on %s", item.Name, host.Name))
 }
 
 func triggerAlert(host model.Host, item model.Item, message string, severity int, externalID string) {
 	if strings.TrimSpace(externalID) != "" {
-		if active, err := repository.FindLatestUnresolvedAlertByExternalIDDAO(externalID); err == nil && active.ID > 0 {
+		if active, err := repository.FindLatestUnresolvedAlertByExternalIDDAO(externalID, nil); err == nil && active.ID > 0 {
 			return
 		}
 	}
@@ -118,22 +118,23 @@ func triggerAlert(host model.Host, item model.Item, message string, severity int
 		}
 	}
 
-	alert := model.Alert{
+	// Go through the shared path so threshold alerts are fingerprinted, folded, silenced and correlated like
+	// every other source
+	if err := AddAlertServ(AlertReq{
 		Message:    message,
 		ExternalID: strings.TrimSpace(externalID),
 		Severity:   severity,
 		Status:     0,
-		ItemID:     &item.ID,
+		ItemID:     item.ID,
+		HostName:   host.Name,
+		ItemName:   item.Name,
 		Comment:    fmt.Sprintf("Automatically detected by Nagare Threshold Engine at %s", time.Now().Format(time.RFC1123)),
+	}); err != nil {
+		LogService("error", "failed to create threshold alert", map[string]interface{}{
+			"item_id": item.ID,
+			"error":   err.Error(),
+		}, nil, "")
 	}
-
-	_ = repository.AddAlertDAO(&alert)
-
-	// Site Message for Real-time notification
-	_ = CreateSiteMessageServ("Threshold Alert", message, "alert", severity, nil)
-
-	// Async AI Analysis and Trigger execution
-	go analyzeAndNotifyAlert(alert)
 }
 
 func pointerToInt(i int) *int {
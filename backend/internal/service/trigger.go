@@ -162,11 +162,32 @@ func UpdateTriggerServ(id uint, req TriggerReq) error {
 		return err
 	}
 	// No recompute needed as status is simple
+
+	// An alert raised under the old settings resolves once the trigger no longer covers it
+	updated.ID = id
+	switch {
+	case updated.Enabled != 1:
+		resolveItemTriggerAlert(existing, fmt.Sprintf("Resolved because trigger %s was disabled", existing.Name))
+	case existing.ItemID != nil && *existing.ItemID != *updated.ItemID:
+		resolveItemTriggerAlert(existing, fmt.Sprintf("Resolved because trigger %s was moved to another item", existing.Name))
+	default:
+		if item, err := repository.GetItemByIDDAO(*updated.ItemID); err == nil && !matchItemTrigger(updated, item) {
+			resolveItemTriggerAlert(updated, fmt.Sprintf("Resolved by internal trigger recovery: %s", updated.Name))
+		}
+	}
 	return nil
 }
 
 func DeleteTriggerByIDServ(id uint) error {
-	return repository.DeleteTriggerByIDDAO(id)
+	existing, err := repository.GetTriggerByIDDAO(id)
+	if err != nil {
+		return err
+	}
+	if err := repository.DeleteTriggerByIDDAO(id); err != nil {
+		return err
+	}
+	resolveItemTriggerAlert(existing, fmt.Sprintf("Resolved because trigger %s was deleted", existing.Name))
+	return nil
 }
 
 func triggerToResp(trigger model.Trigger) TriggerResp {
@@ -193,9 +214,12 @@ func ExecuteTriggersForItem(item model.Item) {
 	}
 
 	for _, trigger := range triggers {
-		externalID := fmt.Sprintf("internal-trigger:%d:item:%d", trigger.ID, item.ID)
+		if trigger.ItemID == nil || *trigger.ItemID != item.ID {
+			continue
+		}
+		externalID := itemTriggerExternalID(trigger.ID, item.ID)
 		if !matchItemTrigger(trigger, item) {
-			_, _ = ResolveActiveAlertByExternalIDServ(externalID, fmt.Sprintf("Resolved by internal trigger recovery: %s", trigger.Name))
+			_, _ = ResolveActiveAlertByExternalIDServ(externalID, nil, fmt.Sprintf("Resolved by internal trigger recovery: %s", trigger.Name))
 			continue
 		}
 		// Generate alert if item trigger matches
@@ -203,10 +227,25 @@ func ExecuteTriggersForItem(item model.Item) {
 	}
 }
 
+// itemTriggerExternalID ties an alert to the trigger and item that raised it
+func itemTriggerExternalID(triggerID, itemID uint) string {
+	return fmt.Sprintf("internal-trigger:%d:item:%d", triggerID, itemID)
+}
+
+// resolveItemTriggerAlert resolves the active alert a trigger raised for its item, sending recovery messages
+func resolveItemTriggerAlert(trigger model.Trigger, comment string) {
+	if trigger.ItemID == nil || *trigger.ItemID == 0 {
+		return
+	}
+	if _, err := ResolveActiveAlertByExternalIDServ(itemTriggerExternalID(trigger.ID, *trigger.ItemID), nil, comment); err != nil {
+		LogService("warn", "failed to resolve item trigger alert", map[string]interface{}{"trigger_id": trigger.ID, "error": err.Error()}, nil, "")
+	}
+}
+
 // generateAlertFromItemTrigger creates an alert when an item trigger matches
 func generateAlertFromItemTrigger(trigger model.Trigger, item model.Item, externalID string) {
 	if strings.TrimSpace(externalID) != "" {
-		if active, err := repository.FindLatestUnresolvedAlertByExternalIDDAO(externalID); err == nil && active.ID > 0 {
+		if active, err := repository.FindLatestUnresolvedAlertByExternalIDDAO(externalID, nil); err == nil && active.ID > 0 {
 			return
 		}
 	}
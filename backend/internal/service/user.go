@@ -26,17 +26,18 @@ import (
 )
 
 type UserRequest struct {
-	Username     string `json:"username"`
-	Password     string `json:"password"`
-	Privileges   int    `json:"privileges"`
-	Status       *int   `json:"status"`
-	Email        string `json:"email"`
-	Phone        string `json:"phone"`
-	Avatar       string `json:"avatar"`
-	Address      string `json:"address"`
-	Introduction string `json:"introduction"`
-	Nickname     string `json:"nickname"`
-	QQ           string `json:"qq"`
+	Username     string  `json:"username"`
+	Password     string  `json:"password"`
+	Privileges   int     `json:"privileges"`
+	Status       *int    `json:"status"`
+	Email        string  `json:"email"`
+	Phone        string  `json:"phone"`
+	Avatar       string  `json:"avatar"`
+	Address      string  `json:"address"`
+	Introduction string  `json:"introduction"`
+	Nickname     string  `json:"nickname"`
+	QQ           string  `json:"qq"`
+	TelegramID   *string `json:"telegram_id"` // nil keeps the current value
 }
 
 type UserResponse struct {
@@ -52,6 +53,7 @@ type UserResponse struct {
 	Introduction string `json:"introduction"`
 	Nickname     string `json:"nickname"`
 	QQ           string `json:"qq"`
+	TelegramID   string `json:"telegram_id"`
 }
 
 type LoginResponse struct {
@@ -162,6 +164,9 @@ func AddUserServ(req UserRequest) error {
 		Nickname:     req.Nickname,
 		QQ:           req.QQ,
 	}
+	if req.TelegramID != nil {
+		user.TelegramID = strings.TrimSpace(*req.TelegramID)
+	}
 	return repository.AddUserDAO(user)
 }
 
@@ -215,6 +220,9 @@ func UpdateUserServ(id int, req UserRequest) error {
 	if req.QQ != "" {
 		user.QQ = req.QQ
 	}
+	if req.TelegramID != nil {
+		user.TelegramID = strings.TrimSpace(*req.TelegramID)
+	}
 
 	return repository.UpdateUserDAO(id, user)
 }
@@ -239,6 +247,9 @@ func UpdateUserProfileServ(username string, req UserRequest) error {
 	user.Introduction = req.Introduction
 	user.Nickname = req.Nickname
 	user.QQ = req.QQ
+	if req.TelegramID != nil {
+		user.TelegramID = strings.TrimSpace(*req.TelegramID)
+	}
 
 	if req.Username != "" {
 		user.Username = req.Username
@@ -483,7 +494,9 @@ func RegisterUserServ(req RegisterRequest) error {
 		if req.Code == "" {
 			return fmt.Errorf("verification code is required when email is provided")
 		}
-		return errors.New("email verification is currently disabled")
+		if err := checkRegistrationCode(req.Email, req.Code); err != nil {
+			return err
+		}
 	}
 
 	if _, err := repository.GetUserByUsernameDAO(req.Username); err == nil {
@@ -500,6 +513,9 @@ func RegisterUserServ(req RegisterRequest) error {
 	}); err != nil {
 		return err
 	}
+	if req.Email != "" {
+		consumeRegistrationCode(req.Email)
+	}
 
 	msg := fmt.Sprintf("A new user '%s' has applied for registration.", req.Username)
 	if req.Email != "" {
@@ -517,7 +533,7 @@ func SendRegistrationCodeServ(email string) error {
 		return model.ErrInvalidEmail
 	}
 
-	return errors.New("email verification is currently disabled")
+	return issueRegistrationCode(email)
 }
 
 func ResetPasswordServ(req ResetPasswordRequest) error {
@@ -608,5 +624,6 @@ func userToResp(u model.User) UserResponse {
 		Introduction: u.Introduction,
 		Nickname:     u.Nickname,
 		QQ:           u.QQ,
+		TelegramID:   u.TelegramID,
 	}
 }
@@ -1,4 +1,6 @@
 import request from '@/utils/request'
+import { getToken } from '@/utils/auth'
+import { getApiBaseURL } from '@/utils/network'
 
 export function fetchChatHistory(params) {
   return request({
@@ -16,3 +18,87 @@ export function sendChatMessage(data) {
     timeout: 120000 // 120 seconds timeout for AI processing
   })
 }
+
+export function fetchConversations(params) {
+  return request({
+    url: '/ai/conversations',
+    method: 'get',
+    params
+  })
+}
+
+export function fetchConversation(id) {
+  return request({
+    url: `/ai/conversations/${id}`,
+    method: 'get'
+  })
+}
+
+export function updateConversation(id, data) {
+  return request({
+    url: `/ai/conversations/${id}`,
+    method: 'put',
+    data
+  })
+}
+
+export function deleteConversation(id) {
+  return request({
+    url: `/ai/conversations/${id}`,
+    method: 'delete'
+  })
+}
+
+// streamChatMessage posts a chat and calls onEvent for every server-sent event
+// (delta, reset, tool_start, tool_finish, done, error). Abort the signal to cancel the request.
+export async function streamChatMessage(data, onEvent, signal) {
+  const headers = {
+    'Content-Type': 'application/json',
+    Accept: 'text/event-stream',
+    'X-Tunnel-Skip-AntiPhishing-Page': 'true'
+  }
+  const token = getToken()
+  if (token) {
+    headers.Authorization = `Bearer ${token}`
+  }
+  const response = await fetch(`${getApiBaseURL()}/api/v1/ai/chats/stream`, {
+    method: 'POST',
+    headers,
+    body: JSON.stringify(data),
+    signal
+  })
+  if (!response.ok || !response.body) {
+    let message = `HTTP ${response.status}`
+    try {
+      const body = await response.json()
+      message = body.error || body.message || message
+    } catch (e) {
+      // Keep the status text
+    }
+    throw new Error(message)
+  }
+
+  const reader = response.body.getReader()
+  const decoder = new TextDecoder()
+  let buffer = ''
+  for (;;) {
+    const { value, done } = await reader.read()
+    if (done) break
+    buffer += decoder.decode(value, { stream: true })
+    let boundary
+    while ((boundary = buffer.indexOf('\n\n')) !== -1) {
+      const block = buffer.slice(0, boundary)
+      buffer = buffer.slice(boundary + 2)
+      const dataLines = block.split('\n').filter(line => line.startsWith('data:')).map(line => line.slice(5).trim())
+      if (dataLines.length === 0) continue
+      let event
+      try {
+        event = JSON.parse(dataLines.join('\n'))
+      } catch (e) {
+        console.error('Invalid chat stream event:', e)
+        continue
+      }
+      onEvent(event)
+    }
+  }
+}
@@ -210,6 +210,11 @@ const messages = {
       loadingHistory: 'Loading history...',
       historyLoaded: 'History loaded',
       thinking: 'Thinking...',
+      toolRunning: 'Running {tool}...',
+      toolDone: 'Ran {tool}',
+      toolFailed: '{tool} failed',
+      newConversation: 'New conversation',
+      deleteConversation: 'Delete',
       placeholder: 'Ask a question...',
       send: 'Send',
       empty: 'Start a conversation to see messages here.',
@@ -353,6 +358,7 @@ const messages = {
       aiProviderId: 'Provider ID',
       aiModel: 'Model',
       aiTimeout: 'Timeout (seconds)',
+      aiChatHistoryTokenBudget: 'Chat history token budget',
       aiMinSeverity: 'Min Severity',
       aiNotificationGuard: 'AI Notification Guard',
       aiLanguage: 'AI Service Language',
@@ -380,6 +386,7 @@ const messages = {
       email: 'Email',
       phone: 'Phone',
       qq: 'QQ Number',
+      telegramId: 'Telegram User ID',
       avatar: 'Avatar',
       emailInvalid: 'Please enter a valid email address',
       avatarHelp: 'PNG, JPG, GIF, or WebP. Max 5MB.',
@@ -1619,6 +1626,11 @@ const messages = {
       loadingHistory: '加载历史记录...',
       historyLoaded: '历史记录已加载',
       thinking: '思考中...',
+      toolRunning: '正在调用 {tool}...',
+      toolDone: '已调用 {tool}',
+      toolFailed: '{tool} 调用失败',
+      newConversation: '新建对话',
+      deleteConversation: '删除',
       placeholder: '请输入问题...',
       send: '发送',
       empty: '开始对话后将在此显示消息。',
@@ -1762,6 +1774,7 @@ const messages = {
       aiProviderId: '供应商 ID',
       aiModel: '模型',
       aiTimeout: '超时（秒）',
+      aiChatHistoryTokenBudget: '对话历史 Token 预算',
       aiMinSeverity: '最小严重级别',
       aiNotificationGuard: 'AI 告警防护',
       aiLanguage: 'AI 服务语言',
@@ -1789,6 +1802,7 @@ const messages = {
       email: '邮箱',
       phone: '电话',
       qq: 'QQ 号码',
+      telegramId: 'Telegram 用户 ID',
       avatar: '头像',
       emailInvalid: '请输入有效的邮箱地址',
       avatarHelp: '支持 PNG、JPG、GIF 或 WebP，最大 5MB。',